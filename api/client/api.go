@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
 	venusTypes "github.com/filecoin-project/venus/pkg/types"
 	"github.com/ipfs/go-cid"
 	"golang.org/x/xerrors"
@@ -33,15 +34,24 @@ type IMessager interface {
 	ReplaceMessage(ctx context.Context, id string, auto bool, maxFee string, gasLimit int64, gasPremium string, gasFeecap string) (cid.Cid, error) //perm:admin
 	RepublishMessage(ctx context.Context, id string) (struct{}, error)                                                                             //perm:admin
 	MarkBadMessage(ctx context.Context, id string) (struct{}, error)                                                                               //perm:admin
-
-	SaveWallet(ctx context.Context, wallet *types.Wallet) (types.UUID, error)            //perm:admin
-	GetWalletByName(ctx context.Context, name string) (*types.Wallet, error)             //perm:admin
-	GetWalletByID(ctx context.Context, id types.UUID) (*types.Wallet, error)             //perm:admin
-	HasWallet(ctx context.Context, name string) (bool, error)                            //perm:admin
-	ListWallet(ctx context.Context) ([]*types.Wallet, error)                             //perm:admin
-	ListRemoteWalletAddress(ctx context.Context, name string) ([]address.Address, error) //perm:admin
-	DeleteWallet(ctx context.Context, name string) (string, error)                       //perm:admin
-	UpdateWallet(ctx context.Context, wallet *types.Wallet) (string, error)              //perm:admin
+	ListMpoolConflict(ctx context.Context) ([]*types.MpoolConflict, error)                                                                         //perm:admin
+	Backfill(ctx context.Context, fromHeight, toHeight abi.ChainEpoch) (*types.BackfillResult, error)                                              //perm:admin
+	GetHeadQueueState(ctx context.Context) (*types.HeadQueueState, error)                                                                          //perm:read
+	ArchiveMessage(ctx context.Context) (*types.ArchiveResult, error)                                                                              //perm:admin
+	ListAuditLog(ctx context.Context, filter *types.AuditLogFilter) ([]*types.AuditLog, error)                                                     //perm:admin
+	ExportOfflineMessage(ctx context.Context, walletName string) ([]*types.OfflineSignMessage, error)                                              //perm:admin
+	ImportOfflineSignature(ctx context.Context, msgs []*types.OfflineSignMessage) ([]*types.OfflineImportResult, error)                            //perm:admin
+
+	SaveWallet(ctx context.Context, wallet *types.Wallet) (types.UUID, error)                             //perm:admin
+	GetWalletByName(ctx context.Context, name string) (*types.Wallet, error)                              //perm:admin
+	GetWalletByID(ctx context.Context, id types.UUID) (*types.Wallet, error)                              //perm:admin
+	HasWallet(ctx context.Context, name string) (bool, error)                                             //perm:admin
+	ListWallet(ctx context.Context) ([]*types.Wallet, error)                                              //perm:admin
+	ListRemoteWalletAddress(ctx context.Context, name string) ([]address.Address, error)                  //perm:admin
+	ScanWallet(ctx context.Context, name string) ([]address.Address, error)                               //perm:admin
+	SetWalletSignLimit(ctx context.Context, name string, concurrency int, rate float64) (struct{}, error) //perm:admin
+	DeleteWallet(ctx context.Context, name string) (string, error)                                        //perm:admin
+	UpdateWallet(ctx context.Context, wallet *types.Wallet) (string, error)                               //perm:admin
 
 	SaveAddress(ctx context.Context, address *types.Address) (string, error)                      //perm:admin
 	GetAddress(ctx context.Context, addr address.Address) (*types.Address, error)                 //perm:admin
@@ -50,9 +60,13 @@ type IMessager interface {
 	UpdateNonce(ctx context.Context, addr address.Address, nonce uint64) (address.Address, error) //perm:admin
 	DeleteAddress(ctx context.Context, addr address.Address) (address.Address, error)             //perm:admin
 
-	GetSharedParams(ctx context.Context) (*types.SharedParams, error)                             //perm:admin
-	SetSharedParams(ctx context.Context, params *types.SharedParams) (*types.SharedParams, error) //perm:admin
-	RefreshSharedParams(ctx context.Context) (struct{}, error)                                    //perm:admin
+	GetSharedParams(ctx context.Context) (*types.SharedParams, error)                                             //perm:admin
+	SetSharedParams(ctx context.Context, params *types.SharedParams) (*types.SharedParams, error)                 //perm:admin
+	RefreshSharedParams(ctx context.Context) (struct{}, error)                                                    //perm:admin
+	SetSharedParamsWithComment(ctx context.Context, params *types.SharedParams, comment string) (struct{}, error) //perm:admin
+	ListParamsVersion(ctx context.Context, target string, limit int) ([]*types.ParamsVersion, error)              //perm:admin
+	DiffParamsVersion(ctx context.Context, target string, from, to int64) ([]*types.ParamsChange, error)          //perm:admin
+	RollbackSharedParams(ctx context.Context, version int64, comment string) (*types.SharedParams, error)         //perm:admin
 
 	SaveNode(ctx context.Context, node *types.Node) (struct{}, error) //perm:admin
 	GetNode(ctx context.Context, name string) (*types.Node, error)    //perm:admin
@@ -60,12 +74,21 @@ type IMessager interface {
 	ListNode(ctx context.Context) ([]*types.Node, error)              //perm:admin
 	DeleteNode(ctx context.Context, name string) (struct{}, error)    //perm:admin
 
-	GetWalletAddress(ctx context.Context, walletName string, addr address.Address) (*types.WalletAddress, error)       //perm:admin
-	ForbiddenAddress(ctx context.Context, walletName string, addr address.Address) (address.Address, error)            //perm:admin
-	ActiveAddress(ctx context.Context, walletName string, addr address.Address) (address.Address, error)               //perm:admin
-	SetSelectMsgNum(ctx context.Context, walletName string, addr address.Address, num uint64) (address.Address, error) //perm:admin
-	HasWalletAddress(ctx context.Context, walletName string, addr address.Address) (bool, error)                       //perm:read
-	ListWalletAddress(ctx context.Context) ([]*types.WalletAddress, error)                                             //perm:admin
+	GetWalletAddress(ctx context.Context, walletName string, addr address.Address) (*types.WalletAddress, error)                                  //perm:admin
+	ForbiddenAddress(ctx context.Context, walletName string, addr address.Address) (address.Address, error)                                       //perm:admin
+	MigrateAddress(ctx context.Context, fromWallet, toWallet string, addr address.Address) (*types.MigrateAddressResult, error)                   //perm:admin
+	ActiveAddress(ctx context.Context, walletName string, addr address.Address) (address.Address, error)                                          //perm:admin
+	SetSelectMsgNum(ctx context.Context, walletName string, addr address.Address, num uint64) (address.Address, error)                            //perm:admin
+	SetSelectMsgNumWithComment(ctx context.Context, walletName string, addr address.Address, num uint64, comment string) (address.Address, error) //perm:admin
+	RollbackSelectMsgNum(ctx context.Context, walletName string, addr address.Address, version int64, comment string) (uint64, error)             //perm:admin
+	HasWalletAddress(ctx context.Context, walletName string, addr address.Address) (bool, error)                                                  //perm:read
+	ListWalletAddress(ctx context.Context) ([]*types.WalletAddress, error)                                                                        //perm:admin
+
+	PushMultisigPropose(ctx context.Context, params *types.MsigProposeParams) (string, error)                                           //perm:admin
+	PushMultisigApprove(ctx context.Context, id string, signer address.Address, walletName string, meta *types.MsgMeta) (string, error) //perm:admin
+	PushMultisigCancel(ctx context.Context, id string, meta *types.MsgMeta) (string, error)                                             //perm:admin
+	GetMultisigProposal(ctx context.Context, id string) (*types.MsigProposal, error)                                                    //perm:read
+	ListMultisigProposal(ctx context.Context, msig address.Address) ([]*types.MsigProposal, error)                                      //perm:admin
 }
 
 var _ IMessager = (*Message)(nil)
@@ -92,6 +115,13 @@ type Message struct {
 		ReplaceMessage           func(ctx context.Context, id string, auto bool, maxFee string, gasLimit int64, gasPremium string, gasFeecap string) (cid.Cid, error)
 		RepublishMessage         func(ctx context.Context, id string) (struct{}, error)
 		MarkBadMessage           func(ctx context.Context, id string) (struct{}, error)
+		ListMpoolConflict        func(ctx context.Context) ([]*types.MpoolConflict, error)
+		Backfill                 func(ctx context.Context, fromHeight, toHeight abi.ChainEpoch) (*types.BackfillResult, error)
+		GetHeadQueueState        func(ctx context.Context) (*types.HeadQueueState, error)
+		ArchiveMessage           func(ctx context.Context) (*types.ArchiveResult, error)
+		ListAuditLog             func(ctx context.Context, filter *types.AuditLogFilter) ([]*types.AuditLog, error)
+		ExportOfflineMessage     func(ctx context.Context, walletName string) ([]*types.OfflineSignMessage, error)
+		ImportOfflineSignature   func(ctx context.Context, msgs []*types.OfflineSignMessage) ([]*types.OfflineImportResult, error)
 
 		SaveWallet              func(ctx context.Context, wallet *types.Wallet) (types.UUID, error)
 		GetWalletByName         func(ctx context.Context, name string) (*types.Wallet, error)
@@ -99,6 +129,8 @@ type Message struct {
 		HasWallet               func(ctx context.Context, name string) (bool, error)
 		ListWallet              func(ctx context.Context) ([]*types.Wallet, error)
 		ListRemoteWalletAddress func(ctx context.Context, name string) ([]address.Address, error)
+		ScanWallet              func(ctx context.Context, name string) ([]address.Address, error)
+		SetWalletSignLimit      func(ctx context.Context, name string, concurrency int, rate float64) (struct{}, error)
 		DeleteWallet            func(ctx context.Context, name string) (string, error)
 		UpdateWallet            func(ctx context.Context, wallet *types.Wallet) (string, error)
 
@@ -109,9 +141,13 @@ type Message struct {
 		UpdateNonce   func(ctx context.Context, addr address.Address, nonce uint64) (address.Address, error)
 		DeleteAddress func(ctx context.Context, addr address.Address) (address.Address, error)
 
-		GetSharedParams     func(context.Context) (*types.SharedParams, error)
-		SetSharedParams     func(context.Context, *types.SharedParams) (*types.SharedParams, error)
-		RefreshSharedParams func(ctx context.Context) (struct{}, error)
+		GetSharedParams            func(context.Context) (*types.SharedParams, error)
+		SetSharedParams            func(context.Context, *types.SharedParams) (*types.SharedParams, error)
+		RefreshSharedParams        func(ctx context.Context) (struct{}, error)
+		SetSharedParamsWithComment func(ctx context.Context, params *types.SharedParams, comment string) (struct{}, error)
+		ListParamsVersion          func(ctx context.Context, target string, limit int) ([]*types.ParamsVersion, error)
+		DiffParamsVersion          func(ctx context.Context, target string, from, to int64) ([]*types.ParamsChange, error)
+		RollbackSharedParams       func(ctx context.Context, version int64, comment string) (*types.SharedParams, error)
 
 		SaveNode   func(ctx context.Context, node *types.Node) (struct{}, error)
 		GetNode    func(ctx context.Context, name string) (*types.Node, error)
@@ -119,12 +155,21 @@ type Message struct {
 		ListNode   func(ctx context.Context) ([]*types.Node, error)
 		DeleteNode func(ctx context.Context, name string) (struct{}, error)
 
-		GetWalletAddress  func(ctx context.Context, walletName string, addr address.Address) (*types.WalletAddress, error)
-		ForbiddenAddress  func(ctx context.Context, walletName string, addr address.Address) (address.Address, error)
-		ActiveAddress     func(ctx context.Context, walletName string, addr address.Address) (address.Address, error)
-		SetSelectMsgNum   func(ctx context.Context, walletName string, addr address.Address, num uint64) (address.Address, error)
-		HasWalletAddress  func(ctx context.Context, walletName string, addr address.Address) (bool, error)
-		ListWalletAddress func(ctx context.Context) ([]*types.WalletAddress, error)
+		GetWalletAddress           func(ctx context.Context, walletName string, addr address.Address) (*types.WalletAddress, error)
+		ForbiddenAddress           func(ctx context.Context, walletName string, addr address.Address) (address.Address, error)
+		MigrateAddress             func(ctx context.Context, fromWallet, toWallet string, addr address.Address) (*types.MigrateAddressResult, error)
+		ActiveAddress              func(ctx context.Context, walletName string, addr address.Address) (address.Address, error)
+		SetSelectMsgNum            func(ctx context.Context, walletName string, addr address.Address, num uint64) (address.Address, error)
+		SetSelectMsgNumWithComment func(ctx context.Context, walletName string, addr address.Address, num uint64, comment string) (address.Address, error)
+		RollbackSelectMsgNum       func(ctx context.Context, walletName string, addr address.Address, version int64, comment string) (uint64, error)
+		HasWalletAddress           func(ctx context.Context, walletName string, addr address.Address) (bool, error)
+		ListWalletAddress          func(ctx context.Context) ([]*types.WalletAddress, error)
+
+		PushMultisigPropose  func(ctx context.Context, params *types.MsigProposeParams) (string, error)
+		PushMultisigApprove  func(ctx context.Context, id string, signer address.Address, walletName string, meta *types.MsgMeta) (string, error)
+		PushMultisigCancel   func(ctx context.Context, id string, meta *types.MsgMeta) (string, error)
+		GetMultisigProposal  func(ctx context.Context, id string) (*types.MsigProposal, error)
+		ListMultisigProposal func(ctx context.Context, msig address.Address) ([]*types.MsigProposal, error)
 	}
 }
 
@@ -204,6 +249,34 @@ func (message *Message) MarkBadMessage(ctx context.Context, id string) (struct{}
 	return message.Internal.MarkBadMessage(ctx, id)
 }
 
+func (message *Message) ListMpoolConflict(ctx context.Context) ([]*types.MpoolConflict, error) {
+	return message.Internal.ListMpoolConflict(ctx)
+}
+
+func (message *Message) Backfill(ctx context.Context, fromHeight, toHeight abi.ChainEpoch) (*types.BackfillResult, error) {
+	return message.Internal.Backfill(ctx, fromHeight, toHeight)
+}
+
+func (message *Message) GetHeadQueueState(ctx context.Context) (*types.HeadQueueState, error) {
+	return message.Internal.GetHeadQueueState(ctx)
+}
+
+func (message *Message) ArchiveMessage(ctx context.Context) (*types.ArchiveResult, error) {
+	return message.Internal.ArchiveMessage(ctx)
+}
+
+func (message *Message) ListAuditLog(ctx context.Context, filter *types.AuditLogFilter) ([]*types.AuditLog, error) {
+	return message.Internal.ListAuditLog(ctx, filter)
+}
+
+func (message *Message) ExportOfflineMessage(ctx context.Context, walletName string) ([]*types.OfflineSignMessage, error) {
+	return message.Internal.ExportOfflineMessage(ctx, walletName)
+}
+
+func (message *Message) ImportOfflineSignature(ctx context.Context, msgs []*types.OfflineSignMessage) ([]*types.OfflineImportResult, error) {
+	return message.Internal.ImportOfflineSignature(ctx, msgs)
+}
+
 func (message *Message) WaitMessage(ctx context.Context, id string, confidence uint64) (*types.Message, error) {
 	tm := time.NewTicker(time.Second * 30)
 	defer tm.Stop()
@@ -223,6 +296,8 @@ func (message *Message) WaitMessage(ctx context.Context, id string, confidence u
 			//OffChain
 			case types.FillMsg:
 				fallthrough
+			case types.OfflineSignMsg:
+				fallthrough
 			case types.UnFillMsg:
 				fallthrough
 			case types.UnKnown:
@@ -272,6 +347,14 @@ func (message *Message) ListRemoteWalletAddress(ctx context.Context, name string
 	return message.Internal.ListRemoteWalletAddress(ctx, name)
 }
 
+func (message *Message) ScanWallet(ctx context.Context, name string) ([]address.Address, error) {
+	return message.Internal.ScanWallet(ctx, name)
+}
+
+func (message *Message) SetWalletSignLimit(ctx context.Context, name string, concurrency int, rate float64) (struct{}, error) {
+	return message.Internal.SetWalletSignLimit(ctx, name, concurrency, rate)
+}
+
 func (message *Message) ListWallet(ctx context.Context) ([]*types.Wallet, error) {
 	return message.Internal.ListWallet(ctx)
 }
@@ -324,6 +407,22 @@ func (message *Message) RefreshSharedParams(ctx context.Context) (struct{}, erro
 	return message.Internal.RefreshSharedParams(ctx)
 }
 
+func (message *Message) SetSharedParamsWithComment(ctx context.Context, params *types.SharedParams, comment string) (struct{}, error) {
+	return message.Internal.SetSharedParamsWithComment(ctx, params, comment)
+}
+
+func (message *Message) ListParamsVersion(ctx context.Context, target string, limit int) ([]*types.ParamsVersion, error) {
+	return message.Internal.ListParamsVersion(ctx, target, limit)
+}
+
+func (message *Message) DiffParamsVersion(ctx context.Context, target string, from, to int64) ([]*types.ParamsChange, error) {
+	return message.Internal.DiffParamsVersion(ctx, target, from, to)
+}
+
+func (message *Message) RollbackSharedParams(ctx context.Context, version int64, comment string) (*types.SharedParams, error) {
+	return message.Internal.RollbackSharedParams(ctx, version, comment)
+}
+
 /////// node info ///////
 
 func (message *Message) SaveNode(ctx context.Context, node *types.Node) (struct{}, error) {
@@ -352,6 +451,10 @@ func (message *Message) ForbiddenAddress(ctx context.Context, walletName string,
 	return message.Internal.ForbiddenAddress(ctx, walletName, addr)
 }
 
+func (message *Message) MigrateAddress(ctx context.Context, fromWallet, toWallet string, addr address.Address) (*types.MigrateAddressResult, error) {
+	return message.Internal.MigrateAddress(ctx, fromWallet, toWallet, addr)
+}
+
 func (message *Message) ActiveAddress(ctx context.Context, walletName string, addr address.Address) (address.Address, error) {
 	return message.Internal.ActiveAddress(ctx, walletName, addr)
 }
@@ -360,6 +463,14 @@ func (message *Message) SetSelectMsgNum(ctx context.Context, walletName string,
 	return message.Internal.SetSelectMsgNum(ctx, walletName, addr, num)
 }
 
+func (message *Message) SetSelectMsgNumWithComment(ctx context.Context, walletName string, addr address.Address, num uint64, comment string) (address.Address, error) {
+	return message.Internal.SetSelectMsgNumWithComment(ctx, walletName, addr, num, comment)
+}
+
+func (message *Message) RollbackSelectMsgNum(ctx context.Context, walletName string, addr address.Address, version int64, comment string) (uint64, error) {
+	return message.Internal.RollbackSelectMsgNum(ctx, walletName, addr, version, comment)
+}
+
 func (message *Message) HasWalletAddress(ctx context.Context, walletName string, addr address.Address) (bool, error) {
 	return message.Internal.HasWalletAddress(ctx, walletName, addr)
 }
@@ -371,3 +482,23 @@ func (message *Message) ListWalletAddress(ctx context.Context) ([]*types.WalletA
 func (message *Message) GetWalletAddress(ctx context.Context, walletName string, addr address.Address) (*types.WalletAddress, error) {
 	return message.Internal.GetWalletAddress(ctx, walletName, addr)
 }
+
+func (message *Message) PushMultisigPropose(ctx context.Context, params *types.MsigProposeParams) (string, error) {
+	return message.Internal.PushMultisigPropose(ctx, params)
+}
+
+func (message *Message) PushMultisigApprove(ctx context.Context, id string, signer address.Address, walletName string, meta *types.MsgMeta) (string, error) {
+	return message.Internal.PushMultisigApprove(ctx, id, signer, walletName, meta)
+}
+
+func (message *Message) PushMultisigCancel(ctx context.Context, id string, meta *types.MsgMeta) (string, error) {
+	return message.Internal.PushMultisigCancel(ctx, id, meta)
+}
+
+func (message *Message) GetMultisigProposal(ctx context.Context, id string) (*types.MsigProposal, error) {
+	return message.Internal.GetMultisigProposal(ctx, id)
+}
+
+func (message *Message) ListMultisigProposal(ctx context.Context, msig address.Address) ([]*types.MsigProposal, error) {
+	return message.Internal.ListMultisigProposal(ctx, msig)
+}
@@ -12,6 +12,7 @@ import (
 type Address struct {
 	BaseController
 	AddressService *service.AddressService
+	AuditService   *service.AuditService
 }
 
 func (a Address) SaveAddress(ctx context.Context, address *types.Address) (types.UUID, error) {
@@ -31,9 +32,24 @@ func (a Address) ListAddress(ctx context.Context) ([]*types.Address, error) {
 }
 
 func (a Address) UpdateNonce(ctx context.Context, addr address.Address, nonce uint64) (address.Address, error) {
-	return a.AddressService.UpdateNonce(ctx, addr, nonce)
+	before := a.address(ctx, addr)
+	res, err := a.AddressService.UpdateNonce(ctx, addr, nonce)
+	a.AuditService.Record(ctx, "UpdateNonce", addr.String(), []interface{}{addr, nonce}, before, a.address(ctx, addr), err)
+	return res, err
 }
 
 func (a Address) DeleteAddress(ctx context.Context, addr address.Address) (address.Address, error) {
-	return a.AddressService.DeleteAddress(ctx, addr)
+	before := a.address(ctx, addr)
+	res, err := a.AddressService.DeleteAddress(ctx, addr)
+	a.AuditService.Record(ctx, "DeleteAddress", addr.String(), []interface{}{addr}, before, a.address(ctx, addr), err)
+	return res, err
+}
+
+// address is the audit value of address, nil if it is not found or deleted
+func (a Address) address(ctx context.Context, addr address.Address) interface{} {
+	addrInfo, err := a.AddressService.GetAddress(ctx, addr)
+	if err != nil {
+		return nil
+	}
+	return addrInfo
 }
@@ -0,0 +1,17 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/filecoin-project/venus-messager/service"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+type AuditController struct {
+	BaseController
+	AuditService *service.AuditService
+}
+
+func (auditController AuditController) ListAuditLog(ctx context.Context, filter *types.AuditLogFilter) ([]*types.AuditLog, error) {
+	return auditController.AuditService.ListAuditLog(ctx, filter)
+}
@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"context"
 	"net/http"
 	"strings"
 
@@ -38,6 +39,7 @@ func (jwtFilter *JWTFilter) PreRequest(w http.ResponseWriter, req *http.Request)
 	}
 
 	if ip == "127.0.0.1" {
+		setCaller(req, &types.Caller{Name: "local", IP: ip})
 		return 0, nil
 	}
 
@@ -65,8 +67,14 @@ func (jwtFilter *JWTFilter) PreRequest(w http.ResponseWriter, req *http.Request)
 			w.WriteHeader(401)
 			return 401, xerrors.Errorf("Perm failed (need %s): %s", authMap[method], allow.Perm)
 		}
+		setCaller(req, &types.Caller{Name: allow.Name, Perm: allow.Perm, IP: ip})
 		return 0, nil
 	}
 
 	return 401, xerrors.New("no token in request")
 }
+
+// setCaller save the verified caller in request context, it is used by audit log
+func setCaller(req *http.Request, caller *types.Caller) {
+	*req = *req.WithContext(context.WithValue(req.Context(), types.CallerKey{}, caller))
+}
@@ -5,8 +5,10 @@ import (
 	"time"
 
 	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
 	venusTypes "github.com/filecoin-project/venus/pkg/types"
 	"github.com/ipfs/go-cid"
+	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/venus-messager/service"
 	"github.com/filecoin-project/venus-messager/types"
@@ -14,7 +16,8 @@ import (
 
 type Message struct {
 	BaseController
-	MsgService *service.MessageService
+	MsgService   *service.MessageService
+	AuditService *service.AuditService
 }
 
 func (message Message) PushMessage(ctx context.Context, msg *venusTypes.UnsignedMessage, meta *types.MsgMeta, walletName string) (string, error) {
@@ -89,7 +92,10 @@ func (message Message) UpdateMessageStateByCid(ctx context.Context, cid string,
 }
 
 func (message Message) UpdateMessageStateByID(ctx context.Context, id string, state types.MessageState) (string, error) {
-	return message.MsgService.UpdateMessageStateByID(ctx, id, state)
+	before := message.messageState(ctx, id)
+	res, err := message.MsgService.UpdateMessageStateByID(ctx, id, state)
+	message.AuditService.Record(ctx, "UpdateMessageStateByID", id, []interface{}{id, state}, before, message.messageState(ctx, id), err)
+	return res, err
 }
 
 func (message Message) UpdateAllFilledMessage(ctx context.Context) (int, error) {
@@ -101,7 +107,11 @@ func (message Message) UpdateFilledMessageByID(ctx context.Context, id string) (
 }
 
 func (message Message) ReplaceMessage(ctx context.Context, id string, auto bool, maxFee string, gasLimit int64, gasPremium string, gasFeecap string) (cid.Cid, error) {
-	return message.MsgService.ReplaceMessage(ctx, id, auto, maxFee, gasLimit, gasPremium, gasFeecap)
+	before := message.messageGas(ctx, id)
+	res, err := message.MsgService.ReplaceMessage(ctx, id, auto, maxFee, gasLimit, gasPremium, gasFeecap)
+	message.AuditService.Record(ctx, "ReplaceMessage", id, []interface{}{id, auto, maxFee, gasLimit, gasPremium, gasFeecap},
+		before, message.messageGas(ctx, id), err)
+	return res, err
 }
 
 func (message Message) RepublishMessage(ctx context.Context, id string) (struct{}, error) {
@@ -109,5 +119,78 @@ func (message Message) RepublishMessage(ctx context.Context, id string) (struct{
 }
 
 func (message Message) MarkBadMessage(ctx context.Context, id string) (struct{}, error) {
-	return message.MsgService.MarkBadMessage(ctx, id)
+	before := message.messageState(ctx, id)
+	res, err := message.MsgService.MarkBadMessage(ctx, id)
+	message.AuditService.Record(ctx, "MarkBadMessage", id, []interface{}{id}, before, message.messageState(ctx, id), err)
+	return res, err
+}
+
+func (message Message) ListMpoolConflict(ctx context.Context) ([]*types.MpoolConflict, error) {
+	return message.MsgService.ListMpoolConflict(ctx)
+}
+
+func (message Message) Backfill(ctx context.Context, fromHeight, toHeight abi.ChainEpoch) (*types.BackfillResult, error) {
+	return message.MsgService.Backfill(ctx, fromHeight, toHeight)
+}
+
+func (message Message) GetHeadQueueState(ctx context.Context) (*types.HeadQueueState, error) {
+	return message.MsgService.GetHeadQueueState(ctx)
+}
+
+func (message Message) ArchiveMessage(ctx context.Context) (*types.ArchiveResult, error) {
+	return message.MsgService.ArchiveMessage(ctx)
+}
+
+func (message Message) ExportOfflineMessage(ctx context.Context, walletName string) ([]*types.OfflineSignMessage, error) {
+	return message.MsgService.ExportOfflineMessage(ctx, walletName)
+}
+
+func (message Message) ImportOfflineSignature(ctx context.Context, msgs []*types.OfflineSignMessage) ([]*types.OfflineImportResult, error) {
+	before := make([]interface{}, len(msgs))
+	for i, m := range msgs {
+		before[i] = message.messageState(ctx, m.ID)
+	}
+	results, err := message.MsgService.ImportOfflineSignature(ctx, msgs)
+	for i, m := range msgs {
+		recordErr := err
+		if err == nil && len(results[i].Error) > 0 {
+			recordErr = xerrors.New(results[i].Error)
+		}
+		message.AuditService.Record(ctx, "ImportOfflineSignature", m.ID, []interface{}{m.ID, m.Signature}, before[i], message.messageState(ctx, m.ID), recordErr)
+	}
+	return results, err
+}
+
+// messageState is the audit value of message state, nil if the message is not found
+func (message Message) messageState(ctx context.Context, id string) interface{} {
+	state, err := message.MsgService.GetMessageState(ctx, id)
+	if err != nil {
+		return nil
+	}
+	return types.MsgStateToString(state)
+}
+
+type messageGasValue struct {
+	GasLimit   int64    `json:"gasLimit"`
+	GasFeeCap  string   `json:"gasFeeCap"`
+	GasPremium string   `json:"gasPremium"`
+	SignedCid  *cid.Cid `json:"signedCid"`
+	State      string   `json:"state"`
+	Revision   int64    `json:"revision"`
+}
+
+// messageGas is the audit value of message changed by ReplaceMessage, nil if the message is not found
+func (message Message) messageGas(ctx context.Context, id string) interface{} {
+	msg, err := message.MsgService.GetPrimaryMessageByUid(ctx, id)
+	if err != nil {
+		return nil
+	}
+	return &messageGasValue{
+		GasLimit:   msg.GasLimit,
+		GasFeeCap:  msg.GasFeeCap.String(),
+		GasPremium: msg.GasPremium.String(),
+		SignedCid:  msg.SignedCid,
+		State:      types.MsgStateToString(msg.State),
+		Revision:   msg.Revision,
+	}
 }
@@ -0,0 +1,42 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/filecoin-project/go-address"
+
+	"github.com/filecoin-project/venus-messager/service"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+type MultisigController struct {
+	BaseController
+	MultisigService *service.MultisigService
+	AuditService    *service.AuditService
+}
+
+func (msigController MultisigController) PushMultisigPropose(ctx context.Context, params *types.MsigProposeParams) (string, error) {
+	id, err := msigController.MultisigService.PushMultisigPropose(ctx, params)
+	msigController.AuditService.Record(ctx, "PushMultisigPropose", params.Msig.String(), params, nil, id, err)
+	return id, err
+}
+
+func (msigController MultisigController) PushMultisigApprove(ctx context.Context, id string, signer address.Address, walletName string, meta *types.MsgMeta) (string, error) {
+	msgID, err := msigController.MultisigService.PushMultisigApprove(ctx, id, signer, walletName, meta)
+	msigController.AuditService.Record(ctx, "PushMultisigApprove", id, []interface{}{id, signer, walletName, meta}, nil, msgID, err)
+	return msgID, err
+}
+
+func (msigController MultisigController) PushMultisigCancel(ctx context.Context, id string, meta *types.MsgMeta) (string, error) {
+	msgID, err := msigController.MultisigService.PushMultisigCancel(ctx, id, meta)
+	msigController.AuditService.Record(ctx, "PushMultisigCancel", id, []interface{}{id, meta}, nil, msgID, err)
+	return msgID, err
+}
+
+func (msigController MultisigController) GetMultisigProposal(ctx context.Context, id string) (*types.MsigProposal, error) {
+	return msigController.MultisigService.GetMultisigProposal(ctx, id)
+}
+
+func (msigController MultisigController) ListMultisigProposal(ctx context.Context, msig address.Address) ([]*types.MsigProposal, error) {
+	return msigController.MultisigService.ListMultisigProposal(ctx, msig)
+}
@@ -17,7 +17,7 @@ func (nodeController NodeController) SaveNode(ctx context.Context, node *types.N
 }
 
 func (nodeController NodeController) GetNode(ctx context.Context, name string) (*types.Node, error) {
-	return nodeController.NodeService.GetNode(ctx, name)
+	return redactNode(nodeController.NodeService.GetNode(ctx, name))
 }
 
 func (nodeController NodeController) HasNode(ctx context.Context, name string) (bool, error) {
@@ -25,9 +25,25 @@ func (nodeController NodeController) HasNode(ctx context.Context, name string) (
 }
 
 func (nodeController NodeController) ListNode(ctx context.Context) ([]*types.Node, error) {
-	return nodeController.NodeService.ListNode(ctx)
+	nodes, err := nodeController.NodeService.ListNode(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range nodes {
+		node.Token = types.RedactToken(node.Token)
+	}
+	return nodes, nil
 }
 
 func (nodeController NodeController) DeleteNode(ctx context.Context, name string) (struct{}, error) {
 	return nodeController.NodeService.DeleteNode(ctx, name)
 }
+
+// redactNode hide the token of node returned by api
+func redactNode(node *types.Node, err error) (*types.Node, error) {
+	if err != nil {
+		return nil, err
+	}
+	node.Token = types.RedactToken(node.Token)
+	return node, nil
+}
@@ -10,6 +10,7 @@ import (
 type SharedParamsCtrl struct {
 	BaseController
 	SharedParamsService *service.SharedParamsService
+	AuditService        *service.AuditService
 }
 
 func (spc SharedParamsCtrl) GetSharedParams(ctx context.Context) (*types.SharedParams, error) {
@@ -17,7 +18,41 @@ func (spc SharedParamsCtrl) GetSharedParams(ctx context.Context) (*types.SharedP
 }
 
 func (spc SharedParamsCtrl) SetSharedParams(ctx context.Context, params *types.SharedParams) (struct{}, error) {
-	return spc.SharedParamsService.SetSharedParams(ctx, params)
+	before := spc.sharedParams(ctx)
+	res, err := spc.SharedParamsService.SetSharedParams(ctx, params)
+	spc.AuditService.Record(ctx, "SetSharedParams", types.SharedParamsTarget, []interface{}{params}, before, spc.sharedParams(ctx), err)
+	return res, err
+}
+
+func (spc SharedParamsCtrl) SetSharedParamsWithComment(ctx context.Context, params *types.SharedParams, comment string) (struct{}, error) {
+	before := spc.sharedParams(ctx)
+	res, err := spc.SharedParamsService.SetSharedParamsWithComment(ctx, params, comment)
+	spc.AuditService.Record(ctx, "SetSharedParamsWithComment", types.SharedParamsTarget, []interface{}{params, comment}, before, spc.sharedParams(ctx), err)
+	return res, err
+}
+
+func (spc SharedParamsCtrl) ListParamsVersion(ctx context.Context, target string, limit int) ([]*types.ParamsVersion, error) {
+	return spc.SharedParamsService.ListParamsVersion(ctx, target, limit)
+}
+
+func (spc SharedParamsCtrl) DiffParamsVersion(ctx context.Context, target string, from, to int64) ([]*types.ParamsChange, error) {
+	return spc.SharedParamsService.DiffParamsVersion(ctx, target, from, to)
+}
+
+func (spc SharedParamsCtrl) RollbackSharedParams(ctx context.Context, version int64, comment string) (*types.SharedParams, error) {
+	before := spc.sharedParams(ctx)
+	res, err := spc.SharedParamsService.RollbackSharedParams(ctx, version, comment)
+	spc.AuditService.Record(ctx, "RollbackSharedParams", types.SharedParamsTarget, []interface{}{version, comment}, before, spc.sharedParams(ctx), err)
+	return res, err
+}
+
+// sharedParams is the audit value of shared params, nil if it is not set
+func (spc SharedParamsCtrl) sharedParams(ctx context.Context) interface{} {
+	params, err := spc.SharedParamsService.GetSharedParams(ctx)
+	if err != nil {
+		return nil
+	}
+	return params
 }
 
 func (spc SharedParamsCtrl) RefreshSharedParams(ctx context.Context) (struct{}, error) {
@@ -12,6 +12,8 @@ import (
 type WalletController struct {
 	BaseController
 	WalletService *service.WalletService
+	MsgService    *service.MessageService
+	AuditService  *service.AuditService
 }
 
 func (walletController WalletController) SaveWallet(ctx context.Context, wallet *types.Wallet) (types.UUID, error) {
@@ -19,11 +21,11 @@ func (walletController WalletController) SaveWallet(ctx context.Context, wallet
 }
 
 func (walletController WalletController) GetWalletByName(ctx context.Context, name string) (*types.Wallet, error) {
-	return walletController.WalletService.GetWalletByName(ctx, name)
+	return redactWallet(walletController.WalletService.GetWalletByName(ctx, name))
 }
 
 func (walletController WalletController) GetWalletByID(ctx context.Context, id types.UUID) (*types.Wallet, error) {
-	return walletController.WalletService.GetWalletByID(ctx, id)
+	return redactWallet(walletController.WalletService.GetWalletByID(ctx, id))
 }
 
 func (walletController WalletController) HasWallet(ctx context.Context, name string) (bool, error) {
@@ -31,19 +33,42 @@ func (walletController WalletController) HasWallet(ctx context.Context, name str
 }
 
 func (walletController WalletController) ListWallet(ctx context.Context) ([]*types.Wallet, error) {
-	return walletController.WalletService.ListWallet(ctx)
+	wallets, err := walletController.WalletService.ListWallet(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, wallet := range wallets {
+		wallet.Token = types.RedactToken(wallet.Token)
+	}
+	return wallets, nil
 }
 
 func (walletController WalletController) ListRemoteWalletAddress(ctx context.Context, walletName string) ([]address.Address, error) {
 	return walletController.WalletService.ListRemoteWalletAddress(ctx, walletName)
 }
 
+func (walletController WalletController) ScanWallet(ctx context.Context, name string) ([]address.Address, error) {
+	return walletController.WalletService.ScanWallet(ctx, name)
+}
+
+func (walletController WalletController) SetWalletSignLimit(ctx context.Context, name string, concurrency int, rate float64) (struct{}, error) {
+	before := walletController.signLimit(ctx, name)
+	res, err := walletController.WalletService.SetWalletSignLimit(ctx, name, concurrency, rate)
+	walletController.AuditService.Record(ctx, "SetWalletSignLimit", name, []interface{}{name, concurrency, rate},
+		before, walletController.signLimit(ctx, name), err)
+	return res, err
+}
+
 func (walletController WalletController) DeleteWallet(ctx context.Context, name string) (string, error) {
 	return walletController.WalletService.DeleteWallet(ctx, name)
 }
 
 func (walletController WalletController) ForbiddenAddress(ctx context.Context, walletName string, addr address.Address) (address.Address, error) {
-	return walletController.WalletService.ForbiddenAddress(ctx, walletName, addr)
+	before := walletController.addressState(ctx, walletName, addr)
+	res, err := walletController.WalletService.ForbiddenAddress(ctx, walletName, addr)
+	walletController.AuditService.Record(ctx, "ForbiddenAddress", walletName+"/"+addr.String(), []interface{}{walletName, addr},
+		before, walletController.addressState(ctx, walletName, addr), err)
+	return res, err
 }
 
 func (walletController WalletController) ActiveAddress(ctx context.Context, walletName string, addr address.Address) (address.Address, error) {
@@ -54,6 +79,22 @@ func (walletController WalletController) SetSelectMsgNum(ctx context.Context, wa
 	return walletController.WalletService.SetSelectMsgNum(ctx, walletName, addr, num)
 }
 
+func (walletController WalletController) SetSelectMsgNumWithComment(ctx context.Context, walletName string, addr address.Address, num uint64, comment string) (address.Address, error) {
+	before := walletController.selMsgNum(ctx, walletName, addr)
+	res, err := walletController.WalletService.SetSelectMsgNumWithComment(ctx, walletName, addr, num, comment)
+	walletController.AuditService.Record(ctx, "SetSelectMsgNumWithComment", walletName+"/"+addr.String(), []interface{}{walletName, addr, num, comment},
+		before, walletController.selMsgNum(ctx, walletName, addr), err)
+	return res, err
+}
+
+func (walletController WalletController) RollbackSelectMsgNum(ctx context.Context, walletName string, addr address.Address, version int64, comment string) (uint64, error) {
+	before := walletController.selMsgNum(ctx, walletName, addr)
+	res, err := walletController.WalletService.RollbackSelectMsgNum(ctx, walletName, addr, version, comment)
+	walletController.AuditService.Record(ctx, "RollbackSelectMsgNum", walletName+"/"+addr.String(), []interface{}{walletName, addr, version, comment},
+		before, walletController.selMsgNum(ctx, walletName, addr), err)
+	return res, err
+}
+
 func (walletController WalletController) HasWalletAddress(ctx context.Context, walletName string, addr address.Address) (bool, error) {
 	return walletController.WalletService.HasWalletAddress(ctx, walletName, addr)
 }
@@ -65,3 +106,54 @@ func (walletController WalletController) ListWalletAddress(ctx context.Context)
 func (walletController WalletController) GetWalletAddress(ctx context.Context, walletName string, addr address.Address) (*types.WalletAddress, error) {
 	return walletController.WalletService.GetWalletAddress(ctx, walletName, addr)
 }
+
+func (walletController WalletController) MigrateAddress(ctx context.Context, fromWallet, toWallet string, addr address.Address) (*types.MigrateAddressResult, error) {
+	before := map[string]interface{}{
+		fromWallet: walletController.addressState(ctx, fromWallet, addr),
+		toWallet:   walletController.addressState(ctx, toWallet, addr),
+	}
+	res, err := walletController.MsgService.MigrateAddress(ctx, fromWallet, toWallet, addr)
+	var after interface{}
+	if res != nil {
+		after = res
+	}
+	walletController.AuditService.Record(ctx, "MigrateAddress", fromWallet+"/"+addr.String(), []interface{}{fromWallet, toWallet, addr},
+		before, after, err)
+	return res, err
+}
+
+// addressState is the audit value of wallet address state, nil if it is not found
+func (walletController WalletController) addressState(ctx context.Context, walletName string, addr address.Address) interface{} {
+	wa, err := walletController.WalletService.GetWalletAddress(ctx, walletName, addr)
+	if err != nil {
+		return nil
+	}
+	return types.StateToString(wa.AddressState)
+}
+
+// selMsgNum is the audit value of SelMsgNum of wallet address, nil if the address is not found
+func (walletController WalletController) selMsgNum(ctx context.Context, walletName string, addr address.Address) interface{} {
+	wa, err := walletController.WalletService.GetWalletAddress(ctx, walletName, addr)
+	if err != nil {
+		return nil
+	}
+	return wa.SelMsgNum
+}
+
+// signLimit is the audit value of wallet sign limits, nil if the wallet is not found
+func (walletController WalletController) signLimit(ctx context.Context, name string) interface{} {
+	wallet, err := walletController.WalletService.GetWalletByName(ctx, name)
+	if err != nil {
+		return nil
+	}
+	return map[string]interface{}{"concurrency": wallet.SignConcurrency, "rate": wallet.SignRate}
+}
+
+// redactWallet hide the token of wallet returned by api
+func redactWallet(wallet *types.Wallet, err error) (*types.Wallet, error) {
+	if err != nil {
+		return nil, err
+	}
+	wallet.Token = types.RedactToken(wallet.Token)
+	return wallet, nil
+}
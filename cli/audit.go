@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+var AuditCmds = &cli.Command{
+	Name:  "audit",
+	Usage: "audit log of administrative operations",
+	Subcommands: []*cli.Command{
+		listAuditLogCmd,
+	},
+}
+
+var listAuditLogCmd = &cli.Command{
+	Name:  "list",
+	Usage: "list audit log, the newest first",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "caller",
+			Usage: "name of caller, local for calls from 127.0.0.1",
+		},
+		&cli.StringFlag{
+			Name:  "method",
+			Usage: "api method, eg. MarkBadMessage",
+		},
+		&cli.StringFlag{
+			Name:  "target",
+			Usage: "message id, address or wallet_name/address operated on",
+		},
+		&cli.StringFlag{
+			Name:  "start",
+			Usage: "list logs created after the time, in RFC3339 format, eg. 2021-06-01T00:00:00+08:00",
+		},
+		&cli.StringFlag{
+			Name:  "end",
+			Usage: "list logs created before the time, in RFC3339 format",
+		},
+		&cli.IntFlag{
+			Name:  "limit",
+			Usage: "max number of logs",
+			Value: 100,
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		filter := &types.AuditLogFilter{
+			Caller: ctx.String("caller"),
+			Method: ctx.String("method"),
+			Target: ctx.String("target"),
+			Limit:  ctx.Int("limit"),
+		}
+		if ctx.IsSet("start") {
+			filter.Start, err = time.Parse(time.RFC3339, ctx.String("start"))
+			if err != nil {
+				return xerrors.Errorf("parse start time %v", err)
+			}
+		}
+		if ctx.IsSet("end") {
+			filter.End, err = time.Parse(time.RFC3339, ctx.String("end"))
+			if err != nil {
+				return xerrors.Errorf("parse end time %v", err)
+			}
+		}
+
+		logs, err := client.ListAuditLog(ctx.Context, filter)
+		if err != nil {
+			return err
+		}
+
+		bytes, err := json.MarshalIndent(logs, " ", "\t")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(bytes))
+		return nil
+	},
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+)
+
+var BackfillCmd = &cli.Command{
+	Name:  "backfill",
+	Usage: "rebuild message state from chain data in the height range, eg. after restoring an old db backup",
+	Flags: []cli.Flag{
+		&cli.Int64Flag{
+			Name:     "from-height",
+			Usage:    "height to start from",
+			Required: true,
+		},
+		&cli.Int64Flag{
+			Name:     "to-height",
+			Usage:    "height to end with (included)",
+			Required: true,
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		from := ctx.Int64("from-height")
+		to := ctx.Int64("to-height")
+		if from > to {
+			return xerrors.Errorf("from height %d greater than to height %d", from, to)
+		}
+
+		result, err := client.Backfill(ctx.Context, abi.ChainEpoch(from), abi.ChainEpoch(to))
+		if err != nil {
+			return err
+		}
+
+		bytes, err := json.MarshalIndent(result, " ", "\t")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(bytes))
+		return nil
+	},
+}
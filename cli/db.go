@@ -0,0 +1,259 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus-messager/config"
+	"github.com/filecoin-project/venus-messager/models"
+	"github.com/filecoin-project/venus-messager/models/repo"
+)
+
+var DbCmds = &cli.Command{
+	Name:  "db",
+	Usage: "database schema commands, work on the database in config file directly",
+	Subcommands: []*cli.Command{
+		migrateDbCmd,
+		statusDbCmd,
+		rollbackDbCmd,
+		rotateTokenKeyDbCmd,
+	},
+}
+
+var migrateDbCmd = &cli.Command{
+	Name:  "migrate",
+	Usage: "apply pending migrations",
+	Flags: []cli.Flag{
+		&cli.IntFlag{
+			Name:  "to",
+			Usage: "target version, default to the latest version",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		migrator, closer, err := getMigrator(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		if err := migrator.Migrate(ctx.Int("to")); err != nil {
+			return err
+		}
+		return printMigrationStatus(migrator)
+	},
+}
+
+var statusDbCmd = &cli.Command{
+	Name:  "status",
+	Usage: "show schema version of database and pending migrations",
+	Action: func(ctx *cli.Context) error {
+		migrator, closer, err := getMigrator(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return printMigrationStatus(migrator)
+	},
+}
+
+var rollbackDbCmd = &cli.Command{
+	Name:  "rollback",
+	Usage: "revert applied migrations, default revert the last one",
+	Flags: []cli.Flag{
+		&cli.IntFlag{
+			Name:  "to",
+			Usage: "target version",
+			Value: -1,
+		},
+		&cli.BoolFlag{
+			Name:  "really-do-it",
+			Usage: "required when rollback to version 0, all tables will be dropped",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		migrator, closer, err := getMigrator(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		target := ctx.Int("to")
+		if target < 0 {
+			version, err := migrator.Version()
+			if err != nil {
+				return err
+			}
+			if version == 0 {
+				return xerrors.New("no migration applied")
+			}
+			target = version - 1
+		}
+		if target == 0 && !ctx.Bool("really-do-it") {
+			return xerrors.New("rollback to version 0 drop all tables, pass --really-do-it to confirm")
+		}
+
+		if err := migrator.Rollback(target); err != nil {
+			return err
+		}
+		return printMigrationStatus(migrator)
+	},
+}
+
+var rotateTokenKeyDbCmd = &cli.Command{
+	Name: "rotate-token-key",
+	Usage: "generate a new token key in db.tokenEncryption.keyFile and re-encrypt the tokens of wallets and nodes by it, " +
+		"tokens saved in plaintext are encrypted too. Stop messager before rotating and start it after",
+	Action: func(ctx *cli.Context) error {
+		cfg, err := config.ReadConfig(ctx.String("config"))
+		if err != nil {
+			return err
+		}
+		keyCfg := cfg.DB.TokenEncryption
+		if len(keyCfg.Key) != 0 {
+			return xerrors.New("token key is set in config, move it to db.tokenEncryption.keyFile to rotate")
+		}
+		if len(keyCfg.KeyFile) == 0 {
+			return xerrors.New("db.tokenEncryption.keyFile is not configured")
+		}
+		var oldCipher *repo.TokenCipher
+		if _, err := os.Stat(keyCfg.KeyFile); err == nil {
+			if oldCipher, err = newTokenCipher(&keyCfg); err != nil {
+				return err
+			}
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+
+		// tokens are rotated on the tables directly, the repo must not decrypt them by the key being replaced
+		dbCfg := cfg.DB
+		dbCfg.TokenEncryption = config.TokenEncryptionConfig{}
+		r, err := models.SetDataBase(&dbCfg)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = r.DbClose()
+		}()
+		if err := checkSchemaVersion(r); err != nil {
+			return err
+		}
+
+		newKeyCfg := config.TokenEncryptionConfig{KeyFile: keyCfg.KeyFile + ".new"}
+		key, err := config.GenerateTokenKey()
+		if err != nil {
+			return err
+		}
+		if err := config.WriteTokenKeyFile(newKeyCfg.KeyFile, key); err != nil {
+			return err
+		}
+		newCipher, err := newTokenCipher(&newKeyCfg)
+		if err != nil {
+			return err
+		}
+		count, err := repo.RotateTokenKey(r.GetDb(), oldCipher, newCipher)
+		if err != nil {
+			_ = os.Remove(newKeyCfg.KeyFile)
+			return err
+		}
+		if err := os.Rename(newKeyCfg.KeyFile, keyCfg.KeyFile); err != nil {
+			return xerrors.Errorf("tokens are encrypted by the key in %s, move it to %s: %w", newKeyCfg.KeyFile, keyCfg.KeyFile, err)
+		}
+		fmt.Printf("rotate %d tokens, new key is written to %s\n", count, keyCfg.KeyFile)
+		return nil
+	},
+}
+
+// newTokenCipher return nil if the token key is not configured
+func newTokenCipher(cfg *config.TokenEncryptionConfig) (*repo.TokenCipher, error) {
+	key, err := cfg.ReadKey()
+	if err != nil || key == nil {
+		return nil, err
+	}
+	return repo.NewTokenCipher(key)
+}
+
+// configTokenCipher return the token cipher of config file, nil if the token key is not configured
+func configTokenCipher(ctx *cli.Context) (*repo.TokenCipher, error) {
+	cfg, err := config.ReadConfig(ctx.String("config"))
+	if err != nil {
+		return nil, err
+	}
+	return newTokenCipher(&cfg.DB.TokenEncryption)
+}
+
+func getMigrator(ctx *cli.Context) (*repo.Migrator, func(), error) {
+	r, closer, err := openRepo(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	migrator, err := r.Migrator()
+	if err != nil {
+		closer()
+		return nil, nil, err
+	}
+	return migrator, closer, nil
+}
+
+// openRepo open the database in config file directly
+func openRepo(ctx *cli.Context) (repo.Repo, func(), error) {
+	cfg, err := config.ReadConfig(ctx.String("config"))
+	if err != nil {
+		return nil, nil, err
+	}
+	r, err := models.SetDataBase(&cfg.DB)
+	if err != nil {
+		return nil, nil, err
+	}
+	return r, func() {
+		_ = r.DbClose()
+	}, nil
+}
+
+// openMigratedRepo open the database in config file, the schema must be at the version of binary
+func openMigratedRepo(ctx *cli.Context) (repo.Repo, func(), error) {
+	r, closer, err := openRepo(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkSchemaVersion(r); err != nil {
+		closer()
+		return nil, nil, err
+	}
+	return r, closer, nil
+}
+
+func checkSchemaVersion(r repo.Repo) error {
+	migrator, err := r.Migrator()
+	if err != nil {
+		return err
+	}
+	if err := migrator.Check(); err != nil {
+		return err
+	}
+	version, err := migrator.Version()
+	if err != nil {
+		return err
+	}
+	if version < migrator.Latest() {
+		return xerrors.Errorf("database schema version %d is older than %d, run `db migrate` first", version, migrator.Latest())
+	}
+	return nil
+}
+
+func printMigrationStatus(migrator *repo.Migrator) error {
+	status, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+	bytes, err := json.MarshalIndent(status, " ", "\t")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(bytes))
+	return nil
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/filecoin-project/venus-messager/models"
+)
+
+var ExportCmd = &cli.Command{
+	Name: "export",
+	Usage: "export messages, addresses, wallets, wallet addresses, nodes and shared params in the database of config file as jsonl, " +
+		"the tokens of wallets and nodes are encrypted by the token key in config, or redacted if no key is configured",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "output",
+			Aliases:  []string{"o"},
+			Usage:    "file to write",
+			Required: true,
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		r, closer, err := openMigratedRepo(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		cipher, err := configTokenCipher(ctx)
+		if err != nil {
+			return err
+		}
+
+		file, err := os.OpenFile(ctx.String("output"), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err != nil {
+			return err
+		}
+		defer file.Close() // nolint
+		writer := bufio.NewWriter(file)
+
+		result, err := models.Export(ctx.Context, r, writer, cipher)
+		if err != nil {
+			return err
+		}
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+		if err := file.Sync(); err != nil {
+			return err
+		}
+		return printJSON(result)
+	},
+}
+
+var ImportCmd = &cli.Command{
+	Name: "import",
+	Usage: "import the file written by export into the database of config file, the token key in config must be the one of export. " +
+		"Wallets and nodes whose tokens were redacted are imported without token, delete and add them again with the token",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "input",
+			Aliases:  []string{"i"},
+			Usage:    "file to read",
+			Required: true,
+		},
+		&cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "only check the records and report conflicts",
+		},
+		&cli.BoolFlag{
+			Name:  "skip-conflict",
+			Usage: "skip records conflict with the existing data, by default nothing is imported when any conflict is found",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		r, closer, err := openMigratedRepo(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		cipher, err := configTokenCipher(ctx)
+		if err != nil {
+			return err
+		}
+
+		path := ctx.String("input")
+		result, err := models.Import(ctx.Context, r, func() (io.ReadCloser, error) {
+			return os.Open(path)
+		}, models.ImportOptions{
+			DryRun:       ctx.Bool("dry-run"),
+			SkipConflict: ctx.Bool("skip-conflict"),
+			TokenCipher:  cipher,
+		})
+		if result != nil {
+			if printErr := printJSON(result); printErr != nil {
+				return printErr
+			}
+		}
+		return err
+	},
+}
+
+func printJSON(v interface{}) error {
+	bytes, err := json.MarshalIndent(v, " ", "\t")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(bytes))
+	return nil
+}
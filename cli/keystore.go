@@ -0,0 +1,174 @@
+package cli
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/venus/pkg/crypto"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus-messager/service"
+)
+
+var keystoreFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:     "dir",
+		Usage:    "keystore directory",
+		Required: true,
+	},
+	&cli.StringFlag{
+		Name:  "passphrase-file",
+		Usage: "file holding the passphrase of keystore, default read env " + service.KeystorePassphraseEnv,
+	},
+}
+
+var KeystoreCmds = &cli.Command{
+	Name: "keystore",
+	Usage: "manage keys of local wallet, work on the keystore directory directly, register the directory under " +
+		"wallet.keystore.rootDir of messager by `wallet add --url keystore:<dir>`, " +
+		"messager reads the passphrase from wallet.keystore.passphraseFile or env " + service.KeystorePassphraseEnv,
+	Subcommands: []*cli.Command{
+		generateKeyCmd,
+		importKeyCmd,
+		exportKeyCmd,
+		listKeyCmd,
+		signOfflineCmd,
+	},
+}
+
+var generateKeyCmd = &cli.Command{
+	Name:  "generate",
+	Usage: "generate a new key",
+	Flags: append([]cli.Flag{
+		&cli.StringFlag{
+			Name:  "type",
+			Usage: "key type, bls or secp256k1",
+			Value: "bls",
+		},
+	}, keystoreFlags...),
+	Action: func(ctx *cli.Context) error {
+		var sigType crypto.SigType
+		switch ctx.String("type") {
+		case "bls":
+			sigType = crypto.SigTypeBLS
+		case "secp256k1":
+			sigType = crypto.SigTypeSecp256k1
+		default:
+			return xerrors.Errorf("unsupported key type %s", ctx.String("type"))
+		}
+
+		wallet, err := openKeystore(ctx)
+		if err != nil {
+			return err
+		}
+		addr, err := wallet.WalletNew(ctx.Context, sigType)
+		if err != nil {
+			return err
+		}
+		fmt.Println(addr.String())
+		return nil
+	},
+}
+
+var importKeyCmd = &cli.Command{
+	Name:      "import",
+	Usage:     "import a hex encoded key exported by venus or lotus, read from stdin if file is not passed",
+	ArgsUsage: "[file]",
+	Flags:     keystoreFlags,
+	Action: func(ctx *cli.Context) error {
+		var data []byte
+		var err error
+		if ctx.Args().Present() {
+			data, err = ioutil.ReadFile(ctx.Args().First())
+		} else {
+			data, err = ioutil.ReadAll(os.Stdin)
+		}
+		if err != nil {
+			return err
+		}
+		keyJSON, err := hex.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil {
+			return xerrors.Errorf("decode key %w", err)
+		}
+		var ki crypto.KeyInfo
+		if err := json.Unmarshal(keyJSON, &ki); err != nil {
+			return xerrors.Errorf("unmarshal key %w", err)
+		}
+
+		wallet, err := openKeystore(ctx)
+		if err != nil {
+			return err
+		}
+		addr, err := wallet.WalletImport(ctx.Context, &ki)
+		if err != nil {
+			return err
+		}
+		fmt.Println(addr.String())
+		return nil
+	},
+}
+
+var exportKeyCmd = &cli.Command{
+	Name:      "export",
+	Usage:     "print the hex encoded private key, keep it secret",
+	ArgsUsage: "<address>",
+	Flags:     keystoreFlags,
+	Action: func(ctx *cli.Context) error {
+		if !ctx.Args().Present() {
+			return xerrors.Errorf("must pass address")
+		}
+		addr, err := address.NewFromString(ctx.Args().First())
+		if err != nil {
+			return err
+		}
+
+		wallet, err := openKeystore(ctx)
+		if err != nil {
+			return err
+		}
+		ki, err := wallet.WalletExport(ctx.Context, addr)
+		if err != nil {
+			return err
+		}
+		keyJSON, err := json.Marshal(ki)
+		if err != nil {
+			return err
+		}
+		fmt.Println(hex.EncodeToString(keyJSON))
+		return nil
+	},
+}
+
+var listKeyCmd = &cli.Command{
+	Name:  "list",
+	Usage: "list addresses in keystore",
+	Flags: keystoreFlags,
+	Action: func(ctx *cli.Context) error {
+		wallet, err := openKeystore(ctx)
+		if err != nil {
+			return err
+		}
+		addrs, err := wallet.WalletList(ctx.Context)
+		if err != nil {
+			return err
+		}
+		for _, addr := range addrs {
+			fmt.Println(addr.String())
+		}
+		return nil
+	},
+}
+
+func openKeystore(ctx *cli.Context) (*service.LocalWallet, error) {
+	passphrase, err := service.ReadKeystorePassphrase(ctx.String("passphrase-file"))
+	if err != nil {
+		return nil, err
+	}
+	return service.OpenLocalWallet(ctx.String("dir"), passphrase)
+}
@@ -34,6 +34,11 @@ var MsgCmds = &cli.Command{
 		waitMessagerCmd,
 		republishCmd,
 		markBadCmd,
+		listMpoolConflictCmd,
+		headQueueCmd,
+		archiveCmd,
+		exportOfflineCmd,
+		importOfflineCmd,
 	},
 }
 
@@ -150,6 +155,7 @@ state:
   4:  FailedMsg
   5:  ReplacedMsg
   6:  NoWalletMsg
+  7:  OfflineSignMsg
 `,
 		},
 		FromFlag,
@@ -180,7 +186,7 @@ state:
 			}
 		}
 		state := types.MessageState(ctx.Int("state"))
-		if state > types.UnKnown && state <= types.NoWalletMsg {
+		if state > types.UnKnown && state <= types.OfflineSignMsg {
 			tmpMsgs := make([]*types.Message, 0, len(msgs))
 			for _, msg := range msgs {
 				if msg.State == state {
@@ -571,6 +577,78 @@ var markBadCmd = &cli.Command{
 	},
 }
 
+var listMpoolConflictCmd = &cli.Command{
+	Name:  "list-conflict",
+	Usage: "list messages which have a different message with the same from and nonce in mpool",
+	Action: func(cctx *cli.Context) error {
+		client, closer, err := getAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		conflicts, err := client.ListMpoolConflict(cctx.Context)
+		if err != nil {
+			return err
+		}
+
+		bytes, err := json.MarshalIndent(conflicts, " ", "\t")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(bytes))
+		return nil
+	},
+}
+
+var headQueueCmd = &cli.Command{
+	Name:  "head-queue",
+	Usage: "show the state of head changes waiting to be processed",
+	Action: func(cctx *cli.Context) error {
+		client, closer, err := getAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		state, err := client.GetHeadQueueState(cctx.Context)
+		if err != nil {
+			return err
+		}
+
+		bytes, err := json.MarshalIndent(state, " ", "\t")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(bytes))
+		return nil
+	},
+}
+
+var archiveCmd = &cli.Command{
+	Name:  "archive",
+	Usage: "move finished messages older than the retention policy in config out of the messages table",
+	Action: func(cctx *cli.Context) error {
+		client, closer, err := getAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		result, err := client.ArchiveMessage(cctx.Context)
+		if err != nil {
+			return err
+		}
+
+		bytes, err := json.MarshalIndent(result, " ", "\t")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(bytes))
+		return nil
+	},
+}
+
 type message struct {
 	ID string
 
@@ -586,7 +664,8 @@ type message struct {
 
 	Meta *types.MsgMeta
 
-	WalletName string
+	WalletName   string
+	SignerWallet string
 
 	State string
 
@@ -616,6 +695,7 @@ func transformMessage(msg *types.Message) *message {
 		TipSetKey:       msg.TipSetKey,
 		Meta:            msg.Meta,
 		WalletName:      msg.WalletName,
+		SignerWallet:    msg.SignerWallet,
 		State:           types.MsgStateToString(msg.State),
 		UpdatedAt:       msg.UpdatedAt,
 		CreatedAt:       msg.CreatedAt,
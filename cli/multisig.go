@@ -0,0 +1,285 @@
+package cli
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+var MsigCmds = &cli.Command{
+	Name:  "msig",
+	Usage: "multisig proposal commands",
+	Subcommands: []*cli.Command{
+		msigProposeCmd,
+		msigApproveCmd,
+		msigCancelCmd,
+		msigListCmd,
+		msigShowCmd,
+	},
+}
+
+var msigProposeCmd = &cli.Command{
+	Name:      "propose",
+	Usage:     "propose a transaction to multisig",
+	ArgsUsage: "multisig_address to_address",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "from",
+			Usage:    "signer address of multisig to propose the transaction",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:     "wallet",
+			Usage:    "wallet name of the proposer",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:  "value",
+			Usage: "value to send in attoFIL",
+			Value: "0",
+		},
+		&cli.Uint64Flag{
+			Name:  "method",
+			Usage: "method number to call",
+		},
+		&cli.StringFlag{
+			Name:  "params",
+			Usage: "hex encoded params of method",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		if ctx.NArg() != 2 {
+			return xerrors.Errorf("must pass multisig address and to address")
+		}
+		var params types.MsigProposeParams
+		if params.Msig, err = address.NewFromString(ctx.Args().Get(0)); err != nil {
+			return err
+		}
+		if params.To, err = address.NewFromString(ctx.Args().Get(1)); err != nil {
+			return err
+		}
+		if params.Proposer, err = address.NewFromString(ctx.String("from")); err != nil {
+			return err
+		}
+		if params.Value, err = big.FromString(ctx.String("value")); err != nil {
+			return xerrors.Errorf("parse value: %w", err)
+		}
+		if params.Params, err = hex.DecodeString(ctx.String("params")); err != nil {
+			return xerrors.Errorf("parse params: %w", err)
+		}
+		params.Method = abi.MethodNum(ctx.Uint64("method"))
+		params.WalletName = ctx.String("wallet")
+
+		id, err := client.PushMultisigPropose(ctx.Context, &params)
+		if err != nil {
+			return err
+		}
+		fmt.Println(id)
+		return nil
+	},
+}
+
+var msigApproveCmd = &cli.Command{
+	Name:      "approve",
+	Usage:     "approve a pending proposal",
+	ArgsUsage: "proposal_id",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "from",
+			Usage:    "signer address of multisig to approve the proposal",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:     "wallet",
+			Usage:    "wallet name of the signer",
+			Required: true,
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		if !ctx.Args().Present() {
+			return xerrors.Errorf("must pass proposal id")
+		}
+		signer, err := address.NewFromString(ctx.String("from"))
+		if err != nil {
+			return err
+		}
+
+		id, err := client.PushMultisigApprove(ctx.Context, ctx.Args().First(), signer, ctx.String("wallet"), nil)
+		if err != nil {
+			return err
+		}
+		fmt.Println(id)
+		return nil
+	},
+}
+
+var msigCancelCmd = &cli.Command{
+	Name:      "cancel",
+	Usage:     "cancel a pending proposal by the proposer",
+	ArgsUsage: "proposal_id",
+	Action: func(ctx *cli.Context) error {
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		if !ctx.Args().Present() {
+			return xerrors.Errorf("must pass proposal id")
+		}
+
+		id, err := client.PushMultisigCancel(ctx.Context, ctx.Args().First(), nil)
+		if err != nil {
+			return err
+		}
+		fmt.Println(id)
+		return nil
+	},
+}
+
+var msigListCmd = &cli.Command{
+	Name:      "list",
+	Usage:     "list proposals of multisig, all proposals if multisig address is not passed",
+	ArgsUsage: "[multisig_address]",
+	Action: func(ctx *cli.Context) error {
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		msig := address.Undef
+		if ctx.Args().Present() {
+			if msig, err = address.NewFromString(ctx.Args().First()); err != nil {
+				return err
+			}
+		}
+
+		proposals, err := client.ListMultisigProposal(ctx.Context, msig)
+		if err != nil {
+			return err
+		}
+		ps := make([]*msigProposal, len(proposals))
+		for i, p := range proposals {
+			ps[i] = transformMsigProposal(p)
+		}
+
+		bytes, err := json.MarshalIndent(ps, " ", "\t")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(bytes))
+		return nil
+	},
+}
+
+var msigShowCmd = &cli.Command{
+	Name:      "show",
+	Usage:     "show the state and approvals of proposal",
+	ArgsUsage: "proposal_id",
+	Action: func(ctx *cli.Context) error {
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		if !ctx.Args().Present() {
+			return xerrors.Errorf("must pass proposal id")
+		}
+
+		proposal, err := client.GetMultisigProposal(ctx.Context, ctx.Args().First())
+		if err != nil {
+			return err
+		}
+
+		bytes, err := json.MarshalIndent(transformMsigProposal(proposal), " ", "\t")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(bytes))
+		return nil
+	},
+}
+
+type msigProposal struct {
+	ID         string
+	Msig       string
+	TxID       int64
+	Proposer   string
+	WalletName string
+
+	To     string
+	Value  string
+	Method abi.MethodNum
+	Params string
+
+	State       string
+	Approvals   []*msigApproval
+	CancelMsgID string
+	Error       string
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type msigApproval struct {
+	Signer    string
+	MsgID     string
+	State     string
+	CreatedAt time.Time
+}
+
+func transformMsigProposal(p *types.MsigProposal) *msigProposal {
+	if p == nil {
+		return nil
+	}
+
+	mp := &msigProposal{
+		ID:          p.ID,
+		Msig:        p.Msig.String(),
+		TxID:        p.TxID,
+		Proposer:    p.Proposer.String(),
+		WalletName:  p.WalletName,
+		To:          p.To.String(),
+		Value:       p.Value.String(),
+		Method:      p.Method,
+		Params:      hex.EncodeToString(p.Params),
+		State:       types.MsigProposalStateToString(p.State),
+		Approvals:   make([]*msigApproval, 0, len(p.Approvals)),
+		CancelMsgID: p.CancelMsgID,
+		Error:       p.Error,
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+	}
+	for _, a := range p.Approvals {
+		mp.Approvals = append(mp.Approvals, &msigApproval{
+			Signer:    a.Signer.String(),
+			MsgID:     a.MsgID,
+			State:     types.MsigApprovalStateToString(a.State),
+			CreatedAt: a.CreatedAt,
+		})
+	}
+	return mp
+}
@@ -37,6 +37,16 @@ var addNodeCmd = &cli.Command{
 			Name:  "token",
 			Usage: "node token",
 		},
+		&cli.StringFlag{
+			Name:  "type",
+			Usage: "node role, full node is used for state query and push message, light node only receive pushed message, support full and light",
+			Value: "full",
+		},
+		&cli.IntFlag{
+			Name:  "weight",
+			Usage: "weight of node when choosing a node to push message",
+			Value: 1,
+		},
 	},
 	Action: func(ctx *cli.Context) error {
 		client, closer, err := getAPI(ctx)
@@ -56,6 +66,14 @@ var addNodeCmd = &cli.Command{
 		if len(node.Token) == 0 {
 			return xerrors.Errorf("token cannot be empty")
 		}
+		node.Type, err = types.ParseNodeType(ctx.String("type"))
+		if err != nil {
+			return err
+		}
+		node.Weight = ctx.Int("weight")
+		if node.Weight <= 0 {
+			return xerrors.Errorf("weight must be positive")
+		}
 
 		has, err := client.HasNode(ctx.Context, node.Name)
 		if err != nil {
@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/filecoin-project/venus-wallet/core"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+var exportOfflineCmd = &cli.Command{
+	Name: "export-offline",
+	Usage: "export the messages of offline wallet waiting for signatures, " +
+		"sign the file by `keystore sign` on the machine holding the keys and import it by `msg import-offline`",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "wallet",
+			Usage:    "name of offline wallet",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:     "output",
+			Aliases:  []string{"o"},
+			Usage:    "file to write",
+			Required: true,
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		msgs, err := client.ExportOfflineMessage(ctx.Context, ctx.String("wallet"))
+		if err != nil {
+			return err
+		}
+		if err := writeOfflineMessages(ctx.String("output"), msgs); err != nil {
+			return err
+		}
+		fmt.Printf("export %d messages to %s\n", len(msgs), ctx.String("output"))
+		return nil
+	},
+}
+
+var importOfflineCmd = &cli.Command{
+	Name:      "import-offline",
+	Usage:     "import the signatures of messages exported by `msg export-offline`, verified messages are pushed",
+	ArgsUsage: "<file>",
+	Action: func(ctx *cli.Context) error {
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		if !ctx.Args().Present() {
+			return xerrors.Errorf("must pass file")
+		}
+		msgs, err := readOfflineMessages(ctx.Args().First())
+		if err != nil {
+			return err
+		}
+		signed := make([]*types.OfflineSignMessage, 0, len(msgs))
+		for _, msg := range msgs {
+			if msg.Signature != nil {
+				signed = append(signed, msg)
+			}
+		}
+		if len(signed) == 0 {
+			return xerrors.Errorf("no signed message in file")
+		}
+
+		results, err := client.ImportOfflineSignature(ctx.Context, signed)
+		if err != nil {
+			return err
+		}
+		return printJSON(results)
+	},
+}
+
+var signOfflineCmd = &cli.Command{
+	Name:      "sign",
+	Usage:     "sign the messages exported by `msg export-offline` with keys in keystore, run it on the machine holding the keys",
+	ArgsUsage: "<file>",
+	Flags: append([]cli.Flag{
+		&cli.StringFlag{
+			Name:     "output",
+			Aliases:  []string{"o"},
+			Usage:    "file to write signed messages",
+			Required: true,
+		},
+	}, keystoreFlags...),
+	Action: func(ctx *cli.Context) error {
+		if !ctx.Args().Present() {
+			return xerrors.Errorf("must pass file")
+		}
+		msgs, err := readOfflineMessages(ctx.Args().First())
+		if err != nil {
+			return err
+		}
+		wallet, err := openKeystore(ctx)
+		if err != nil {
+			return err
+		}
+
+		var count int
+		for _, msg := range msgs {
+			if msg.Signature != nil {
+				continue
+			}
+			// sign the message in file, not the cid which may not belong to it
+			unsignedCid := msg.Message.Cid()
+			if msg.UnsignedCid != unsignedCid {
+				return xerrors.Errorf("message %s unsigned cid %s not match %s", msg.ID, msg.UnsignedCid, unsignedCid)
+			}
+			data, err := msg.Message.ToStorageBlock()
+			if err != nil {
+				return err
+			}
+			sig, err := wallet.WalletSign(ctx.Context, msg.Message.From, unsignedCid.Bytes(), core.MsgMeta{
+				Type:  core.MTChainMsg,
+				Extra: data.RawData(),
+			})
+			if err != nil {
+				return xerrors.Errorf("sign message %s by %s %w", msg.ID, msg.Message.From, err)
+			}
+			msg.Signature = sig
+			count++
+		}
+		if err := writeOfflineMessages(ctx.String("output"), msgs); err != nil {
+			return err
+		}
+		fmt.Printf("sign %d messages to %s\n", count, ctx.String("output"))
+		return nil
+	},
+}
+
+func readOfflineMessages(path string) ([]*types.OfflineSignMessage, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var msgs []*types.OfflineSignMessage
+	if err := json.Unmarshal(data, &msgs); err != nil {
+		return nil, xerrors.Errorf("unmarshal messages %w", err)
+	}
+	return msgs, nil
+}
+
+func writeOfflineMessages(path string, msgs []*types.OfflineSignMessage) error {
+	data, err := json.MarshalIndent(msgs, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0600)
+}
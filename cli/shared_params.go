@@ -3,6 +3,7 @@ package cli
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 
 	"github.com/urfave/cli/v2"
 	"golang.org/x/xerrors"
@@ -17,6 +18,9 @@ var SharedParamsCmds = &cli.Command{
 		setSharedParamsCmd,
 		getSharedParamCmd,
 		refreshSharedParamCmd,
+		listSharedParamsVersionCmd,
+		diffSharedParamsVersionCmd,
+		rollbackSharedParamsCmd,
 	},
 }
 
@@ -24,6 +28,12 @@ var setSharedParamsCmd = &cli.Command{
 	Name:      "set",
 	Usage:     `set current shared params commands, eg. set: venus-messager share-params set "{\"expireEpoch\": 0, \"gasOverEstimation\": 1.25, \"maxFee\": 7000000000000000, \"maxFeeCap\": 0, \"selMsgNum\": 20, \"scanInterval\": 10, \"maxEstFailNumOfMsg\": 5}"`,
 	ArgsUsage: "[params]",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "comment",
+			Usage: "why the params are changed, saved with the new version",
+		},
+	},
 	Action: func(ctx *cli.Context) error {
 		if ctx.Args().Len() > 1 {
 			return cli.ShowCommandHelp(ctx, ctx.Command.Name)
@@ -47,7 +57,7 @@ var setSharedParamsCmd = &cli.Command{
 			return err
 		}
 
-		_, err = api.SetSharedParams(ctx.Context, sp)
+		_, err = api.SetSharedParamsWithComment(ctx.Context, sp, ctx.String("comment"))
 		if err != nil {
 			return err
 		}
@@ -99,3 +109,103 @@ var refreshSharedParamCmd = &cli.Command{
 		return nil
 	},
 }
+
+var listSharedParamsVersionCmd = &cli.Command{
+	Name:  "versions",
+	Usage: "list versions of shared params, the latest first",
+	Flags: []cli.Flag{
+		&cli.IntFlag{
+			Name:  "limit",
+			Usage: "max number of versions to list, 0 means all",
+			Value: 20,
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		versions, err := client.ListParamsVersion(ctx.Context, types.SharedParamsTarget, ctx.Int("limit"))
+		if err != nil {
+			return err
+		}
+
+		return printJSON(versions)
+	},
+}
+
+var diffSharedParamsVersionCmd = &cli.Command{
+	Name:      "diff",
+	Usage:     "show the changed fields between two versions of shared params",
+	ArgsUsage: "<from version> <to version>",
+	Action: func(ctx *cli.Context) error {
+		if ctx.Args().Len() != 2 {
+			return cli.ShowCommandHelp(ctx, ctx.Command.Name)
+		}
+		from, to, err := parseVersions(ctx.Args().Get(0), ctx.Args().Get(1))
+		if err != nil {
+			return err
+		}
+
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		changes, err := client.DiffParamsVersion(ctx.Context, types.SharedParamsTarget, from, to)
+		if err != nil {
+			return err
+		}
+
+		return printJSON(changes)
+	},
+}
+
+var rollbackSharedParamsCmd = &cli.Command{
+	Name:      "rollback",
+	Usage:     "set shared params to the value of a version, the rollback is saved as a new version",
+	ArgsUsage: "<version>",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "comment",
+			Usage: "why the params are rolled back, default 'rollback to version <version>'",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		if ctx.Args().Len() != 1 {
+			return cli.ShowCommandHelp(ctx, ctx.Command.Name)
+		}
+		version, err := strconv.ParseInt(ctx.Args().First(), 10, 64)
+		if err != nil {
+			return xerrors.Errorf("parse version %w", err)
+		}
+
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		params, err := client.RollbackSharedParams(ctx.Context, version, ctx.String("comment"))
+		if err != nil {
+			return err
+		}
+
+		return printJSON(params)
+	},
+}
+
+func parseVersions(fromStr, toStr string) (int64, int64, error) {
+	from, err := strconv.ParseInt(fromStr, 10, 64)
+	if err != nil {
+		return 0, 0, xerrors.Errorf("parse from version %w", err)
+	}
+	to, err := strconv.ParseInt(toStr, 10, 64)
+	if err != nil {
+		return 0, 0, xerrors.Errorf("parse to version %w", err)
+	}
+	return from, to, nil
+}
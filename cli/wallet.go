@@ -10,6 +10,7 @@ import (
 	"github.com/urfave/cli/v2"
 	"golang.org/x/xerrors"
 
+	"github.com/filecoin-project/venus-messager/service"
 	"github.com/filecoin-project/venus-messager/types"
 )
 
@@ -21,6 +22,8 @@ var WalletCmds = &cli.Command{
 		searchWalletCmd,
 		listWalletCmd,
 		listRemoteWalletAddrCmd,
+		scanWalletCmd,
+		setSignLimitCmd,
 		deleteWalletCmd,
 	},
 }
@@ -34,12 +37,21 @@ var addWalletCmd = &cli.Command{
 			Usage: "wallet name",
 		},
 		&cli.StringFlag{
-			Name:  "url",
-			Usage: "wallet url",
+			Name: "url",
+			Usage: "wallet url, keystore:<dir> for the keystore directory under wallet.keystore.rootDir managed by keystore commands, " +
+				"offline:<addr1>,<addr2> for addresses signed by exporting messages",
 		},
 		&cli.StringFlag{
 			Name:  "token",
-			Usage: "wallet token",
+			Usage: "wallet token, not needed by keystore and offline wallet",
+		},
+		&cli.IntFlag{
+			Name:  "sign-concurrency",
+			Usage: "max number of concurrent sign requests to the wallet, 0 means unlimited",
+		},
+		&cli.Float64Flag{
+			Name:  "sign-rate",
+			Usage: "max number of sign requests per second to the wallet, 0 means unlimited",
 		},
 	},
 	Action: func(ctx *cli.Context) error {
@@ -60,9 +72,11 @@ var addWalletCmd = &cli.Command{
 			return xerrors.Errorf("url cannot be empty")
 		}
 		w.Token = ctx.String("token")
-		if len(w.Token) == 0 {
+		if len(w.Token) == 0 && !service.IsOfflineWalletUrl(w.Url) {
 			return xerrors.Errorf("token cannot be empty")
 		}
+		w.SignConcurrency = ctx.Int("sign-concurrency")
+		w.SignRate = ctx.Float64("sign-rate")
 
 		_, err = client.SaveWallet(ctx.Context, &w)
 		if err != nil {
@@ -178,6 +192,65 @@ var listRemoteWalletAddrCmd = &cli.Command{
 	},
 }
 
+var scanWalletCmd = &cli.Command{
+	Name:      "scan",
+	Usage:     "refresh addresses of wallet now and list them",
+	ArgsUsage: "wallet_name",
+	Action: func(ctx *cli.Context) error {
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		if !ctx.Args().Present() {
+			return xerrors.Errorf("must pass name")
+		}
+
+		addrs, err := client.ScanWallet(ctx.Context, ctx.Args().First())
+		if err != nil {
+			return err
+		}
+
+		bytes, err := json.MarshalIndent(addrs, " ", "\t")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(bytes))
+		return nil
+	},
+}
+
+var setSignLimitCmd = &cli.Command{
+	Name:      "set-sign-limit",
+	Usage:     "set the limits of sign requests to the wallet, the sign queue is shown by `wallet list`",
+	ArgsUsage: "name",
+	Flags: []cli.Flag{
+		&cli.IntFlag{
+			Name:  "concurrency",
+			Usage: "max number of concurrent sign requests, 0 means unlimited",
+		},
+		&cli.Float64Flag{
+			Name:  "rate",
+			Usage: "max number of sign requests per second, 0 means unlimited",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		if !ctx.Args().Present() {
+			return xerrors.Errorf("must pass name")
+		}
+
+		_, err = client.SetWalletSignLimit(ctx.Context, ctx.Args().First(), ctx.Int("concurrency"), ctx.Float64("rate"))
+		return err
+	},
+}
+
 var deleteWalletCmd = &cli.Command{
 	Name:      "del",
 	Usage:     "delete wallet by name",
@@ -210,17 +283,44 @@ type walletFormat struct {
 	Token string
 	State string
 
+	SignConcurrency int
+	SignRate        float64
+	// runtime health of wallet
+	Health *walletHealthFormat `json:",omitempty"`
+	// runtime sign requests of wallet
+	SignStats *walletSignStatsFormat `json:",omitempty"`
+
 	IsDeleted int
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
 
+type walletHealthFormat struct {
+	State     string
+	LastError string
+	Latency   string
+	Failures  int
+	LastCall  time.Time
+	OpenUntil time.Time
+
+	InvalidSignatures int
+}
+
+type walletSignStatsFormat struct {
+	Waiting    int
+	Running    int
+	Signed     uint64
+	Wait       string
+	Latency    string
+	AvgLatency string
+}
+
 func transformWallet(w *types.Wallet) *walletFormat {
 	if w == nil {
 		return nil
 	}
 
-	return &walletFormat{
+	wf := &walletFormat{
 		ID:        w.ID,
 		Name:      w.Name,
 		Url:       w.Url,
@@ -229,5 +329,31 @@ func transformWallet(w *types.Wallet) *walletFormat {
 		IsDeleted: w.IsDeleted,
 		CreatedAt: w.CreatedAt,
 		UpdatedAt: w.UpdatedAt,
+
+		SignConcurrency: w.SignConcurrency,
+		SignRate:        w.SignRate,
+	}
+	if w.Health != nil {
+		wf.Health = &walletHealthFormat{
+			State:     types.WalletHealthStateToString(w.Health.State),
+			LastError: w.Health.LastError,
+			Latency:   w.Health.Latency.String(),
+			Failures:  w.Health.Failures,
+			LastCall:  w.Health.LastCall,
+			OpenUntil: w.Health.OpenUntil,
+
+			InvalidSignatures: w.Health.InvalidSignatures,
+		}
+	}
+	if w.SignStats != nil {
+		wf.SignStats = &walletSignStatsFormat{
+			Waiting:    w.SignStats.Waiting,
+			Running:    w.SignStats.Running,
+			Signed:     w.SignStats.Signed,
+			Wait:       w.SignStats.Wait.String(),
+			Latency:    w.SignStats.Latency.String(),
+			AvgLatency: w.SignStats.AvgLatency.String(),
+		}
 	}
+	return wf
 }
@@ -3,6 +3,7 @@ package cli
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/filecoin-project/venus-messager/types"
@@ -21,7 +22,11 @@ var WalletAddrCmds = &cli.Command{
 		listWalletAddrCmd,
 		forbiddenAddrCmd,
 		activeAddrCmd,
+		migrateAddrCmd,
 		setAddrSelMsgNumCmd,
+		listAddrSelMsgNumVersionCmd,
+		diffAddrSelMsgNumVersionCmd,
+		rollbackAddrSelMsgNumCmd,
 	},
 }
 
@@ -140,6 +145,46 @@ var forbiddenAddrCmd = &cli.Command{
 	},
 }
 
+var migrateAddrCmd = &cli.Command{
+	Name: "migrate",
+	Usage: "move address and its unfilled messages to another wallet which must be able to sign for it, " +
+		"remove the key from the old wallet afterwards or it is added back by the next scan",
+	ArgsUsage: "address",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "from-wallet",
+			Usage:    "wallet holding the address now",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:     "to-wallet",
+			Usage:    "wallet to sign for the address",
+			Required: true,
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		if !ctx.Args().Present() {
+			return xerrors.Errorf("must pass address")
+		}
+		addr, err := address.NewFromString(ctx.Args().First())
+		if err != nil {
+			return err
+		}
+
+		res, err := client.MigrateAddress(ctx.Context, ctx.String("from-wallet"), ctx.String("to-wallet"), addr)
+		if err != nil {
+			return err
+		}
+		return printJSON(res)
+	},
+}
+
 var activeAddrCmd = &cli.Command{
 	Name:      "active",
 	Usage:     "activate a frozen address",
@@ -199,6 +244,10 @@ var setAddrSelMsgNumCmd = &cli.Command{
 			Usage:   "wallet name",
 			Aliases: []string{"name"},
 		},
+		&cli.StringFlag{
+			Name:  "comment",
+			Usage: "why the number is changed, saved with the new version",
+		},
 	},
 	Action: func(ctx *cli.Context) error {
 		client, closer, err := getAPI(ctx)
@@ -215,9 +264,133 @@ var setAddrSelMsgNumCmd = &cli.Command{
 			return err
 		}
 		walletName := ctx.String("wallet_name")
-		if _, err := client.SetSelectMsgNum(ctx.Context, walletName, addr, ctx.Uint64("num")); err != nil {
+		if _, err := client.SetSelectMsgNumWithComment(ctx.Context, walletName, addr, ctx.Uint64("num"), ctx.String("comment")); err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var listAddrSelMsgNumVersionCmd = &cli.Command{
+	Name:      "sel_msg_num_versions",
+	Usage:     "list versions of the number of address selection messages, the latest first",
+	ArgsUsage: "address",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:    "wallet_name",
+			Usage:   "wallet name",
+			Aliases: []string{"name"},
+		},
+		&cli.IntFlag{
+			Name:  "limit",
+			Usage: "max number of versions to list, 0 means all",
+			Value: 20,
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		if !ctx.Args().Present() {
+			return xerrors.Errorf("must pass address")
+		}
+		addr, err := address.NewFromString(ctx.Args().First())
+		if err != nil {
+			return err
+		}
+		target := types.SelMsgNumTarget(ctx.String("wallet_name"), addr)
+		versions, err := client.ListParamsVersion(ctx.Context, target, ctx.Int("limit"))
+		if err != nil {
+			return err
+		}
+
+		return printJSON(versions)
+	},
+}
+
+var diffAddrSelMsgNumVersionCmd = &cli.Command{
+	Name:      "sel_msg_num_diff",
+	Usage:     "show the change between two versions of the number of address selection messages",
+	ArgsUsage: "<address> <from version> <to version>",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:    "wallet_name",
+			Usage:   "wallet name",
+			Aliases: []string{"name"},
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		if ctx.Args().Len() != 3 {
+			return cli.ShowCommandHelp(ctx, ctx.Command.Name)
+		}
+		addr, err := address.NewFromString(ctx.Args().Get(0))
+		if err != nil {
+			return err
+		}
+		from, to, err := parseVersions(ctx.Args().Get(1), ctx.Args().Get(2))
+		if err != nil {
+			return err
+		}
+
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		target := types.SelMsgNumTarget(ctx.String("wallet_name"), addr)
+		changes, err := client.DiffParamsVersion(ctx.Context, target, from, to)
+		if err != nil {
+			return err
+		}
+
+		return printJSON(changes)
+	},
+}
+
+var rollbackAddrSelMsgNumCmd = &cli.Command{
+	Name:      "sel_msg_num_rollback",
+	Usage:     "set the number of address selection messages to the value of a version, the rollback is saved as a new version",
+	ArgsUsage: "<address> <version>",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:    "wallet_name",
+			Usage:   "wallet name",
+			Aliases: []string{"name"},
+		},
+		&cli.StringFlag{
+			Name:  "comment",
+			Usage: "why the number is rolled back, default 'rollback to version <version>'",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		if ctx.Args().Len() != 2 {
+			return cli.ShowCommandHelp(ctx, ctx.Command.Name)
+		}
+		addr, err := address.NewFromString(ctx.Args().Get(0))
+		if err != nil {
+			return err
+		}
+		version, err := strconv.ParseInt(ctx.Args().Get(1), 10, 64)
+		if err != nil {
+			return xerrors.Errorf("parse version %w", err)
+		}
+
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		num, err := client.RollbackSelectMsgNum(ctx.Context, ctx.String("wallet_name"), addr, version, ctx.String("comment"))
+		if err != nil {
 			return err
 		}
+		fmt.Printf("rollback %s to version %d, select message number: %d\n", addr, version, num)
 
 		return nil
 	},
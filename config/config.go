@@ -30,9 +30,22 @@ type APIConfig struct {
 }
 
 type DbConfig struct {
-	Type   string       `toml:"type"`
-	MySql  MySqlConfig  `toml:"mysql"`
-	Sqlite SqliteConfig `toml:"sqlite"`
+	Type     string         `toml:"type"`
+	MySql    MySqlConfig    `toml:"mysql"`
+	Sqlite   SqliteConfig   `toml:"sqlite"`
+	Postgres PostgresConfig `toml:"postgres"`
+
+	TokenEncryption TokenEncryptionConfig `toml:"tokenEncryption"`
+}
+
+// TokenEncryptionConfig is the key encrypting the tokens of wallets and nodes saved in database, Key is used if both are set,
+// tokens are saved in plaintext if neither is set. Rotate the key by `db rotate-token-key`
+type TokenEncryptionConfig struct {
+	// hex encoded 32 bytes key
+	Key string `toml:"key"`
+	// file holding the hex encoded key, relative path is relative to the directory of config file.
+	// It is generated when messager starts if not exist, unless the database already has encrypted tokens
+	KeyFile string `toml:"keyFile"`
 }
 
 type SqliteConfig struct {
@@ -46,6 +59,29 @@ type MySqlConfig struct {
 	MaxIdleConn      int           `toml:"maxIdleConn"`
 	ConnMaxLifeTime  time.Duration `toml:"connMaxLifeTime"`
 	Debug            bool          `toml:"debug"`
+
+	Replica MySqlReplicaConfig `toml:"replica"`
+}
+
+// MySqlReplicaConfig read only message queries of api are served by the replicas in turn,
+// writes and the queries of message selection and state refresh always go to the primary
+type MySqlReplicaConfig struct {
+	ConnectionStrings []string `toml:"connectionStrings"`
+	// check the replicas every interval, unreachable replicas are not used until they are back
+	CheckInterval int `toml:"checkInterval"` // second
+	// replicas lagging behind the primary more than this are not used until they catch up, 0 means never check the lag,
+	// the lag is read from `SHOW SLAVE STATUS` which needs the REPLICATION CLIENT privilege
+	MaxLag int `toml:"maxLag"` // second
+	// query the primary again when a message is not found in the replica, the message may not have been replicated yet
+	FallbackOnNotFound bool `toml:"fallbackOnNotFound"`
+}
+
+type PostgresConfig struct {
+	ConnectionString string        `toml:"connectionString"`
+	MaxOpenConn      int           `toml:"maxOpenConn"`
+	MaxIdleConn      int           `toml:"maxIdleConn"`
+	ConnMaxLifeTime  time.Duration `toml:"connMaxLifeTime"`
+	Debug            bool          `toml:"debug"`
 }
 
 type JWTConfig struct {
@@ -54,12 +90,68 @@ type JWTConfig struct {
 
 type WalletConfig struct {
 	ScanInterval int `toml:"scanInterval"` // second
+	// timeout of every call to wallet, 0 means no timeout
+	CallTimeout int `toml:"callTimeout"` // second
+
+	Health       WalletHealthConfig `toml:"health"`
+	SignFailover SignFailoverConfig `toml:"signFailover"`
+	Keystore     KeystoreConfig     `toml:"keystore"`
+}
+
+// KeystoreConfig is the keystore directories registered as local wallets by url `keystore:<dir>`
+type KeystoreConfig struct {
+	// keystore directories are relative to it and can't be out of it, local wallet is disabled if it is empty
+	RootDir string `toml:"rootDir"`
+	// file holding the passphrase of keystores, env MESSAGER_KEYSTORE_PASSPHRASE is read if it is empty
+	PassphraseFile string `toml:"passphraseFile"`
+}
+
+// WalletHealthConfig is the health probing and circuit breaker of wallets
+type WalletHealthConfig struct {
+	ProbeInterval int `toml:"probeInterval"` // second, 0 means no probing
+	// open the circuit of wallet after this number of consecutive failed calls, 0 means never open
+	FailureThreshold int `toml:"failureThreshold"`
+	// calls to wallet are rejected in this period after the circuit opened
+	CoolOff int `toml:"coolOff"` // second
+}
+
+// SignFailoverConfig let messages be signed by another wallet holding the same address when the wallet of message can't be reached
+type SignFailoverConfig struct {
+	Enable bool `toml:"enable"`
+	// wallet names tried in order, wallets not listed are tried after them in name order
+	Order []string `toml:"order"`
 }
 
 type MessageServiceConfig struct {
 	TipsetFilePath  string `toml:"tipsetFilePath"`
 	SkipProcessHead bool   `toml:"skipProcessHead"`
 	SkipPushMessage bool   `toml:"skipPushMessage"`
+
+	// check whether filled messages are in mpool and only rebroadcast the missing ones, 0 means push all filled message every head change
+	MpoolCheckInterval int `toml:"mpoolCheckInterval"` // second
+
+	// max number of head change waiting to be processed, new head change is merged into the last one when the queue is full
+	HeadChangeQueueSize int `toml:"headChangeQueueSize"`
+	// skip selecting message when the node head lags behind the current time more than this epochs, 0 means never skip
+	MaxHeadLag int `toml:"maxHeadLag"`
+	// refresh the state of multisig proposals every interval, 0 means disabled
+	MultisigTrackInterval int `toml:"multisigTrackInterval"` // second
+
+	Archive ArchiveConfig `toml:"archive"`
+}
+
+// ArchiveConfig is the retention policy of finished(OnChainMsg, FailedMsg, ReplacedMsg) messages
+type ArchiveConfig struct {
+	// run the archive job every interval, 0 means the job only run by `msg archive` command
+	Interval int `toml:"interval"` // second
+	// archive messages not updated in the days, 0 means disabled
+	RetentionDays int `toml:"retentionDays"`
+	// archive messages packed more than the epochs ago, 0 means disabled
+	RetentionEpochs int `toml:"retentionEpochs"`
+	// move archived messages to the archive table(table) or compressed jsonl files in FileDir(file)
+	Target    string `toml:"target"`
+	FileDir   string `toml:"fileDir"`
+	BatchSize int    `toml:"batchSize"`
 }
 
 type MessageStateConfig struct {
@@ -78,8 +170,25 @@ func DefaultConfig() *Config {
 				MaxIdleConn:      10,
 				ConnMaxLifeTime:  time.Second * 60,
 				Debug:            false,
+				Replica: MySqlReplicaConfig{
+					ConnectionStrings:  []string{},
+					CheckInterval:      10,
+					MaxLag:             30,
+					FallbackOnNotFound: true,
+				},
 			},
 			Sqlite: SqliteConfig{Path: "./message.db"},
+			Postgres: PostgresConfig{
+				ConnectionString: "",
+				MaxOpenConn:      10,
+				MaxIdleConn:      10,
+				ConnMaxLifeTime:  time.Second * 60,
+				Debug:            false,
+			},
+			TokenEncryption: TokenEncryptionConfig{
+				Key:     "",
+				KeyFile: "./token.key",
+			},
 		},
 		JWT: JWTConfig{
 			Url: "http://127.0.0.1:8989",
@@ -97,6 +206,16 @@ func DefaultConfig() *Config {
 		},
 		Wallet: WalletConfig{
 			ScanInterval: 10,
+			CallTimeout:  10,
+			Health: WalletHealthConfig{
+				ProbeInterval:    30,
+				FailureThreshold: 3,
+				CoolOff:          60,
+			},
+			SignFailover: SignFailoverConfig{
+				Enable: false,
+				Order:  []string{},
+			},
 		},
 		MessageState: MessageStateConfig{
 			BackTime:          3600 * 24,
@@ -104,9 +223,21 @@ func DefaultConfig() *Config {
 			CleanupInterval:   3600 * 24,
 		},
 		MessageService: MessageServiceConfig{
-			TipsetFilePath:  "./tipset.json",
-			SkipProcessHead: false,
-			SkipPushMessage: false,
+			TipsetFilePath:        "./tipset.json",
+			SkipProcessHead:       false,
+			SkipPushMessage:       false,
+			MpoolCheckInterval:    60,
+			HeadChangeQueueSize:   5,
+			MaxHeadLag:            10,
+			MultisigTrackInterval: 30,
+			Archive: ArchiveConfig{
+				Interval:        0,
+				RetentionDays:   30,
+				RetentionEpochs: 0,
+				Target:          "table",
+				FileDir:         "./archive",
+				BatchSize:       1000,
+			},
 		},
 	}
 }
@@ -3,6 +3,7 @@ package config
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
 
 	"github.com/pelletier/go-toml"
 )
@@ -26,6 +27,7 @@ func ReadConfig(path string) (*Config, error) {
 	if err := toml.Unmarshal(configBytes, config); err != nil {
 		return nil, err
 	}
+	config.DB.TokenEncryption.ResolveKeyFile(filepath.Dir(path))
 	return config, nil
 }
 
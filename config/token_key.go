@@ -0,0 +1,74 @@
+package config
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// TokenKeyLen is the length of token encryption key, AES-256 is used
+const TokenKeyLen = 32
+
+// GenerateTokenKey return a random hex encoded token encryption key
+func GenerateTokenKey() (string, error) {
+	key := make([]byte, TokenKeyLen)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(key), nil
+}
+
+// WriteTokenKeyFile write the hex encoded key readable by the owner only
+func WriteTokenKeyFile(path, key string) error {
+	return ioutil.WriteFile(path, []byte(key+"\n"), 0600)
+}
+
+// ReadKey return the token encryption key, nil if it is not configured
+func (cfg *TokenEncryptionConfig) ReadKey() ([]byte, error) {
+	hexKey := cfg.Key
+	if len(hexKey) == 0 {
+		if len(cfg.KeyFile) == 0 {
+			return nil, nil
+		}
+		data, err := ioutil.ReadFile(cfg.KeyFile)
+		if err != nil {
+			return nil, xerrors.Errorf("read token key file %w", err)
+		}
+		hexKey = string(data)
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(hexKey))
+	if err != nil {
+		return nil, xerrors.Errorf("decode token key %w", err)
+	}
+	if len(key) != TokenKeyLen {
+		return nil, xerrors.Errorf("token key must be %d bytes, got %d", TokenKeyLen, len(key))
+	}
+	return key, nil
+}
+
+// ResolveKeyFile make the relative key file path relative to dir instead of the working directory
+func (cfg *TokenEncryptionConfig) ResolveKeyFile(dir string) {
+	if len(cfg.KeyFile) != 0 && !filepath.IsAbs(cfg.KeyFile) {
+		cfg.KeyFile = filepath.Join(dir, cfg.KeyFile)
+	}
+}
+
+// KeyFileMissing return true if the key is read from a file not exist
+func (cfg *TokenEncryptionConfig) KeyFileMissing() (bool, error) {
+	if len(cfg.Key) != 0 || len(cfg.KeyFile) == 0 {
+		return false, nil
+	}
+	_, err := os.Stat(cfg.KeyFile)
+	if err == nil {
+		return false, nil
+	}
+	if os.IsNotExist(err) {
+		return true, nil
+	}
+	return false, err
+}
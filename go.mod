@@ -9,6 +9,7 @@ require (
 	github.com/filecoin-project/go-address v0.0.5
 	github.com/filecoin-project/go-jsonrpc v0.1.4-0.20210217175800-45ea43ac2bec
 	github.com/filecoin-project/go-state-types v0.1.0
+	github.com/filecoin-project/specs-actors/v3 v3.1.0
 	github.com/filecoin-project/venus v0.9.2-0.20210413100211-57a27da696c3
 	github.com/filecoin-project/venus-auth v1.0.2-0.20210507023017-76ce8b64e6db
 	github.com/filecoin-project/venus-wallet v1.0.1-0.20210507062236-b94c303c7104
@@ -39,6 +40,7 @@ require (
 	google.golang.org/api v0.29.0 // indirect
 	google.golang.org/genproto v0.0.0-20200707001353-8e8330bf89df // indirect
 	gorm.io/driver/mysql v1.0.5
+	gorm.io/driver/postgres v1.0.8
 	gorm.io/driver/sqlite v1.1.4
 	gorm.io/gorm v1.21.3
 	honnef.co/go/tools v0.1.3 // indirect
@@ -40,6 +40,13 @@ func main() {
 			ccli.SharedParamsCmds,
 			ccli.NodeCmds,
 			ccli.WalletAddrCmds,
+			ccli.KeystoreCmds,
+			ccli.BackfillCmd,
+			ccli.AuditCmds,
+			ccli.MsigCmds,
+			ccli.DbCmds,
+			ccli.ExportCmd,
+			ccli.ImportCmd,
 			runCmd,
 		},
 	}
@@ -120,6 +127,7 @@ func runAction(ctx *cli.Context) error {
 		if err != nil {
 			return err
 		}
+		cfg.DB.TokenEncryption.ResolveKeyFile(filepath.Dir(path))
 	} else {
 		cfg, err = config.ReadConfig(path)
 		if err != nil {
@@ -157,7 +165,10 @@ func runAction(ctx *cli.Context) error {
 		//prover
 		fx.Supply(cfg, &cfg.DB, &cfg.API, &cfg.JWT, &cfg.Node, &cfg.Log, &cfg.MessageService, &cfg.MessageState, &cfg.Wallet),
 		fx.Supply(log),
-		fx.Supply(client),
+		// the full nodes in db serve the state and gas calls of configured node when it is unreachable
+		fx.Provide(func(nodeService *service.NodeService) *service.NodeClient {
+			return service.NewFailoverNodeClient(client, nodeService, log)
+		}),
 		fx.Supply((ShutdownChan)(shutdownChan)),
 
 		fx.Provide(service.NewMessageState),
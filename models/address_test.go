@@ -81,6 +81,9 @@ func TestAddress(t *testing.T) {
 			t.SkipNow()
 			addressRepoTest(t, mysqlRepo.AddressRepo())
 		})
+		t.Run("postgres", func(t *testing.T) {
+			addressRepoTest(t, setupPostgresRepo(t).AddressRepo())
+		})
 	})
 }
 
@@ -125,6 +128,9 @@ func TestUpdateAddressNonce(t *testing.T) {
 			t.SkipNow()
 			addressRepoTest(t, mysqlRepo.AddressRepo())
 		})
+		t.Run("postgres", func(t *testing.T) {
+			addressRepoTest(t, setupPostgresRepo(t).AddressRepo())
+		})
 	})
 }
 
@@ -167,6 +173,9 @@ func TestSqliteAddressRepo_Delete(t *testing.T) {
 			t.SkipNow()
 			addressRepoTest(t, mysqlRepo.AddressRepo())
 		})
+		t.Run("postgres", func(t *testing.T) {
+			addressRepoTest(t, setupPostgresRepo(t).AddressRepo())
+		})
 	})
 }
 
@@ -211,5 +220,8 @@ func TestSqliteAddressRepo_HasAddress(t *testing.T) {
 			t.SkipNow()
 			addressRepoTest(t, mysqlRepo.AddressRepo())
 		})
+		t.Run("postgres", func(t *testing.T) {
+			addressRepoTest(t, setupPostgresRepo(t).AddressRepo())
+		})
 	})
 }
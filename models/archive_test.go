@@ -0,0 +1,93 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/filecoin-project/venus-messager/models/repo"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+func TestArchiveMessage(t *testing.T) {
+	sqliteRepo, mysqlRepo := setupRepo(t)
+
+	archiveTest := func(t *testing.T, r repo.Repo) {
+		msgs := NewSignedMessages(4)
+		msgs[0].State = types.OnChainMsg
+		msgs[0].Height = 10
+		msgs[1].State = types.FailedMsg
+		msgs[2].State = types.ReplacedMsg
+		msgs[2].Height = 10
+		msgs[3].State = types.FillMsg
+		for _, msg := range msgs {
+			assert.NoError(t, r.MessageRepo().CreateMessage(msg))
+		}
+
+		archivable := func(before time.Time, belowHeight abi.ChainEpoch) map[string]*types.Message {
+			list, err := r.MessageRepo().ListArchivableMessage(before, belowHeight, 100000)
+			assert.NoError(t, err)
+			res := make(map[string]*types.Message)
+			for _, msg := range list {
+				res[msg.ID] = msg
+			}
+			return res
+		}
+		byTime := archivable(time.Now().Add(time.Minute), 0)
+		for _, msg := range msgs[:3] {
+			assert.Contains(t, byTime, msg.ID)
+		}
+		assert.NotContains(t, byTime, msgs[3].ID)
+
+		// failed message has no height
+		byHeight := archivable(time.Time{}, 11)
+		assert.Contains(t, byHeight, msgs[0].ID)
+		assert.NotContains(t, byHeight, msgs[1].ID)
+		assert.Contains(t, byHeight, msgs[2].ID)
+
+		none, err := r.MessageRepo().ListArchivableMessage(time.Time{}, 0, 100)
+		assert.NoError(t, err)
+		assert.Len(t, none, 0)
+
+		toArchive := []*types.Message{byTime[msgs[0].ID], byTime[msgs[1].ID]}
+		assert.NoError(t, r.Transaction(func(txRepo repo.TxRepo) error {
+			if err := txRepo.ArchiveRepo().ArchiveMessage(toArchive); err != nil {
+				return err
+			}
+			return txRepo.MessageRepo().DeleteMessage([]string{msgs[0].ID, msgs[1].ID})
+		}))
+
+		has, err := r.MessageRepo().HasMessageByUid(msgs[0].ID)
+		assert.NoError(t, err)
+		assert.False(t, has)
+		has, err = r.ArchiveRepo().HasArchivedMessageByUid(msgs[0].ID)
+		assert.NoError(t, err)
+		assert.True(t, has)
+
+		archived, err := r.ArchiveRepo().GetArchivedMessageByUid(msgs[0].ID)
+		assert.NoError(t, err)
+		assert.Equal(t, ObjectToString(byTime[msgs[0].ID]), ObjectToString(archived))
+		archived, err = r.ArchiveRepo().GetArchivedMessageByCid(*msgs[1].UnsignedCid)
+		assert.NoError(t, err)
+		assert.Equal(t, msgs[1].ID, archived.ID)
+		archived, err = r.ArchiveRepo().GetArchivedMessageBySignedCid(*msgs[1].SignedCid)
+		assert.NoError(t, err)
+		assert.Equal(t, msgs[1].ID, archived.ID)
+
+		_, err = r.ArchiveRepo().GetArchivedMessageByUid(msgs[2].ID)
+		assert.Error(t, err)
+	}
+
+	t.Run("sqlite", func(t *testing.T) {
+		archiveTest(t, sqliteRepo)
+	})
+	t.Run("mysql", func(t *testing.T) {
+		t.SkipNow()
+		archiveTest(t, mysqlRepo)
+	})
+	t.Run("postgres", func(t *testing.T) {
+		archiveTest(t, setupPostgresRepo(t))
+	})
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/venus-messager/models/memory"
+	"github.com/filecoin-project/venus-messager/models/repo"
+	"github.com/filecoin-project/venus-messager/models/repotest"
+)
+
+func TestRepoConformance(t *testing.T) {
+	t.Run("sqlite", func(t *testing.T) {
+		repotest.RunRepoSuite(t, func(t *testing.T) repo.Repo {
+			return openTempSqlite(t, "conformance.db")
+		})
+	})
+	t.Run("memory", func(t *testing.T) {
+		repotest.RunRepoSuite(t, func(t *testing.T) repo.Repo {
+			r, err := memory.OpenMemory()
+			if err != nil {
+				t.Fatal(err)
+			}
+			return r
+		})
+	})
+	t.Run("mysql", func(t *testing.T) {
+		r := setupMysqlRepo(t)
+		repotest.RunRepoSuite(t, func(t *testing.T) repo.Repo {
+			return r
+		})
+	})
+	t.Run("postgres", func(t *testing.T) {
+		r := setupPostgresRepo(t)
+		repotest.RunRepoSuite(t, func(t *testing.T) repo.Repo {
+			return r
+		})
+	})
+}
@@ -0,0 +1,472 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"reflect"
+	"time"
+
+	"golang.org/x/xerrors"
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-messager/models/repo"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+// ExportVersion is the version of export format, bump it when the layout of records changes
+const ExportVersion = 1
+
+const exportPageSize = 1000
+
+// kinds of export record, records are written in this order so that the referenced records are imported first
+const (
+	KindHeader          = "header"
+	KindSharedParams    = "shared_params"
+	KindWallet          = "wallet"
+	KindAddress         = "address"
+	KindWalletAddress   = "wallet_address"
+	KindNode            = "node"
+	KindMessage         = "message"
+	KindArchivedMessage = "archived_message"
+)
+
+// exportRecord is one line of the jsonl export file
+type exportRecord struct {
+	Kind string          `json:"kind"`
+	Data json.RawMessage `json:"data"`
+}
+
+type ExportHeader struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type ExportResult struct {
+	Counts map[string]int `json:"counts"`
+}
+
+type ImportOptions struct {
+	// check the records and report conflicts without writing anything
+	DryRun bool
+	// skip the conflicting records and the wallet addresses referencing them instead of failing the import
+	SkipConflict bool
+	// decrypt the tokens of wallets and nodes, it must be the key of export
+	TokenCipher *repo.TokenCipher
+}
+
+type ImportResult struct {
+	DryRun    bool           `json:"dryRun"`
+	Counts    map[string]int `json:"counts"`
+	Imported  map[string]int `json:"imported"`
+	Conflicts []string       `json:"conflicts"`
+	// wallets and nodes imported without token as it was redacted by export, add them again with the token
+	RedactedTokens []string `json:"redactedTokens"`
+}
+
+type exportWriter struct {
+	enc    *json.Encoder
+	counts map[string]int
+}
+
+func (w *exportWriter) write(kind string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return xerrors.Errorf("marshal %s %w", kind, err)
+	}
+	if err := w.enc.Encode(&exportRecord{Kind: kind, Data: data}); err != nil {
+		return err
+	}
+	if kind != KindHeader {
+		w.counts[kind]++
+	}
+	return nil
+}
+
+// Export dump the contents of repo as jsonl, the first line is the header with version of the format.
+// The tokens of wallets and nodes are encrypted by cipher, or redacted if cipher is nil
+func Export(ctx context.Context, r repo.Repo, writer io.Writer, cipher *repo.TokenCipher) (*ExportResult, error) {
+	w := &exportWriter{enc: json.NewEncoder(writer), counts: make(map[string]int)}
+	if err := w.write(KindHeader, &ExportHeader{Version: ExportVersion, CreatedAt: time.Now()}); err != nil {
+		return nil, err
+	}
+
+	params, err := r.SharedParamsRepo().GetSharedParams(ctx)
+	if err != nil && !xerrors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err == nil {
+		if err := w.write(KindSharedParams, params); err != nil {
+			return nil, err
+		}
+	}
+
+	wallets, err := r.WalletRepo().ListWallet()
+	if err != nil {
+		return nil, err
+	}
+	for _, wallet := range wallets {
+		if wallet.Token, err = exportToken(wallet.Token, cipher); err != nil {
+			return nil, err
+		}
+		if err := w.write(KindWallet, wallet); err != nil {
+			return nil, err
+		}
+	}
+
+	addrs, err := r.AddressRepo().ListAddress(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, addr := range addrs {
+		if err := w.write(KindAddress, addr); err != nil {
+			return nil, err
+		}
+	}
+
+	was, err := r.WalletAddressRepo().ListWalletAddress()
+	if err != nil {
+		return nil, err
+	}
+	for _, wa := range was {
+		if err := w.write(KindWalletAddress, wa); err != nil {
+			return nil, err
+		}
+	}
+
+	nodes, err := r.NodeRepo().ListNode()
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range nodes {
+		if node.Token, err = exportToken(node.Token, cipher); err != nil {
+			return nil, err
+		}
+		if err := w.write(KindNode, node); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := exportMessages(ctx, w, KindMessage, r.MessageRepo().ListMessageAfterID); err != nil {
+		return nil, err
+	}
+	if err := exportMessages(ctx, w, KindArchivedMessage, r.ArchiveRepo().ListArchivedMessageAfterID); err != nil {
+		return nil, err
+	}
+
+	return &ExportResult{Counts: w.counts}, nil
+}
+
+func exportToken(token string, cipher *repo.TokenCipher) (string, error) {
+	if cipher == nil {
+		return types.RedactToken(token), nil
+	}
+	return cipher.Encrypt(token)
+}
+
+// importToken replace the exported token of wallet or node by the plaintext one, redacted token is imported as empty
+func importToken(kind string, v interface{}, cipher *repo.TokenCipher) (bool, error) {
+	var token *string
+	switch kind {
+	case KindWallet:
+		token = &v.(*types.Wallet).Token
+	case KindNode:
+		token = &v.(*types.Node).Token
+	default:
+		return false, nil
+	}
+	if *token == types.RedactedToken {
+		*token = ""
+		return true, nil
+	}
+	if !repo.IsEncryptedToken(*token) {
+		return false, nil
+	}
+	if cipher == nil {
+		return false, xerrors.Errorf("token of %s %s is encrypted, import with the token key of export", kind, recordKey(kind, v))
+	}
+	plain, err := cipher.Decrypt(*token)
+	if err != nil {
+		return false, xerrors.Errorf("%s %s %w", kind, recordKey(kind, v), err)
+	}
+	*token = plain
+	return false, nil
+}
+
+func exportMessages(ctx context.Context, w *exportWriter, kind string, list func(id string, limit int) ([]*types.Message, error)) error {
+	lastID := ""
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		msgs, err := list(lastID, exportPageSize)
+		if err != nil {
+			return err
+		}
+		for _, msg := range msgs {
+			if err := w.write(kind, msg); err != nil {
+				return err
+			}
+		}
+		if len(msgs) < exportPageSize {
+			return nil
+		}
+		lastID = msgs[len(msgs)-1].ID
+	}
+}
+
+// Import load the records written by Export, all records are checked for conflicts with the existing data and each other
+// before anything is written, then they are written in one transaction. open is called for each pass over the records
+func Import(ctx context.Context, r repo.Repo, open func() (io.ReadCloser, error), opts ImportOptions) (*ImportResult, error) {
+	result := &ImportResult{
+		DryRun:         opts.DryRun,
+		Counts:         make(map[string]int),
+		Imported:       make(map[string]int),
+		Conflicts:      []string{},
+		RedactedTokens: []string{},
+	}
+
+	// first pass, find conflicts, the wallet addresses referencing the conflicted wallets and addresses conflict too,
+	// as those wallets and addresses are not imported with the ids in file
+	seen := make(map[string]map[string]struct{})
+	skipped := make(map[types.UUID]string)
+	conflicts := make(map[string]map[string]struct{})
+	addKey := func(keys map[string]map[string]struct{}, kind, key string) {
+		if _, ok := keys[kind]; !ok {
+			keys[kind] = make(map[string]struct{})
+		}
+		keys[kind][key] = struct{}{}
+	}
+	err := walkRecords(ctx, open, func(kind string, v interface{}) error {
+		result.Counts[kind]++
+		if _, err := importToken(kind, v, opts.TokenCipher); err != nil {
+			return err
+		}
+		key, reason, err := checkConflict(ctx, r, kind, v)
+		if err != nil {
+			return err
+		}
+		if _, ok := seen[kind][key]; ok {
+			reason = "duplicated in file"
+		}
+		addKey(seen, kind, key)
+		if len(reason) == 0 && kind == KindWalletAddress {
+			wa := v.(*types.WalletAddress)
+			if ref, ok := skipped[wa.WalletID]; ok {
+				reason = ref + " is not imported"
+			} else if ref, ok := skipped[wa.AddrID]; ok {
+				reason = ref + " is not imported"
+			}
+		}
+		if len(reason) > 0 {
+			switch kind {
+			case KindWallet:
+				skipped[v.(*types.Wallet).ID] = kind + " " + key
+			case KindAddress:
+				skipped[v.(*types.Address).ID] = kind + " " + key
+			}
+			addKey(conflicts, kind, key)
+			result.Conflicts = append(result.Conflicts, kind+" "+key+": "+reason)
+		}
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+	if len(result.Conflicts) > 0 && !opts.SkipConflict {
+		return result, xerrors.Errorf("found %d conflicts", len(result.Conflicts))
+	}
+	if opts.DryRun {
+		return result, nil
+	}
+
+	// second pass, write records not conflicted, nothing is written if any fails
+	imported := make(map[string]int)
+	redactedTokens := []string{}
+	err = r.Transaction(func(txRepo repo.TxRepo) error {
+		return walkRecords(ctx, open, func(kind string, v interface{}) error {
+			if _, ok := conflicts[kind][recordKey(kind, v)]; ok {
+				return nil
+			}
+			redacted, err := importToken(kind, v, opts.TokenCipher)
+			if err != nil {
+				return err
+			}
+			if redacted {
+				redactedTokens = append(redactedTokens, kind+" "+recordKey(kind, v))
+			}
+			if err := importRecord(ctx, txRepo, kind, v); err != nil {
+				return xerrors.Errorf("import %s %s %w", kind, recordKey(kind, v), err)
+			}
+			imported[kind]++
+			return nil
+		})
+	})
+	if err != nil {
+		return result, err
+	}
+	result.Imported = imported
+	result.RedactedTokens = redactedTokens
+	return result, nil
+}
+
+func walkRecords(ctx context.Context, open func() (io.ReadCloser, error), cb func(kind string, v interface{}) error) error {
+	reader, err := open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close() // nolint
+
+	dec := json.NewDecoder(reader)
+	var header ExportHeader
+	var record exportRecord
+	if err := dec.Decode(&record); err != nil {
+		return xerrors.Errorf("read header %w", err)
+	}
+	if record.Kind != KindHeader {
+		return xerrors.Errorf("expect header as the first record, got %s", record.Kind)
+	}
+	if err := json.Unmarshal(record.Data, &header); err != nil {
+		return err
+	}
+	if header.Version != ExportVersion {
+		return xerrors.Errorf("unsupport export version %d, expect %d", header.Version, ExportVersion)
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var record exportRecord
+		if err := dec.Decode(&record); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		v, err := newRecordValue(record.Kind)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(record.Data, v); err != nil {
+			return xerrors.Errorf("unmarshal %s %w", record.Kind, err)
+		}
+		if err := cb(record.Kind, v); err != nil {
+			return err
+		}
+	}
+}
+
+func newRecordValue(kind string) (interface{}, error) {
+	switch kind {
+	case KindSharedParams:
+		return &types.SharedParams{}, nil
+	case KindWallet:
+		return &types.Wallet{}, nil
+	case KindAddress:
+		return &types.Address{}, nil
+	case KindWalletAddress:
+		return &types.WalletAddress{}, nil
+	case KindNode:
+		return &types.Node{}, nil
+	case KindMessage, KindArchivedMessage:
+		return &types.Message{}, nil
+	default:
+		return nil, xerrors.Errorf("unknown record kind %s", kind)
+	}
+}
+
+func recordKey(kind string, v interface{}) string {
+	switch kind {
+	case KindSharedParams:
+		return "params"
+	case KindWallet:
+		return v.(*types.Wallet).Name
+	case KindAddress:
+		return v.(*types.Address).Addr.String()
+	case KindWalletAddress:
+		wa := v.(*types.WalletAddress)
+		return wa.WalletID.String() + "/" + wa.AddrID.String()
+	case KindNode:
+		return v.(*types.Node).Name
+	default:
+		return v.(*types.Message).ID
+	}
+}
+
+// checkConflict return the reason if the record conflicts with the existing data
+func checkConflict(ctx context.Context, r repo.Repo, kind string, v interface{}) (string, string, error) {
+	key := recordKey(kind, v)
+	found := func(_ interface{}, err error) (bool, error) {
+		if err == nil {
+			return true, nil
+		}
+		if xerrors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	var exist bool
+	var err error
+	reason := "already exists"
+	switch kind {
+	case KindSharedParams:
+		params, getErr := r.SharedParamsRepo().GetSharedParams(ctx)
+		if exist, err = found(params, getErr); exist {
+			exist = !reflect.DeepEqual(params, v)
+			reason = "different shared params exists"
+		}
+	case KindWallet:
+		exist, err = found(r.WalletRepo().GetOneRecord(key))
+	case KindAddress:
+		exist, err = found(r.AddressRepo().GetOneRecord(ctx, v.(*types.Address).Addr))
+	case KindWalletAddress:
+		wa := v.(*types.WalletAddress)
+		exist, err = found(r.WalletAddressRepo().GetOneRecord(wa.WalletID, wa.AddrID))
+	case KindNode:
+		exist, err = r.NodeRepo().HasNode(key)
+	case KindMessage, KindArchivedMessage:
+		msg := v.(*types.Message)
+		if exist, err = r.MessageRepo().HasMessageByUid(msg.ID); err != nil || exist {
+			break
+		}
+		if exist, err = r.ArchiveRepo().HasArchivedMessageByUid(msg.ID); err != nil || exist {
+			break
+		}
+		// nonce is assigned only after the message is signed
+		if kind == KindMessage && msg.Signature != nil {
+			exist, err = found(r.MessageRepo().GetMessageByFromAndNonce(msg.From, msg.Nonce))
+			reason = "nonce is used by another message"
+		}
+	}
+	if err != nil {
+		return key, "", err
+	}
+	if !exist {
+		return key, "", nil
+	}
+	return key, reason, nil
+}
+
+func importRecord(ctx context.Context, r repo.TxRepo, kind string, v interface{}) error {
+	switch kind {
+	case KindSharedParams:
+		_, err := r.SharedParamsRepo().SetSharedParams(ctx, v.(*types.SharedParams))
+		return err
+	case KindWallet:
+		return r.WalletRepo().SaveWallet(v.(*types.Wallet))
+	case KindAddress:
+		return r.AddressRepo().SaveAddress(ctx, v.(*types.Address))
+	case KindWalletAddress:
+		return r.WalletAddressRepo().SaveWalletAddress(v.(*types.WalletAddress))
+	case KindNode:
+		return r.NodeRepo().CreateNode(v.(*types.Node))
+	case KindMessage:
+		return r.MessageRepo().CreateMessage(v.(*types.Message))
+	case KindArchivedMessage:
+		return r.ArchiveRepo().ArchiveMessage([]*types.Message{v.(*types.Message)})
+	default:
+		return xerrors.Errorf("unknown record kind %s", kind)
+	}
+}
@@ -0,0 +1,204 @@
+package models
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/filecoin-project/venus-messager/config"
+	"github.com/filecoin-project/venus-messager/models/repo"
+	"github.com/filecoin-project/venus-messager/models/sqlite"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+func TestExportAndImport(t *testing.T) {
+	ctx := context.Background()
+	src := openTempSqlite(t, "src.db")
+	dst := openTempSqlite(t, "dst.db")
+
+	_, err := src.SharedParamsRepo().SetSharedParams(ctx, &types.SharedParams{ID: 1, SelMsgNum: 20, ScanInterval: 10})
+	assert.NoError(t, err)
+	wallet := &types.Wallet{ID: types.NewUUID(), Name: "wallet", Url: "url", Token: "token", State: types.Alive, IsDeleted: repo.NotDeleted,
+		CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	assert.NoError(t, src.WalletRepo().SaveWallet(wallet))
+	addr, err := address.NewIDAddress(100)
+	assert.NoError(t, err)
+	addrInfo := &types.Address{ID: types.NewUUID(), Addr: addr, Nonce: 5, IsDeleted: repo.NotDeleted, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	assert.NoError(t, src.AddressRepo().SaveAddress(ctx, addrInfo))
+	assert.NoError(t, src.WalletAddressRepo().SaveWalletAddress(&types.WalletAddress{ID: types.NewUUID(), WalletID: wallet.ID,
+		AddrID: addrInfo.ID, AddressState: types.Alive, IsDeleted: repo.NotDeleted}))
+	node := randNode()
+	assert.NoError(t, src.NodeRepo().CreateNode(node))
+
+	msgs := NewSignedMessages(3)
+	msgs = append(msgs, NewMessage())
+	for _, msg := range msgs {
+		msg.State = types.FillMsg
+		assert.NoError(t, src.MessageRepo().CreateMessage(msg))
+	}
+	archived := NewSignedMessages(1)[0]
+	archived.State = types.OnChainMsg
+	assert.NoError(t, src.ArchiveRepo().ArchiveMessage([]*types.Message{archived}))
+
+	buf := &bytes.Buffer{}
+	exportResult, err := Export(ctx, src, buf, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{
+		KindSharedParams:    1,
+		KindWallet:          1,
+		KindAddress:         1,
+		KindWalletAddress:   1,
+		KindNode:            1,
+		KindMessage:         4,
+		KindArchivedMessage: 1,
+	}, exportResult.Counts)
+
+	open := func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(buf.Bytes())), nil
+	}
+
+	// dry run writes nothing
+	importResult, err := Import(ctx, dst, open, ImportOptions{DryRun: true})
+	assert.NoError(t, err)
+	assert.Equal(t, exportResult.Counts, importResult.Counts)
+	assert.Len(t, importResult.Imported, 0)
+	has, err := dst.MessageRepo().HasMessageByUid(msgs[0].ID)
+	assert.NoError(t, err)
+	assert.False(t, has)
+
+	// records duplicated in file conflict with each other
+	duplicated := append(append([]byte{}, buf.Bytes()...), buf.Bytes()[bytes.IndexByte(buf.Bytes(), '\n')+1:]...)
+	importResult, err = Import(ctx, dst, func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(duplicated)), nil
+	}, ImportOptions{})
+	assert.Error(t, err)
+	assert.Contains(t, importResult.Conflicts, KindWallet+" "+wallet.Name+": duplicated in file")
+
+	// nothing is written if the write pass fails
+	opened := 0
+	importResult, err = Import(ctx, dst, func() (io.ReadCloser, error) {
+		opened++
+		data := buf.Bytes()
+		if opened > 1 {
+			data = append(append([]byte{}, data...), []byte("broken")...)
+		}
+		return ioutil.NopCloser(bytes.NewReader(data)), nil
+	}, ImportOptions{})
+	assert.Error(t, err)
+	assert.Len(t, importResult.Imported, 0)
+	has, err = dst.MessageRepo().HasMessageByUid(msgs[0].ID)
+	assert.NoError(t, err)
+	assert.False(t, has)
+	has, err = dst.NodeRepo().HasNode(node.Name)
+	assert.NoError(t, err)
+	assert.False(t, has)
+
+	importResult, err = Import(ctx, dst, open, ImportOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, exportResult.Counts, importResult.Imported)
+	// tokens are redacted without token key
+	assert.Len(t, importResult.RedactedTokens, 2)
+	dstWallet, err := dst.WalletRepo().GetWalletByName(wallet.Name)
+	assert.NoError(t, err)
+	assert.Empty(t, dstWallet.Token)
+	for _, msg := range msgs {
+		srcMsg, err := src.MessageRepo().GetMessageByUid(msg.ID)
+		assert.NoError(t, err)
+		dstMsg, err := dst.MessageRepo().GetMessageByUid(msg.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, ObjectToString(srcMsg), ObjectToString(dstMsg))
+	}
+	dstArchived, err := dst.ArchiveRepo().GetArchivedMessageByUid(archived.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, archived.ID, dstArchived.ID)
+	dstAddr, err := dst.AddressRepo().GetAddress(ctx, addr)
+	assert.NoError(t, err)
+	assert.Equal(t, addrInfo.Nonce, dstAddr.Nonce)
+
+	// everything conflicts now
+	importResult, err = Import(ctx, dst, open, ImportOptions{})
+	assert.Error(t, err)
+	assert.Len(t, importResult.Conflicts, 9)
+	assert.Len(t, importResult.Imported, 0)
+
+	importResult, err = Import(ctx, dst, open, ImportOptions{SkipConflict: true})
+	assert.NoError(t, err)
+	// the same shared params is not a conflict
+	assert.Equal(t, map[string]int{KindSharedParams: 1}, importResult.Imported)
+
+	// wallet address of the wallet skipped by conflict is skipped too, rather than referencing the skipped wallet
+	other := openTempSqlite(t, "other.db")
+	otherWallet := &types.Wallet{ID: types.NewUUID(), Name: wallet.Name, Url: "other", State: types.Alive, IsDeleted: repo.NotDeleted,
+		CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	assert.NoError(t, other.WalletRepo().SaveWallet(otherWallet))
+	importResult, err = Import(ctx, other, open, ImportOptions{SkipConflict: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		KindWallet + " " + wallet.Name + ": already exists",
+		KindWalletAddress + " " + wallet.ID.String() + "/" + addrInfo.ID.String() + ": " + KindWallet + " " + wallet.Name + " is not imported",
+	}, importResult.Conflicts)
+	assert.Equal(t, 1, importResult.Imported[KindAddress])
+	assert.Equal(t, 0, importResult.Imported[KindWalletAddress])
+	was, err := other.WalletAddressRepo().ListWalletAddress()
+	assert.NoError(t, err)
+	assert.Len(t, was, 0)
+}
+
+func TestExportTokens(t *testing.T) {
+	ctx := context.Background()
+	src := openTempSqlite(t, "src.db")
+	dst := openTempSqlite(t, "dst.db")
+
+	wallet := &types.Wallet{ID: types.NewUUID(), Name: "wallet", Url: "url", Token: "wallet-token", State: types.Alive, IsDeleted: repo.NotDeleted}
+	assert.NoError(t, src.WalletRepo().SaveWallet(wallet))
+	node := randNode()
+	node.Token = "node-token"
+	assert.NoError(t, src.NodeRepo().CreateNode(node))
+
+	key, err := config.GenerateTokenKey()
+	assert.NoError(t, err)
+	rawKey, err := (&config.TokenEncryptionConfig{Key: key}).ReadKey()
+	assert.NoError(t, err)
+	tokenCipher, err := repo.NewTokenCipher(rawKey)
+	assert.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	_, err = Export(ctx, src, buf, tokenCipher)
+	assert.NoError(t, err)
+	assert.NotContains(t, buf.String(), "wallet-token")
+	assert.NotContains(t, buf.String(), "node-token")
+	open := func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(buf.Bytes())), nil
+	}
+
+	// the key of export is required
+	_, err = Import(ctx, dst, open, ImportOptions{})
+	assert.Error(t, err)
+	has, err := dst.NodeRepo().HasNode(node.Name)
+	assert.NoError(t, err)
+	assert.False(t, has)
+
+	importResult, err := Import(ctx, dst, open, ImportOptions{TokenCipher: tokenCipher})
+	assert.NoError(t, err)
+	assert.Len(t, importResult.RedactedTokens, 0)
+	dstWallet, err := dst.WalletRepo().GetWalletByName(wallet.Name)
+	assert.NoError(t, err)
+	assert.Equal(t, "wallet-token", dstWallet.Token)
+	dstNode, err := dst.NodeRepo().GetNode(node.Name)
+	assert.NoError(t, err)
+	assert.Equal(t, "node-token", dstNode.Token)
+}
+
+func openTempSqlite(t *testing.T, name string) repo.Repo {
+	r, err := sqlite.OpenSqlite(&config.SqliteConfig{Path: filepath.Join(t.TempDir(), name)})
+	assert.NoError(t, err)
+	assert.NoError(t, r.AutoMigrate())
+	return r
+}
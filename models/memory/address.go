@@ -0,0 +1,130 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-messager/models/repo"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+func cloneAddress(src *types.Address) *types.Address {
+	addr := *src
+	return &addr
+}
+
+// firstAddress return the copy of matched address with the smallest id
+func firstAddress(table map[types.UUID]*types.Address, match func(addr *types.Address) bool) (*types.Address, error) {
+	var first *types.Address
+	for _, addr := range table {
+		if match(addr) && (first == nil || uuidLess(addr.ID, first.ID)) {
+			first = addr
+		}
+	}
+	if first == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return cloneAddress(first), nil
+}
+
+var _ repo.AddressRepo = (*memoryAddressRepo)(nil)
+
+type memoryAddressRepo struct {
+	store
+}
+
+func newMemoryAddressRepo(s store) *memoryAddressRepo {
+	return &memoryAddressRepo{store: s}
+}
+
+func (s *memoryAddressRepo) SaveAddress(ctx context.Context, addr *types.Address) error {
+	newAddr := cloneAddress(addr)
+	newAddr.UpdatedAt = time.Now()
+	return s.update(func(tx *txTables) error {
+		if newAddr.CreatedAt.IsZero() {
+			if exist, ok := tx.addresses[addr.ID]; ok {
+				newAddr.CreatedAt = exist.CreatedAt
+			} else {
+				newAddr.CreatedAt = time.Now()
+			}
+		}
+		tx.writeAddresses()[addr.ID] = newAddr
+		return nil
+	})
+}
+
+// updateAddress change all records of the address
+func (s *memoryAddressRepo) updateAddress(addr address.Address, change func(a *types.Address)) error {
+	return s.update(func(tx *txTables) error {
+		for id, a := range tx.addresses {
+			if a.Addr != addr {
+				continue
+			}
+			newAddr := cloneAddress(a)
+			change(newAddr)
+			tx.writeAddresses()[id] = newAddr
+		}
+		return nil
+	})
+}
+
+func (s *memoryAddressRepo) UpdateAddress(ctx context.Context, addr *types.Address) error {
+	return s.updateAddress(addr.Addr, func(a *types.Address) {
+		a.Nonce = addr.Nonce
+		a.IsDeleted = addr.IsDeleted
+		a.UpdatedAt = time.Now()
+	})
+}
+
+func (s *memoryAddressRepo) UpdateNonce(ctx context.Context, addr address.Address, nonce uint64) error {
+	return s.updateAddress(addr, func(a *types.Address) {
+		a.Nonce = nonce
+	})
+}
+
+func (s *memoryAddressRepo) HasAddress(ctx context.Context, addr address.Address) (bool, error) {
+	for _, a := range s.view().addresses {
+		if a.Addr == addr {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *memoryAddressRepo) GetAddress(ctx context.Context, addr address.Address) (*types.Address, error) {
+	return firstAddress(s.view().addresses, func(a *types.Address) bool {
+		return a.Addr == addr && a.IsDeleted == repo.NotDeleted
+	})
+}
+
+func (s *memoryAddressRepo) GetOneRecord(ctx context.Context, addr address.Address) (*types.Address, error) {
+	return firstAddress(s.view().addresses, func(a *types.Address) bool {
+		return a.Addr == addr
+	})
+}
+
+func (s *memoryAddressRepo) DelAddress(ctx context.Context, addr address.Address) error {
+	return s.updateAddress(addr, func(a *types.Address) {
+		if a.IsDeleted == repo.NotDeleted {
+			a.IsDeleted = repo.Deleted
+			a.UpdatedAt = time.Now()
+		}
+	})
+}
+
+func (s *memoryAddressRepo) ListAddress(ctx context.Context) ([]*types.Address, error) {
+	result := make([]*types.Address, 0)
+	for _, a := range s.view().addresses {
+		if a.IsDeleted == repo.NotDeleted {
+			result = append(result, cloneAddress(a))
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return uuidLess(result[i].ID, result[j].ID)
+	})
+	return result, nil
+}
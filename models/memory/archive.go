@@ -0,0 +1,61 @@
+package memory
+
+import (
+	"github.com/ipfs/go-cid"
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-messager/models/repo"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+var _ repo.ArchiveRepo = (*memoryArchiveRepo)(nil)
+
+type memoryArchiveRepo struct {
+	store
+}
+
+func newMemoryArchiveRepo(s store) *memoryArchiveRepo {
+	return &memoryArchiveRepo{store: s}
+}
+
+func (s *memoryArchiveRepo) ArchiveMessage(msgs []*types.Message) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	return s.update(func(tx *txTables) error {
+		archived := tx.writeArchived()
+		for _, msg := range msgs {
+			archived[msg.ID] = cloneMessage(msg)
+		}
+		return nil
+	})
+}
+
+func (s *memoryArchiveRepo) HasArchivedMessageByUid(id string) (bool, error) {
+	_, ok := s.view().archived[id]
+	return ok, nil
+}
+
+func (s *memoryArchiveRepo) GetArchivedMessageByUid(id string) (*types.Message, error) {
+	msg, ok := s.view().archived[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return cloneMessage(msg), nil
+}
+
+func (s *memoryArchiveRepo) GetArchivedMessageByCid(unsignedCid cid.Cid) (*types.Message, error) {
+	return firstMessage(s.view().archived, func(msg *types.Message) bool {
+		return msg.UnsignedCid != nil && msg.UnsignedCid.Equals(unsignedCid)
+	})
+}
+
+func (s *memoryArchiveRepo) GetArchivedMessageBySignedCid(signedCid cid.Cid) (*types.Message, error) {
+	return firstMessage(s.view().archived, func(msg *types.Message) bool {
+		return msg.SignedCid != nil && msg.SignedCid.Equals(signedCid)
+	})
+}
+
+func (s *memoryArchiveRepo) ListArchivedMessageAfterID(id string, limit int) ([]*types.Message, error) {
+	return listMessageAfterID(s.view().archived, id, limit), nil
+}
@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/filecoin-project/venus-messager/models/repo"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+func cloneAuditLog(src *types.AuditLog) *types.AuditLog {
+	log := *src
+	log.Args = cloneRawJSON(src.Args)
+	log.Before = cloneRawJSON(src.Before)
+	log.After = cloneRawJSON(src.After)
+	return &log
+}
+
+// cloneRawJSON keep empty value as null like sql repo
+func cloneRawJSON(src json.RawMessage) json.RawMessage {
+	if len(src) == 0 {
+		return nil
+	}
+	return cloneBytes(src)
+}
+
+var _ repo.AuditRepo = (*memoryAuditRepo)(nil)
+
+type memoryAuditRepo struct {
+	store
+}
+
+func newMemoryAuditRepo(s store) *memoryAuditRepo {
+	return &memoryAuditRepo{store: s}
+}
+
+func (s *memoryAuditRepo) SaveAuditLog(log *types.AuditLog) error {
+	return s.update(func(tx *txTables) error {
+		tx.appendAuditLog(cloneAuditLog(log))
+		return nil
+	})
+}
+
+func (s *memoryAuditRepo) ListAuditLog(filter *types.AuditLogFilter) ([]*types.AuditLog, error) {
+	result := make([]*types.AuditLog, 0)
+	for _, log := range s.view().auditLogs {
+		if len(filter.Caller) > 0 && log.Caller != filter.Caller {
+			continue
+		}
+		if len(filter.Method) > 0 && log.Method != filter.Method {
+			continue
+		}
+		if len(filter.Target) > 0 && log.Target != filter.Target {
+			continue
+		}
+		if !filter.Start.IsZero() && log.CreatedAt.Before(filter.Start) {
+			continue
+		}
+		if !filter.End.IsZero() && !log.CreatedAt.Before(filter.End) {
+			continue
+		}
+		result = append(result, cloneAuditLog(log))
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+	if filter.Limit > 0 && len(result) > filter.Limit {
+		result = result[:filter.Limit]
+	}
+	return result, nil
+}
@@ -0,0 +1,390 @@
+package memory
+
+import (
+	"bytes"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/xerrors"
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-messager/models/repo"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+// tables hold all records of the memory repo, a record is never changed after it is put into a table,
+// every write puts a new copy, so that a transaction can share the records with the committed tables
+type tables struct {
+	messages        map[string]*types.Message
+	archived        map[string]*types.Message
+	addresses       map[types.UUID]*types.Address
+	wallets         map[types.UUID]*types.Wallet
+	walletAddresses map[types.UUID]*types.WalletAddress
+	nodes           map[types.UUID]*memoryNode
+	sharedParams    *types.SharedParams
+	auditLogs       []*types.AuditLog
+	// versions of each target ordered by version
+	paramsVersions map[string][]*types.ParamsVersion
+	msigProposals  map[string]*types.MsigProposal
+}
+
+func newTables() *tables {
+	return &tables{
+		messages:        map[string]*types.Message{},
+		archived:        map[string]*types.Message{},
+		addresses:       map[types.UUID]*types.Address{},
+		wallets:         map[types.UUID]*types.Wallet{},
+		walletAddresses: map[types.UUID]*types.WalletAddress{},
+		nodes:           map[types.UUID]*memoryNode{},
+		paramsVersions:  map[string][]*types.ParamsVersion{},
+		msigProposals:   map[string]*types.MsigProposal{},
+	}
+}
+
+// txTables is the copy-on-write view of tables used by a write, a table is copied when it is written the first time
+type txTables struct {
+	tables
+
+	messagesCopied        bool
+	archivedCopied        bool
+	addressesCopied       bool
+	walletsCopied         bool
+	walletAddressesCopied bool
+	nodesCopied           bool
+	auditLogsCopied       bool
+	paramsVersionsCopied  bool
+	msigProposalsCopied   bool
+}
+
+func newTxTables(base *tables) *txTables {
+	return &txTables{tables: *base}
+}
+
+func (t *txTables) writeMessages() map[string]*types.Message {
+	if !t.messagesCopied {
+		t.messages = copyMessageTable(t.messages)
+		t.messagesCopied = true
+	}
+	return t.messages
+}
+
+func (t *txTables) writeArchived() map[string]*types.Message {
+	if !t.archivedCopied {
+		t.archived = copyMessageTable(t.archived)
+		t.archivedCopied = true
+	}
+	return t.archived
+}
+
+func (t *txTables) writeAddresses() map[types.UUID]*types.Address {
+	if !t.addressesCopied {
+		addresses := make(map[types.UUID]*types.Address, len(t.addresses))
+		for id, addr := range t.addresses {
+			addresses[id] = addr
+		}
+		t.addresses = addresses
+		t.addressesCopied = true
+	}
+	return t.addresses
+}
+
+func (t *txTables) writeWallets() map[types.UUID]*types.Wallet {
+	if !t.walletsCopied {
+		wallets := make(map[types.UUID]*types.Wallet, len(t.wallets))
+		for id, wallet := range t.wallets {
+			wallets[id] = wallet
+		}
+		t.wallets = wallets
+		t.walletsCopied = true
+	}
+	return t.wallets
+}
+
+func (t *txTables) writeWalletAddresses() map[types.UUID]*types.WalletAddress {
+	if !t.walletAddressesCopied {
+		walletAddresses := make(map[types.UUID]*types.WalletAddress, len(t.walletAddresses))
+		for id, wa := range t.walletAddresses {
+			walletAddresses[id] = wa
+		}
+		t.walletAddresses = walletAddresses
+		t.walletAddressesCopied = true
+	}
+	return t.walletAddresses
+}
+
+func (t *txTables) writeNodes() map[types.UUID]*memoryNode {
+	if !t.nodesCopied {
+		nodes := make(map[types.UUID]*memoryNode, len(t.nodes))
+		for id, node := range t.nodes {
+			nodes[id] = node
+		}
+		t.nodes = nodes
+		t.nodesCopied = true
+	}
+	return t.nodes
+}
+
+func (t *txTables) appendAuditLog(log *types.AuditLog) {
+	if !t.auditLogsCopied {
+		t.auditLogs = append(make([]*types.AuditLog, 0, len(t.auditLogs)+1), t.auditLogs...)
+		t.auditLogsCopied = true
+	}
+	t.auditLogs = append(t.auditLogs, log)
+}
+
+// putParamsVersion insert v into the versions of target in order, the versions are copied because they may be shared with the base tables
+func (t *txTables) putParamsVersion(v *types.ParamsVersion) {
+	if !t.paramsVersionsCopied {
+		paramsVersions := make(map[string][]*types.ParamsVersion, len(t.paramsVersions))
+		for target, versions := range t.paramsVersions {
+			paramsVersions[target] = versions
+		}
+		t.paramsVersions = paramsVersions
+		t.paramsVersionsCopied = true
+	}
+	versions := t.paramsVersions[v.Target]
+	newVersions := make([]*types.ParamsVersion, 0, len(versions)+1)
+	idx := sort.Search(len(versions), func(i int) bool {
+		return versions[i].Version > v.Version
+	})
+	newVersions = append(newVersions, versions[:idx]...)
+	newVersions = append(newVersions, v)
+	newVersions = append(newVersions, versions[idx:]...)
+	t.paramsVersions[v.Target] = newVersions
+}
+
+func (t *txTables) writeMsigProposals() map[string]*types.MsigProposal {
+	if !t.msigProposalsCopied {
+		msigProposals := make(map[string]*types.MsigProposal, len(t.msigProposals))
+		for id, p := range t.msigProposals {
+			msigProposals[id] = p
+		}
+		t.msigProposals = msigProposals
+		t.msigProposalsCopied = true
+	}
+	return t.msigProposals
+}
+
+func copyMessageTable(src map[string]*types.Message) map[string]*types.Message {
+	dst := make(map[string]*types.Message, len(src))
+	for id, msg := range src {
+		dst[id] = msg
+	}
+	return dst
+}
+
+// store is where the sub repos read and write tables
+type store interface {
+	view() *tables
+	update(func(tx *txTables) error) error
+}
+
+var _ repo.Repo = (*MemoryRepo)(nil)
+
+// ErrReentrantWrite is returned by the writes of MemoryRepo in the callback of its own Transaction,
+// which would wait for the transaction forever, the callback should write by the TxRepo passed to it
+var ErrReentrantWrite = xerrors.New("memory repo is written in the callback of its transaction")
+
+// MemoryRepo keep all records in memory, nothing is persisted, it is used for tests and ephemeral runs.
+// Writes are serialized, a transaction works on a copy-on-write view and replaces the tables when it commits,
+// writing MemoryRepo in the callback of Transaction fails with ErrReentrantWrite, reading it returns the records
+// before the transaction.
+type MemoryRepo struct {
+	writeLk sync.Mutex
+	// id of the goroutine holding writeLk, 0 if it is not held
+	writer int64
+
+	lk   sync.RWMutex
+	data *tables
+}
+
+func OpenMemory() (repo.Repo, error) {
+	return &MemoryRepo{data: newTables()}, nil
+}
+
+func (d *MemoryRepo) view() *tables {
+	d.lk.RLock()
+	defer d.lk.RUnlock()
+	return d.data
+}
+
+// lockWrite acquire writeLk, the goroutine already holding it gets ErrReentrantWrite rather than a deadlock
+func (d *MemoryRepo) lockWrite() (func(), error) {
+	gid := goroutineID()
+	if atomic.LoadInt64(&d.writer) == gid {
+		return nil, ErrReentrantWrite
+	}
+	d.writeLk.Lock()
+	atomic.StoreInt64(&d.writer, gid)
+	return func() {
+		atomic.StoreInt64(&d.writer, 0)
+		d.writeLk.Unlock()
+	}, nil
+}
+
+// goroutineID parse the id of current goroutine from its stack, go doesn't expose it otherwise
+func goroutineID() int64 {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	// the stack starts with "goroutine <id> ["
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+	if idx := bytes.IndexByte(buf, ' '); idx >= 0 {
+		buf = buf[:idx]
+	}
+	id, _ := strconv.ParseInt(string(buf), 10, 64)
+	return id
+}
+
+func (d *MemoryRepo) update(cb func(tx *txTables) error) error {
+	unlock, err := d.lockWrite()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	tx := newTxTables(d.view())
+	if err := cb(tx); err != nil {
+		return err
+	}
+	d.commit(tx)
+	return nil
+}
+
+func (d *MemoryRepo) commit(tx *txTables) {
+	committed := tx.tables
+	d.lk.Lock()
+	d.data = &committed
+	d.lk.Unlock()
+}
+
+func (d *MemoryRepo) MessageRepo() repo.MessageRepo {
+	return newMemoryMessageRepo(d)
+}
+
+func (d *MemoryRepo) MessageQueryRepo() repo.MessageQueryRepo {
+	return newMemoryMessageRepo(d)
+}
+
+func (d *MemoryRepo) WalletRepo() repo.WalletRepo {
+	return newMemoryWalletRepo(d)
+}
+
+func (d *MemoryRepo) AddressRepo() repo.AddressRepo {
+	return newMemoryAddressRepo(d)
+}
+
+func (d *MemoryRepo) SharedParamsRepo() repo.SharedParamsRepo {
+	return newMemorySharedParamsRepo(d)
+}
+
+func (d *MemoryRepo) NodeRepo() repo.NodeRepo {
+	return newMemoryNodeRepo(d)
+}
+
+func (d *MemoryRepo) WalletAddressRepo() repo.WalletAddressRepo {
+	return newMemoryWalletAddressRepo(d)
+}
+
+func (d *MemoryRepo) ArchiveRepo() repo.ArchiveRepo {
+	return newMemoryArchiveRepo(d)
+}
+
+func (d *MemoryRepo) AuditRepo() repo.AuditRepo {
+	return newMemoryAuditRepo(d)
+}
+
+func (d *MemoryRepo) ParamsVersionRepo() repo.ParamsVersionRepo {
+	return newMemoryParamsVersionRepo(d)
+}
+
+func (d *MemoryRepo) MultisigRepo() repo.MultisigRepo {
+	return newMemoryMultisigRepo(d)
+}
+
+// Migrator memory repo is always at the latest schema, there is no migration
+func (d *MemoryRepo) Migrator() (*repo.Migrator, error) {
+	return nil, repo.ErrMigrationNotSupported
+}
+
+func (d *MemoryRepo) AutoMigrate() error {
+	return nil
+}
+
+// GetDb memory repo has no sql database
+func (d *MemoryRepo) GetDb() *gorm.DB {
+	return nil
+}
+
+// Transaction run cb on a copy-on-write view of tables, changes are committed if cb returns nil
+func (d *MemoryRepo) Transaction(cb func(txRepo repo.TxRepo) error) error {
+	unlock, err := d.lockWrite()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	tx := newTxTables(d.view())
+	if err := cb(&TxMemoryRepo{tx: tx}); err != nil {
+		return err
+	}
+	d.commit(tx)
+	return nil
+}
+
+func (d *MemoryRepo) DbClose() error {
+	return nil
+}
+
+var _ repo.TxRepo = (*TxMemoryRepo)(nil)
+
+type TxMemoryRepo struct {
+	tx *txTables
+}
+
+func (t *TxMemoryRepo) view() *tables {
+	return &t.tx.tables
+}
+
+func (t *TxMemoryRepo) update(cb func(tx *txTables) error) error {
+	return cb(t.tx)
+}
+
+func (t *TxMemoryRepo) WalletAddressRepo() repo.WalletAddressRepo {
+	return newMemoryWalletAddressRepo(t)
+}
+
+func (t *TxMemoryRepo) ArchiveRepo() repo.ArchiveRepo {
+	return newMemoryArchiveRepo(t)
+}
+
+func (t *TxMemoryRepo) SharedParamsRepo() repo.SharedParamsRepo {
+	return newMemorySharedParamsRepo(t)
+}
+
+func (t *TxMemoryRepo) ParamsVersionRepo() repo.ParamsVersionRepo {
+	return newMemoryParamsVersionRepo(t)
+}
+
+func (t *TxMemoryRepo) WalletRepo() repo.WalletRepo {
+	return newMemoryWalletRepo(t)
+}
+
+func (t *TxMemoryRepo) MessageRepo() repo.MessageRepo {
+	return newMemoryMessageRepo(t)
+}
+
+func (t *TxMemoryRepo) AddressRepo() repo.AddressRepo {
+	return newMemoryAddressRepo(t)
+}
+
+func (t *TxMemoryRepo) NodeRepo() repo.NodeRepo {
+	return newMemoryNodeRepo(t)
+}
+
+// uuidLess order records by id like the primary key of sql table
+func uuidLess(a, b types.UUID) bool {
+	return bytes.Compare(a[:], b[:]) < 0
+}
@@ -0,0 +1,486 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	venustypes "github.com/filecoin-project/venus/pkg/types"
+	"github.com/ipfs/go-cid"
+	"golang.org/x/xerrors"
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-messager/models/repo"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+// cloneMessage deep copy message so that the records in tables are not shared with callers
+func cloneMessage(src *types.Message) *types.Message {
+	msg := *src
+	msg.Params = cloneBytes(src.Params)
+	msg.Value = cloneInt(src.Value)
+	msg.GasFeeCap = cloneInt(src.GasFeeCap)
+	msg.GasPremium = cloneInt(src.GasPremium)
+	if src.UnsignedCid != nil {
+		unsignedCid := *src.UnsignedCid
+		msg.UnsignedCid = &unsignedCid
+	}
+	if src.SignedCid != nil {
+		signedCid := *src.SignedCid
+		msg.SignedCid = &signedCid
+	}
+	if src.Signature != nil {
+		signature := *src.Signature
+		signature.Data = cloneBytes(src.Signature.Data)
+		msg.Signature = &signature
+	}
+	if src.Receipt != nil {
+		receipt := *src.Receipt
+		receipt.ReturnValue = cloneBytes(src.Receipt.ReturnValue)
+		msg.Receipt = &receipt
+	}
+	if src.Meta != nil {
+		meta := *src.Meta
+		meta.MaxFee = cloneInt(src.Meta.MaxFee)
+		meta.MaxFeeCap = cloneInt(src.Meta.MaxFeeCap)
+		msg.Meta = &meta
+	} else {
+		// same as sql repo, meta of message read out is never nil
+		msg.Meta = &types.MsgMeta{}
+	}
+	return &msg
+}
+
+func cloneBytes(src []byte) []byte {
+	if src == nil {
+		return nil
+	}
+	return append([]byte{}, src...)
+}
+
+func cloneInt(src big.Int) big.Int {
+	if src.Int == nil {
+		return src
+	}
+	return big.NewFromGo(src.Int)
+}
+
+// findMessages return copies of matched messages ordered by created_at and id
+func findMessages(table map[string]*types.Message, match func(msg *types.Message) bool) []*types.Message {
+	result := make([]*types.Message, 0)
+	for _, msg := range table {
+		if match(msg) {
+			result = append(result, cloneMessage(msg))
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.Before(result[j].CreatedAt)
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+// firstMessage return the copy of matched message with the smallest id, like `First` of gorm
+func firstMessage(table map[string]*types.Message, match func(msg *types.Message) bool) (*types.Message, error) {
+	var first *types.Message
+	for _, msg := range table {
+		if match(msg) && (first == nil || msg.ID < first.ID) {
+			first = msg
+		}
+	}
+	if first == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return cloneMessage(first), nil
+}
+
+// listMessageAfterID list messages ordered by id
+func listMessageAfterID(table map[string]*types.Message, id string, limit int) []*types.Message {
+	result := make([]*types.Message, 0)
+	for _, msg := range table {
+		if msg.ID > id {
+			result = append(result, msg)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	for i, msg := range result {
+		result[i] = cloneMessage(msg)
+	}
+	return result
+}
+
+func cidEqual(c *cid.Cid, str string) bool {
+	return c != nil && c.String() == str
+}
+
+var _ repo.MessageRepo = (*memoryMessageRepo)(nil)
+
+type memoryMessageRepo struct {
+	store
+}
+
+func newMemoryMessageRepo(s store) *memoryMessageRepo {
+	return &memoryMessageRepo{store: s}
+}
+
+func (m *memoryMessageRepo) GetMessageState(id string) (types.MessageState, error) {
+	msg, ok := m.view().messages[id]
+	if !ok {
+		return types.UnKnown, nil
+	}
+	return msg.State, nil
+}
+
+func (m *memoryMessageRepo) HasMessageByUid(id string) (bool, error) {
+	_, ok := m.view().messages[id]
+	return ok, nil
+}
+
+func (m *memoryMessageRepo) ExpireMessage(msgs []*types.Message) error {
+	err := m.update(func(tx *txTables) error {
+		for _, msg := range msgs {
+			exist, ok := tx.messages[msg.ID]
+			if !ok || exist.Revision != msg.Revision {
+				return &repo.RevisionConflictError{ID: msg.ID, Revision: msg.Revision}
+			}
+			expired := cloneMessage(exist)
+			expired.State = types.FailedMsg
+			expired.Revision++
+			tx.writeMessages()[msg.ID] = expired
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, msg := range msgs {
+		msg.State = types.FailedMsg
+		msg.Revision++
+	}
+	return nil
+}
+
+func (m *memoryMessageRepo) ListFilledMessageByAddress(addr address.Address) ([]*types.Message, error) {
+	return findMessages(m.view().messages, func(msg *types.Message) bool {
+		return msg.From == addr && msg.State == types.FillMsg
+	}), nil
+}
+
+func (m *memoryMessageRepo) ListFilledMessageByWallet(walletName string, addr address.Address) ([]*types.Message, error) {
+	return findMessages(m.view().messages, func(msg *types.Message) bool {
+		return msg.From == addr && msg.State == types.FillMsg && msg.WalletName == walletName
+	}), nil
+}
+
+func (m *memoryMessageRepo) ListOfflineSignMessageByWallet(walletName string) ([]*types.Message, error) {
+	return findMessages(m.view().messages, func(msg *types.Message) bool {
+		return msg.State == types.OfflineSignMsg && msg.WalletName == walletName
+	}), nil
+}
+
+func (m *memoryMessageRepo) ListFilledMessageBelowNonce(addr address.Address, nonce uint64) ([]*types.Message, error) {
+	return findMessages(m.view().messages, func(msg *types.Message) bool {
+		return msg.From == addr && msg.State == types.FillMsg && msg.Nonce < nonce
+	}), nil
+}
+
+func (m *memoryMessageRepo) ListFilledMessageByHeight(height abi.ChainEpoch) ([]*types.Message, error) {
+	return findMessages(m.view().messages, func(msg *types.Message) bool {
+		return msg.Height == int64(height) && msg.State == types.FillMsg
+	}), nil
+}
+
+func (m *memoryMessageRepo) ListChainMessageByHeight(height abi.ChainEpoch) ([]*types.Message, error) {
+	return findMessages(m.view().messages, func(msg *types.Message) bool {
+		return msg.Height == int64(height) && msg.State == types.OnChainMsg
+	}), nil
+}
+
+func (m *memoryMessageRepo) ListMessageAfterID(id string, limit int) ([]*types.Message, error) {
+	return listMessageAfterID(m.view().messages, id, limit), nil
+}
+
+func (m *memoryMessageRepo) ListArchivableMessage(before time.Time, belowHeight abi.ChainEpoch, limit int) ([]*types.Message, error) {
+	if before.IsZero() && belowHeight <= 0 {
+		return nil, nil
+	}
+	result := findMessages(m.view().messages, func(msg *types.Message) bool {
+		if msg.State != types.OnChainMsg && msg.State != types.FailedMsg && msg.State != types.ReplacedMsg {
+			return false
+		}
+		return (!before.IsZero() && msg.UpdatedAt.Before(before)) ||
+			(belowHeight > 0 && msg.Height > 0 && msg.Height < int64(belowHeight))
+	})
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].UpdatedAt.Before(result[j].UpdatedAt)
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (m *memoryMessageRepo) DeleteMessage(ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return m.update(func(tx *txTables) error {
+		messages := tx.writeMessages()
+		for _, id := range ids {
+			delete(messages, id)
+		}
+		return nil
+	})
+}
+
+func (m *memoryMessageRepo) ListUnChainMessageByAddress(addr address.Address) ([]*types.Message, error) {
+	return findMessages(m.view().messages, func(msg *types.Message) bool {
+		return msg.From == addr && msg.State == types.UnFillMsg
+	}), nil
+}
+
+func (m *memoryMessageRepo) BatchSaveMessage(msgs []*types.Message) error {
+	for _, msg := range msgs {
+		err := m.SaveMessage(msg)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *memoryMessageRepo) CreateMessage(msg *types.Message) error {
+	newMsg := cloneMessage(msg)
+	// keep the time of message imported from other messager
+	if newMsg.CreatedAt.IsZero() {
+		newMsg.CreatedAt = time.Now()
+	}
+	if newMsg.UpdatedAt.IsZero() {
+		newMsg.UpdatedAt = time.Now()
+	}
+	return m.update(func(tx *txTables) error {
+		if _, ok := tx.messages[msg.ID]; ok {
+			return xerrors.Errorf("message %s already exists", msg.ID)
+		}
+		tx.writeMessages()[msg.ID] = newMsg
+		return nil
+	})
+}
+
+// SaveMessage used to update message and create message with CreateMessage,
+// the update fails with RevisionConflictError if the message is changed after msg was read
+func (m *memoryMessageRepo) SaveMessage(msg *types.Message) error {
+	newMsg := cloneMessage(msg)
+	newMsg.UpdatedAt = time.Now()
+	newMsg.Revision = msg.Revision + 1
+
+	err := m.update(func(tx *txTables) error {
+		exist, ok := tx.messages[msg.ID]
+		if !ok || exist.Revision != msg.Revision {
+			return &repo.RevisionConflictError{ID: msg.ID, Revision: msg.Revision}
+		}
+		newMsg.CreatedAt = exist.CreatedAt
+		tx.writeMessages()[msg.ID] = newMsg
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	msg.Revision = newMsg.Revision
+	msg.UpdatedAt = newMsg.UpdatedAt
+	return nil
+}
+
+func (m *memoryMessageRepo) GetMessageByUid(id string) (*types.Message, error) {
+	msg, ok := m.view().messages[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return cloneMessage(msg), nil
+}
+
+func (m *memoryMessageRepo) GetMessageByCid(unsignedCid cid.Cid) (*types.Message, error) {
+	return firstMessage(m.view().messages, func(msg *types.Message) bool {
+		return msg.UnsignedCid != nil && msg.UnsignedCid.Equals(unsignedCid)
+	})
+}
+
+func (m *memoryMessageRepo) GetMessageBySignedCid(signedCid cid.Cid) (*types.Message, error) {
+	return firstMessage(m.view().messages, func(msg *types.Message) bool {
+		return msg.SignedCid != nil && msg.SignedCid.Equals(signedCid)
+	})
+}
+
+func (m *memoryMessageRepo) GetSignedMessageByTime(start time.Time) ([]*types.Message, error) {
+	return findMessages(m.view().messages, func(msg *types.Message) bool {
+		return !msg.CreatedAt.Before(start) && msg.Signature != nil
+	}), nil
+}
+
+func (m *memoryMessageRepo) GetSignedMessageByHeight(height abi.ChainEpoch) ([]*types.Message, error) {
+	return findMessages(m.view().messages, func(msg *types.Message) bool {
+		return msg.Height >= int64(height) && msg.Signature != nil
+	}), nil
+}
+
+func (m *memoryMessageRepo) GetMessageByFromAndNonce(from address.Address, nonce uint64) (*types.Message, error) {
+	return firstMessage(m.view().messages, func(msg *types.Message) bool {
+		return msg.From == from && msg.Nonce == nonce
+	})
+}
+
+func (m *memoryMessageRepo) ListMessage() ([]*types.Message, error) {
+	return findMessages(m.view().messages, func(msg *types.Message) bool {
+		return true
+	}), nil
+}
+
+func (m *memoryMessageRepo) ListMessageByAddress(addr address.Address) ([]*types.Message, error) {
+	return findMessages(m.view().messages, func(msg *types.Message) bool {
+		return msg.From == addr
+	}), nil
+}
+
+func (m *memoryMessageRepo) ListFailedMessage() ([]*types.Message, error) {
+	return findMessages(m.view().messages, func(msg *types.Message) bool {
+		return msg.State == types.UnFillMsg && msg.Receipt != nil && msg.Receipt.ReturnValue != nil
+	}), nil
+}
+
+func (m *memoryMessageRepo) ListBlockedMessage(addr address.Address, d time.Duration) ([]*types.Message, error) {
+	t := time.Now().Add(-d)
+	return findMessages(m.view().messages, func(msg *types.Message) bool {
+		return msg.From == addr && msg.State == types.FillMsg && msg.CreatedAt.Before(t)
+	}), nil
+}
+
+func (m *memoryMessageRepo) ListUnchainedMsgs() ([]*types.Message, error) {
+	return findMessages(m.view().messages, func(msg *types.Message) bool {
+		return msg.Height == 0 && msg.Signature == nil
+	}), nil
+}
+
+func (m *memoryMessageRepo) ListSignedMsgs() ([]*types.Message, error) {
+	return findMessages(m.view().messages, func(msg *types.Message) bool {
+		return msg.Height == 0 && msg.Signature != nil
+	}), nil
+}
+
+// updateColumns change the matched messages and increase their revision, updated_at is not changed like `UpdateColumns` of gorm
+func (m *memoryMessageRepo) updateColumns(match func(msg *types.Message) bool, change func(msg *types.Message)) error {
+	return m.update(func(tx *txTables) error {
+		for id, msg := range tx.messages {
+			if !match(msg) {
+				continue
+			}
+			newMsg := cloneMessage(msg)
+			change(newMsg)
+			newMsg.Revision++
+			tx.writeMessages()[id] = newMsg
+		}
+		return nil
+	})
+}
+
+// updateRevision change the message matched if it is still at revision, key identifies the message in error
+func (m *memoryMessageRepo) updateRevision(key string, revision int64, match func(msg *types.Message) bool, change func(msg *types.Message)) error {
+	return m.update(func(tx *txTables) error {
+		for id, msg := range tx.messages {
+			if !match(msg) || msg.Revision != revision {
+				continue
+			}
+			newMsg := cloneMessage(msg)
+			change(newMsg)
+			newMsg.Revision++
+			tx.writeMessages()[id] = newMsg
+			return nil
+		}
+		return &repo.RevisionConflictError{ID: key, Revision: revision}
+	})
+}
+
+func (m *memoryMessageRepo) UpdateMessageInfoByCid(unsignedCid string,
+	revision int64,
+	receipt *venustypes.MessageReceipt,
+	height abi.ChainEpoch,
+	state types.MessageState,
+	tsKey venustypes.TipSetKey) error {
+	return m.updateRevision(unsignedCid, revision, func(msg *types.Message) bool {
+		return cidEqual(msg.UnsignedCid, unsignedCid)
+	}, func(msg *types.Message) {
+		msg.Height = int64(height)
+		if receipt != nil {
+			msg.Receipt = &venustypes.MessageReceipt{
+				ExitCode:    receipt.ExitCode,
+				ReturnValue: cloneBytes(receipt.ReturnValue),
+				GasUsed:     receipt.GasUsed,
+			}
+		}
+		msg.State = state
+		msg.TipSetKey = tsKey
+	})
+}
+
+func (m *memoryMessageRepo) UpdateMessageStateByCid(cid string, revision int64, state types.MessageState) error {
+	return m.updateRevision(cid, revision, func(msg *types.Message) bool {
+		return cidEqual(msg.UnsignedCid, cid)
+	}, func(msg *types.Message) {
+		msg.State = state
+	})
+}
+
+func (m *memoryMessageRepo) UpdateMessageStateByID(id string, revision int64, state types.MessageState) error {
+	return m.updateRevision(id, revision, func(msg *types.Message) bool {
+		return msg.ID == id
+	}, func(msg *types.Message) {
+		msg.State = state
+	})
+}
+
+func (m *memoryMessageRepo) UpdateUnFilledMessageState(walletName string, addr address.Address, state types.MessageState) error {
+	return m.updateColumns(func(msg *types.Message) bool {
+		return msg.WalletName == walletName && msg.From == addr && msg.State == types.UnFillMsg
+	}, func(msg *types.Message) {
+		msg.State = state
+	})
+}
+
+func (m *memoryMessageRepo) MigrateUnFilledMessage(fromWallet, toWallet string, addr address.Address) ([]string, error) {
+	ids := make([]string, 0)
+	err := m.updateColumns(func(msg *types.Message) bool {
+		return msg.WalletName == fromWallet && msg.From == addr && (msg.State == types.UnFillMsg || msg.State == types.NoWalletMsg)
+	}, func(msg *types.Message) {
+		msg.WalletName = toWallet
+		msg.State = types.UnFillMsg
+		ids = append(ids, msg.ID)
+	})
+	return ids, err
+}
+
+func (m *memoryMessageRepo) MarkBadMessage(id string, revision int64) (struct{}, error) {
+	return struct{}{}, m.updateRevision(id, revision, func(msg *types.Message) bool {
+		return msg.ID == id
+	}, func(msg *types.Message) {
+		msg.State = types.FailedMsg
+	})
+}
+
+func (m *memoryMessageRepo) UpdateReturnValue(id string, revision int64, returnVal string) error {
+	return m.updateRevision(id, revision, func(msg *types.Message) bool {
+		return msg.ID == id
+	}, func(msg *types.Message) {
+		if msg.Receipt == nil {
+			msg.Receipt = &venustypes.MessageReceipt{ExitCode: -1}
+		}
+		msg.Receipt.ReturnValue = []byte(returnVal)
+	})
+}
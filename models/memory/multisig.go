@@ -0,0 +1,95 @@
+package memory
+
+import (
+	"sort"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-messager/models/repo"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+func cloneMsigProposal(src *types.MsigProposal) *types.MsigProposal {
+	p := *src
+	p.Value = cloneInt(src.Value)
+	if p.Value.Int == nil {
+		// same as sql repo, value read out is never nil
+		p.Value = big.Zero()
+	}
+	p.Params = cloneBytes(src.Params)
+	if src.Approvals != nil {
+		p.Approvals = make([]*types.MsigApproval, 0, len(src.Approvals))
+		for _, approval := range src.Approvals {
+			a := *approval
+			p.Approvals = append(p.Approvals, &a)
+		}
+	}
+	return &p
+}
+
+var _ repo.MultisigRepo = (*memoryMultisigRepo)(nil)
+
+type memoryMultisigRepo struct {
+	store
+}
+
+func newMemoryMultisigRepo(s store) *memoryMultisigRepo {
+	return &memoryMultisigRepo{store: s}
+}
+
+func (s *memoryMultisigRepo) SaveProposal(p *types.MsigProposal) error {
+	newProposal := cloneMsigProposal(p)
+	return s.update(func(tx *txTables) error {
+		tx.writeMsigProposals()[p.ID] = newProposal
+		return nil
+	})
+}
+
+func (s *memoryMultisigRepo) GetProposal(id string) (*types.MsigProposal, error) {
+	if p, ok := s.view().msigProposals[id]; ok {
+		return cloneMsigProposal(p), nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (s *memoryMultisigRepo) DeleteProposal(id string) error {
+	return s.update(func(tx *txTables) error {
+		delete(tx.writeMsigProposals(), id)
+		return nil
+	})
+}
+
+func (s *memoryMultisigRepo) ListProposal(msig address.Address) ([]*types.MsigProposal, error) {
+	result := s.find(func(p *types.MsigProposal) bool {
+		return msig == address.Undef || p.Msig == msig
+	})
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return result, nil
+}
+
+func (s *memoryMultisigRepo) ListUnfinishedProposal() ([]*types.MsigProposal, error) {
+	return s.find(func(p *types.MsigProposal) bool {
+		return p.State == types.MsigProposing || p.State == types.MsigPending
+	}), nil
+}
+
+// find return copies of matched proposals ordered by created_at
+func (s *memoryMultisigRepo) find(match func(p *types.MsigProposal) bool) []*types.MsigProposal {
+	result := make([]*types.MsigProposal, 0)
+	for _, p := range s.view().msigProposals {
+		if match(p) {
+			result = append(result, cloneMsigProposal(p))
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].ID < result[j].ID
+		}
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result
+}
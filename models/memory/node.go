@@ -0,0 +1,125 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"golang.org/x/xerrors"
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-messager/models/repo"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+// memoryNode add the columns of sql table which types.Node does not have
+type memoryNode struct {
+	types.Node
+
+	IsDeleted int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// firstNode return the copy of matched node with the smallest id
+func firstNode(table map[types.UUID]*memoryNode, match func(node *memoryNode) bool) (*memoryNode, error) {
+	var first *memoryNode
+	for _, node := range table {
+		if match(node) && (first == nil || uuidLess(node.ID, first.ID)) {
+			first = node
+		}
+	}
+	if first == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	node := *first
+	return &node, nil
+}
+
+var _ repo.NodeRepo = (*memoryNodeRepo)(nil)
+
+type memoryNodeRepo struct {
+	store
+}
+
+func newMemoryNodeRepo(s store) *memoryNodeRepo {
+	return &memoryNodeRepo{store: s}
+}
+
+func (s *memoryNodeRepo) CreateNode(node *types.Node) error {
+	return s.update(func(tx *txTables) error {
+		if _, ok := tx.nodes[node.ID]; ok {
+			return xerrors.Errorf("node %s already exists", node.ID)
+		}
+		tx.writeNodes()[node.ID] = &memoryNode{
+			Node:      *node,
+			IsDeleted: repo.NotDeleted,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+		return nil
+	})
+}
+
+func (s *memoryNodeRepo) SaveNode(node *types.Node) error {
+	return s.update(func(tx *txTables) error {
+		newNode := &memoryNode{
+			Node:      *node,
+			IsDeleted: repo.NotDeleted,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+		// keep the creation time of existing node
+		if exist, ok := tx.nodes[node.ID]; ok {
+			newNode.CreatedAt = exist.CreatedAt
+		}
+		tx.writeNodes()[node.ID] = newNode
+		return nil
+	})
+}
+
+func (s *memoryNodeRepo) GetNode(name string) (*types.Node, error) {
+	node, err := firstNode(s.view().nodes, func(node *memoryNode) bool {
+		return node.Name == name && node.IsDeleted == repo.NotDeleted
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &node.Node, nil
+}
+
+func (s *memoryNodeRepo) HasNode(name string) (bool, error) {
+	_, err := s.GetNode(name)
+	if err == gorm.ErrRecordNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *memoryNodeRepo) ListNode() ([]*types.Node, error) {
+	result := make([]*types.Node, 0)
+	for _, node := range s.view().nodes {
+		if node.IsDeleted == repo.NotDeleted {
+			n := node.Node
+			result = append(result, &n)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return uuidLess(result[i].ID, result[j].ID)
+	})
+	return result, nil
+}
+
+func (s *memoryNodeRepo) DelNode(name string) error {
+	return s.update(func(tx *txTables) error {
+		node, err := firstNode(tx.nodes, func(node *memoryNode) bool {
+			return node.Name == name && node.IsDeleted == repo.NotDeleted
+		})
+		if err != nil {
+			return err
+		}
+		node.IsDeleted = repo.Deleted
+		node.UpdatedAt = time.Now()
+		tx.writeNodes()[node.ID] = node
+		return nil
+	})
+}
@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"golang.org/x/xerrors"
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-messager/models/repo"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+func cloneParamsVersion(src *types.ParamsVersion) *types.ParamsVersion {
+	v := *src
+	v.Value = cloneRawJSON(src.Value)
+	return &v
+}
+
+var _ repo.ParamsVersionRepo = (*memoryParamsVersionRepo)(nil)
+
+type memoryParamsVersionRepo struct {
+	store
+}
+
+func newMemoryParamsVersionRepo(s store) *memoryParamsVersionRepo {
+	return &memoryParamsVersionRepo{store: s}
+}
+
+func (s *memoryParamsVersionRepo) CreateParamsVersion(v *types.ParamsVersion) error {
+	newVersion := cloneParamsVersion(v)
+	return s.update(func(tx *txTables) error {
+		for _, exist := range tx.paramsVersions[v.Target] {
+			if exist.Version == v.Version {
+				return xerrors.Errorf("version %d of %s already exists", v.Version, v.Target)
+			}
+		}
+		tx.putParamsVersion(newVersion)
+		return nil
+	})
+}
+
+func (s *memoryParamsVersionRepo) GetParamsVersion(target string, version int64) (*types.ParamsVersion, error) {
+	for _, v := range s.view().paramsVersions[target] {
+		if v.Version == version {
+			return cloneParamsVersion(v), nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (s *memoryParamsVersionRepo) GetLatestParamsVersion(target string) (*types.ParamsVersion, error) {
+	versions := s.view().paramsVersions[target]
+	if len(versions) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return cloneParamsVersion(versions[len(versions)-1]), nil
+}
+
+func (s *memoryParamsVersionRepo) ListParamsVersion(target string, limit int) ([]*types.ParamsVersion, error) {
+	versions := s.view().paramsVersions[target]
+	result := make([]*types.ParamsVersion, 0, len(versions))
+	for i := len(versions) - 1; i >= 0; i-- {
+		if limit > 0 && len(result) == limit {
+			break
+		}
+		result = append(result, cloneParamsVersion(versions[i]))
+	}
+	return result, nil
+}
@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-messager/models/repo"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+var _ repo.SharedParamsRepo = (*memorySharedParamsRepo)(nil)
+
+type memorySharedParamsRepo struct {
+	store
+}
+
+func newMemorySharedParamsRepo(s store) *memorySharedParamsRepo {
+	return &memorySharedParamsRepo{store: s}
+}
+
+func (s *memorySharedParamsRepo) GetSharedParams(ctx context.Context) (*types.SharedParams, error) {
+	params := s.view().sharedParams
+	if params == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	res := *params
+	return &res, nil
+}
+
+// SetSharedParams there is only one row of shared params, id of the existing row is kept
+func (s *memorySharedParamsRepo) SetSharedParams(ctx context.Context, params *types.SharedParams) (uint, error) {
+	err := s.update(func(tx *txTables) error {
+		newParams := *params
+		if tx.sharedParams == nil {
+			if params.ID == 0 {
+				params.ID = 1
+			}
+			newParams.ID = params.ID
+		} else {
+			newParams.ID = tx.sharedParams.ID
+		}
+		tx.sharedParams = &newParams
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return params.ID, nil
+}
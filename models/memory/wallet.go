@@ -0,0 +1,141 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-messager/models/repo"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+func cloneWallet(src *types.Wallet) *types.Wallet {
+	wallet := *src
+	return &wallet
+}
+
+// firstWallet return the copy of matched wallet with the smallest id
+func firstWallet(table map[types.UUID]*types.Wallet, match func(wallet *types.Wallet) bool) (*types.Wallet, error) {
+	var first *types.Wallet
+	for _, wallet := range table {
+		if match(wallet) && (first == nil || uuidLess(wallet.ID, first.ID)) {
+			first = wallet
+		}
+	}
+	if first == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return cloneWallet(first), nil
+}
+
+var _ repo.WalletRepo = (*memoryWalletRepo)(nil)
+
+type memoryWalletRepo struct {
+	store
+}
+
+func newMemoryWalletRepo(s store) *memoryWalletRepo {
+	return &memoryWalletRepo{store: s}
+}
+
+func (s *memoryWalletRepo) SaveWallet(wallet *types.Wallet) error {
+	newWallet := cloneWallet(wallet)
+	newWallet.UpdatedAt = time.Now()
+	return s.update(func(tx *txTables) error {
+		if newWallet.CreatedAt.IsZero() {
+			if exist, ok := tx.wallets[wallet.ID]; ok {
+				newWallet.CreatedAt = exist.CreatedAt
+			} else {
+				newWallet.CreatedAt = time.Now()
+			}
+		}
+		tx.writeWallets()[wallet.ID] = newWallet
+		return nil
+	})
+}
+
+func (s *memoryWalletRepo) GetWalletByID(uuid types.UUID) (*types.Wallet, error) {
+	return firstWallet(s.view().wallets, func(wallet *types.Wallet) bool {
+		return wallet.ID == uuid && wallet.IsDeleted == repo.NotDeleted
+	})
+}
+
+func (s *memoryWalletRepo) GetWalletByName(name string) (*types.Wallet, error) {
+	return firstWallet(s.view().wallets, func(wallet *types.Wallet) bool {
+		return wallet.Name == name && wallet.IsDeleted == repo.NotDeleted
+	})
+}
+
+func (s *memoryWalletRepo) GetOneRecord(name string) (*types.Wallet, error) {
+	return firstWallet(s.view().wallets, func(wallet *types.Wallet) bool {
+		return wallet.Name == name
+	})
+}
+
+func (s *memoryWalletRepo) HasWallet(name string) (bool, error) {
+	for _, wallet := range s.view().wallets {
+		if wallet.Name == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *memoryWalletRepo) ListWallet() ([]*types.Wallet, error) {
+	result := make([]*types.Wallet, 0)
+	for _, wallet := range s.view().wallets {
+		if wallet.IsDeleted == repo.NotDeleted {
+			result = append(result, cloneWallet(wallet))
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return uuidLess(result[i].ID, result[j].ID)
+	})
+	return result, nil
+}
+
+func (s *memoryWalletRepo) UpdateState(name string, state types.State) error {
+	return s.update(func(tx *txTables) error {
+		for id, wallet := range tx.wallets {
+			if wallet.Name != name || wallet.IsDeleted != repo.NotDeleted {
+				continue
+			}
+			newWallet := cloneWallet(wallet)
+			newWallet.State = state
+			tx.writeWallets()[id] = newWallet
+		}
+		return nil
+	})
+}
+
+func (s *memoryWalletRepo) UpdateSignLimit(name string, concurrency int, rate float64) error {
+	return s.update(func(tx *txTables) error {
+		for id, wallet := range tx.wallets {
+			if wallet.Name != name || wallet.IsDeleted != repo.NotDeleted {
+				continue
+			}
+			newWallet := cloneWallet(wallet)
+			newWallet.SignConcurrency = concurrency
+			newWallet.SignRate = rate
+			tx.writeWallets()[id] = newWallet
+		}
+		return nil
+	})
+}
+
+func (s *memoryWalletRepo) DelWallet(name string) error {
+	return s.update(func(tx *txTables) error {
+		wallet, err := firstWallet(tx.wallets, func(wallet *types.Wallet) bool {
+			return wallet.Name == name && wallet.IsDeleted == repo.NotDeleted
+		})
+		if err != nil {
+			return err
+		}
+		wallet.IsDeleted = repo.Deleted
+		wallet.State = types.Removed
+		wallet.UpdatedAt = time.Now()
+		tx.writeWallets()[wallet.ID] = wallet
+		return nil
+	})
+}
@@ -0,0 +1,144 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-messager/models/repo"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+func cloneWalletAddress(src *types.WalletAddress) *types.WalletAddress {
+	wa := *src
+	return &wa
+}
+
+// firstWalletAddress return the copy of matched wallet address with the smallest id
+func firstWalletAddress(table map[types.UUID]*types.WalletAddress, match func(wa *types.WalletAddress) bool) (*types.WalletAddress, error) {
+	var first *types.WalletAddress
+	for _, wa := range table {
+		if match(wa) && (first == nil || uuidLess(wa.ID, first.ID)) {
+			first = wa
+		}
+	}
+	if first == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return cloneWalletAddress(first), nil
+}
+
+func findWalletAddress(table map[types.UUID]*types.WalletAddress, match func(wa *types.WalletAddress) bool) []*types.WalletAddress {
+	result := make([]*types.WalletAddress, 0)
+	for _, wa := range table {
+		if match(wa) {
+			result = append(result, cloneWalletAddress(wa))
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return uuidLess(result[i].ID, result[j].ID)
+	})
+	return result
+}
+
+var _ repo.WalletAddressRepo = (*memoryWalletAddressRepo)(nil)
+
+type memoryWalletAddressRepo struct {
+	store
+}
+
+func newMemoryWalletAddressRepo(s store) *memoryWalletAddressRepo {
+	return &memoryWalletAddressRepo{store: s}
+}
+
+func (s *memoryWalletAddressRepo) SaveWalletAddress(wa *types.WalletAddress) error {
+	newWa := cloneWalletAddress(wa)
+	newWa.UpdatedAt = time.Now()
+	return s.update(func(tx *txTables) error {
+		if newWa.CreatedAt.IsZero() {
+			if exist, ok := tx.walletAddresses[wa.ID]; ok {
+				newWa.CreatedAt = exist.CreatedAt
+			} else {
+				newWa.CreatedAt = time.Now()
+			}
+		}
+		tx.writeWalletAddresses()[wa.ID] = newWa
+		return nil
+	})
+}
+
+func (s *memoryWalletAddressRepo) GetWalletAddress(walletID, addrID types.UUID) (*types.WalletAddress, error) {
+	return firstWalletAddress(s.view().walletAddresses, func(wa *types.WalletAddress) bool {
+		return wa.WalletID == walletID && wa.AddrID == addrID && wa.IsDeleted == repo.NotDeleted
+	})
+}
+
+func (s *memoryWalletAddressRepo) GetOneRecord(walletID, addrID types.UUID) (*types.WalletAddress, error) {
+	return firstWalletAddress(s.view().walletAddresses, func(wa *types.WalletAddress) bool {
+		return wa.WalletID == walletID && wa.AddrID == addrID
+	})
+}
+
+func (s *memoryWalletAddressRepo) GetWalletAddressByWalletID(walletID types.UUID) ([]*types.WalletAddress, error) {
+	return findWalletAddress(s.view().walletAddresses, func(wa *types.WalletAddress) bool {
+		return wa.WalletID == walletID && wa.IsDeleted == repo.NotDeleted
+	}), nil
+}
+
+func (s *memoryWalletAddressRepo) HasWalletAddress(walletID, addrID types.UUID) (bool, error) {
+	_, err := s.GetOneRecord(walletID, addrID)
+	if err == gorm.ErrRecordNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *memoryWalletAddressRepo) ListWalletAddress() ([]*types.WalletAddress, error) {
+	return findWalletAddress(s.view().walletAddresses, func(wa *types.WalletAddress) bool {
+		return wa.IsDeleted == repo.NotDeleted
+	}), nil
+}
+
+// updateWalletAddress change all records of the wallet and address
+func (s *memoryWalletAddressRepo) updateWalletAddress(walletID, addrID types.UUID, change func(wa *types.WalletAddress)) error {
+	return s.update(func(tx *txTables) error {
+		for id, wa := range tx.walletAddresses {
+			if wa.WalletID != walletID || wa.AddrID != addrID {
+				continue
+			}
+			newWa := cloneWalletAddress(wa)
+			change(newWa)
+			tx.writeWalletAddresses()[id] = newWa
+		}
+		return nil
+	})
+}
+
+func (s *memoryWalletAddressRepo) UpdateAddressState(walletID, addrID types.UUID, state types.State) error {
+	return s.updateWalletAddress(walletID, addrID, func(wa *types.WalletAddress) {
+		wa.AddressState = state
+	})
+}
+
+func (s *memoryWalletAddressRepo) UpdateSelectMsgNum(walletID, addrID types.UUID, selMsgNum uint64) error {
+	return s.updateWalletAddress(walletID, addrID, func(wa *types.WalletAddress) {
+		wa.SelMsgNum = selMsgNum
+	})
+}
+
+func (s *memoryWalletAddressRepo) DelWalletAddress(walletID, addrID types.UUID) error {
+	return s.update(func(tx *txTables) error {
+		wa, err := firstWalletAddress(tx.walletAddresses, func(wa *types.WalletAddress) bool {
+			return wa.WalletID == walletID && wa.AddrID == addrID && wa.IsDeleted == repo.NotDeleted
+		})
+		if err != nil {
+			return err
+		}
+		wa.IsDeleted = repo.Deleted
+		wa.AddressState = types.Removed
+		wa.UpdatedAt = time.Now()
+		tx.writeWalletAddresses()[wa.ID] = wa
+		return nil
+	})
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-messager/models/memory"
+	"github.com/filecoin-project/venus-messager/models/repo"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+func TestMemoryRepoIsolation(t *testing.T) {
+	r, err := memory.OpenMemory()
+	assert.NoError(t, err)
+
+	msg := NewMessage()
+	assert.NoError(t, r.MessageRepo().CreateMessage(msg))
+
+	// records read out are copies
+	res, err := r.MessageRepo().GetMessageByUid(msg.ID)
+	assert.NoError(t, err)
+	res.State = types.FailedMsg
+	res.Meta.GasOverEstimation = 2
+	res, err = r.MessageRepo().GetMessageByUid(msg.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, types.UnKnown, res.State)
+	assert.Equal(t, msg.Meta.GasOverEstimation, res.Meta.GasOverEstimation)
+
+	// changes in transaction are invisible outside before commit
+	newMsg := NewMessage()
+	assert.NoError(t, r.Transaction(func(txRepo repo.TxRepo) error {
+		assert.NoError(t, txRepo.MessageRepo().CreateMessage(newMsg))
+		assert.NoError(t, txRepo.MessageRepo().UpdateMessageStateByID(msg.ID, msg.Revision, types.FillMsg))
+
+		has, err := txRepo.MessageRepo().HasMessageByUid(newMsg.ID)
+		assert.NoError(t, err)
+		assert.True(t, has)
+		has, err = r.MessageRepo().HasMessageByUid(newMsg.ID)
+		assert.NoError(t, err)
+		assert.False(t, has)
+		state, err := r.MessageRepo().GetMessageState(msg.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, types.UnKnown, state)
+		return nil
+	}))
+	state, err := r.MessageRepo().GetMessageState(msg.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, types.FillMsg, state)
+	has, err := r.MessageRepo().HasMessageByUid(newMsg.ID)
+	assert.NoError(t, err)
+	assert.True(t, has)
+
+	// nothing is changed by a failed transaction
+	other := NewMessage()
+	err = r.Transaction(func(txRepo repo.TxRepo) error {
+		assert.NoError(t, txRepo.MessageRepo().CreateMessage(other))
+		return txRepo.MessageRepo().SaveMessage(res)
+	})
+	assert.True(t, xerrors.Is(err, repo.ErrRevisionConflict))
+	_, err = r.MessageRepo().GetMessageByUid(other.ID)
+	assert.True(t, xerrors.Is(err, gorm.ErrRecordNotFound))
+
+	// writing the repo in its own transaction fails rather than waiting for the transaction forever,
+	// while the other goroutines wait for it
+	waited := make(chan error, 1)
+	err = r.Transaction(func(txRepo repo.TxRepo) error {
+		go func() {
+			waited <- r.MessageRepo().CreateMessage(NewMessage())
+		}()
+		return r.MessageRepo().CreateMessage(other)
+	})
+	assert.True(t, xerrors.Is(err, memory.ErrReentrantWrite))
+	assert.NoError(t, <-waited)
+	assert.True(t, xerrors.Is(r.Transaction(func(repo.TxRepo) error {
+		return r.Transaction(func(repo.TxRepo) error { return nil })
+	}), memory.ErrReentrantWrite))
+	_, err = r.MessageRepo().GetMessageByUid(other.ID)
+	assert.True(t, xerrors.Is(err, gorm.ErrRecordNotFound))
+
+	_, err = r.Migrator()
+	assert.True(t, xerrors.Is(err, repo.ErrMigrationNotSupported))
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"math"
 	"math/rand"
 	"testing"
 	"time"
@@ -60,6 +61,9 @@ func TestSaveAndGetMessage(t *testing.T) {
 			t.SkipNow()
 			messageRepoTest(t, mysqlRepo.MessageRepo())
 		})
+		t.Run("postgres", func(t *testing.T) {
+			messageRepoTest(t, setupPostgresRepo(t).MessageRepo())
+		})
 	})
 }
 
@@ -85,7 +89,7 @@ func TestUpdateMessageInfoByCid(t *testing.T) {
 
 		height := abi.ChainEpoch(10)
 		state := types.OnChainMsg
-		err = messageRepo.UpdateMessageInfoByCid(unsignedCid.String(), rec, height, state, tsKey)
+		err = messageRepo.UpdateMessageInfoByCid(unsignedCid.String(), msg.Revision, rec, height, state, tsKey)
 		assert.NoError(t, err)
 
 		msg2, err := messageRepo.GetMessageByCid(*unsignedCid)
@@ -103,6 +107,9 @@ func TestUpdateMessageInfoByCid(t *testing.T) {
 			t.SkipNow()
 			messageRepoTest(t, mysqlRepo.MessageRepo())
 		})
+		t.Run("postgres", func(t *testing.T) {
+			messageRepoTest(t, setupPostgresRepo(t).MessageRepo())
+		})
 	})
 }
 
@@ -118,7 +125,7 @@ func TestUpdateMessageStateByCid(t *testing.T) {
 		err := messageRepo.CreateMessage(msg)
 		assert.NoError(t, err)
 
-		err = messageRepo.UpdateMessageStateByCid(cid.String(), types.OnChainMsg)
+		err = messageRepo.UpdateMessageStateByCid(cid.String(), msg.Revision, types.OnChainMsg)
 		assert.NoError(t, err)
 
 		msg2, err := messageRepo.GetMessageByUid(msg.ID)
@@ -133,6 +140,9 @@ func TestUpdateMessageStateByCid(t *testing.T) {
 			t.SkipNow()
 			messageRepoTest(t, mysqlRepo.MessageRepo())
 		})
+		t.Run("postgres", func(t *testing.T) {
+			messageRepoTest(t, setupPostgresRepo(t).MessageRepo())
+		})
 	})
 }
 
@@ -160,6 +170,9 @@ func TestExpireMessage(t *testing.T) {
 			t.SkipNow()
 			messageRepoTest(t, mysqlRepo.MessageRepo())
 		})
+		t.Run("postgres", func(t *testing.T) {
+			messageRepoTest(t, setupPostgresRepo(t).MessageRepo())
+		})
 	})
 }
 
@@ -191,6 +204,9 @@ func TestGetMessageState(t *testing.T) {
 			t.SkipNow()
 			messageRepoTest(t, mysqlRepo.MessageRepo())
 		})
+		t.Run("postgres", func(t *testing.T) {
+			messageRepoTest(t, setupPostgresRepo(t).MessageRepo())
+		})
 	})
 }
 
@@ -220,6 +236,9 @@ func TestGetSignedMessageByTime(t *testing.T) {
 			t.SkipNow()
 			messageRepoTest(t, mysqlRepo.MessageRepo())
 		})
+		t.Run("postgres", func(t *testing.T) {
+			messageRepoTest(t, setupPostgresRepo(t).MessageRepo())
+		})
 	})
 }
 
@@ -250,6 +269,9 @@ func TestGetSignedMessageByHeight(t *testing.T) {
 			t.SkipNow()
 			messageRepoTest(t, mysqlRepo.MessageRepo())
 		})
+		t.Run("postgres", func(t *testing.T) {
+			messageRepoTest(t, setupPostgresRepo(t).MessageRepo())
+		})
 	})
 }
 
@@ -274,6 +296,9 @@ func TestGetMessageByFromAndNonce(t *testing.T) {
 			t.SkipNow()
 			messageRepoTest(t, mysqlRepo.MessageRepo())
 		})
+		t.Run("postgres", func(t *testing.T) {
+			messageRepoTest(t, setupPostgresRepo(t).MessageRepo())
+		})
 	})
 }
 
@@ -301,6 +326,42 @@ func TestListFilledMessageByHeight(t *testing.T) {
 			t.SkipNow()
 			messageRepoTest(t, mysqlRepo.MessageRepo())
 		})
+		t.Run("postgres", func(t *testing.T) {
+			messageRepoTest(t, setupPostgresRepo(t).MessageRepo())
+		})
+	})
+}
+
+func TestListChainMessageByHeight(t *testing.T) {
+	sqliteRepo, mysqlRepo := setupRepo(t)
+
+	messageRepoTest := func(t *testing.T, messageRepo repo.MessageRepo) {
+		randHeight := rand.Uint64() / 2
+		for _, msg := range NewSignedMessages(10) {
+			msg.Height = int64(randHeight)
+			msg.State = types.OnChainMsg
+			err := messageRepo.CreateMessage(msg)
+			assert.NoError(t, err)
+		}
+
+		result, err := messageRepo.ListChainMessageByHeight(abi.ChainEpoch(randHeight))
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, len(result), 10)
+		for _, msg := range result {
+			assert.Equal(t, types.OnChainMsg, msg.State)
+		}
+	}
+	t.Run("ListChainMessageByHeight", func(t *testing.T) {
+		t.Run("sqlite", func(t *testing.T) {
+			messageRepoTest(t, sqliteRepo.MessageRepo())
+		})
+		t.Run("mysql", func(t *testing.T) {
+			t.SkipNow()
+			messageRepoTest(t, mysqlRepo.MessageRepo())
+		})
+		t.Run("postgres", func(t *testing.T) {
+			messageRepoTest(t, setupPostgresRepo(t).MessageRepo())
+		})
 	})
 }
 
@@ -340,6 +401,9 @@ func TestListFilledMessageByAddress(t *testing.T) {
 			t.SkipNow()
 			messageRepoTest(t, mysqlRepo.MessageRepo())
 		})
+		t.Run("postgres", func(t *testing.T) {
+			messageRepoTest(t, setupPostgresRepo(t).MessageRepo())
+		})
 	})
 }
 
@@ -369,6 +433,9 @@ func TestUpdateUnFilledMessageState(t *testing.T) {
 			t.SkipNow()
 			messageRepoTest(t, mysqlRepo.MessageRepo())
 		})
+		t.Run("postgres", func(t *testing.T) {
+			messageRepoTest(t, setupPostgresRepo(t).MessageRepo())
+		})
 	})
 }
 
@@ -384,7 +451,7 @@ func TestMarkBadMessage(t *testing.T) {
 			assert.NoError(t, err)
 		}
 
-		_, err := messageRepo.MarkBadMessage(msgs[0].ID)
+		_, err := messageRepo.MarkBadMessage(msgs[0].ID, msgs[0].Revision)
 		assert.NoError(t, err)
 
 		msg, err := messageRepo.GetMessageByUid(msgs[0].ID)
@@ -399,6 +466,9 @@ func TestMarkBadMessage(t *testing.T) {
 			t.SkipNow()
 			messageRepoTest(t, mysqlRepo.MessageRepo())
 		})
+		t.Run("postgres", func(t *testing.T) {
+			messageRepoTest(t, setupPostgresRepo(t).MessageRepo())
+		})
 	})
 }
 
@@ -414,7 +484,7 @@ func TestUpdateReturnValue(t *testing.T) {
 			assert.NoError(t, err)
 		}
 		failedInfo := "gas estimate failed"
-		err := messageRepo.UpdateReturnValue(msgs[0].ID, failedInfo)
+		err := messageRepo.UpdateReturnValue(msgs[0].ID, msgs[0].Revision, failedInfo)
 		assert.NoError(t, err)
 		msg, err := messageRepo.GetMessageByUid(msgs[0].ID)
 		assert.NoError(t, err)
@@ -432,6 +502,9 @@ func TestUpdateReturnValue(t *testing.T) {
 			t.SkipNow()
 			messageRepoTest(t, mysqlRepo.MessageRepo())
 		})
+		t.Run("postgres", func(t *testing.T) {
+			messageRepoTest(t, setupPostgresRepo(t).MessageRepo())
+		})
 	})
 }
 
@@ -464,5 +537,23 @@ func TestListBlockedMessage(t *testing.T) {
 			t.SkipNow()
 			messageRepoTest(t, mysqlRepo.MessageRepo())
 		})
+		t.Run("postgres", func(t *testing.T) {
+			messageRepoTest(t, setupPostgresRepo(t).MessageRepo())
+		})
 	})
 }
+
+func TestPostgresRejectNonceAboveInt64(t *testing.T) {
+	messageRepo := setupPostgresRepo(t).MessageRepo()
+
+	msg := NewMessage()
+	msg.Nonce = math.MaxInt64 + 1
+	assert.Error(t, messageRepo.CreateMessage(msg))
+	assert.Error(t, messageRepo.SaveMessage(msg))
+
+	msg.Nonce = math.MaxInt64
+	assert.NoError(t, messageRepo.CreateMessage(msg))
+	result, err := messageRepo.GetMessageByUid(msg.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(math.MaxInt64), result.Nonce)
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-messager/config"
+	"github.com/filecoin-project/venus-messager/models/repo"
+	"github.com/filecoin-project/venus-messager/models/sqlite"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+func TestMigrator(t *testing.T) {
+	db, err := sqlite.OpenSqlite(&config.SqliteConfig{Path: filepath.Join(t.TempDir(), "migrate.db")})
+	assert.NoError(t, err)
+
+	migrator, err := db.Migrator()
+	assert.NoError(t, err)
+	status, err := migrator.Status()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, status.Version)
+	assert.Len(t, status.Pending, migrator.Latest())
+
+	assert.NoError(t, db.AutoMigrate())
+	status, err = migrator.Status()
+	assert.NoError(t, err)
+	assert.Equal(t, migrator.Latest(), status.Version)
+	assert.Len(t, status.Applied, migrator.Latest())
+	assert.Len(t, status.Pending, 0)
+	assert.True(t, db.GetDb().Migrator().HasTable("messages"))
+
+	// migrate again is a no-op
+	assert.NoError(t, db.AutoMigrate())
+
+	assert.NoError(t, migrator.Rollback(0))
+	version, err := migrator.Version()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, version)
+	assert.False(t, db.GetDb().Migrator().HasTable("messages"))
+
+	// database migrated by a newer binary
+	assert.NoError(t, db.AutoMigrate())
+	assert.NoError(t, db.GetDb().Create(&repo.SchemaVersion{Version: migrator.Latest() + 1}).Error)
+	assert.True(t, xerrors.Is(db.AutoMigrate(), repo.ErrSchemaTooNew))
+}
+
+func TestMigratorOrder(t *testing.T) {
+	db, err := sqlite.OpenSqlite(&config.SqliteConfig{Path: filepath.Join(t.TempDir(), "migrate.db")})
+	assert.NoError(t, err)
+
+	var steps []string
+	step := func(name string) func(*gorm.DB) error {
+		return func(*gorm.DB) error {
+			steps = append(steps, name)
+			return nil
+		}
+	}
+	migrations := []repo.Migration{
+		{Version: 1, Description: "one", Up: step("up1"), Down: step("down1")},
+		{Version: 2, Description: "two", Up: step("up2"), Down: step("down2")},
+		{Version: 3, Description: "three", Up: step("up3"), Down: step("down3")},
+	}
+	migrator, err := repo.NewMigrator(db.GetDb(), migrations)
+	assert.NoError(t, err)
+
+	assert.NoError(t, migrator.Migrate(2))
+	assert.NoError(t, migrator.Migrate(0))
+	assert.Error(t, migrator.Migrate(1))
+	assert.NoError(t, migrator.Rollback(1))
+	assert.Equal(t, []string{"up1", "up2", "up3", "down3", "down2"}, steps)
+
+	_, err = repo.NewMigrator(db.GetDb(), migrations[1:])
+	assert.Error(t, err)
+}
+
+func TestMessageRevisionMigration(t *testing.T) {
+	db, err := sqlite.OpenSqlite(&config.SqliteConfig{Path: filepath.Join(t.TempDir(), "migrate.db")})
+	assert.NoError(t, err)
+	migrator, err := db.Migrator()
+	assert.NoError(t, err)
+
+	// the initial schema doesn't change with the models
+	msg := NewMessage()
+	model := sqlite.FromMessage(msg)
+	assert.NoError(t, migrator.Migrate(1))
+	assert.True(t, db.GetDb().Migrator().HasTable(model))
+	assert.False(t, db.GetDb().Migrator().HasColumn(model, "revision"))
+	assert.False(t, db.GetDb().Migrator().HasColumn(sqlite.FromWallet(types.Wallet{}), "sign_concurrency"))
+
+	// database of version 2 has no revision column
+	assert.NoError(t, migrator.Migrate(0))
+	assert.NoError(t, migrator.Rollback(2))
+	assert.False(t, db.GetDb().Migrator().HasColumn(model, "revision"))
+	assert.False(t, db.GetDb().Migrator().HasColumn(model, "signer_wallet"))
+	assert.NoError(t, db.GetDb().Omit("revision", "signer_wallet").Create(model).Error)
+
+	assert.NoError(t, migrator.Migrate(0))
+	assert.True(t, db.GetDb().Migrator().HasColumn(model, "revision"))
+	assert.True(t, db.GetDb().Migrator().HasColumn(model, "signer_wallet"))
+	res, err := db.MessageRepo().GetMessageByUid(msg.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), res.Revision)
+	assert.NoError(t, db.MessageRepo().SaveMessage(res))
+	assert.Equal(t, int64(1), res.Revision)
+}
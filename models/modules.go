@@ -4,19 +4,73 @@ import (
 	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/venus-messager/config"
+	"github.com/filecoin-project/venus-messager/models/memory"
 	"github.com/filecoin-project/venus-messager/models/mysql"
+	"github.com/filecoin-project/venus-messager/models/postgres"
 	"github.com/filecoin-project/venus-messager/models/repo"
 	"github.com/filecoin-project/venus-messager/models/sqlite"
 )
 
+// SetDataBase open the database in config, the tokens of wallets and nodes are encrypted if the token key is configured
 func SetDataBase(cfg *config.DbConfig) (repo.Repo, error) {
+	r, err := openDataBase(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTokenKeyFile(&cfg.TokenEncryption, r); err != nil {
+		_ = r.DbClose()
+		return nil, err
+	}
+	key, err := cfg.TokenEncryption.ReadKey()
+	if err != nil {
+		_ = r.DbClose()
+		return nil, err
+	}
+	if key == nil {
+		return r, nil
+	}
+	cipher, err := repo.NewTokenCipher(key)
+	if err != nil {
+		_ = r.DbClose()
+		return nil, err
+	}
+	return repo.NewTokenEncryptedRepo(r, cipher), nil
+}
+
+// checkTokenKeyFile generate the key file if it not exist, a new key can not decrypt the tokens encrypted by the lost one,
+// so it fails if the database already has encrypted tokens
+func checkTokenKeyFile(cfg *config.TokenEncryptionConfig, r repo.Repo) error {
+	missing, err := cfg.KeyFileMissing()
+	if err != nil || !missing {
+		return err
+	}
+	encrypted, err := repo.HasEncryptedToken(r.GetDb())
+	if err != nil {
+		return err
+	}
+	if encrypted {
+		return xerrors.Errorf("token key file %s not exist but tokens in database are encrypted, restore the key file", cfg.KeyFile)
+	}
+	key, err := config.GenerateTokenKey()
+	if err != nil {
+		return err
+	}
+	return config.WriteTokenKeyFile(cfg.KeyFile, key)
+}
+
+func openDataBase(cfg *config.DbConfig) (repo.Repo, error) {
 	switch cfg.Type {
 	case "sqlite":
 		return sqlite.OpenSqlite(&cfg.Sqlite)
 	case "mysql":
 		return mysql.OpenMysql(&cfg.MySql)
+	case "postgres":
+		return postgres.OpenPostgres(&cfg.Postgres)
+	case "memory":
+		// nothing is persisted, for demo and test only
+		return memory.OpenMemory()
 	default:
-		return nil, xerrors.Errorf("unsupport db type,(%s, %s)", "sqlite", "mysql")
+		return nil, xerrors.Errorf("unsupport db type,(%s, %s, %s, %s)", "sqlite", "mysql", "postgres", "memory")
 	}
 }
 
@@ -110,7 +110,7 @@ func (s mysqlAddressRepo) GetOneRecord(ctx context.Context, addr address.Address
 
 func (s mysqlAddressRepo) DelAddress(ctx context.Context, addr address.Address) error {
 	return s.DB.Model((*mysqlAddress)(nil)).Where("addr = ? and is_deleted = -1", addr.String()).
-		Updates(map[string]interface{}{"is_deleted": 1}).Error
+		Updates(map[string]interface{}{"is_deleted": repo.Deleted}).Error
 }
 
 func (s mysqlAddressRepo) ListAddress(ctx context.Context) ([]*types.Address, error) {
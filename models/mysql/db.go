@@ -13,6 +13,7 @@ import (
 
 type MysqlRepo struct {
 	*gorm.DB
+	replicas *replicaSet
 }
 
 func (d MysqlRepo) WalletAddressRepo() repo.WalletAddressRepo {
@@ -23,6 +24,16 @@ func (d MysqlRepo) MessageRepo() repo.MessageRepo {
 	return newMysqlMessageRepo(d.DB)
 }
 
+// MessageQueryRepo use a healthy replica if there is one, otherwise the primary
+func (d MysqlRepo) MessageQueryRepo() repo.MessageQueryRepo {
+	primary := newMysqlMessageRepo(d.DB)
+	db := d.replicas.pick()
+	if db == nil {
+		return primary
+	}
+	return repo.NewReplicaMessageQueryRepo(newMysqlMessageRepo(db), primary, d.replicas.cfg.FallbackOnNotFound)
+}
+
 func (d MysqlRepo) WalletRepo() repo.WalletRepo {
 	return newMysqlWalletRepo(d.DB)
 }
@@ -39,29 +50,32 @@ func (d MysqlRepo) NodeRepo() repo.NodeRepo {
 	return newMysqlNodeRepo(d.DB)
 }
 
-func (d MysqlRepo) AutoMigrate() error {
-	err := d.GetDb().AutoMigrate(mysqlMessage{})
-	if err != nil {
-		return err
-	}
+func (d MysqlRepo) ArchiveRepo() repo.ArchiveRepo {
+	return newMysqlArchiveRepo(d.DB)
+}
 
-	if err := d.GetDb().AutoMigrate(mysqlAddress{}); err != nil {
-		return err
-	}
+func (d MysqlRepo) AuditRepo() repo.AuditRepo {
+	return newMysqlAuditRepo(d.DB)
+}
 
-	if err := d.GetDb().AutoMigrate(mysqlSharedParams{}); err != nil {
-		return err
-	}
+func (d MysqlRepo) ParamsVersionRepo() repo.ParamsVersionRepo {
+	return newMysqlParamsVersionRepo(d.DB)
+}
 
-	if err := d.GetDb().AutoMigrate(mysqlNode{}); err != nil {
-		return err
-	}
+func (d MysqlRepo) MultisigRepo() repo.MultisigRepo {
+	return newMysqlMultisigRepo(d.DB)
+}
+
+func (d MysqlRepo) Migrator() (*repo.Migrator, error) {
+	return repo.NewMigrator(d.DB, migrations)
+}
 
-	if err := d.GetDb().AutoMigrate(mysqlWalletAddress{}); err != nil {
+func (d MysqlRepo) AutoMigrate() error {
+	migrator, err := d.Migrator()
+	if err != nil {
 		return err
 	}
-
-	return d.GetDb().AutoMigrate(mysqlWallet{})
+	return migrator.Migrate(0)
 }
 
 func (d MysqlRepo) GetDb() *gorm.DB {
@@ -69,6 +83,7 @@ func (d MysqlRepo) GetDb() *gorm.DB {
 }
 
 func (d MysqlRepo) DbClose() error {
+	d.replicas.close()
 	// return d.DbClose()
 	// todo:
 	return nil
@@ -91,6 +106,18 @@ func (t *TxMysqlRepo) WalletAddressRepo() repo.WalletAddressRepo {
 	return newMysqlWalletAddressRepo(t.DB)
 }
 
+func (t *TxMysqlRepo) ArchiveRepo() repo.ArchiveRepo {
+	return newMysqlArchiveRepo(t.DB)
+}
+
+func (t *TxMysqlRepo) SharedParamsRepo() repo.SharedParamsRepo {
+	return newMysqlSharedParamsRepo(t.DB)
+}
+
+func (t *TxMysqlRepo) ParamsVersionRepo() repo.ParamsVersionRepo {
+	return newMysqlParamsVersionRepo(t.DB)
+}
+
 func (t *TxMysqlRepo) WalletRepo() repo.WalletRepo {
 	return newMysqlWalletRepo(t.DB)
 }
@@ -103,13 +130,35 @@ func (t *TxMysqlRepo) AddressRepo() repo.AddressRepo {
 	return newMysqlAddressRepo(t.DB)
 }
 
+func (t *TxMysqlRepo) NodeRepo() repo.NodeRepo {
+	return newMysqlNodeRepo(t.DB)
+}
+
 func OpenMysql(cfg *config.MySqlConfig) (repo.Repo, error) {
-	db, err := gorm.Open(mysql.Open(cfg.ConnectionString), &gorm.Config{
+	db, err := openDB(cfg.ConnectionString, cfg)
+	if err != nil {
+		return nil, err
+	}
+	replicas, err := openReplicaSet(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// 使用插件
+	//db.Use(&TracePlugin{})
+	return &MysqlRepo{
+		DB:       db,
+		replicas: replicas,
+	}, nil
+}
+
+func openDB(dsn string, cfg *config.MySqlConfig) (*gorm.DB, error) {
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
 		//Logger: logger.Default.LogMode(logger.Info), // 日志配置
 	})
 
 	if err != nil {
-		return nil, xerrors.Errorf("[db connection failed] Database name: %s %w", cfg.ConnectionString, err)
+		return nil, xerrors.Errorf("[db connection failed] Database name: %s %w", dsn, err)
 	}
 
 	db.Set("gorm:table_options", "CHARSET=utf8mb4")
@@ -126,9 +175,5 @@ func OpenMysql(cfg *config.MySqlConfig) (repo.Repo, error) {
 	sqlDB.SetMaxIdleConns(cfg.MaxIdleConn)
 	sqlDB.SetConnMaxLifetime(time.Minute * cfg.ConnMaxLifeTime)
 
-	// 使用插件
-	//db.Use(&TracePlugin{})
-	return &MysqlRepo{
-		db,
-	}, nil
+	return db, nil
 }
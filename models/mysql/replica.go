@@ -0,0 +1,163 @@
+package mysql
+
+import (
+	"database/sql"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/xerrors"
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-messager/config"
+)
+
+type replica struct {
+	db      *gorm.DB
+	healthy int32
+}
+
+func (r *replica) isHealthy() bool {
+	return atomic.LoadInt32(&r.healthy) == 1
+}
+
+func (r *replica) setHealthy(healthy bool) {
+	var v int32
+	if healthy {
+		v = 1
+	}
+	atomic.StoreInt32(&r.healthy, v)
+}
+
+// replicaSet pick the healthy replicas in turn, a replica is unhealthy when it can't be reached or lags too much
+type replicaSet struct {
+	replicas []*replica
+	cfg      *config.MySqlReplicaConfig
+
+	next     uint64
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func openReplicaSet(cfg *config.MySqlConfig) (*replicaSet, error) {
+	rs := &replicaSet{cfg: &cfg.Replica, stop: make(chan struct{})}
+	for _, dsn := range cfg.Replica.ConnectionStrings {
+		db, err := openDB(dsn, cfg)
+		if err != nil {
+			return nil, xerrors.Errorf("open replica %w", err)
+		}
+		rs.replicas = append(rs.replicas, &replica{db: db})
+	}
+	if len(rs.replicas) == 0 {
+		return rs, nil
+	}
+
+	rs.check()
+	if rs.cfg.CheckInterval > 0 {
+		go rs.checkLoop(time.Duration(rs.cfg.CheckInterval) * time.Second)
+	}
+	return rs, nil
+}
+
+// pick return nil if no replica is healthy
+func (rs *replicaSet) pick() *gorm.DB {
+	if rs == nil {
+		return nil
+	}
+	for range rs.replicas {
+		r := rs.replicas[atomic.AddUint64(&rs.next, 1)%uint64(len(rs.replicas))]
+		if r.isHealthy() {
+			return r.db
+		}
+	}
+	return nil
+}
+
+func (rs *replicaSet) checkLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			rs.check()
+		case <-rs.stop:
+			return
+		}
+	}
+}
+
+func (rs *replicaSet) check() {
+	for _, r := range rs.replicas {
+		r.setHealthy(rs.checkReplica(r.db) == nil)
+	}
+}
+
+func (rs *replicaSet) checkReplica(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	if err := sqlDB.Ping(); err != nil {
+		return err
+	}
+	if rs.cfg.MaxLag <= 0 {
+		return nil
+	}
+	lag, err := replicaLag(db)
+	if err != nil {
+		return err
+	}
+	if lag > time.Duration(rs.cfg.MaxLag)*time.Second {
+		return xerrors.Errorf("replica lags %v behind the primary", lag)
+	}
+	return nil
+}
+
+func (rs *replicaSet) close() {
+	if rs == nil {
+		return
+	}
+	rs.stopOnce.Do(func() {
+		close(rs.stop)
+	})
+}
+
+// replicaLag read Seconds_Behind_Master of `SHOW SLAVE STATUS`, which is NULL when the replication is stopped
+func replicaLag(db *gorm.DB) (time.Duration, error) {
+	rows, err := db.Raw("SHOW SLAVE STATUS").Rows()
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+	if !rows.Next() {
+		return 0, xerrors.New("replication is not configured")
+	}
+	values := make([]sql.NullString, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return 0, err
+	}
+	for i, column := range columns {
+		if column != "Seconds_Behind_Master" {
+			continue
+		}
+		if !values[i].Valid {
+			return 0, xerrors.New("replication is stopped")
+		}
+		seconds, err := strconv.ParseInt(values[i].String, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(seconds) * time.Second, nil
+	}
+	return 0, xerrors.New("Seconds_Behind_Master not found in slave status")
+}
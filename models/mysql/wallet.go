@@ -16,9 +16,12 @@ type mysqlWallet struct {
 
 	Name  string      `gorm:"column:name;type:varchar(256);NOT NULL"`
 	Url   string      `gorm:"column:url;type:varchar(256);NOT NULL"`
-	Token string      `gorm:"column:token;type:varchar(256);NOT NULL"`
+	Token string      `gorm:"column:token;type:varchar(1024);NOT NULL"`
 	State types.State `gorm:"column:state;type:int;"`
 
+	SignConcurrency int     `gorm:"column:sign_concurrency;type:int;default:0;NOT NULL"`
+	SignRate        float64 `gorm:"column:sign_rate;type:DOUBLE;default:0;NOT NULL"`
+
 	IsDeleted int       `gorm:"column:is_deleted;index;default:-1;NOT NULL"` // 是否删除 1:是  -1:否
 	CreatedAt time.Time `gorm:"column:created_at;index;NOT NULL"`            // 创建时间
 	UpdatedAt time.Time `gorm:"column:updated_at;index;NOT NULL"`            // 更新时间
@@ -100,6 +103,11 @@ func (s mysqlWalletRepo) UpdateState(name string, state types.State) error {
 		UpdateColumn("state", state).Error
 }
 
+func (s mysqlWalletRepo) UpdateSignLimit(name string, concurrency int, rate float64) error {
+	return s.DB.Model((*mysqlWallet)(nil)).Where("name = ? and is_deleted = -1", name).
+		UpdateColumns(map[string]interface{}{"sign_concurrency": concurrency, "sign_rate": rate}).Error
+}
+
 func (s mysqlWalletRepo) DelWallet(name string) error {
 	var wallet mysqlWallet
 	if err := s.DB.Where("name = ? and is_deleted = -1", name).First(&wallet).Error; err != nil {
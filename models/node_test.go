@@ -10,11 +10,12 @@ import (
 
 func randNode() *types.Node {
 	return &types.Node{
-		ID:    types.NewUUID(),
-		Name:  types.NewUUID().String(),
-		URL:   types.NewUUID().String(),
-		Token: types.NewUUID().String(),
-		Type:  0,
+		ID:     types.NewUUID(),
+		Name:   types.NewUUID().String(),
+		URL:    types.NewUUID().String(),
+		Token:  types.NewUUID().String(),
+		Type:   types.FullNode,
+		Weight: 1,
 	}
 }
 
@@ -56,6 +57,9 @@ func TestNode(t *testing.T) {
 		t.Skip()
 		nodeRepoTest(t, mysqlRepo.NodeRepo())
 	})
+	t.Run("postgres", func(t *testing.T) {
+		nodeRepoTest(t, setupPostgresRepo(t).NodeRepo())
+	})
 }
 
 func TestGetNode(t *testing.T) {
@@ -78,4 +82,7 @@ func TestGetNode(t *testing.T) {
 		t.SkipNow()
 		nodeRepoTest(t, mysqlRepo.NodeRepo())
 	})
+	t.Run("postgres", func(t *testing.T) {
+		nodeRepoTest(t, setupPostgresRepo(t).NodeRepo())
+	})
 }
@@ -0,0 +1,115 @@
+package postgres
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-messager/models/repo"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+type postgresAuditLog struct {
+	ID     types.UUID `gorm:"column:id;type:varchar(256);primary_key"`
+	Caller string     `gorm:"column:caller;type:varchar(256);index:audit_caller"`
+	Perm   string     `gorm:"column:perm;type:varchar(64)"`
+	IP     string     `gorm:"column:ip;type:varchar(256)"`
+	Method string     `gorm:"column:method;type:varchar(256);index:audit_method"`
+	Target string     `gorm:"column:target;type:varchar(256);index:audit_target"`
+	Args   string     `gorm:"column:args;type:text"`
+	Before string     `gorm:"column:before_value;type:text"`
+	After  string     `gorm:"column:after_value;type:text"`
+	Error  string     `gorm:"column:error;type:text"`
+
+	CreatedAt time.Time `gorm:"column:created_at;index:audit_created_at;NOT NULL"`
+}
+
+func (a *postgresAuditLog) TableName() string {
+	return "audit_logs"
+}
+
+func fromPostgresAuditLog(log *types.AuditLog) *postgresAuditLog {
+	return &postgresAuditLog{
+		ID:        log.ID,
+		Caller:    log.Caller,
+		Perm:      log.Perm,
+		IP:        log.IP,
+		Method:    log.Method,
+		Target:    log.Target,
+		Args:      string(log.Args),
+		Before:    string(log.Before),
+		After:     string(log.After),
+		Error:     log.Error,
+		CreatedAt: log.CreatedAt,
+	}
+}
+
+func (a *postgresAuditLog) AuditLog() *types.AuditLog {
+	return &types.AuditLog{
+		ID:        a.ID,
+		Caller:    a.Caller,
+		Perm:      a.Perm,
+		IP:        a.IP,
+		Method:    a.Method,
+		Target:    a.Target,
+		Args:      rawJSON(a.Args),
+		Before:    rawJSON(a.Before),
+		After:     rawJSON(a.After),
+		Error:     a.Error,
+		CreatedAt: a.CreatedAt,
+	}
+}
+
+var _ repo.AuditRepo = (*postgresAuditRepo)(nil)
+
+type postgresAuditRepo struct {
+	*gorm.DB
+}
+
+func newPostgresAuditRepo(db *gorm.DB) *postgresAuditRepo {
+	return &postgresAuditRepo{DB: db}
+}
+
+func (s *postgresAuditRepo) SaveAuditLog(log *types.AuditLog) error {
+	return s.DB.Create(fromPostgresAuditLog(log)).Error
+}
+
+func (s *postgresAuditRepo) ListAuditLog(filter *types.AuditLogFilter) ([]*types.AuditLog, error) {
+	query := s.DB.Model(&postgresAuditLog{})
+	if len(filter.Caller) > 0 {
+		query = query.Where("caller = ?", filter.Caller)
+	}
+	if len(filter.Method) > 0 {
+		query = query.Where("method = ?", filter.Method)
+	}
+	if len(filter.Target) > 0 {
+		query = query.Where("target = ?", filter.Target)
+	}
+	if !filter.Start.IsZero() {
+		query = query.Where("created_at >= ?", filter.Start)
+	}
+	if !filter.End.IsZero() {
+		query = query.Where("created_at < ?", filter.End)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var logs []*postgresAuditLog
+	if err := query.Order("created_at desc").Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	result := make([]*types.AuditLog, 0, len(logs))
+	for _, log := range logs {
+		result = append(result, log.AuditLog())
+	}
+	return result, nil
+}
+
+// rawJSON keep empty value as null instead of invalid json
+func rawJSON(s string) []byte {
+	if len(s) == 0 {
+		return nil
+	}
+	return []byte(s)
+}
@@ -0,0 +1,149 @@
+package postgres
+
+import (
+	"golang.org/x/xerrors"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-messager/config"
+	"github.com/filecoin-project/venus-messager/models/repo"
+)
+
+type PostgresRepo struct {
+	*gorm.DB
+}
+
+func (d PostgresRepo) WalletAddressRepo() repo.WalletAddressRepo {
+	return newPostgresWalletAddressRepo(d.DB)
+}
+
+func (d PostgresRepo) MessageRepo() repo.MessageRepo {
+	return newPostgresMessageRepo(d.DB)
+}
+
+func (d PostgresRepo) MessageQueryRepo() repo.MessageQueryRepo {
+	return newPostgresMessageRepo(d.DB)
+}
+
+func (d PostgresRepo) WalletRepo() repo.WalletRepo {
+	return newPostgresWalletRepo(d.DB)
+}
+
+func (d PostgresRepo) AddressRepo() repo.AddressRepo {
+	return newPostgresAddressRepo(d.DB)
+}
+
+func (d PostgresRepo) SharedParamsRepo() repo.SharedParamsRepo {
+	return newPostgresSharedParamsRepo(d.DB)
+}
+
+func (d PostgresRepo) NodeRepo() repo.NodeRepo {
+	return newPostgresNodeRepo(d.DB)
+}
+
+func (d PostgresRepo) ArchiveRepo() repo.ArchiveRepo {
+	return newPostgresArchiveRepo(d.DB)
+}
+
+func (d PostgresRepo) AuditRepo() repo.AuditRepo {
+	return newPostgresAuditRepo(d.DB)
+}
+
+func (d PostgresRepo) ParamsVersionRepo() repo.ParamsVersionRepo {
+	return newPostgresParamsVersionRepo(d.DB)
+}
+
+func (d PostgresRepo) MultisigRepo() repo.MultisigRepo {
+	return newPostgresMultisigRepo(d.DB)
+}
+
+func (d PostgresRepo) Migrator() (*repo.Migrator, error) {
+	return repo.NewMigrator(d.DB, migrations)
+}
+
+func (d PostgresRepo) AutoMigrate() error {
+	migrator, err := d.Migrator()
+	if err != nil {
+		return err
+	}
+	return migrator.Migrate(0)
+}
+
+func (d PostgresRepo) GetDb() *gorm.DB {
+	return d.DB
+}
+
+func (d PostgresRepo) DbClose() error {
+	// return d.DbClose()
+	// todo:
+	return nil
+}
+
+func (d PostgresRepo) Transaction(cb func(txRepo repo.TxRepo) error) error {
+	return d.DB.Transaction(func(tx *gorm.DB) error {
+		txRepo := &TxPostgresRepo{tx}
+		return cb(txRepo)
+	})
+}
+
+var _ repo.TxRepo = (*TxPostgresRepo)(nil)
+
+type TxPostgresRepo struct {
+	*gorm.DB
+}
+
+func (t *TxPostgresRepo) WalletAddressRepo() repo.WalletAddressRepo {
+	return newPostgresWalletAddressRepo(t.DB)
+}
+
+func (t *TxPostgresRepo) ArchiveRepo() repo.ArchiveRepo {
+	return newPostgresArchiveRepo(t.DB)
+}
+
+func (t *TxPostgresRepo) SharedParamsRepo() repo.SharedParamsRepo {
+	return newPostgresSharedParamsRepo(t.DB)
+}
+
+func (t *TxPostgresRepo) ParamsVersionRepo() repo.ParamsVersionRepo {
+	return newPostgresParamsVersionRepo(t.DB)
+}
+
+func (t *TxPostgresRepo) WalletRepo() repo.WalletRepo {
+	return newPostgresWalletRepo(t.DB)
+}
+
+func (t *TxPostgresRepo) MessageRepo() repo.MessageRepo {
+	return newPostgresMessageRepo(t.DB)
+}
+
+func (t *TxPostgresRepo) AddressRepo() repo.AddressRepo {
+	return newPostgresAddressRepo(t.DB)
+}
+
+func (t *TxPostgresRepo) NodeRepo() repo.NodeRepo {
+	return newPostgresNodeRepo(t.DB)
+}
+
+func OpenPostgres(cfg *config.PostgresConfig) (repo.Repo, error) {
+	db, err := gorm.Open(postgres.Open(cfg.ConnectionString), &gorm.Config{})
+	if err != nil {
+		return nil, xerrors.Errorf("[db connection failed] Database name: %s %w", cfg.ConnectionString, err)
+	}
+
+	if cfg.Debug {
+		db = db.Debug()
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConn)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConn)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifeTime)
+
+	return &PostgresRepo{
+		db,
+	}, nil
+}
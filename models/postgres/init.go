@@ -0,0 +1,25 @@
+package postgres
+
+import (
+	"reflect"
+
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+var TPostgresMessage = reflect.TypeOf(&postgresMessage{})
+var TMessage = reflect.TypeOf(&types.Message{})
+
+var TWallet = reflect.TypeOf(&types.Wallet{})
+var TPostgresWallet = reflect.TypeOf(&postgresWallet{})
+
+var TSharedParams = reflect.TypeOf(&types.SharedParams{})
+var TPostgresSharedParams = reflect.TypeOf(&postgresSharedParams{})
+
+var TAddress = reflect.TypeOf(&types.Address{})
+var TPostgresAddress = reflect.TypeOf(&postgresAddress{})
+
+var TNode = reflect.TypeOf(&types.Node{})
+var TPostgresNode = reflect.TypeOf(&postgresNode{})
+
+var TWalletAddress = reflect.TypeOf(&types.WalletAddress{})
+var TPostgresWalletAddress = reflect.TypeOf(&postgresWalletAddress{})
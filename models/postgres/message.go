@@ -0,0 +1,677 @@
+package postgres
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/crypto"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	venustypes "github.com/filecoin-project/venus/pkg/types"
+	"github.com/ipfs/go-cid"
+	"golang.org/x/xerrors"
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-messager/models/repo"
+	"github.com/filecoin-project/venus-messager/types"
+	"github.com/filecoin-project/venus-messager/utils"
+)
+
+type postgresMessage struct {
+	ID      string `gorm:"column:id;type:varchar(256);primary_key"`
+	Version uint64 `gorm:"column:version;type:bigint"`
+
+	From  string `gorm:"column:from_addr;type:varchar(256);NOT NULL;index:msg_from;index:idx_from_nonce;index:msg_from_state;"`
+	Nonce uint64 `gorm:"column:nonce;type:bigint;index:msg_nonce;index:idx_from_nonce"`
+	To    string `gorm:"column:to;type:varchar(256);NOT NULL"`
+
+	Value types.Int `gorm:"column:value;type:varchar(256);"`
+
+	GasLimit   int64     `gorm:"column:gas_limit;type:bigint"`
+	GasFeeCap  types.Int `gorm:"column:gas_fee_cap;type:varchar(256);"`
+	GasPremium types.Int `gorm:"column:gas_premium;type:varchar(256);"`
+
+	Method int `gorm:"column:method;type:int"`
+
+	Params []byte `gorm:"column:params;type:bytea;"`
+
+	Signature *repo.SqlSignature `gorm:"column:signed_data;type:bytea;"`
+
+	UnsignedCid string `gorm:"column:unsigned_cid;type:varchar(256);index:msg_unsigned_cid;"`
+	SignedCid   string `gorm:"column:signed_cid;type:varchar(256);index:msg_signed_cid"`
+
+	Height    int64               `gorm:"column:height;type:bigint;index:msg_height"`
+	Receipt   *postgresMsgReceipt `gorm:"embedded;embeddedPrefix:receipt_"`
+	TipsetKey string              `gorm:"column:tipset_key;type:varchar(1024);"`
+
+	Meta *MsgMeta `gorm:"embedded;embeddedPrefix:meta_"`
+
+	WalletName   string `gorm:"column:wallet_name;type:varchar(256)"`
+	SignerWallet string `gorm:"column:signer_wallet;type:varchar(256)"`
+
+	State types.MessageState `gorm:"column:state;type:int;index:msg_state;index:msg_from_state;"`
+	// increased by every update, used to detect concurrent updates
+	Revision int64 `gorm:"column:revision;type:bigint;default:0;NOT NULL"`
+
+	IsDeleted int       `gorm:"column:is_deleted;index;default:-1;NOT NULL"` // 是否删除 1:是  -1:否
+	CreatedAt time.Time `gorm:"column:created_at;index;NOT NULL"`            // 创建时间
+	UpdatedAt time.Time `gorm:"column:updated_at;index;NOT NULL"`            // 更新时间
+}
+
+// postgresMsgReceipt is repo.SqlMsgReceipt with the return value stored in bytea, postgres has no blob type
+type postgresMsgReceipt struct {
+	ExitCode    exitcode.ExitCode `gorm:"column:exit_code;default:-1"`
+	ReturnValue []byte            `gorm:"column:return_value;type:bytea;"`
+	GasUsed     int64             `gorm:"column:gas_used;type:bigint;"`
+}
+
+func (s *postgresMsgReceipt) MsgReceipt() *venustypes.MessageReceipt {
+	return (*repo.SqlMsgReceipt)(s).MsgReceipt()
+}
+
+func fromMsgReceipt(receipt *venustypes.MessageReceipt) *postgresMsgReceipt {
+	return (*postgresMsgReceipt)(repo.FromMsgReceipt(receipt))
+}
+
+// checkMessageRange reject the uint64 fields which can't be stored in the signed bigint columns
+func checkMessageRange(msg *types.Message) error {
+	if msg.Nonce > math.MaxInt64 {
+		return xerrors.Errorf("nonce %d of message %s is greater than %d", msg.Nonce, msg.ID, int64(math.MaxInt64))
+	}
+	if msg.Version > math.MaxInt64 {
+		return xerrors.Errorf("version %d of message %s is greater than %d", msg.Version, msg.ID, int64(math.MaxInt64))
+	}
+	return nil
+}
+
+func (sqlMsg *postgresMessage) TableName() string {
+	return "messages"
+}
+
+func (sqlMsg *postgresMessage) Message() *types.Message {
+	var destMsg = &types.Message{
+		ID: sqlMsg.ID,
+		UnsignedMessage: venustypes.UnsignedMessage{
+			Version:    sqlMsg.Version,
+			Nonce:      sqlMsg.Nonce,
+			Value:      big.NewFromGo(sqlMsg.Value.Int),
+			GasLimit:   sqlMsg.GasLimit,
+			GasFeeCap:  big.NewFromGo(sqlMsg.GasFeeCap.Int),
+			GasPremium: big.NewFromGo(sqlMsg.GasPremium.Int),
+			Method:     abi.MethodNum(sqlMsg.Method),
+			Params:     sqlMsg.Params,
+		},
+		Height:       sqlMsg.Height,
+		Receipt:      sqlMsg.Receipt.MsgReceipt(),
+		Signature:    (*crypto.Signature)(sqlMsg.Signature),
+		Meta:         sqlMsg.Meta.Meta(),
+		WalletName:   sqlMsg.WalletName,
+		SignerWallet: sqlMsg.SignerWallet,
+		State:        sqlMsg.State,
+		UpdatedAt:    sqlMsg.UpdatedAt,
+		CreatedAt:    sqlMsg.CreatedAt,
+	}
+	destMsg.From, _ = address.NewFromString(sqlMsg.From)
+	destMsg.To, _ = address.NewFromString(sqlMsg.To)
+	if len(sqlMsg.UnsignedCid) > 0 {
+		unsignedCid, _ := cid.Decode(sqlMsg.UnsignedCid)
+		destMsg.UnsignedCid = &unsignedCid
+	}
+	if len(sqlMsg.SignedCid) > 0 {
+		signedCid, _ := cid.Decode(sqlMsg.SignedCid)
+		destMsg.SignedCid = &signedCid
+	}
+	if len(sqlMsg.TipsetKey) > 0 {
+		destMsg.TipSetKey, _ = utils.StringToTipsetKey(sqlMsg.TipsetKey)
+	}
+
+	return destMsg
+}
+
+func FromMessage(srcMsg *types.Message) *postgresMessage {
+	destMsg := &postgresMessage{
+		ID:           srcMsg.ID,
+		Version:      srcMsg.Version,
+		To:           srcMsg.To.String(),
+		From:         srcMsg.From.String(),
+		Nonce:        srcMsg.Nonce,
+		GasLimit:     srcMsg.GasLimit,
+		Method:       int(srcMsg.Method),
+		Params:       srcMsg.Params,
+		Signature:    (*repo.SqlSignature)(srcMsg.Signature),
+		Height:       srcMsg.Height,
+		Receipt:      fromMsgReceipt(srcMsg.Receipt),
+		Meta:         FromMeta(srcMsg.Meta),
+		WalletName:   srcMsg.WalletName,
+		SignerWallet: srcMsg.SignerWallet,
+		State:        srcMsg.State,
+		Revision:     srcMsg.Revision,
+		IsDeleted:    repo.NotDeleted,
+		CreatedAt:    srcMsg.CreatedAt,
+		UpdatedAt:    srcMsg.UpdatedAt,
+	}
+
+	if srcMsg.UnsignedCid != nil {
+		destMsg.UnsignedCid = srcMsg.UnsignedCid.String()
+	}
+
+	if srcMsg.SignedCid != nil {
+		destMsg.SignedCid = srcMsg.SignedCid.String()
+	}
+
+	if srcMsg.Value.Int != nil {
+		destMsg.Value = types.Int{Int: srcMsg.Value.Int}
+	}
+
+	if srcMsg.GasFeeCap.Int != nil {
+		destMsg.GasFeeCap = types.Int{Int: srcMsg.GasFeeCap.Int}
+	}
+
+	if srcMsg.GasPremium.Int != nil {
+		destMsg.GasPremium = types.Int{Int: srcMsg.GasPremium.Int}
+	}
+
+	if !srcMsg.TipSetKey.IsEmpty() {
+		destMsg.TipsetKey = srcMsg.TipSetKey.String()
+	}
+
+	return destMsg
+}
+
+type MsgMeta struct {
+	ExpireEpoch       abi.ChainEpoch `gorm:"column:expire_epoch;type:bigint;"`
+	GasOverEstimation float64        `gorm:"column:gas_over_estimation;type:decimal(10,2);"`
+	MaxFee            types.Int      `gorm:"column:max_fee;type:varchar(256);"`
+	MaxFeeCap         types.Int      `gorm:"column:max_fee_cap;type:varchar(256);"`
+}
+
+func (meta *MsgMeta) Meta() *types.MsgMeta {
+	return &types.MsgMeta{
+		ExpireEpoch:       meta.ExpireEpoch,
+		GasOverEstimation: meta.GasOverEstimation,
+		MaxFee:            big.NewFromGo(meta.MaxFee.Int),
+		MaxFeeCap:         big.NewFromGo(meta.MaxFeeCap.Int),
+	}
+}
+
+func FromMeta(srcMeta *types.MsgMeta) *MsgMeta {
+	if srcMeta == nil {
+		return &MsgMeta{
+			ExpireEpoch:       0,
+			GasOverEstimation: 0,
+			MaxFee:            types.Int{},
+			MaxFeeCap:         types.Int{},
+		}
+	}
+	meta := &MsgMeta{
+		ExpireEpoch:       srcMeta.ExpireEpoch,
+		GasOverEstimation: srcMeta.GasOverEstimation,
+	}
+
+	if srcMeta.MaxFee.Int != nil {
+		meta.MaxFee = types.Int{Int: srcMeta.MaxFee.Int}
+	}
+
+	if srcMeta.MaxFeeCap.Int != nil {
+		meta.MaxFeeCap = types.Int{Int: srcMeta.MaxFeeCap.Int}
+	}
+	return meta
+}
+
+var _ repo.MessageRepo = (*postgresMessageRepo)(nil)
+
+type postgresMessageRepo struct {
+	*gorm.DB
+}
+
+func (m *postgresMessageRepo) HasMessageByUid(id string) (bool, error) {
+	var count int64
+	err := m.DB.Table("messages").Where("id=?", id).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func newPostgresMessageRepo(db *gorm.DB) *postgresMessageRepo {
+	return &postgresMessageRepo{DB: db}
+}
+
+func (m *postgresMessageRepo) GetMessageState(id string) (types.MessageState, error) {
+	type Result struct {
+		State int
+	}
+
+	var result Result
+	err := m.DB.Table("messages").
+		Select("state").
+		Where("id = ?", id).
+		Scan(&result).Error
+	if err != nil {
+		return types.UnKnown, err
+	}
+
+	return types.MessageState(result.State), nil
+}
+
+func (m *postgresMessageRepo) ExpireMessage(msgs []*types.Message) error {
+	for _, msg := range msgs {
+		result := m.DB.Model(&postgresMessage{}).Where("id = ? AND revision = ?", msg.ID, msg.Revision).
+			UpdateColumns(map[string]interface{}{"state": types.FailedMsg, "revision": gorm.Expr("revision + 1")})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return &repo.RevisionConflictError{ID: msg.ID, Revision: msg.Revision}
+		}
+		msg.State = types.FailedMsg
+		msg.Revision++
+	}
+	return nil
+}
+
+func (m *postgresMessageRepo) ListFilledMessageByAddress(addr address.Address) ([]*types.Message, error) {
+	var sqlMsgs []*postgresMessage
+	err := m.DB.Find(&sqlMsgs, "from_addr=? AND state=?", addr.String(), types.FillMsg).Error
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*types.Message, len(sqlMsgs))
+	for index, sqlMsg := range sqlMsgs {
+		result[index] = sqlMsg.Message()
+	}
+	return result, nil
+}
+
+func (m *postgresMessageRepo) ListFilledMessageByWallet(walletName string, addr address.Address) ([]*types.Message, error) {
+	var sqlMsgs []*postgresMessage
+	err := m.DB.Find(&sqlMsgs, "from_addr=? AND state=? and wallet_name = ?", addr.String(), types.FillMsg, walletName).Error
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*types.Message, len(sqlMsgs))
+	for index, sqlMsg := range sqlMsgs {
+		result[index] = sqlMsg.Message()
+	}
+	return result, nil
+}
+
+func (m *postgresMessageRepo) ListOfflineSignMessageByWallet(walletName string) ([]*types.Message, error) {
+	var sqlMsgs []*postgresMessage
+	err := m.DB.Find(&sqlMsgs, "state=? and wallet_name = ?", types.OfflineSignMsg, walletName).Error
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*types.Message, len(sqlMsgs))
+	for index, sqlMsg := range sqlMsgs {
+		result[index] = sqlMsg.Message()
+	}
+	return result, nil
+}
+
+func (m *postgresMessageRepo) ListFilledMessageBelowNonce(addr address.Address, nonce uint64) ([]*types.Message, error) {
+	var sqlMsgs []*postgresMessage
+	err := m.DB.Find(&sqlMsgs, "from_addr=? AND state=? AND nonce < ?", addr.String(), types.FillMsg, nonce).Error
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*types.Message, len(sqlMsgs))
+	for index, sqlMsg := range sqlMsgs {
+		result[index] = sqlMsg.Message()
+	}
+	return result, nil
+}
+
+func (m *postgresMessageRepo) ListFilledMessageByHeight(height abi.ChainEpoch) ([]*types.Message, error) {
+	var sqlMsgs []*postgresMessage
+	err := m.DB.Find(&sqlMsgs, "height=? AND state=?", height, types.FillMsg).Error
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*types.Message, len(sqlMsgs))
+	for index, sqlMsg := range sqlMsgs {
+		result[index] = sqlMsg.Message()
+	}
+	return result, nil
+}
+
+func (m *postgresMessageRepo) ListChainMessageByHeight(height abi.ChainEpoch) ([]*types.Message, error) {
+	var sqlMsgs []*postgresMessage
+	err := m.DB.Find(&sqlMsgs, "height=? AND state=?", height, types.OnChainMsg).Error
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*types.Message, len(sqlMsgs))
+	for index, sqlMsg := range sqlMsgs {
+		result[index] = sqlMsg.Message()
+	}
+	return result, nil
+}
+
+func (m *postgresMessageRepo) ListMessageAfterID(id string, limit int) ([]*types.Message, error) {
+	var sqlMsgs []*postgresMessage
+	if err := m.DB.Where("id > ?", id).Order("id").Limit(limit).Find(&sqlMsgs).Error; err != nil {
+		return nil, err
+	}
+	result := make([]*types.Message, len(sqlMsgs))
+	for index, sqlMsg := range sqlMsgs {
+		result[index] = sqlMsg.Message()
+	}
+	return result, nil
+}
+
+func (m *postgresMessageRepo) ListArchivableMessage(before time.Time, belowHeight abi.ChainEpoch, limit int) ([]*types.Message, error) {
+	var conds []string
+	var args []interface{}
+	if !before.IsZero() {
+		conds = append(conds, "updated_at < ?")
+		args = append(args, before)
+	}
+	if belowHeight > 0 {
+		conds = append(conds, "(height > 0 AND height < ?)")
+		args = append(args, belowHeight)
+	}
+	if len(conds) == 0 {
+		return nil, nil
+	}
+
+	var sqlMsgs []*postgresMessage
+	err := m.DB.Where("state in (?, ?, ?)", types.OnChainMsg, types.FailedMsg, types.ReplacedMsg).
+		Where("("+strings.Join(conds, " OR ")+")", args...).
+		Order("updated_at").Limit(limit).Find(&sqlMsgs).Error
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*types.Message, len(sqlMsgs))
+	for index, sqlMsg := range sqlMsgs {
+		result[index] = sqlMsg.Message()
+	}
+	return result, nil
+}
+
+func (m *postgresMessageRepo) DeleteMessage(ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return m.DB.Where("id in ?", ids).Delete(&postgresMessage{}).Error
+}
+
+func (m *postgresMessageRepo) ListUnChainMessageByAddress(addr address.Address) ([]*types.Message, error) {
+	var sqlMsgs []*postgresMessage
+	err := m.DB.Find(&sqlMsgs, "from_addr=? AND state=?", addr.String(), types.UnFillMsg).Order("created_at").Error
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*types.Message, len(sqlMsgs))
+	for index, sqlMsg := range sqlMsgs {
+		result[index] = sqlMsg.Message()
+	}
+	return result, nil
+}
+
+//todo better batch update
+func (m *postgresMessageRepo) BatchSaveMessage(msgs []*types.Message) error {
+	for _, msg := range msgs {
+		err := m.SaveMessage(msg)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *postgresMessageRepo) CreateMessage(msg *types.Message) error {
+	if err := checkMessageRange(msg); err != nil {
+		return err
+	}
+	sqlMsg := FromMessage(msg)
+	// keep the time of message imported from other messager
+	if sqlMsg.CreatedAt.IsZero() {
+		sqlMsg.CreatedAt = time.Now()
+	}
+	if sqlMsg.UpdatedAt.IsZero() {
+		sqlMsg.UpdatedAt = time.Now()
+	}
+	return m.DB.Create(sqlMsg).Error
+}
+
+// SaveMessage used to update message and create message with CreateMessage,
+// the update fails with RevisionConflictError if the message is changed after msg was read
+func (m *postgresMessageRepo) SaveMessage(msg *types.Message) error {
+	if err := checkMessageRange(msg); err != nil {
+		return err
+	}
+	sqlMsg := FromMessage(msg)
+	sqlMsg.UpdatedAt = time.Now()
+	sqlMsg.Revision = msg.Revision + 1
+
+	result := m.DB.Model(&postgresMessage{}).Where("id = ? AND revision = ?", msg.ID, msg.Revision).
+		Select("*").Omit("id", "created_at").Updates(sqlMsg)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return &repo.RevisionConflictError{ID: msg.ID, Revision: msg.Revision}
+	}
+	msg.Revision = sqlMsg.Revision
+	msg.UpdatedAt = sqlMsg.UpdatedAt
+	return nil
+}
+
+func (m *postgresMessageRepo) GetMessageByUid(id string) (*types.Message, error) {
+	var msg postgresMessage
+	if err := m.DB.Where("id = ?", id).First(&msg).Error; err != nil {
+		return nil, err
+	}
+	return msg.Message(), nil
+}
+
+func (m *postgresMessageRepo) GetMessageByCid(unsignedCid cid.Cid) (*types.Message, error) {
+	var msg postgresMessage
+	if err := m.DB.Where("unsigned_cid = ?", unsignedCid.String()).First(&msg).Error; err != nil {
+		return nil, err
+	}
+	return msg.Message(), nil
+}
+
+func (m *postgresMessageRepo) GetMessageBySignedCid(signedCid cid.Cid) (*types.Message, error) {
+	var msg postgresMessage
+	if err := m.DB.Where("signed_cid = ?", signedCid.String()).First(&msg).Error; err != nil {
+		return nil, err
+	}
+	return msg.Message(), nil
+}
+
+func (m *postgresMessageRepo) GetSignedMessageByTime(start time.Time) ([]*types.Message, error) {
+	var sqlMsgs []*postgresMessage
+	if err := m.DB.Where("created_at >= ? and signed_data is not null", start).Find(&sqlMsgs).Error; err != nil {
+		return nil, err
+	}
+	result := make([]*types.Message, len(sqlMsgs))
+	for idx, msg := range sqlMsgs {
+		result[idx] = msg.Message()
+	}
+
+	return result, nil
+}
+
+func (m *postgresMessageRepo) GetSignedMessageByHeight(height abi.ChainEpoch) ([]*types.Message, error) {
+	var sqlMsgs []*postgresMessage
+	if err := m.DB.Where("height >= ? and signed_data is not null", uint64(height)).Find(&sqlMsgs).Error; err != nil {
+		return nil, err
+	}
+	result := make([]*types.Message, len(sqlMsgs))
+	for idx, msg := range sqlMsgs {
+		result[idx] = msg.Message()
+	}
+
+	return result, nil
+}
+
+func (m *postgresMessageRepo) GetMessageByFromAndNonce(from address.Address, nonce uint64) (*types.Message, error) {
+	var msg postgresMessage
+	if err := m.DB.Where("from_addr = ? and nonce = ?", from.String(), nonce).First(&msg).Error; err != nil {
+		return nil, err
+	}
+	return msg.Message(), nil
+}
+
+func (m *postgresMessageRepo) ListMessage() ([]*types.Message, error) {
+	var sqlMsgs []*postgresMessage
+	if err := m.DB.Find(&sqlMsgs).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]*types.Message, len(sqlMsgs))
+	for idx, msg := range sqlMsgs {
+		result[idx] = msg.Message()
+	}
+	return result, nil
+}
+
+func (m *postgresMessageRepo) ListMessageByAddress(addr address.Address) ([]*types.Message, error) {
+	var sqlMsgs []*postgresMessage
+	if err := m.DB.Find(&sqlMsgs, "from_addr=?", addr.String()).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]*types.Message, len(sqlMsgs))
+	for idx, msg := range sqlMsgs {
+		result[idx] = msg.Message()
+	}
+	return result, nil
+}
+
+func (m *postgresMessageRepo) ListFailedMessage() ([]*types.Message, error) {
+	var sqlMsgs []*postgresMessage
+	err := m.DB.Find(&sqlMsgs, "state = ? AND receipt_return_value is not null", types.UnFillMsg).Error
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*types.Message, len(sqlMsgs))
+	for index, sqlMsg := range sqlMsgs {
+		result[index] = sqlMsg.Message()
+	}
+	return result, nil
+}
+
+func (m *postgresMessageRepo) ListBlockedMessage(addr address.Address, d time.Duration) ([]*types.Message, error) {
+	var sqlMsgs []*postgresMessage
+	t := time.Now().Add(-d)
+	err := m.DB.Find(&sqlMsgs, "from_addr = ? AND state = ? AND created_at < ?", addr.String(), types.FillMsg, t).Error
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*types.Message, len(sqlMsgs))
+	for index, sqlMsg := range sqlMsgs {
+		result[index] = sqlMsg.Message()
+	}
+	return result, nil
+}
+
+func (m *postgresMessageRepo) ListUnchainedMsgs() ([]*types.Message, error) {
+	var sqlMsgs []*postgresMessage
+	if err := m.DB.Model((*postgresMessage)(nil)).
+		Where("height=0 and signed_data is null").
+		Find(&sqlMsgs).Error; err != nil {
+		return nil, err
+	}
+
+	var result = make([]*types.Message, len(sqlMsgs))
+
+	for idx, msg := range sqlMsgs {
+		result[idx] = msg.Message()
+	}
+	return result, nil
+}
+
+func (m *postgresMessageRepo) ListSignedMsgs() ([]*types.Message, error) {
+	var sqlMsgs []*postgresMessage
+	if err := m.DB.Model((*postgresMessage)(nil)).
+		Where("height=0 and signed_data is not null").
+		Find(&sqlMsgs).Error; err != nil {
+		return nil, err
+	}
+
+	var result = make([]*types.Message, len(sqlMsgs))
+
+	for idx, msg := range sqlMsgs {
+		result[idx] = msg.Message()
+	}
+	return result, nil
+}
+
+func (m *postgresMessageRepo) UpdateMessageInfoByCid(unsignedCid string,
+	revision int64,
+	receipt *venustypes.MessageReceipt,
+	height abi.ChainEpoch,
+	state types.MessageState,
+	tsKey venustypes.TipSetKey) error {
+	rcp := fromMsgReceipt(receipt)
+	updateClause := map[string]interface{}{
+		"height":               uint64(height),
+		"receipt_exit_code":    rcp.ExitCode,
+		"receipt_return_value": rcp.ReturnValue,
+		"receipt_gas_used":     rcp.GasUsed,
+		"state":                state,
+		"tipset_key":           tsKey.String(),
+	}
+	return m.updateColumns("unsigned_cid", unsignedCid, revision, updateClause)
+}
+
+func (m *postgresMessageRepo) UpdateMessageStateByCid(cid string, revision int64, state types.MessageState) error {
+	return m.updateColumns("unsigned_cid", cid, revision, map[string]interface{}{"state": state})
+}
+
+func (m *postgresMessageRepo) UpdateMessageStateByID(id string, revision int64, state types.MessageState) error {
+	return m.updateColumns("id", id, revision, map[string]interface{}{"state": state})
+}
+
+func (m *postgresMessageRepo) UpdateUnFilledMessageState(walletName string, addr address.Address, state types.MessageState) error {
+	return m.DB.Model(&postgresMessage{}).Where("wallet_name = ? and from_addr = ? and state = ?", walletName, addr.String(), types.UnFillMsg).
+		UpdateColumns(map[string]interface{}{"state": state, "revision": gorm.Expr("revision + 1")}).Error
+}
+
+// MigrateUnFilledMessage fails with ErrRevisionConflict if the messages are changed between reading the ids and updating them,
+// the update repeats the conditions so messages filled in between are never moved
+func (m *postgresMessageRepo) MigrateUnFilledMessage(fromWallet, toWallet string, addr address.Address) ([]string, error) {
+	states := []types.MessageState{types.UnFillMsg, types.NoWalletMsg}
+	var ids []string
+	if err := m.DB.Model(&postgresMessage{}).Where("wallet_name = ? and from_addr = ? and state in ?", fromWallet, addr.String(), states).
+		Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	result := m.DB.Model(&postgresMessage{}).Where("wallet_name = ? and from_addr = ? and state in ?", fromWallet, addr.String(), states).
+		UpdateColumns(map[string]interface{}{"wallet_name": toWallet, "state": types.UnFillMsg, "revision": gorm.Expr("revision + 1")})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected != int64(len(ids)) {
+		return nil, xerrors.Errorf("unfilled messages of %s changed while migrating: %w", addr, repo.ErrRevisionConflict)
+	}
+	return ids, nil
+}
+
+func (m *postgresMessageRepo) MarkBadMessage(id string, revision int64) (struct{}, error) {
+	return struct{}{}, m.updateColumns("id", id, revision, map[string]interface{}{"state": types.FailedMsg})
+}
+
+func (m *postgresMessageRepo) UpdateReturnValue(id string, revision int64, returnVal string) error {
+	return m.updateColumns("id", id, revision, map[string]interface{}{"receipt_return_value": []byte(returnVal)})
+}
+
+// updateColumns update the message whose key column equals value if it is still at revision
+func (m *postgresMessageRepo) updateColumns(key, value string, revision int64, columns map[string]interface{}) error {
+	columns["revision"] = gorm.Expr("revision + 1")
+	result := m.DB.Model(&postgresMessage{}).Where(key+" = ? AND revision = ?", value, revision).UpdateColumns(columns)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return &repo.RevisionConflictError{ID: value, Revision: revision}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package postgres
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-messager/models/repo"
+)
+
+// migrations is the schema history of postgres, only append to it, an applied migration must not be changed
+var migrations = []repo.Migration{
+	{
+		// databases created by AutoMigrate before schema_version existed are adopted by this migration
+		Version:     1,
+		Description: "initial schema",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(v1Tables...)
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(v1Tables...)
+		},
+	},
+	{
+		Version:     2,
+		Description: "archived messages",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&v2ArchivedMessage{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&v2ArchivedMessage{})
+		},
+	},
+	{
+		Version:     3,
+		Description: "message revision",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().AddColumn(&postgresMessage{}, "Revision")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&postgresMessage{}, "revision")
+		},
+	},
+	{
+		Version:     4,
+		Description: "audit log",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&v4AuditLog{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&v4AuditLog{})
+		},
+	},
+	{
+		Version:     5,
+		Description: "params version",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&v5ParamsVersion{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&v5ParamsVersion{})
+		},
+	},
+	{
+		Version:     6,
+		Description: "message signer wallet",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().AddColumn(&postgresMessage{}, "SignerWallet")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&postgresMessage{}, "signer_wallet")
+		},
+	},
+	{
+		Version:     7,
+		Description: "multisig proposal",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&v7MsigProposal{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&v7MsigProposal{})
+		},
+	},
+	{
+		// encrypted tokens are longer than 256
+		Version:     8,
+		Description: "token column size",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Migrator().AlterColumn(&postgresWallet{}, "Token"); err != nil {
+				return err
+			}
+			return tx.Migrator().AlterColumn(&postgresNode{}, "Token")
+		},
+		Down: func(tx *gorm.DB) error {
+			// fails if any token is longer than 256, rotate them to plaintext first
+			if err := tx.Exec("ALTER TABLE wallets ALTER COLUMN token TYPE varchar(256)").Error; err != nil {
+				return err
+			}
+			return tx.Exec("ALTER TABLE nodes ALTER COLUMN token TYPE varchar(256)").Error
+		},
+	},
+	{
+		Version:     9,
+		Description: "wallet sign limit",
+		Up: func(tx *gorm.DB) error {
+			for _, field := range []string{"SignConcurrency", "SignRate"} {
+				if err := tx.Migrator().AddColumn(&postgresWallet{}, field); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropColumn(&postgresWallet{}, "sign_concurrency"); err != nil {
+				return err
+			}
+			return tx.Migrator().DropColumn(&postgresWallet{}, "sign_rate")
+		},
+	},
+}
@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/hunjixin/automapper"
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-messager/models/repo"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+type postgresNode struct {
+	ID types.UUID `gorm:"column:id;type:varchar(256);primary_key;"` // 主键
+
+	Name   string         `gorm:"column:name;type:varchar(256);NOT NULL"`
+	URL    string         `gorm:"column:url;type:varchar(256);NOT NULL"`
+	Token  string         `gorm:"column:token;type:varchar(1024);NOT NULL"`
+	Type   types.NodeType `gorm:"column:node_type;type:int"`
+	Weight int            `gorm:"column:weight;type:int"`
+
+	IsDeleted int       `gorm:"column:is_deleted;index;default:-1;NOT NULL"` // 是否删除 1:是  -1:否
+	CreatedAt time.Time `gorm:"column:created_at;index;NOT NULL"`            // 创建时间
+	UpdatedAt time.Time `gorm:"column:updated_at;index;NOT NULL"`            // 更新时间
+}
+
+func FromNode(node *types.Node) *postgresNode {
+	return automapper.MustMapper(node, TPostgresNode).(*postgresNode)
+}
+
+func (postgresNode postgresNode) Node() *types.Node {
+	return automapper.MustMapper(&postgresNode, TNode).(*types.Node)
+}
+
+func (postgresNode postgresNode) TableName() string {
+	return "nodes"
+}
+
+var _ repo.NodeRepo = (*postgresNodeRepo)(nil)
+
+type postgresNodeRepo struct {
+	*gorm.DB
+}
+
+func newPostgresNodeRepo(db *gorm.DB) postgresNodeRepo {
+	return postgresNodeRepo{DB: db}
+}
+
+func (s postgresNodeRepo) CreateNode(node *types.Node) error {
+	sNode := FromNode(node)
+	sNode.CreatedAt = time.Now()
+	sNode.UpdatedAt = time.Now()
+	return s.DB.Create(sNode).Error
+}
+
+func (s postgresNodeRepo) SaveNode(node *types.Node) error {
+	sNode := FromNode(node)
+	sNode.IsDeleted = repo.NotDeleted
+	sNode.UpdatedAt = time.Now()
+	// keep the creation time of existing node
+	var exist postgresNode
+	if err := s.DB.Where("id = ?", node.ID).Take(&exist).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return err
+		}
+		sNode.CreatedAt = time.Now()
+	} else {
+		sNode.CreatedAt = exist.CreatedAt
+	}
+	return s.DB.Save(sNode).Error
+}
+
+func (s postgresNodeRepo) GetNode(name string) (*types.Node, error) {
+	var node postgresNode
+	if err := s.DB.Where("name = ? and is_deleted = -1", name).First(&node).Error; err != nil {
+		return nil, err
+	}
+	return node.Node(), nil
+}
+
+func (s postgresNodeRepo) HasNode(name string) (bool, error) {
+	var count int64
+	if err := s.DB.Model(&postgresNode{}).Where("name = ? and is_deleted = -1", name).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (s postgresNodeRepo) ListNode() ([]*types.Node, error) {
+	var internalNode []*postgresNode
+	if err := s.DB.Find(&internalNode, "is_deleted = ?", -1).Error; err != nil {
+		return nil, err
+	}
+
+	result, err := automapper.Mapper(internalNode, reflect.TypeOf([]*types.Node{}))
+	if err != nil {
+		return nil, err
+	}
+	return result.([]*types.Node), nil
+}
+
+func (s postgresNodeRepo) DelNode(name string) error {
+	var node postgresNode
+	if err := s.DB.Where("name = ? and is_deleted = -1", name).First(&node).Error; err != nil {
+		return err
+	}
+	node.IsDeleted = repo.Deleted
+
+	return s.DB.Save(&node).Error
+}
@@ -0,0 +1,27 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+// the table created by migration 2, it is frozen so that later changes of the model don't change the schema
+
+type v2ArchivedMessage struct {
+	ID          string             `gorm:"column:id;type:varchar(256);primary_key"`
+	From        string             `gorm:"column:from_addr;type:varchar(256);NOT NULL;index:archive_from_nonce"`
+	Nonce       uint64             `gorm:"column:nonce;type:bigint;index:archive_from_nonce"`
+	UnsignedCid string             `gorm:"column:unsigned_cid;type:varchar(256);index:archive_unsigned_cid"`
+	SignedCid   string             `gorm:"column:signed_cid;type:varchar(256);index:archive_signed_cid"`
+	Height      int64              `gorm:"column:height;type:bigint"`
+	State       types.MessageState `gorm:"column:state;type:int"`
+	Data        []byte             `gorm:"column:data;type:bytea;NOT NULL"`
+
+	CreatedAt  time.Time `gorm:"column:created_at;NOT NULL"`
+	ArchivedAt time.Time `gorm:"column:archived_at;index;NOT NULL"`
+}
+
+func (v2ArchivedMessage) TableName() string {
+	return "archived_messages"
+}
@@ -0,0 +1,28 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+// the table created by migration 4, it is frozen so that later changes of the model don't change the schema
+
+type v4AuditLog struct {
+	ID     types.UUID `gorm:"column:id;type:varchar(256);primary_key"`
+	Caller string     `gorm:"column:caller;type:varchar(256);index:audit_caller"`
+	Perm   string     `gorm:"column:perm;type:varchar(64)"`
+	IP     string     `gorm:"column:ip;type:varchar(256)"`
+	Method string     `gorm:"column:method;type:varchar(256);index:audit_method"`
+	Target string     `gorm:"column:target;type:varchar(256);index:audit_target"`
+	Args   string     `gorm:"column:args;type:text"`
+	Before string     `gorm:"column:before_value;type:text"`
+	After  string     `gorm:"column:after_value;type:text"`
+	Error  string     `gorm:"column:error;type:text"`
+
+	CreatedAt time.Time `gorm:"column:created_at;index:audit_created_at;NOT NULL"`
+}
+
+func (v4AuditLog) TableName() string {
+	return "audit_logs"
+}
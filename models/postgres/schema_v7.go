@@ -0,0 +1,34 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+// the table created by migration 7, it is frozen so that later changes of the model don't change the schema
+
+type v7MsigProposal struct {
+	ID         string    `gorm:"column:id;type:varchar(256);primaryKey"`
+	Msig       string    `gorm:"column:msig;type:varchar(256);NOT NULL;index:msig_proposal_msig"`
+	TxID       int64     `gorm:"column:tx_id;type:bigint"`
+	Proposer   string    `gorm:"column:proposer;type:varchar(256);NOT NULL"`
+	WalletName string    `gorm:"column:wallet_name;type:varchar(256)"`
+	To         string    `gorm:"column:to;type:varchar(256);NOT NULL"`
+	Value      types.Int `gorm:"column:value;type:varchar(256);"`
+	Method     int       `gorm:"column:method;type:int"`
+	Params     []byte    `gorm:"column:params;type:bytea;"`
+
+	State types.MsigProposalState `gorm:"column:state;type:int;index:msig_proposal_state"`
+	// json of approvals
+	Approvals   string `gorm:"column:approvals;type:text"`
+	CancelMsgID string `gorm:"column:cancel_msg_id;type:varchar(256)"`
+	Error       string `gorm:"column:error;type:text"`
+
+	CreatedAt time.Time `gorm:"column:created_at;index;NOT NULL"`
+	UpdatedAt time.Time `gorm:"column:updated_at;NOT NULL"`
+}
+
+func (v7MsigProposal) TableName() string {
+	return "msig_proposals"
+}
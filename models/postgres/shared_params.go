@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/hunjixin/automapper"
+
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/venus-messager/models/repo"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+type postgresSharedParams struct {
+	ID uint `gorm:"primary_key;column:id;type:smallint;NOT NULL" json:"id"`
+
+	ExpireEpoch       abi.ChainEpoch `gorm:"column:expire_epoch;type:bigint;NOT NULL"`
+	GasOverEstimation float64        `gorm:"column:gas_over_estimation;type:double precision;NOT NULL"`
+	MaxFee            int64          `gorm:"column:max_fee;type:bigint;NOT NULL"`
+	MaxFeeCap         int64          `gorm:"column:max_fee_cap;type:bigint;NOT NULL"`
+
+	SelMsgNum uint64 `gorm:"column:sel_msg_num;type:bigint;NOT NULL"`
+
+	ScanInterval int `gorm:"column:scan_interval;NOT NULL"`
+
+	MaxEstFailNumOfMsg uint64 `gorm:"column:max_ext_fail_num_of_msg;type:bigint;NOT NULL"`
+}
+
+func FromSharedParams(sp types.SharedParams) *postgresSharedParams {
+	return automapper.MustMapper(&sp, TPostgresSharedParams).(*postgresSharedParams)
+}
+
+func (ssp postgresSharedParams) SharedParams() *types.SharedParams {
+	return automapper.MustMapper(&ssp, TSharedParams).(*types.SharedParams)
+}
+
+func (ssp postgresSharedParams) TableName() string {
+	return "shared_params"
+}
+
+var _ repo.SharedParamsRepo = (*postgresSharedParamsRepo)(nil)
+
+type postgresSharedParamsRepo struct {
+	*gorm.DB
+}
+
+func newPostgresSharedParamsRepo(db *gorm.DB) postgresSharedParamsRepo {
+	return postgresSharedParamsRepo{DB: db}
+}
+
+func (s postgresSharedParamsRepo) GetSharedParams(ctx context.Context) (*types.SharedParams, error) {
+	var ssp postgresSharedParams
+	if err := s.DB.Take(&ssp).Error; err != nil {
+		return nil, err
+	}
+	return ssp.SharedParams(), nil
+}
+
+func (s postgresSharedParamsRepo) SetSharedParams(ctx context.Context, params *types.SharedParams) (uint, error) {
+	var ssp postgresSharedParams
+	if err := s.DB.Where("id = ?", 1).Take(&ssp).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			if params.ID == 0 {
+				params.ID = 1
+			}
+			if err := s.DB.Save(FromSharedParams(*params)).Error; err != nil {
+				return 0, err
+			}
+			return params.ID, nil
+		}
+		return 0, err
+	}
+
+	ssp.ExpireEpoch = params.ExpireEpoch
+	ssp.GasOverEstimation = params.GasOverEstimation
+	ssp.MaxFeeCap = params.MaxFeeCap
+	ssp.MaxFee = params.MaxFee
+
+	ssp.SelMsgNum = params.SelMsgNum
+
+	ssp.ScanInterval = params.ScanInterval
+
+	ssp.MaxEstFailNumOfMsg = params.MaxEstFailNumOfMsg
+
+	if err := s.DB.Save(&ssp).Error; err != nil {
+		return 0, err
+	}
+
+	return params.ID, nil
+}
@@ -0,0 +1,120 @@
+package postgres
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/hunjixin/automapper"
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-messager/models/repo"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+type postgresWallet struct {
+	ID types.UUID `gorm:"column:id;type:varchar(256);primary_key;"` // 主键
+
+	Name  string      `gorm:"column:name;type:varchar(256);NOT NULL"`
+	Url   string      `gorm:"column:url;type:varchar(256);NOT NULL"`
+	Token string      `gorm:"column:token;type:varchar(1024);NOT NULL"`
+	State types.State `gorm:"column:state;type:int;"`
+
+	SignConcurrency int     `gorm:"column:sign_concurrency;type:int;default:0;NOT NULL"`
+	SignRate        float64 `gorm:"column:sign_rate;type:double precision;default:0;NOT NULL"`
+
+	IsDeleted int       `gorm:"column:is_deleted;index;default:-1;NOT NULL"` // 是否删除 1:是  -1:否
+	CreatedAt time.Time `gorm:"column:created_at;index;NOT NULL"`            // 创建时间
+	UpdatedAt time.Time `gorm:"column:updated_at;index;NOT NULL"`            // 更新时间
+}
+
+func FromWallet(msg types.Wallet) *postgresWallet {
+	return automapper.MustMapper(&msg, TPostgresWallet).(*postgresWallet)
+}
+
+func (postgresWallet postgresWallet) Wallet() *types.Wallet {
+	return automapper.MustMapper(&postgresWallet, TWallet).(*types.Wallet)
+}
+
+func (postgresWallet postgresWallet) TableName() string {
+	return "wallets"
+}
+
+var _ repo.WalletRepo = (*postgresWalletRepo)(nil)
+
+type postgresWalletRepo struct {
+	*gorm.DB
+}
+
+func newPostgresWalletRepo(db *gorm.DB) postgresWalletRepo {
+	return postgresWalletRepo{DB: db}
+}
+
+func (s postgresWalletRepo) SaveWallet(wallet *types.Wallet) error {
+	return s.DB.Save(FromWallet(*wallet)).Error
+}
+
+func (s postgresWalletRepo) GetWalletByID(uuid types.UUID) (*types.Wallet, error) {
+	var wallet postgresWallet
+	if err := s.DB.Where("id = ? and is_deleted = -1", uuid.String()).First(&wallet).Error; err != nil {
+		return nil, err
+	}
+	return wallet.Wallet(), nil
+}
+
+func (s postgresWalletRepo) GetWalletByName(name string) (*types.Wallet, error) {
+	var wallet postgresWallet
+	if err := s.DB.Where("name = ? and is_deleted = -1", name).First(&wallet).Error; err != nil {
+		return nil, err
+	}
+	return wallet.Wallet(), nil
+}
+
+func (s postgresWalletRepo) GetOneRecord(name string) (*types.Wallet, error) {
+	var wallet postgresWallet
+	if err := s.DB.Where("name = ?", name).First(&wallet).Error; err != nil {
+		return nil, err
+	}
+	return wallet.Wallet(), nil
+}
+
+func (s postgresWalletRepo) HasWallet(name string) (bool, error) {
+	var count int64
+	if err := s.DB.Model(&postgresWallet{}).Where("name = ?", name).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (s postgresWalletRepo) ListWallet() ([]*types.Wallet, error) {
+	var internalWallet []*postgresWallet
+	if err := s.DB.Find(&internalWallet, "is_deleted = ?", -1).Error; err != nil {
+		return nil, err
+	}
+
+	result, err := automapper.Mapper(internalWallet, reflect.TypeOf([]*types.Wallet{}))
+	if err != nil {
+		return nil, err
+	}
+	return result.([]*types.Wallet), nil
+}
+
+func (s postgresWalletRepo) UpdateState(name string, state types.State) error {
+	return s.DB.Model((*postgresWallet)(nil)).Where("name = ? and is_deleted = -1", name).
+		UpdateColumn("state", state).Error
+}
+
+func (s postgresWalletRepo) UpdateSignLimit(name string, concurrency int, rate float64) error {
+	return s.DB.Model((*postgresWallet)(nil)).Where("name = ? and is_deleted = -1", name).
+		UpdateColumns(map[string]interface{}{"sign_concurrency": concurrency, "sign_rate": rate}).Error
+}
+
+func (s postgresWalletRepo) DelWallet(name string) error {
+	var wallet postgresWallet
+	if err := s.DB.Where("name = ? and is_deleted = -1", name).First(&wallet).Error; err != nil {
+		return err
+	}
+	wallet.IsDeleted = repo.Deleted
+	wallet.State = types.Removed
+
+	return s.DB.Save(&wallet).Error
+}
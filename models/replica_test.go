@@ -0,0 +1,72 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-messager/models/memory"
+	"github.com/filecoin-project/venus-messager/models/repo"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+type brokenQueryRepo struct {
+	repo.MessageQueryRepo
+}
+
+func (r brokenQueryRepo) GetMessageByUid(id string) (*types.Message, error) {
+	return nil, xerrors.New("connection refused")
+}
+
+func (r brokenQueryRepo) ListMessage() ([]*types.Message, error) {
+	return nil, xerrors.New("connection refused")
+}
+
+func TestReplicaMessageQueryRepo(t *testing.T) {
+	primary, err := memory.OpenMemory()
+	require.NoError(t, err)
+	replica, err := memory.OpenMemory()
+	require.NoError(t, err)
+
+	replicated := NewMessage()
+	require.NoError(t, primary.MessageRepo().CreateMessage(replicated))
+	require.NoError(t, replica.MessageRepo().CreateMessage(replicated))
+	// not replicated yet
+	pending := NewMessage()
+	require.NoError(t, primary.MessageRepo().CreateMessage(pending))
+
+	queryRepo := repo.NewReplicaMessageQueryRepo(replica.MessageQueryRepo(), primary.MessageQueryRepo(), false)
+	msg, err := queryRepo.GetMessageByUid(replicated.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, replicated.ID, msg.ID)
+	_, err = queryRepo.GetMessageByUid(pending.ID)
+	assert.True(t, xerrors.Is(err, gorm.ErrRecordNotFound))
+	has, err := queryRepo.HasMessageByUid(pending.ID)
+	assert.NoError(t, err)
+	assert.False(t, has)
+	msgs, err := queryRepo.ListMessage()
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 1)
+
+	queryRepo = repo.NewReplicaMessageQueryRepo(replica.MessageQueryRepo(), primary.MessageQueryRepo(), true)
+	msg, err = queryRepo.GetMessageByUid(pending.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, pending.ID, msg.ID)
+	has, err = queryRepo.HasMessageByUid(pending.ID)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	_, err = queryRepo.GetMessageByUid(types.NewUUID().String())
+	assert.True(t, xerrors.Is(err, gorm.ErrRecordNotFound))
+
+	// failed queries are retried on the primary
+	queryRepo = repo.NewReplicaMessageQueryRepo(brokenQueryRepo{replica.MessageQueryRepo()}, primary.MessageQueryRepo(), false)
+	msg, err = queryRepo.GetMessageByUid(pending.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, pending.ID, msg.ID)
+	msgs, err = queryRepo.ListMessage()
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 2)
+}
@@ -0,0 +1,17 @@
+package repo
+
+import (
+	"github.com/ipfs/go-cid"
+
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+// ArchiveRepo store finished messages moved out of the messages table
+type ArchiveRepo interface {
+	ArchiveMessage(msgs []*types.Message) error
+	HasArchivedMessageByUid(id string) (bool, error)
+	GetArchivedMessageByUid(id string) (*types.Message, error)
+	GetArchivedMessageByCid(unsignedCid cid.Cid) (*types.Message, error)
+	GetArchivedMessageBySignedCid(signedCid cid.Cid) (*types.Message, error)
+	ListArchivedMessageAfterID(id string, limit int) ([]*types.Message, error)
+}
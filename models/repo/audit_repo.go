@@ -0,0 +1,8 @@
+package repo
+
+import "github.com/filecoin-project/venus-messager/types"
+
+type AuditRepo interface {
+	SaveAuditLog(log *types.AuditLog) error
+	ListAuditLog(filter *types.AuditLogFilter) ([]*types.AuditLog, error)
+}
@@ -1,6 +1,7 @@
 package repo
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/ipfs/go-cid"
@@ -10,38 +11,74 @@ import (
 
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/venus-messager/types"
+	"golang.org/x/xerrors"
 )
 
-type MessageRepo interface {
-	ExpireMessage(msg []*types.Message) error
-	BatchSaveMessage(msg []*types.Message) error
-	CreateMessage(msg *types.Message) error
-	SaveMessage(msg *types.Message) error
+var ErrRevisionConflict = xerrors.New("message revision conflict")
+
+// RevisionConflictError is returned when a message is updated by others after it was read, read it again and retry
+type RevisionConflictError struct {
+	ID       string
+	Revision int64
+}
 
+func (e *RevisionConflictError) Error() string {
+	return fmt.Sprintf("message %s has been changed since revision %d", e.ID, e.Revision)
+}
+
+func (e *RevisionConflictError) Is(target error) bool {
+	return target == ErrRevisionConflict
+}
+
+// MessageQueryRepo is the read only message queries served by api
+type MessageQueryRepo interface {
 	GetMessageByFromAndNonce(from address.Address, nonce uint64) (*types.Message, error)
 	GetMessageByUid(id string) (*types.Message, error)
 	HasMessageByUid(id string) (bool, error)
-	GetMessageState(id string) (types.MessageState, error)
 	GetMessageByCid(unsignedCid cid.Cid) (*types.Message, error)
 	GetMessageBySignedCid(signedCid cid.Cid) (*types.Message, error)
-	GetSignedMessageByTime(start time.Time) ([]*types.Message, error)
-	GetSignedMessageByHeight(height abi.ChainEpoch) ([]*types.Message, error)
 	ListMessage() ([]*types.Message, error)
 	ListMessageByAddress(addr address.Address) ([]*types.Message, error)
 	ListFailedMessage() ([]*types.Message, error)
 	ListBlockedMessage(addr address.Address, d time.Duration) ([]*types.Message, error)
+}
+
+// MessageRepo every update increase the revision of message, the updates of one message write the message read before
+// and fail with RevisionConflictError if the revision was changed, the batch updates only change the messages still in the expected state
+type MessageRepo interface {
+	MessageQueryRepo
+
+	ExpireMessage(msg []*types.Message) error
+	BatchSaveMessage(msg []*types.Message) error
+	CreateMessage(msg *types.Message) error
+	SaveMessage(msg *types.Message) error
+
+	GetMessageState(id string) (types.MessageState, error)
+	GetSignedMessageByTime(start time.Time) ([]*types.Message, error)
+	GetSignedMessageByHeight(height abi.ChainEpoch) ([]*types.Message, error)
+	// ListMessageAfterID list messages ordered by id, used to walk through the table page by page
+	ListMessageAfterID(id string, limit int) ([]*types.Message, error)
 	ListUnChainMessageByAddress(addr address.Address) ([]*types.Message, error)
 	ListFilledMessageByAddress(addr address.Address) ([]*types.Message, error)
 	ListFilledMessageByWallet(walletName string, addr address.Address) ([]*types.Message, error)
+	// ListOfflineSignMessageByWallet list messages of the offline wallet waiting for signatures
+	ListOfflineSignMessageByWallet(walletName string) ([]*types.Message, error)
 	ListFilledMessageByHeight(height abi.ChainEpoch) ([]*types.Message, error)
+	ListChainMessageByHeight(height abi.ChainEpoch) ([]*types.Message, error)
 	ListUnchainedMsgs() ([]*types.Message, error)
 	ListSignedMsgs() ([]*types.Message, error)
 	ListFilledMessageBelowNonce(addr address.Address, nonce uint64) ([]*types.Message, error)
+	// ListArchivableMessage list finished messages updated before the time or packed below the height, zero value disable the condition
+	ListArchivableMessage(before time.Time, belowHeight abi.ChainEpoch, limit int) ([]*types.Message, error)
+	DeleteMessage(ids []string) error
 
-	UpdateMessageInfoByCid(unsignedCid string, receipt *venustypes.MessageReceipt, height abi.ChainEpoch, state types.MessageState, tsKey venustypes.TipSetKey) error
-	UpdateMessageStateByCid(unsignedCid string, state types.MessageState) error
-	UpdateMessageStateByID(id string, state types.MessageState) error
+	UpdateMessageInfoByCid(unsignedCid string, revision int64, receipt *venustypes.MessageReceipt, height abi.ChainEpoch, state types.MessageState, tsKey venustypes.TipSetKey) error
+	UpdateMessageStateByCid(unsignedCid string, revision int64, state types.MessageState) error
+	UpdateMessageStateByID(id string, revision int64, state types.MessageState) error
+	// UpdateUnFilledMessageState change the state of messages of addr in wallet which are still unfilled
 	UpdateUnFilledMessageState(walletName string, addr address.Address, state types.MessageState) error
-	MarkBadMessage(id string) (struct{}, error)
-	UpdateReturnValue(id string, returnVal string) error
+	// MigrateUnFilledMessage move the unfilled and no wallet messages of addr from fromWallet to toWallet as unfilled, return their ids
+	MigrateUnFilledMessage(fromWallet, toWallet string, addr address.Address) ([]string, error)
+	MarkBadMessage(id string, revision int64) (struct{}, error)
+	UpdateReturnValue(id string, revision int64, returnVal string) error
 }
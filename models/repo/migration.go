@@ -0,0 +1,185 @@
+package repo
+
+import (
+	"time"
+
+	"golang.org/x/xerrors"
+	"gorm.io/gorm"
+)
+
+var ErrSchemaTooNew = xerrors.New("database schema is newer than the binary")
+
+// ErrMigrationNotSupported is returned by Migrator of repo without schema, eg. memory repo
+var ErrMigrationNotSupported = xerrors.New("repo has no schema to migrate")
+
+// Migration is a numbered schema change, Down must revert what Up does
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(tx *gorm.DB) error
+	Down        func(tx *gorm.DB) error
+}
+
+// SchemaVersion is a migration applied to the database
+type SchemaVersion struct {
+	Version     int       `gorm:"column:version;primaryKey;autoIncrement:false" json:"version"`
+	Description string    `gorm:"column:description;type:varchar(256)" json:"description"`
+	AppliedAt   time.Time `gorm:"column:applied_at" json:"appliedAt"`
+}
+
+func (s SchemaVersion) TableName() string {
+	return "schema_version"
+}
+
+type MigrationStatus struct {
+	Version int              `json:"version"`
+	Latest  int              `json:"latest"`
+	Applied []*SchemaVersion `json:"applied"`
+	Pending []*SchemaVersion `json:"pending"`
+}
+
+// Migrator apply the ordered migrations of a backend and record them in the schema_version table
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// NewMigrator check that the versions of migrations start from 1 and are consecutive
+func NewMigrator(db *gorm.DB, migrations []Migration) (*Migrator, error) {
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			return nil, xerrors.Errorf("migration %d: expect version %d, got %d", i, i+1, m.Version)
+		}
+		if m.Up == nil || m.Down == nil {
+			return nil, xerrors.Errorf("migration %d: missing up or down", m.Version)
+		}
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Latest return the newest version known by the binary
+func (m *Migrator) Latest() int {
+	return len(m.migrations)
+}
+
+func (m *Migrator) applied() ([]*SchemaVersion, error) {
+	if err := m.db.AutoMigrate(&SchemaVersion{}); err != nil {
+		return nil, xerrors.Errorf("create schema_version table %w", err)
+	}
+	var versions []*SchemaVersion
+	if err := m.db.Order("version").Find(&versions).Error; err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// Version return the current version of the database, 0 means no migration applied
+func (m *Migrator) Version() (int, error) {
+	versions, err := m.applied()
+	if err != nil {
+		return 0, err
+	}
+	if len(versions) == 0 {
+		return 0, nil
+	}
+	return versions[len(versions)-1].Version, nil
+}
+
+func (m *Migrator) Status() (*MigrationStatus, error) {
+	versions, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	status := &MigrationStatus{
+		Latest:  m.Latest(),
+		Applied: versions,
+		Pending: []*SchemaVersion{},
+	}
+	if len(versions) > 0 {
+		status.Version = versions[len(versions)-1].Version
+	}
+	for _, mig := range m.migrations {
+		if mig.Version > status.Version {
+			status.Pending = append(status.Pending, &SchemaVersion{Version: mig.Version, Description: mig.Description})
+		}
+	}
+	return status, nil
+}
+
+// Check return ErrSchemaTooNew if the database was migrated by a newer binary
+func (m *Migrator) Check() error {
+	version, err := m.Version()
+	if err != nil {
+		return err
+	}
+	if version > m.Latest() {
+		return xerrors.Errorf("version %d, binary supports %d: %w", version, m.Latest(), ErrSchemaTooNew)
+	}
+	return nil
+}
+
+// Migrate apply the pending migrations up to target, 0 means the latest version
+func (m *Migrator) Migrate(target int) error {
+	if target == 0 {
+		target = m.Latest()
+	}
+	if target < 0 || target > m.Latest() {
+		return xerrors.Errorf("target version %d out of range [1, %d]", target, m.Latest())
+	}
+	if err := m.Check(); err != nil {
+		return err
+	}
+	version, err := m.Version()
+	if err != nil {
+		return err
+	}
+	if target < version {
+		return xerrors.Errorf("target version %d is lower than current version %d, use rollback", target, version)
+	}
+
+	for _, mig := range m.migrations[version:target] {
+		mig := mig
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := mig.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaVersion{
+				Version:     mig.Version,
+				Description: mig.Description,
+				AppliedAt:   time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return xerrors.Errorf("apply migration %d(%s) %w", mig.Version, mig.Description, err)
+		}
+	}
+	return nil
+}
+
+// Rollback revert the applied migrations until the database is at target version
+func (m *Migrator) Rollback(target int) error {
+	if err := m.Check(); err != nil {
+		return err
+	}
+	version, err := m.Version()
+	if err != nil {
+		return err
+	}
+	if target < 0 || target > version {
+		return xerrors.Errorf("target version %d out of range [0, %d]", target, version)
+	}
+
+	for v := version; v > target; v-- {
+		mig := m.migrations[v-1]
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := mig.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaVersion{}, "version = ?", mig.Version).Error
+		})
+		if err != nil {
+			return xerrors.Errorf("rollback migration %d(%s) %w", mig.Version, mig.Description, err)
+		}
+	}
+	return nil
+}
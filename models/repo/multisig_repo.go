@@ -0,0 +1,19 @@
+package repo
+
+import (
+	"github.com/filecoin-project/go-address"
+
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+// MultisigRepo store the multisig proposals made by the messager
+type MultisigRepo interface {
+	// SaveProposal insert or update the proposal
+	SaveProposal(p *types.MsigProposal) error
+	GetProposal(id string) (*types.MsigProposal, error)
+	DeleteProposal(id string) error
+	// ListProposal list proposals of msig from the latest, address.Undef means all multisig
+	ListProposal(msig address.Address) ([]*types.MsigProposal, error)
+	// ListUnfinishedProposal list proposals waiting for messages on chain
+	ListUnfinishedProposal() ([]*types.MsigProposal, error)
+}
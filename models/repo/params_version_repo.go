@@ -0,0 +1,13 @@
+package repo
+
+import "github.com/filecoin-project/venus-messager/types"
+
+// ParamsVersionRepo store the history of shared params and select message number of address
+type ParamsVersionRepo interface {
+	// CreateParamsVersion fails if the version of target exists
+	CreateParamsVersion(v *types.ParamsVersion) error
+	GetParamsVersion(target string, version int64) (*types.ParamsVersion, error)
+	GetLatestParamsVersion(target string) (*types.ParamsVersion, error)
+	// ListParamsVersion list versions of target from the latest, limit 0 means no limit
+	ListParamsVersion(target string, limit int) ([]*types.ParamsVersion, error)
+}
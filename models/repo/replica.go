@@ -0,0 +1,105 @@
+package repo
+
+import (
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/ipfs/go-cid"
+	"golang.org/x/xerrors"
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+var _ MessageQueryRepo = (*replicaMessageQueryRepo)(nil)
+
+// replicaMessageQueryRepo query the replica first, a failed query is retried on the primary,
+// so is a message not found when fallbackOnNotFound is set, the message may not have been replicated yet
+type replicaMessageQueryRepo struct {
+	replica            MessageQueryRepo
+	primary            MessageQueryRepo
+	fallbackOnNotFound bool
+}
+
+func NewReplicaMessageQueryRepo(replica, primary MessageQueryRepo, fallbackOnNotFound bool) MessageQueryRepo {
+	return &replicaMessageQueryRepo{replica: replica, primary: primary, fallbackOnNotFound: fallbackOnNotFound}
+}
+
+func (r *replicaMessageQueryRepo) retry(err error) bool {
+	if err == nil {
+		return false
+	}
+	return r.fallbackOnNotFound || !xerrors.Is(err, gorm.ErrRecordNotFound)
+}
+
+func (r *replicaMessageQueryRepo) getMessage(get func(repo MessageQueryRepo) (*types.Message, error)) (*types.Message, error) {
+	msg, err := get(r.replica)
+	if r.retry(err) {
+		return get(r.primary)
+	}
+	return msg, err
+}
+
+func (r *replicaMessageQueryRepo) listMessage(list func(repo MessageQueryRepo) ([]*types.Message, error)) ([]*types.Message, error) {
+	msgs, err := list(r.replica)
+	if err != nil {
+		return list(r.primary)
+	}
+	return msgs, nil
+}
+
+func (r *replicaMessageQueryRepo) GetMessageByFromAndNonce(from address.Address, nonce uint64) (*types.Message, error) {
+	return r.getMessage(func(repo MessageQueryRepo) (*types.Message, error) {
+		return repo.GetMessageByFromAndNonce(from, nonce)
+	})
+}
+
+func (r *replicaMessageQueryRepo) GetMessageByUid(id string) (*types.Message, error) {
+	return r.getMessage(func(repo MessageQueryRepo) (*types.Message, error) {
+		return repo.GetMessageByUid(id)
+	})
+}
+
+func (r *replicaMessageQueryRepo) HasMessageByUid(id string) (bool, error) {
+	has, err := r.replica.HasMessageByUid(id)
+	if err != nil || (!has && r.fallbackOnNotFound) {
+		return r.primary.HasMessageByUid(id)
+	}
+	return has, nil
+}
+
+func (r *replicaMessageQueryRepo) GetMessageByCid(unsignedCid cid.Cid) (*types.Message, error) {
+	return r.getMessage(func(repo MessageQueryRepo) (*types.Message, error) {
+		return repo.GetMessageByCid(unsignedCid)
+	})
+}
+
+func (r *replicaMessageQueryRepo) GetMessageBySignedCid(signedCid cid.Cid) (*types.Message, error) {
+	return r.getMessage(func(repo MessageQueryRepo) (*types.Message, error) {
+		return repo.GetMessageBySignedCid(signedCid)
+	})
+}
+
+func (r *replicaMessageQueryRepo) ListMessage() ([]*types.Message, error) {
+	return r.listMessage(func(repo MessageQueryRepo) ([]*types.Message, error) {
+		return repo.ListMessage()
+	})
+}
+
+func (r *replicaMessageQueryRepo) ListMessageByAddress(addr address.Address) ([]*types.Message, error) {
+	return r.listMessage(func(repo MessageQueryRepo) ([]*types.Message, error) {
+		return repo.ListMessageByAddress(addr)
+	})
+}
+
+func (r *replicaMessageQueryRepo) ListFailedMessage() ([]*types.Message, error) {
+	return r.listMessage(func(repo MessageQueryRepo) ([]*types.Message, error) {
+		return repo.ListFailedMessage()
+	})
+}
+
+func (r *replicaMessageQueryRepo) ListBlockedMessage(addr address.Address, d time.Duration) ([]*types.Message, error) {
+	return r.listMessage(func(repo MessageQueryRepo) ([]*types.Message, error) {
+		return repo.ListBlockedMessage(addr, d)
+	})
+}
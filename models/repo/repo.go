@@ -20,14 +20,22 @@ type Repo interface {
 	GetDb() *gorm.DB
 	Transaction(func(txRepo TxRepo) error) error
 	DbClose() error
+	// AutoMigrate apply all pending migrations, fails if the schema is newer than the binary
 	AutoMigrate() error
+	Migrator() (*Migrator, error)
 
 	WalletRepo() WalletRepo
 	MessageRepo() MessageRepo
+	// MessageQueryRepo serve the read only message queries of api, it may be a read replica lagging behind MessageRepo
+	MessageQueryRepo() MessageQueryRepo
 	AddressRepo() AddressRepo
 	SharedParamsRepo() SharedParamsRepo
 	NodeRepo() NodeRepo
 	WalletAddressRepo() WalletAddressRepo
+	ArchiveRepo() ArchiveRepo
+	AuditRepo() AuditRepo
+	ParamsVersionRepo() ParamsVersionRepo
+	MultisigRepo() MultisigRepo
 }
 
 type TxRepo interface {
@@ -35,6 +43,10 @@ type TxRepo interface {
 	MessageRepo() MessageRepo
 	AddressRepo() AddressRepo
 	WalletAddressRepo() WalletAddressRepo
+	ArchiveRepo() ArchiveRepo
+	SharedParamsRepo() SharedParamsRepo
+	ParamsVersionRepo() ParamsVersionRepo
+	NodeRepo() NodeRepo
 }
 
 type ISqlField interface {
@@ -45,6 +57,9 @@ type ISqlField interface {
 type SqlSignature crypto.Signature
 
 func (s *SqlSignature) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
 	sqlBin, isok := value.([]byte)
 	if !isok {
 		return fmt.Errorf("value must be []byte")
@@ -0,0 +1,160 @@
+package repo
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+
+	"golang.org/x/xerrors"
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+// encryptedTokenPrefix mark the encrypted token, tokens without it are plaintext saved before encryption was enabled
+const encryptedTokenPrefix = "enc:"
+
+// TokenCipher encrypt the tokens of wallets and nodes by AES-GCM
+type TokenCipher struct {
+	aead cipher.AEAD
+}
+
+func NewTokenCipher(key []byte) (*TokenCipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenCipher{aead: aead}, nil
+}
+
+func IsEncryptedToken(token string) bool {
+	return strings.HasPrefix(token, encryptedTokenPrefix)
+}
+
+// Encrypt return the encrypted token, empty token is kept empty
+func (c *TokenCipher) Encrypt(token string) (string, error) {
+	if len(token) == 0 || IsEncryptedToken(token) {
+		return token, nil
+	}
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(token), nil)
+	return encryptedTokenPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt return the plaintext token, plaintext token is returned as it is
+func (c *TokenCipher) Decrypt(token string) (string, error) {
+	if !IsEncryptedToken(token) {
+		return token, nil
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(token, encryptedTokenPrefix))
+	if err != nil {
+		return "", xerrors.Errorf("decode token %w", err)
+	}
+	if len(sealed) < c.aead.NonceSize() {
+		return "", xerrors.New("encrypted token too short")
+	}
+	nonce, data := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	plain, err := c.aead.Open(nil, nonce, data, nil)
+	if err != nil {
+		return "", xerrors.Errorf("decrypt token, the key may not be the one encrypted it: %w", err)
+	}
+	return string(plain), nil
+}
+
+// tokenRecord is the token column shared by the wallets and nodes tables of all backends
+type tokenRecord struct {
+	ID    types.UUID `gorm:"column:id"`
+	Token string     `gorm:"column:token"`
+}
+
+// tokenTables are the tables having token column
+var tokenTables = []string{"wallets", "nodes"}
+
+// RotateTokenKey re-encrypt the tokens of all wallets and nodes, deleted ones included, from oldCipher to newCipher in one transaction,
+// oldCipher is nil if the tokens are saved in plaintext, newCipher is nil to save them in plaintext. Return the number of tokens rotated
+func RotateTokenKey(db *gorm.DB, oldCipher, newCipher *TokenCipher) (int, error) {
+	return rewriteTokens(db, func(table string, record *tokenRecord) (string, error) {
+		token := record.Token
+		if IsEncryptedToken(token) {
+			if oldCipher == nil {
+				return "", xerrors.Errorf("token of %s %s is encrypted but no key is configured", table, record.ID)
+			}
+			var err error
+			if token, err = oldCipher.Decrypt(token); err != nil {
+				return "", xerrors.Errorf("%s %s %w", table, record.ID, err)
+			}
+		}
+		if newCipher != nil {
+			return newCipher.Encrypt(token)
+		}
+		return token, nil
+	})
+}
+
+// EncryptPlainTokens encrypt the tokens saved in plaintext before the key was configured, encrypted tokens are kept.
+// Return the number of tokens encrypted
+func EncryptPlainTokens(db *gorm.DB, cipher *TokenCipher) (int, error) {
+	return rewriteTokens(db, func(_ string, record *tokenRecord) (string, error) {
+		return cipher.Encrypt(record.Token)
+	})
+}
+
+// HasEncryptedToken return true if any wallet or node has encrypted token, tables not created yet are skipped
+func HasEncryptedToken(db *gorm.DB) (bool, error) {
+	if db == nil {
+		return false, nil
+	}
+	for _, table := range tokenTables {
+		if !db.Migrator().HasTable(table) {
+			continue
+		}
+		var count int64
+		if err := db.Table(table).Where("token LIKE ?", encryptedTokenPrefix+"%").Count(&count).Error; err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// rewriteTokens replace the tokens of all wallets and nodes by convert in one transaction, return the number of tokens changed
+func rewriteTokens(db *gorm.DB, convert func(table string, record *tokenRecord) (string, error)) (int, error) {
+	if db == nil {
+		return 0, xerrors.New("repo has no database to rewrite tokens")
+	}
+	var count int
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for _, table := range tokenTables {
+			var records []*tokenRecord
+			if err := tx.Table(table).Select("id", "token").Find(&records).Error; err != nil {
+				return err
+			}
+			for _, record := range records {
+				token, err := convert(table, record)
+				if err != nil {
+					return err
+				}
+				if token == record.Token {
+					continue
+				}
+				if err := tx.Table(table).Where("id = ?", record.ID).UpdateColumn("token", token).Error; err != nil {
+					return err
+				}
+				count++
+			}
+		}
+		return nil
+	})
+	return count, err
+}
@@ -0,0 +1,167 @@
+package repo
+
+import (
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+var _ Repo = (*tokenEncryptedRepo)(nil)
+
+// tokenEncryptedRepo encrypt the tokens of wallets and nodes before they are saved and decrypt them after they are read,
+// so the services always see plaintext tokens
+type tokenEncryptedRepo struct {
+	Repo
+	cipher *TokenCipher
+}
+
+func NewTokenEncryptedRepo(r Repo, cipher *TokenCipher) Repo {
+	return &tokenEncryptedRepo{Repo: r, cipher: cipher}
+}
+
+// AutoMigrate also encrypt the tokens saved in plaintext before the key was configured
+func (r *tokenEncryptedRepo) AutoMigrate() error {
+	if err := r.Repo.AutoMigrate(); err != nil {
+		return err
+	}
+	if r.GetDb() == nil {
+		return nil
+	}
+	_, err := EncryptPlainTokens(r.GetDb(), r.cipher)
+	return err
+}
+
+func (r *tokenEncryptedRepo) Transaction(cb func(txRepo TxRepo) error) error {
+	return r.Repo.Transaction(func(txRepo TxRepo) error {
+		return cb(&tokenEncryptedTxRepo{TxRepo: txRepo, cipher: r.cipher})
+	})
+}
+
+func (r *tokenEncryptedRepo) WalletRepo() WalletRepo {
+	return &tokenEncryptedWalletRepo{WalletRepo: r.Repo.WalletRepo(), cipher: r.cipher}
+}
+
+func (r *tokenEncryptedRepo) NodeRepo() NodeRepo {
+	return &tokenEncryptedNodeRepo{NodeRepo: r.Repo.NodeRepo(), cipher: r.cipher}
+}
+
+type tokenEncryptedTxRepo struct {
+	TxRepo
+	cipher *TokenCipher
+}
+
+func (r *tokenEncryptedTxRepo) WalletRepo() WalletRepo {
+	return &tokenEncryptedWalletRepo{WalletRepo: r.TxRepo.WalletRepo(), cipher: r.cipher}
+}
+
+func (r *tokenEncryptedTxRepo) NodeRepo() NodeRepo {
+	return &tokenEncryptedNodeRepo{NodeRepo: r.TxRepo.NodeRepo(), cipher: r.cipher}
+}
+
+type tokenEncryptedWalletRepo struct {
+	WalletRepo
+	cipher *TokenCipher
+}
+
+func (r *tokenEncryptedWalletRepo) decrypt(wallet *types.Wallet, err error) (*types.Wallet, error) {
+	if err != nil {
+		return nil, err
+	}
+	if wallet.Token, err = r.cipher.Decrypt(wallet.Token); err != nil {
+		return nil, xerrors.Errorf("wallet %s %w", wallet.Name, err)
+	}
+	return wallet, nil
+}
+
+func (r *tokenEncryptedWalletRepo) SaveWallet(wallet *types.Wallet) error {
+	token, err := r.cipher.Encrypt(wallet.Token)
+	if err != nil {
+		return err
+	}
+	encrypted := *wallet
+	encrypted.Token = token
+	return r.WalletRepo.SaveWallet(&encrypted)
+}
+
+func (r *tokenEncryptedWalletRepo) GetWalletByID(uuid types.UUID) (*types.Wallet, error) {
+	return r.decrypt(r.WalletRepo.GetWalletByID(uuid))
+}
+
+func (r *tokenEncryptedWalletRepo) GetWalletByName(name string) (*types.Wallet, error) {
+	return r.decrypt(r.WalletRepo.GetWalletByName(name))
+}
+
+func (r *tokenEncryptedWalletRepo) GetOneRecord(name string) (*types.Wallet, error) {
+	return r.decrypt(r.WalletRepo.GetOneRecord(name))
+}
+
+func (r *tokenEncryptedWalletRepo) ListWallet() ([]*types.Wallet, error) {
+	wallets, err := r.WalletRepo.ListWallet()
+	if err != nil {
+		return nil, err
+	}
+	for _, wallet := range wallets {
+		if _, err := r.decrypt(wallet, nil); err != nil {
+			return nil, err
+		}
+	}
+	return wallets, nil
+}
+
+type tokenEncryptedNodeRepo struct {
+	NodeRepo
+	cipher *TokenCipher
+}
+
+func (r *tokenEncryptedNodeRepo) encrypt(node *types.Node) (*types.Node, error) {
+	token, err := r.cipher.Encrypt(node.Token)
+	if err != nil {
+		return nil, err
+	}
+	encrypted := *node
+	encrypted.Token = token
+	return &encrypted, nil
+}
+
+func (r *tokenEncryptedNodeRepo) decrypt(node *types.Node, err error) (*types.Node, error) {
+	if err != nil {
+		return nil, err
+	}
+	if node.Token, err = r.cipher.Decrypt(node.Token); err != nil {
+		return nil, xerrors.Errorf("node %s %w", node.Name, err)
+	}
+	return node, nil
+}
+
+func (r *tokenEncryptedNodeRepo) CreateNode(node *types.Node) error {
+	encrypted, err := r.encrypt(node)
+	if err != nil {
+		return err
+	}
+	return r.NodeRepo.CreateNode(encrypted)
+}
+
+func (r *tokenEncryptedNodeRepo) SaveNode(node *types.Node) error {
+	encrypted, err := r.encrypt(node)
+	if err != nil {
+		return err
+	}
+	return r.NodeRepo.SaveNode(encrypted)
+}
+
+func (r *tokenEncryptedNodeRepo) GetNode(name string) (*types.Node, error) {
+	return r.decrypt(r.NodeRepo.GetNode(name))
+}
+
+func (r *tokenEncryptedNodeRepo) ListNode() ([]*types.Node, error) {
+	nodes, err := r.NodeRepo.ListNode()
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range nodes {
+		if _, err := r.decrypt(node, nil); err != nil {
+			return nil, err
+		}
+	}
+	return nodes, nil
+}
@@ -12,5 +12,6 @@ type WalletRepo interface {
 	HasWallet(name string) (bool, error)
 	ListWallet() ([]*types.Wallet, error)
 	UpdateState(name string, state types.State) error
+	UpdateSignLimit(name string, concurrency int, rate float64) error
 	DelWallet(name string) error
 }
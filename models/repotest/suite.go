@@ -0,0 +1,1184 @@
+// Package repotest is the conformance suite of repo.Repo, every implementation should pass it
+package repotest
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/crypto"
+	venustypes "github.com/filecoin-project/venus/pkg/types"
+	"github.com/google/uuid"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-messager/models/repo"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+// RunRepoSuite run all cases against the repo returned by newRepo, newRepo is called by each case.
+// Cases only check the records created by themselves, so a database shared by cases and runs can be used.
+func RunRepoSuite(t *testing.T, newRepo func(t *testing.T) repo.Repo) {
+	cases := []struct {
+		name string
+		f    func(t *testing.T, r repo.Repo)
+	}{
+		{"MessageCreateAndGet", testMessageCreateAndGet},
+		{"MessageSave", testMessageSave},
+		{"MessageList", testMessageList},
+		{"MessageListByState", testMessageListByState},
+		{"MessageListByHeight", testMessageListByHeight},
+		{"MessageUpdate", testMessageUpdate},
+		{"MessageRevision", testMessageRevision},
+		{"MessagePageAndDelete", testMessagePageAndDelete},
+		{"MessageArchive", testMessageArchive},
+		{"Address", testAddress},
+		{"AddressSoftDelete", testAddressSoftDelete},
+		{"Wallet", testWallet},
+		{"WalletSoftDelete", testWalletSoftDelete},
+		{"WalletAddress", testWalletAddress},
+		{"WalletAddressSoftDelete", testWalletAddressSoftDelete},
+		{"Node", testNode},
+		{"SharedParams", testSharedParams},
+		{"AuditLog", testAuditLog},
+		{"ParamsVersion", testParamsVersion},
+		{"MultisigProposal", testMultisigProposal},
+		{"TransactionCommit", testTransactionCommit},
+		{"TransactionRollback", testTransactionRollback},
+		{"Migrator", testMigrator},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			c.f(t, newRepo(t))
+		})
+	}
+}
+
+func newAddress(t *testing.T) address.Address {
+	uid := uuid.New()
+	addr, err := address.NewActorAddress(uid[:])
+	require.NoError(t, err)
+	return addr
+}
+
+func newMessage(t *testing.T, from address.Address) *types.Message {
+	return &types.Message{
+		ID: types.NewUUID().String(),
+		UnsignedMessage: venustypes.UnsignedMessage{
+			From:       from,
+			To:         newAddress(t),
+			Value:      big.NewInt(rand.Int63n(1024)),
+			GasLimit:   rand.Int63n(100),
+			GasFeeCap:  abi.NewTokenAmount(2000),
+			GasPremium: abi.NewTokenAmount(1024),
+			Method:     1,
+			Params:     []byte(uuid.New().String()),
+		},
+		Meta: &types.MsgMeta{
+			ExpireEpoch:       100,
+			MaxFee:            big.NewInt(10),
+			MaxFeeCap:         big.NewInt(20),
+			GasOverEstimation: 1.25,
+		},
+		WalletName: "wallet",
+		State:      types.UnFillMsg,
+	}
+}
+
+func sign(msg *types.Message, nonce uint64) *types.Message {
+	msg.Nonce = nonce
+	msg.Signature = &crypto.Signature{Type: crypto.SigTypeSecp256k1, Data: []byte(uuid.New().String())}
+	unsignedCid := msg.UnsignedMessage.Cid()
+	msg.UnsignedCid = &unsignedCid
+	signedCid := (&venustypes.SignedMessage{Message: msg.UnsignedMessage, Signature: *msg.Signature}).Cid()
+	msg.SignedCid = &signedCid
+	msg.State = types.FillMsg
+	return msg
+}
+
+func createMessages(t *testing.T, r repo.Repo, msgs ...*types.Message) {
+	for _, msg := range msgs {
+		require.NoError(t, r.MessageRepo().CreateMessage(msg))
+	}
+}
+
+func assertSameMessage(t *testing.T, expect, actual *types.Message) {
+	if !assert.NotNil(t, actual) {
+		return
+	}
+	assert.Equal(t, expect.ID, actual.ID)
+	assert.Equal(t, expect.UnsignedMessage.Cid(), actual.UnsignedMessage.Cid())
+	assert.Equal(t, expect.UnsignedCid, actual.UnsignedCid)
+	assert.Equal(t, expect.SignedCid, actual.SignedCid)
+	assert.Equal(t, expect.Signature, actual.Signature)
+	assert.Equal(t, expect.State, actual.State)
+	assert.Equal(t, expect.Height, actual.Height)
+	assert.Equal(t, expect.WalletName, actual.WalletName)
+	assert.Equal(t, expect.SignerWallet, actual.SignerWallet)
+	assert.Equal(t, expect.Meta.ExpireEpoch, actual.Meta.ExpireEpoch)
+	assert.Equal(t, expect.Meta.GasOverEstimation, actual.Meta.GasOverEstimation)
+	assert.Equal(t, expect.Meta.MaxFee.String(), actual.Meta.MaxFee.String())
+	assert.Equal(t, expect.Meta.MaxFeeCap.String(), actual.Meta.MaxFeeCap.String())
+}
+
+func ids(msgs []*types.Message) []string {
+	res := make([]string, 0, len(msgs))
+	for _, msg := range msgs {
+		res = append(res, msg.ID)
+	}
+	return res
+}
+
+func isNotFound(err error) bool {
+	return err != nil && xerrors.Is(err, gorm.ErrRecordNotFound)
+}
+
+func testMessageCreateAndGet(t *testing.T, r repo.Repo) {
+	from := newAddress(t)
+	unsigned := newMessage(t, from)
+	signed := sign(newMessage(t, from), 3)
+	createMessages(t, r, unsigned, signed)
+	assert.Error(t, r.MessageRepo().CreateMessage(signed), "duplicate id")
+
+	msg, err := r.MessageRepo().GetMessageByUid(unsigned.ID)
+	assert.NoError(t, err)
+	assertSameMessage(t, unsigned, msg)
+	assert.False(t, msg.CreatedAt.IsZero())
+
+	msg, err = r.MessageRepo().GetMessageByUid(signed.ID)
+	assert.NoError(t, err)
+	assertSameMessage(t, signed, msg)
+
+	has, err := r.MessageRepo().HasMessageByUid(signed.ID)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	has, err = r.MessageRepo().HasMessageByUid(types.NewUUID().String())
+	assert.NoError(t, err)
+	assert.False(t, has)
+
+	_, err = r.MessageRepo().GetMessageByUid(types.NewUUID().String())
+	assert.True(t, isNotFound(err))
+
+	state, err := r.MessageRepo().GetMessageState(signed.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, types.FillMsg, state)
+
+	msg, err = r.MessageRepo().GetMessageByCid(*signed.UnsignedCid)
+	assert.NoError(t, err)
+	assertSameMessage(t, signed, msg)
+	msg, err = r.MessageRepo().GetMessageBySignedCid(*signed.SignedCid)
+	assert.NoError(t, err)
+	assertSameMessage(t, signed, msg)
+	_, err = r.MessageRepo().GetMessageBySignedCid(*unsignedCidOf(t, newMessage(t, from)))
+	assert.True(t, isNotFound(err))
+
+	msg, err = r.MessageRepo().GetMessageByFromAndNonce(from, 3)
+	assert.NoError(t, err)
+	assertSameMessage(t, signed, msg)
+	_, err = r.MessageRepo().GetMessageByFromAndNonce(from, 4)
+	assert.True(t, isNotFound(err))
+
+	// time of imported message is kept
+	imported := newMessage(t, from)
+	imported.CreatedAt = time.Now().Add(-time.Hour * 24).Truncate(time.Second)
+	imported.UpdatedAt = imported.CreatedAt
+	createMessages(t, r, imported)
+	msg, err = r.MessageRepo().GetMessageByUid(imported.ID)
+	assert.NoError(t, err)
+	assert.True(t, imported.CreatedAt.Equal(msg.CreatedAt), "%v %v", imported.CreatedAt, msg.CreatedAt)
+}
+
+func unsignedCidOf(t *testing.T, msg *types.Message) *cid.Cid {
+	c := msg.UnsignedMessage.Cid()
+	return &c
+}
+
+func testMessageSave(t *testing.T, r repo.Repo) {
+	from := newAddress(t)
+	msg := newMessage(t, from)
+	createMessages(t, r, msg)
+	before, err := r.MessageRepo().GetMessageByUid(msg.ID)
+	require.NoError(t, err)
+
+	sign(msg, 1)
+	msg.WalletName = "other"
+	msg.SignerWallet = "backup"
+	assert.NoError(t, r.MessageRepo().SaveMessage(msg))
+	after, err := r.MessageRepo().GetMessageByUid(msg.ID)
+	assert.NoError(t, err)
+	assertSameMessage(t, msg, after)
+	// created_at is not changed by save
+	assert.True(t, before.CreatedAt.Equal(after.CreatedAt))
+
+	msg2 := sign(newMessage(t, from), 2)
+	createMessages(t, r, msg2)
+	msg.State = types.OnChainMsg
+	msg2.State = types.OnChainMsg
+	assert.NoError(t, r.MessageRepo().BatchSaveMessage([]*types.Message{msg, msg2}))
+	for _, m := range []*types.Message{msg, msg2} {
+		state, err := r.MessageRepo().GetMessageState(m.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, types.OnChainMsg, state)
+	}
+
+	msg3 := newMessage(t, from)
+	createMessages(t, r, msg3)
+	assert.NoError(t, r.MessageRepo().ExpireMessage([]*types.Message{msg3}))
+	state, err := r.MessageRepo().GetMessageState(msg3.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, types.FailedMsg, state)
+}
+
+func testMessageList(t *testing.T, r repo.Repo) {
+	from := newAddress(t)
+	msgs := []*types.Message{newMessage(t, from), sign(newMessage(t, from), 0), newMessage(t, newAddress(t))}
+	createMessages(t, r, msgs...)
+
+	all, err := r.MessageRepo().ListMessage()
+	assert.NoError(t, err)
+	assert.Subset(t, ids(all), ids(msgs))
+
+	byAddr, err := r.MessageRepo().ListMessageByAddress(from)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, ids(msgs[:2]), ids(byAddr))
+
+	// signed messages created after the time
+	start := time.Now().Add(-time.Minute)
+	signed, err := r.MessageRepo().GetSignedMessageByTime(start)
+	assert.NoError(t, err)
+	assert.Contains(t, ids(signed), msgs[1].ID)
+	assert.NotContains(t, ids(signed), msgs[0].ID)
+	signed, err = r.MessageRepo().GetSignedMessageByTime(time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+	assert.NotContains(t, ids(signed), msgs[1].ID)
+
+	unchained, err := r.MessageRepo().ListUnchainedMsgs()
+	assert.NoError(t, err)
+	assert.Contains(t, ids(unchained), msgs[0].ID)
+	assert.NotContains(t, ids(unchained), msgs[1].ID)
+
+	signedMsgs, err := r.MessageRepo().ListSignedMsgs()
+	assert.NoError(t, err)
+	assert.Contains(t, ids(signedMsgs), msgs[1].ID)
+	assert.NotContains(t, ids(signedMsgs), msgs[0].ID)
+}
+
+func testMessageListByState(t *testing.T, r repo.Repo) {
+	from := newAddress(t)
+	unfilled := newMessage(t, from)
+	filled := make([]*types.Message, 0, 3)
+	for i := 0; i < 3; i++ {
+		filled = append(filled, sign(newMessage(t, from), uint64(i)))
+	}
+	// blocked for a hour
+	filled[0].CreatedAt = time.Now().Add(-time.Hour)
+	otherWallet := sign(newMessage(t, from), 3)
+	otherWallet.WalletName = "other"
+	createMessages(t, r, unfilled, otherWallet)
+	createMessages(t, r, filled...)
+
+	res, err := r.MessageRepo().ListUnChainMessageByAddress(from)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{unfilled.ID}, ids(res))
+
+	res, err = r.MessageRepo().ListFilledMessageByAddress(from)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, append(ids(filled), otherWallet.ID), ids(res))
+
+	res, err = r.MessageRepo().ListFilledMessageByWallet("wallet", from)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, ids(filled), ids(res))
+
+	res, err = r.MessageRepo().ListFilledMessageBelowNonce(from, 2)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, ids(filled[:2]), ids(res))
+
+	res, err = r.MessageRepo().ListBlockedMessage(from, time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filled[0].ID}, ids(res))
+
+	// waiting for the signature of offline wallet
+	offline := newMessage(t, from)
+	offline.WalletName = "offline-" + from.String()
+	offline.Nonce = 4
+	offline.State = types.OfflineSignMsg
+	createMessages(t, r, offline)
+	res, err = r.MessageRepo().ListOfflineSignMessageByWallet(offline.WalletName)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{offline.ID}, ids(res))
+	res, err = r.MessageRepo().ListUnChainMessageByAddress(from)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{unfilled.ID}, ids(res))
+
+	// failed to estimate gas
+	assert.NoError(t, r.MessageRepo().UpdateReturnValue(unfilled.ID, unfilled.Revision, "gas estimate failed"))
+	res, err = r.MessageRepo().ListFailedMessage()
+	assert.NoError(t, err)
+	assert.Contains(t, ids(res), unfilled.ID)
+	assert.NotContains(t, ids(res), filled[0].ID)
+	msg, err := r.MessageRepo().GetMessageByUid(unfilled.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "gas estimate failed", string(msg.Receipt.ReturnValue))
+}
+
+func testMessageListByHeight(t *testing.T, r repo.Repo) {
+	from := newAddress(t)
+	// use a height no other case uses
+	height := abi.ChainEpoch(rand.Int63n(1<<40) + 1<<20)
+	filled := sign(newMessage(t, from), 0)
+	filled.Height = int64(height)
+	onChain := sign(newMessage(t, from), 1)
+	onChain.Height = int64(height)
+	onChain.State = types.OnChainMsg
+	higher := sign(newMessage(t, from), 2)
+	higher.Height = int64(height) + 1
+	higher.State = types.OnChainMsg
+	createMessages(t, r, filled, onChain, higher)
+
+	res, err := r.MessageRepo().ListFilledMessageByHeight(height)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filled.ID}, ids(res))
+
+	res, err = r.MessageRepo().ListChainMessageByHeight(height)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{onChain.ID}, ids(res))
+
+	res, err = r.MessageRepo().GetSignedMessageByHeight(height)
+	assert.NoError(t, err)
+	assert.Subset(t, ids(res), []string{filled.ID, onChain.ID, higher.ID})
+	res, err = r.MessageRepo().GetSignedMessageByHeight(height + 1)
+	assert.NoError(t, err)
+	assert.Contains(t, ids(res), higher.ID)
+	assert.NotContains(t, ids(res), onChain.ID)
+}
+
+func testMessageUpdate(t *testing.T, r repo.Repo) {
+	from := newAddress(t)
+	msgs := []*types.Message{sign(newMessage(t, from), 0), sign(newMessage(t, from), 1), newMessage(t, from), newMessage(t, from)}
+	msgs[3].WalletName = "other"
+	createMessages(t, r, msgs...)
+
+	tsKey := venustypes.NewTipSetKey(*msgs[0].SignedCid)
+	receipt := &venustypes.MessageReceipt{ExitCode: 1, ReturnValue: []byte("return"), GasUsed: 100}
+	assert.NoError(t, r.MessageRepo().UpdateMessageInfoByCid(msgs[0].UnsignedCid.String(), 0, receipt, 10, types.OnChainMsg, tsKey))
+	msg, err := r.MessageRepo().GetMessageByUid(msgs[0].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, types.OnChainMsg, msg.State)
+	assert.Equal(t, int64(10), msg.Height)
+	assert.Equal(t, receipt, msg.Receipt)
+	assert.Equal(t, tsKey, msg.TipSetKey)
+
+	assert.NoError(t, r.MessageRepo().UpdateMessageStateByCid(msgs[1].UnsignedCid.String(), 0, types.ReplacedMsg))
+	state, err := r.MessageRepo().GetMessageState(msgs[1].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, types.ReplacedMsg, state)
+
+	assert.NoError(t, r.MessageRepo().UpdateMessageStateByID(msgs[1].ID, 1, types.FillMsg))
+	state, err = r.MessageRepo().GetMessageState(msgs[1].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, types.FillMsg, state)
+
+	// only unfilled messages of the wallet are updated
+	assert.NoError(t, r.MessageRepo().UpdateUnFilledMessageState("wallet", from, types.NoWalletMsg))
+	for i, expect := range []types.MessageState{types.OnChainMsg, types.FillMsg, types.NoWalletMsg, types.UnFillMsg} {
+		state, err := r.MessageRepo().GetMessageState(msgs[i].ID)
+		assert.NoError(t, err)
+		assert.Equal(t, expect, state, "message %d", i)
+	}
+
+	// unfilled and no wallet messages of the wallet are moved to the new wallet as unfilled
+	migrated, err := r.MessageRepo().MigrateUnFilledMessage("wallet", "new-wallet", from)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{msgs[2].ID}, migrated)
+	for i, expect := range []types.MessageState{types.OnChainMsg, types.FillMsg, types.UnFillMsg, types.UnFillMsg} {
+		msg, err := r.MessageRepo().GetMessageByUid(msgs[i].ID)
+		assert.NoError(t, err)
+		assert.Equal(t, expect, msg.State, "message %d", i)
+	}
+	msg, err = r.MessageRepo().GetMessageByUid(msgs[2].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "new-wallet", msg.WalletName)
+	migrated, err = r.MessageRepo().MigrateUnFilledMessage("wallet", "new-wallet", from)
+	assert.NoError(t, err)
+	assert.Empty(t, migrated)
+
+	_, err = r.MessageRepo().MarkBadMessage(msgs[3].ID, 0)
+	assert.NoError(t, err)
+	state, err = r.MessageRepo().GetMessageState(msgs[3].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, types.FailedMsg, state)
+}
+
+func testMessageRevision(t *testing.T, r repo.Repo) {
+	from := newAddress(t)
+	msg := newMessage(t, from)
+	createMessages(t, r, msg)
+
+	stale, err := r.MessageRepo().GetMessageByUid(msg.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), stale.Revision)
+
+	sign(msg, 0)
+	assert.NoError(t, r.MessageRepo().SaveMessage(msg))
+	assert.Equal(t, int64(1), msg.Revision)
+
+	// write based on an old revision is rejected and changes nothing
+	stale.WalletName = "stale"
+	err = r.MessageRepo().SaveMessage(stale)
+	assert.True(t, xerrors.Is(err, repo.ErrRevisionConflict))
+	var conflict *repo.RevisionConflictError
+	assert.True(t, xerrors.As(err, &conflict))
+	assert.Equal(t, msg.ID, conflict.ID)
+	assert.Equal(t, int64(0), stale.Revision)
+	assert.True(t, xerrors.Is(r.MessageRepo().BatchSaveMessage([]*types.Message{stale}), repo.ErrRevisionConflict))
+	assert.True(t, xerrors.Is(r.MessageRepo().ExpireMessage([]*types.Message{stale}), repo.ErrRevisionConflict))
+	res, err := r.MessageRepo().GetMessageByUid(msg.ID)
+	assert.NoError(t, err)
+	assertSameMessage(t, msg, res)
+	assert.Equal(t, int64(1), res.Revision)
+
+	// updates of one message are rejected at an old revision
+	assert.True(t, xerrors.Is(r.MessageRepo().UpdateMessageStateByID(msg.ID, 0, types.FailedMsg), repo.ErrRevisionConflict))
+	assert.True(t, xerrors.Is(r.MessageRepo().UpdateMessageStateByCid(msg.UnsignedCid.String(), 0, types.FailedMsg), repo.ErrRevisionConflict))
+	assert.True(t, xerrors.Is(r.MessageRepo().UpdateMessageInfoByCid(msg.UnsignedCid.String(), 0, &venustypes.MessageReceipt{}, 1, types.OnChainMsg, venustypes.EmptyTSK), repo.ErrRevisionConflict))
+	assert.True(t, xerrors.Is(r.MessageRepo().UpdateReturnValue(msg.ID, 0, "return"), repo.ErrRevisionConflict))
+	_, err = r.MessageRepo().MarkBadMessage(msg.ID, 0)
+	assert.True(t, xerrors.Is(err, repo.ErrRevisionConflict))
+	state, err := r.MessageRepo().GetMessageState(msg.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, types.FillMsg, state)
+
+	// every update increase the revision
+	assert.NoError(t, r.MessageRepo().UpdateMessageStateByID(msg.ID, 1, types.FillMsg))
+	assert.NoError(t, r.MessageRepo().UpdateMessageStateByCid(msg.UnsignedCid.String(), 2, types.FillMsg))
+	assert.NoError(t, r.MessageRepo().UpdateMessageInfoByCid(msg.UnsignedCid.String(), 3, &venustypes.MessageReceipt{}, 1, types.OnChainMsg, venustypes.EmptyTSK))
+	assert.NoError(t, r.MessageRepo().UpdateReturnValue(msg.ID, 4, "return"))
+	_, err = r.MessageRepo().MarkBadMessage(msg.ID, 5)
+	assert.NoError(t, err)
+	res, err = r.MessageRepo().GetMessageByUid(msg.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(6), res.Revision)
+	assert.True(t, xerrors.Is(r.MessageRepo().SaveMessage(msg), repo.ErrRevisionConflict))
+
+	assert.NoError(t, r.MessageRepo().ExpireMessage([]*types.Message{res}))
+	assert.Equal(t, int64(7), res.Revision)
+
+	unfilled := newMessage(t, from)
+	createMessages(t, r, unfilled)
+	assert.NoError(t, r.MessageRepo().UpdateUnFilledMessageState("wallet", from, types.NoWalletMsg))
+	res, err = r.MessageRepo().GetMessageByUid(unfilled.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), res.Revision)
+}
+
+func testMessagePageAndDelete(t *testing.T, r repo.Repo) {
+	from := newAddress(t)
+	msgs := []*types.Message{newMessage(t, from), newMessage(t, from), newMessage(t, from)}
+	createMessages(t, r, msgs...)
+
+	var walked []string
+	lastID := ""
+	for {
+		page, err := r.MessageRepo().ListMessageAfterID(lastID, 2)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(page), 2)
+		for _, msg := range page {
+			assert.Greater(t, msg.ID, lastID)
+			lastID = msg.ID
+			walked = append(walked, msg.ID)
+		}
+		if len(page) < 2 {
+			break
+		}
+	}
+	assert.Subset(t, walked, ids(msgs))
+
+	assert.NoError(t, r.MessageRepo().DeleteMessage(ids(msgs[:2])))
+	assert.NoError(t, r.MessageRepo().DeleteMessage(nil))
+	res, err := r.MessageRepo().ListMessageByAddress(from)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{msgs[2].ID}, ids(res))
+}
+
+func testMessageArchive(t *testing.T, r repo.Repo) {
+	from := newAddress(t)
+	onChain := sign(newMessage(t, from), 0)
+	onChain.State = types.OnChainMsg
+	onChain.Height = 10
+	failed := newMessage(t, from)
+	failed.State = types.FailedMsg
+	filled := sign(newMessage(t, from), 1)
+	filled.Height = 10
+	createMessages(t, r, onChain, failed, filled)
+
+	res, err := r.MessageRepo().ListArchivableMessage(time.Now().Add(time.Minute), 0, 1<<20)
+	assert.NoError(t, err)
+	assert.Subset(t, ids(res), []string{onChain.ID, failed.ID})
+	assert.NotContains(t, ids(res), filled.ID)
+	res, err = r.MessageRepo().ListArchivableMessage(time.Now().Add(-time.Minute), 11, 1<<20)
+	assert.NoError(t, err)
+	assert.Contains(t, ids(res), onChain.ID)
+	assert.NotContains(t, ids(res), failed.ID)
+	res, err = r.MessageRepo().ListArchivableMessage(time.Time{}, 0, 1<<20)
+	assert.NoError(t, err)
+	assert.Len(t, res, 0)
+
+	assert.NoError(t, r.ArchiveRepo().ArchiveMessage([]*types.Message{onChain, failed}))
+	has, err := r.ArchiveRepo().HasArchivedMessageByUid(onChain.ID)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	msg, err := r.ArchiveRepo().GetArchivedMessageByUid(onChain.ID)
+	assert.NoError(t, err)
+	assertSameMessage(t, onChain, msg)
+	msg, err = r.ArchiveRepo().GetArchivedMessageByCid(*onChain.UnsignedCid)
+	assert.NoError(t, err)
+	assertSameMessage(t, onChain, msg)
+	msg, err = r.ArchiveRepo().GetArchivedMessageBySignedCid(*onChain.SignedCid)
+	assert.NoError(t, err)
+	assertSameMessage(t, onChain, msg)
+	_, err = r.ArchiveRepo().GetArchivedMessageByUid(filled.ID)
+	assert.True(t, isNotFound(err))
+
+	page, err := r.ArchiveRepo().ListArchivedMessageAfterID("", 1<<20)
+	assert.NoError(t, err)
+	assert.Subset(t, ids(page), []string{onChain.ID, failed.ID})
+}
+
+func newAddressInfo(t *testing.T) *types.Address {
+	return &types.Address{
+		ID:        types.NewUUID(),
+		Addr:      newAddress(t),
+		Nonce:     3,
+		Weight:    1,
+		IsDeleted: repo.NotDeleted,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+func testAddress(t *testing.T, r repo.Repo) {
+	ctx := context.Background()
+	addrRepo := r.AddressRepo()
+	addrInfo := newAddressInfo(t)
+	assert.NoError(t, addrRepo.SaveAddress(ctx, addrInfo))
+
+	has, err := addrRepo.HasAddress(ctx, addrInfo.Addr)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	has, err = addrRepo.HasAddress(ctx, newAddress(t))
+	assert.NoError(t, err)
+	assert.False(t, has)
+
+	res, err := addrRepo.GetAddress(ctx, addrInfo.Addr)
+	assert.NoError(t, err)
+	assert.Equal(t, addrInfo.ID, res.ID)
+	assert.Equal(t, addrInfo.Nonce, res.Nonce)
+	assert.Equal(t, addrInfo.Weight, res.Weight)
+	_, err = addrRepo.GetAddress(ctx, newAddress(t))
+	assert.True(t, isNotFound(err))
+
+	assert.NoError(t, addrRepo.UpdateNonce(ctx, addrInfo.Addr, 10))
+	res, err = addrRepo.GetAddress(ctx, addrInfo.Addr)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), res.Nonce)
+
+	addrInfo.Nonce = 20
+	assert.NoError(t, addrRepo.UpdateAddress(ctx, addrInfo))
+	res, err = addrRepo.GetAddress(ctx, addrInfo.Addr)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(20), res.Nonce)
+
+	list, err := addrRepo.ListAddress(ctx)
+	assert.NoError(t, err)
+	var found bool
+	for _, a := range list {
+		found = found || a.Addr == addrInfo.Addr
+	}
+	assert.True(t, found)
+}
+
+func testAddressSoftDelete(t *testing.T, r repo.Repo) {
+	ctx := context.Background()
+	addrRepo := r.AddressRepo()
+	addrInfo := newAddressInfo(t)
+	assert.NoError(t, addrRepo.SaveAddress(ctx, addrInfo))
+	assert.NoError(t, addrRepo.DelAddress(ctx, addrInfo.Addr))
+
+	_, err := addrRepo.GetAddress(ctx, addrInfo.Addr)
+	assert.True(t, isNotFound(err))
+	// deleted record is still kept
+	res, err := addrRepo.GetOneRecord(ctx, addrInfo.Addr)
+	assert.NoError(t, err)
+	assert.Equal(t, repo.Deleted, res.IsDeleted)
+	has, err := addrRepo.HasAddress(ctx, addrInfo.Addr)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	list, err := addrRepo.ListAddress(ctx)
+	assert.NoError(t, err)
+	for _, a := range list {
+		assert.NotEqual(t, addrInfo.Addr, a.Addr)
+	}
+
+	// restore
+	addrInfo.IsDeleted = repo.NotDeleted
+	assert.NoError(t, addrRepo.UpdateAddress(ctx, addrInfo))
+	_, err = addrRepo.GetAddress(ctx, addrInfo.Addr)
+	assert.NoError(t, err)
+}
+
+func newWallet() *types.Wallet {
+	return &types.Wallet{
+		ID:        types.NewUUID(),
+		Name:      types.NewUUID().String(),
+		Url:       "/ip4/127.0.0.1/tcp/5678",
+		Token:     types.NewUUID().String(),
+		State:     types.Alive,
+		IsDeleted: repo.NotDeleted,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+func testWallet(t *testing.T, r repo.Repo) {
+	walletRepo := r.WalletRepo()
+	wallet := newWallet()
+	assert.NoError(t, walletRepo.SaveWallet(wallet))
+
+	res, err := walletRepo.GetWalletByName(wallet.Name)
+	assert.NoError(t, err)
+	assert.Equal(t, wallet.ID, res.ID)
+	assert.Equal(t, wallet.Url, res.Url)
+	assert.Equal(t, wallet.Token, res.Token)
+	res, err = walletRepo.GetWalletByID(wallet.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, wallet.Name, res.Name)
+	_, err = walletRepo.GetWalletByName(types.NewUUID().String())
+	assert.True(t, isNotFound(err))
+
+	has, err := walletRepo.HasWallet(wallet.Name)
+	assert.NoError(t, err)
+	assert.True(t, has)
+
+	assert.NoError(t, walletRepo.UpdateState(wallet.Name, types.Removing))
+	res, err = walletRepo.GetWalletByName(wallet.Name)
+	assert.NoError(t, err)
+	assert.Equal(t, types.Removing, res.State)
+
+	assert.NoError(t, walletRepo.UpdateSignLimit(wallet.Name, 2, 0.5))
+	res, err = walletRepo.GetWalletByName(wallet.Name)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, res.SignConcurrency)
+	assert.Equal(t, 0.5, res.SignRate)
+
+	list, err := walletRepo.ListWallet()
+	assert.NoError(t, err)
+	var found bool
+	for _, w := range list {
+		found = found || w.Name == wallet.Name
+	}
+	assert.True(t, found)
+}
+
+func testWalletSoftDelete(t *testing.T, r repo.Repo) {
+	walletRepo := r.WalletRepo()
+	wallet := newWallet()
+	assert.NoError(t, walletRepo.SaveWallet(wallet))
+	assert.NoError(t, walletRepo.DelWallet(wallet.Name))
+	assert.True(t, isNotFound(walletRepo.DelWallet(wallet.Name)))
+
+	_, err := walletRepo.GetWalletByName(wallet.Name)
+	assert.True(t, isNotFound(err))
+	_, err = walletRepo.GetWalletByID(wallet.ID)
+	assert.True(t, isNotFound(err))
+	res, err := walletRepo.GetOneRecord(wallet.Name)
+	assert.NoError(t, err)
+	assert.Equal(t, repo.Deleted, res.IsDeleted)
+	assert.Equal(t, types.Removed, res.State)
+	has, err := walletRepo.HasWallet(wallet.Name)
+	assert.NoError(t, err)
+	assert.True(t, has)
+
+	list, err := walletRepo.ListWallet()
+	assert.NoError(t, err)
+	for _, w := range list {
+		assert.NotEqual(t, wallet.Name, w.Name)
+	}
+}
+
+func newWalletAddress() *types.WalletAddress {
+	return &types.WalletAddress{
+		ID:           types.NewUUID(),
+		WalletID:     types.NewUUID(),
+		AddrID:       types.NewUUID(),
+		AddressState: types.Alive,
+		SelMsgNum:    10,
+		IsDeleted:    repo.NotDeleted,
+		CreatedAt:    time.Now(),
+	}
+}
+
+func testWalletAddress(t *testing.T, r repo.Repo) {
+	waRepo := r.WalletAddressRepo()
+	wa := newWalletAddress()
+	wa2 := newWalletAddress()
+	wa2.WalletID = wa.WalletID
+	assert.NoError(t, waRepo.SaveWalletAddress(wa))
+	assert.NoError(t, waRepo.SaveWalletAddress(wa2))
+
+	res, err := waRepo.GetWalletAddress(wa.WalletID, wa.AddrID)
+	assert.NoError(t, err)
+	assert.Equal(t, wa.ID, res.ID)
+	assert.Equal(t, wa.SelMsgNum, res.SelMsgNum)
+	_, err = waRepo.GetWalletAddress(wa.WalletID, types.NewUUID())
+	assert.True(t, isNotFound(err))
+
+	has, err := waRepo.HasWalletAddress(wa.WalletID, wa.AddrID)
+	assert.NoError(t, err)
+	assert.True(t, has)
+
+	list, err := waRepo.GetWalletAddressByWalletID(wa.WalletID)
+	assert.NoError(t, err)
+	assert.Len(t, list, 2)
+
+	assert.NoError(t, waRepo.UpdateAddressState(wa.WalletID, wa.AddrID, types.Forbiden))
+	assert.NoError(t, waRepo.UpdateSelectMsgNum(wa.WalletID, wa.AddrID, 20))
+	res, err = waRepo.GetWalletAddress(wa.WalletID, wa.AddrID)
+	assert.NoError(t, err)
+	assert.Equal(t, types.Forbiden, res.AddressState)
+	assert.Equal(t, uint64(20), res.SelMsgNum)
+
+	all, err := waRepo.ListWalletAddress()
+	assert.NoError(t, err)
+	var count int
+	for _, w := range all {
+		if w.WalletID == wa.WalletID {
+			count++
+		}
+	}
+	assert.Equal(t, 2, count)
+}
+
+func testWalletAddressSoftDelete(t *testing.T, r repo.Repo) {
+	waRepo := r.WalletAddressRepo()
+	wa := newWalletAddress()
+	assert.NoError(t, waRepo.SaveWalletAddress(wa))
+	assert.NoError(t, waRepo.DelWalletAddress(wa.WalletID, wa.AddrID))
+	assert.True(t, isNotFound(waRepo.DelWalletAddress(wa.WalletID, wa.AddrID)))
+
+	_, err := waRepo.GetWalletAddress(wa.WalletID, wa.AddrID)
+	assert.True(t, isNotFound(err))
+	res, err := waRepo.GetOneRecord(wa.WalletID, wa.AddrID)
+	assert.NoError(t, err)
+	assert.Equal(t, repo.Deleted, res.IsDeleted)
+	assert.Equal(t, types.Removed, res.AddressState)
+	has, err := waRepo.HasWalletAddress(wa.WalletID, wa.AddrID)
+	assert.NoError(t, err)
+	assert.True(t, has)
+
+	list, err := waRepo.GetWalletAddressByWalletID(wa.WalletID)
+	assert.NoError(t, err)
+	assert.Len(t, list, 0)
+}
+
+func testNode(t *testing.T, r repo.Repo) {
+	nodeRepo := r.NodeRepo()
+	node := &types.Node{
+		ID:     types.NewUUID(),
+		Name:   types.NewUUID().String(),
+		URL:    "/ip4/127.0.0.1/tcp/3453",
+		Token:  types.NewUUID().String(),
+		Type:   types.LightNode,
+		Weight: 3,
+	}
+	assert.NoError(t, nodeRepo.CreateNode(node))
+
+	res, err := nodeRepo.GetNode(node.Name)
+	assert.NoError(t, err)
+	assert.Equal(t, node, res)
+	has, err := nodeRepo.HasNode(node.Name)
+	assert.NoError(t, err)
+	assert.True(t, has)
+
+	node.Weight = 5
+	assert.NoError(t, nodeRepo.SaveNode(node))
+	res, err = nodeRepo.GetNode(node.Name)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, res.Weight)
+
+	list, err := nodeRepo.ListNode()
+	assert.NoError(t, err)
+	assert.Contains(t, list, node)
+
+	// deleted node is invisible
+	assert.NoError(t, nodeRepo.DelNode(node.Name))
+	assert.True(t, isNotFound(nodeRepo.DelNode(node.Name)))
+	_, err = nodeRepo.GetNode(node.Name)
+	assert.True(t, isNotFound(err))
+	has, err = nodeRepo.HasNode(node.Name)
+	assert.NoError(t, err)
+	assert.False(t, has)
+	list, err = nodeRepo.ListNode()
+	assert.NoError(t, err)
+	assert.NotContains(t, list, node)
+}
+
+func testSharedParams(t *testing.T, r repo.Repo) {
+	ctx := context.Background()
+	params := &types.SharedParams{
+		ID:                 1,
+		ExpireEpoch:        100,
+		GasOverEstimation:  1.25,
+		MaxFee:             7000,
+		MaxFeeCap:          8000,
+		SelMsgNum:          rand.Uint64() % 100,
+		ScanInterval:       10,
+		MaxEstFailNumOfMsg: 5,
+	}
+	id, err := r.SharedParamsRepo().SetSharedParams(ctx, params)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), id)
+	res, err := r.SharedParamsRepo().GetSharedParams(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, params, res)
+
+	params.SelMsgNum++
+	params.GasOverEstimation = 1.5
+	_, err = r.SharedParamsRepo().SetSharedParams(ctx, params)
+	assert.NoError(t, err)
+	res, err = r.SharedParamsRepo().GetSharedParams(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, params, res)
+}
+
+func testAuditLog(t *testing.T, r repo.Repo) {
+	target := uuid.New().String()
+	now := time.Now().Truncate(time.Second)
+	var logs []*types.AuditLog
+	for i, method := range []string{"MarkBadMessage", "UpdateMessageStateByID", "MarkBadMessage"} {
+		log := &types.AuditLog{
+			ID:        types.NewUUID(),
+			Caller:    "admin",
+			Perm:      "admin",
+			IP:        "10.0.0.1",
+			Method:    method,
+			Target:    target,
+			Args:      []byte(`["` + target + `"]`),
+			Before:    []byte(`"FillMsg"`),
+			After:     []byte(`"FailedMsg"`),
+			CreatedAt: now.Add(time.Duration(i) * time.Second),
+		}
+		if i == 2 {
+			log.Error = "message not found"
+			log.After = nil
+		}
+		require.NoError(t, r.AuditRepo().SaveAuditLog(log))
+		logs = append(logs, log)
+	}
+
+	res, err := r.AuditRepo().ListAuditLog(&types.AuditLogFilter{Target: target})
+	assert.NoError(t, err)
+	require.Len(t, res, 3)
+	// the newest first
+	for i, log := range res {
+		expect := logs[2-i]
+		assert.Equal(t, expect.ID, log.ID)
+		assert.Equal(t, expect.Caller, log.Caller)
+		assert.Equal(t, expect.Perm, log.Perm)
+		assert.Equal(t, expect.IP, log.IP)
+		assert.Equal(t, expect.Method, log.Method)
+		assert.JSONEq(t, string(expect.Args), string(log.Args))
+		assert.JSONEq(t, string(expect.Before), string(log.Before))
+		assert.Equal(t, expect.Error, log.Error)
+		assert.Equal(t, expect.CreatedAt.Unix(), log.CreatedAt.Unix())
+	}
+	assert.Len(t, res[0].After, 0)
+
+	res, err = r.AuditRepo().ListAuditLog(&types.AuditLogFilter{Target: target, Method: "MarkBadMessage"})
+	assert.NoError(t, err)
+	assert.Equal(t, []types.UUID{logs[2].ID, logs[0].ID}, []types.UUID{res[0].ID, res[1].ID})
+
+	res, err = r.AuditRepo().ListAuditLog(&types.AuditLogFilter{Target: target, Start: now.Add(time.Second), End: now.Add(2 * time.Second)})
+	assert.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.Equal(t, logs[1].ID, res[0].ID)
+
+	res, err = r.AuditRepo().ListAuditLog(&types.AuditLogFilter{Target: target, Limit: 1})
+	assert.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.Equal(t, logs[2].ID, res[0].ID)
+
+	res, err = r.AuditRepo().ListAuditLog(&types.AuditLogFilter{Target: target, Caller: "other"})
+	assert.NoError(t, err)
+	assert.Len(t, res, 0)
+}
+
+func testParamsVersion(t *testing.T, r repo.Repo) {
+	target := types.SelMsgNumTarget("wallet", newAddress(t))
+	now := time.Now().Truncate(time.Second)
+
+	_, err := r.ParamsVersionRepo().GetLatestParamsVersion(target)
+	assert.True(t, isNotFound(err))
+
+	var versions []*types.ParamsVersion
+	for i := int64(1); i <= 3; i++ {
+		v := &types.ParamsVersion{
+			Target:    target,
+			Version:   i,
+			Value:     []byte(fmt.Sprintf(`{"selMsgNum":%d}`, i*10)),
+			Author:    "admin",
+			Comment:   fmt.Sprintf("change %d", i),
+			CreatedAt: now.Add(time.Duration(i) * time.Second),
+		}
+		require.NoError(t, r.ParamsVersionRepo().CreateParamsVersion(v))
+		versions = append(versions, v)
+	}
+	assert.Error(t, r.ParamsVersionRepo().CreateParamsVersion(versions[1]))
+
+	assertSameVersion := func(expect, actual *types.ParamsVersion) {
+		assert.Equal(t, expect.Target, actual.Target)
+		assert.Equal(t, expect.Version, actual.Version)
+		assert.JSONEq(t, string(expect.Value), string(actual.Value))
+		assert.Equal(t, expect.Author, actual.Author)
+		assert.Equal(t, expect.Comment, actual.Comment)
+		assert.Equal(t, expect.CreatedAt.Unix(), actual.CreatedAt.Unix())
+	}
+
+	res, err := r.ParamsVersionRepo().GetParamsVersion(target, 2)
+	assert.NoError(t, err)
+	assertSameVersion(versions[1], res)
+	_, err = r.ParamsVersionRepo().GetParamsVersion(target, 4)
+	assert.True(t, isNotFound(err))
+	res, err = r.ParamsVersionRepo().GetLatestParamsVersion(target)
+	assert.NoError(t, err)
+	assertSameVersion(versions[2], res)
+
+	list, err := r.ParamsVersionRepo().ListParamsVersion(target, 0)
+	assert.NoError(t, err)
+	require.Len(t, list, 3)
+	for i, v := range list {
+		assertSameVersion(versions[2-i], v)
+	}
+	list, err = r.ParamsVersionRepo().ListParamsVersion(target, 2)
+	assert.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, int64(3), list[0].Version)
+
+	// versions are saved in transaction with shared params
+	otherTarget := types.SelMsgNumTarget("other", newAddress(t))
+	assert.Error(t, r.Transaction(func(txRepo repo.TxRepo) error {
+		require.NoError(t, txRepo.ParamsVersionRepo().CreateParamsVersion(&types.ParamsVersion{
+			Target:    otherTarget,
+			Version:   1,
+			Value:     []byte(`{}`),
+			CreatedAt: now,
+		}))
+		return xerrors.New("rollback")
+	}))
+	list, err = r.ParamsVersionRepo().ListParamsVersion(otherTarget, 0)
+	assert.NoError(t, err)
+	assert.Len(t, list, 0)
+}
+
+func testMultisigProposal(t *testing.T, r repo.Repo) {
+	msig := newAddress(t)
+	now := time.Now().Truncate(time.Second)
+	newProposal := func(i int) *types.MsigProposal {
+		return &types.MsigProposal{
+			ID:         types.NewUUID().String(),
+			Msig:       msig,
+			TxID:       -1,
+			Proposer:   newAddress(t),
+			WalletName: "wallet",
+			To:         newAddress(t),
+			Value:      big.NewInt(int64(100 + i)),
+			Method:     0,
+			Params:     []byte(fmt.Sprintf("params %d", i)),
+			State:      types.MsigProposing,
+			CreatedAt:  now.Add(time.Duration(i) * time.Second),
+			UpdatedAt:  now.Add(time.Duration(i) * time.Second),
+		}
+	}
+	p1, p2 := newProposal(1), newProposal(2)
+	require.NoError(t, r.MultisigRepo().SaveProposal(p1))
+	require.NoError(t, r.MultisigRepo().SaveProposal(p2))
+
+	assertSameProposal := func(expect, actual *types.MsigProposal) {
+		assert.Equal(t, expect.ID, actual.ID)
+		assert.Equal(t, expect.Msig, actual.Msig)
+		assert.Equal(t, expect.TxID, actual.TxID)
+		assert.Equal(t, expect.Proposer, actual.Proposer)
+		assert.Equal(t, expect.WalletName, actual.WalletName)
+		assert.Equal(t, expect.To, actual.To)
+		assert.Equal(t, expect.Value.String(), actual.Value.String())
+		assert.Equal(t, expect.Method, actual.Method)
+		assert.Equal(t, expect.Params, actual.Params)
+		assert.Equal(t, expect.State, actual.State)
+		assert.Equal(t, expect.CancelMsgID, actual.CancelMsgID)
+		assert.Equal(t, expect.Error, actual.Error)
+		require.Len(t, actual.Approvals, len(expect.Approvals))
+		for i := range expect.Approvals {
+			assert.Equal(t, expect.Approvals[i].Signer, actual.Approvals[i].Signer)
+			assert.Equal(t, expect.Approvals[i].MsgID, actual.Approvals[i].MsgID)
+			assert.Equal(t, expect.Approvals[i].State, actual.Approvals[i].State)
+		}
+		assert.Equal(t, expect.CreatedAt.Unix(), actual.CreatedAt.Unix())
+	}
+
+	res, err := r.MultisigRepo().GetProposal(p1.ID)
+	assert.NoError(t, err)
+	assertSameProposal(p1, res)
+	_, err = r.MultisigRepo().GetProposal(types.NewUUID().String())
+	assert.True(t, isNotFound(err))
+
+	p1.TxID = 3
+	p1.State = types.MsigPending
+	p1.Approvals = append(p1.Approvals, &types.MsigApproval{
+		Signer:    newAddress(t),
+		MsgID:     types.NewUUID().String(),
+		State:     types.MsigApprovalConfirmed,
+		CreatedAt: now,
+	})
+	p2.State = types.MsigFailed
+	p2.Error = "propose message failed"
+	require.NoError(t, r.MultisigRepo().SaveProposal(p1))
+	require.NoError(t, r.MultisigRepo().SaveProposal(p2))
+	res, err = r.MultisigRepo().GetProposal(p1.ID)
+	assert.NoError(t, err)
+	assertSameProposal(p1, res)
+
+	list, err := r.MultisigRepo().ListProposal(msig)
+	assert.NoError(t, err)
+	require.Len(t, list, 2)
+	assertSameProposal(p2, list[0])
+	assertSameProposal(p1, list[1])
+	list, err = r.MultisigRepo().ListProposal(address.Undef)
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, len(list), 2)
+
+	list, err = r.MultisigRepo().ListUnfinishedProposal()
+	assert.NoError(t, err)
+	ids := make(map[string]struct{})
+	for _, p := range list {
+		ids[p.ID] = struct{}{}
+	}
+	assert.Contains(t, ids, p1.ID)
+	assert.NotContains(t, ids, p2.ID)
+
+	require.NoError(t, r.MultisigRepo().DeleteProposal(p2.ID))
+	_, err = r.MultisigRepo().GetProposal(p2.ID)
+	assert.True(t, isNotFound(err))
+	res, err = r.MultisigRepo().GetProposal(p1.ID)
+	assert.NoError(t, err)
+	assertSameProposal(p1, res)
+}
+
+func testTransactionCommit(t *testing.T, r repo.Repo) {
+	ctx := context.Background()
+	msg := sign(newMessage(t, newAddress(t)), 0)
+	addrInfo := newAddressInfo(t)
+	wallet := newWallet()
+	wa := newWalletAddress()
+	archived := newMessage(t, newAddress(t))
+	assert.NoError(t, r.Transaction(func(txRepo repo.TxRepo) error {
+		if err := txRepo.MessageRepo().CreateMessage(msg); err != nil {
+			return err
+		}
+		if err := txRepo.AddressRepo().SaveAddress(ctx, addrInfo); err != nil {
+			return err
+		}
+		if err := txRepo.WalletRepo().SaveWallet(wallet); err != nil {
+			return err
+		}
+		if err := txRepo.WalletAddressRepo().SaveWalletAddress(wa); err != nil {
+			return err
+		}
+		return txRepo.ArchiveRepo().ArchiveMessage([]*types.Message{archived})
+	}))
+
+	has, err := r.MessageRepo().HasMessageByUid(msg.ID)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	has, err = r.AddressRepo().HasAddress(ctx, addrInfo.Addr)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	has, err = r.WalletRepo().HasWallet(wallet.Name)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	has, err = r.WalletAddressRepo().HasWalletAddress(wa.WalletID, wa.AddrID)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	has, err = r.ArchiveRepo().HasArchivedMessageByUid(archived.ID)
+	assert.NoError(t, err)
+	assert.True(t, has)
+}
+
+func testTransactionRollback(t *testing.T, r repo.Repo) {
+	ctx := context.Background()
+	existing := newMessage(t, newAddress(t))
+	createMessages(t, r, existing)
+
+	msg := sign(newMessage(t, newAddress(t)), 0)
+	addrInfo := newAddressInfo(t)
+	wallet := newWallet()
+	errRollback := xerrors.New("rollback")
+	err := r.Transaction(func(txRepo repo.TxRepo) error {
+		if err := txRepo.MessageRepo().CreateMessage(msg); err != nil {
+			return err
+		}
+		if err := txRepo.MessageRepo().UpdateMessageStateByID(existing.ID, existing.Revision, types.FailedMsg); err != nil {
+			return err
+		}
+		if err := txRepo.AddressRepo().SaveAddress(ctx, addrInfo); err != nil {
+			return err
+		}
+		if err := txRepo.WalletRepo().SaveWallet(wallet); err != nil {
+			return err
+		}
+		// changes are visible inside the transaction
+		has, err := txRepo.MessageRepo().HasMessageByUid(msg.ID)
+		if err != nil {
+			return err
+		}
+		assert.True(t, has)
+		return errRollback
+	})
+	assert.True(t, xerrors.Is(err, errRollback))
+
+	has, err := r.MessageRepo().HasMessageByUid(msg.ID)
+	assert.NoError(t, err)
+	assert.False(t, has)
+	state, err := r.MessageRepo().GetMessageState(existing.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, types.UnFillMsg, state)
+	has, err = r.AddressRepo().HasAddress(ctx, addrInfo.Addr)
+	assert.NoError(t, err)
+	assert.False(t, has)
+	has, err = r.WalletRepo().HasWallet(wallet.Name)
+	assert.NoError(t, err)
+	assert.False(t, has)
+}
+
+func testMigrator(t *testing.T, r repo.Repo) {
+	migrator, err := r.Migrator()
+	if xerrors.Is(err, repo.ErrMigrationNotSupported) {
+		// repo without schema only need AutoMigrate to be a no-op
+		assert.NoError(t, r.AutoMigrate())
+		return
+	}
+	assert.NoError(t, err)
+	assert.NoError(t, migrator.Check())
+	version, err := migrator.Version()
+	assert.NoError(t, err)
+	assert.Equal(t, migrator.Latest(), version)
+	// AutoMigrate on a migrated database is a no-op
+	assert.NoError(t, r.AutoMigrate())
+}
@@ -0,0 +1,132 @@
+package sqlite
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-messager/models/repo"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+// sqliteArchivedMessage keep the columns used for lookup, the whole message is stored in Data as json
+type sqliteArchivedMessage struct {
+	ID          string             `gorm:"column:id;type:varchar(256);primary_key"`
+	From        string             `gorm:"column:from_addr;type:varchar(256);NOT NULL;index:archive_from_nonce"`
+	Nonce       uint64             `gorm:"column:nonce;type:unsigned bigint;index:archive_from_nonce"`
+	UnsignedCid string             `gorm:"column:unsigned_cid;type:varchar(256);index:archive_unsigned_cid"`
+	SignedCid   string             `gorm:"column:signed_cid;type:varchar(256);index:archive_signed_cid"`
+	Height      int64              `gorm:"column:height;type:bigint"`
+	State       types.MessageState `gorm:"column:state;type:int"`
+	Data        []byte             `gorm:"column:data;type:blob;NOT NULL"`
+
+	CreatedAt  time.Time `gorm:"column:created_at;NOT NULL"`
+	ArchivedAt time.Time `gorm:"column:archived_at;index;NOT NULL"`
+}
+
+func (sqlMsg *sqliteArchivedMessage) TableName() string {
+	return "archived_messages"
+}
+
+func fromArchivedMessage(msg *types.Message) (*sqliteArchivedMessage, error) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	archived := &sqliteArchivedMessage{
+		ID:         msg.ID,
+		From:       msg.From.String(),
+		Nonce:      msg.Nonce,
+		Height:     msg.Height,
+		State:      msg.State,
+		Data:       data,
+		CreatedAt:  msg.CreatedAt,
+		ArchivedAt: time.Now(),
+	}
+	if msg.UnsignedCid != nil {
+		archived.UnsignedCid = msg.UnsignedCid.String()
+	}
+	if msg.SignedCid != nil {
+		archived.SignedCid = msg.SignedCid.String()
+	}
+	return archived, nil
+}
+
+func (sqlMsg *sqliteArchivedMessage) Message() (*types.Message, error) {
+	var msg types.Message
+	if err := json.Unmarshal(sqlMsg.Data, &msg); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+var _ repo.ArchiveRepo = (*sqliteArchiveRepo)(nil)
+
+type sqliteArchiveRepo struct {
+	*gorm.DB
+}
+
+func newSqliteArchiveRepo(db *gorm.DB) *sqliteArchiveRepo {
+	return &sqliteArchiveRepo{DB: db}
+}
+
+func (s *sqliteArchiveRepo) ArchiveMessage(msgs []*types.Message) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	archived := make([]*sqliteArchivedMessage, 0, len(msgs))
+	for _, msg := range msgs {
+		sqlMsg, err := fromArchivedMessage(msg)
+		if err != nil {
+			return err
+		}
+		archived = append(archived, sqlMsg)
+	}
+	return s.DB.Save(archived).Error
+}
+
+func (s *sqliteArchiveRepo) HasArchivedMessageByUid(id string) (bool, error) {
+	var count int64
+	if err := s.DB.Model(&sqliteArchivedMessage{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (s *sqliteArchiveRepo) GetArchivedMessageByUid(id string) (*types.Message, error) {
+	return s.getArchivedMessage("id = ?", id)
+}
+
+func (s *sqliteArchiveRepo) GetArchivedMessageByCid(unsignedCid cid.Cid) (*types.Message, error) {
+	return s.getArchivedMessage("unsigned_cid = ?", unsignedCid.String())
+}
+
+func (s *sqliteArchiveRepo) GetArchivedMessageBySignedCid(signedCid cid.Cid) (*types.Message, error) {
+	return s.getArchivedMessage("signed_cid = ?", signedCid.String())
+}
+
+func (s *sqliteArchiveRepo) ListArchivedMessageAfterID(id string, limit int) ([]*types.Message, error) {
+	var sqlMsgs []*sqliteArchivedMessage
+	if err := s.DB.Where("id > ?", id).Order("id").Limit(limit).Find(&sqlMsgs).Error; err != nil {
+		return nil, err
+	}
+	result := make([]*types.Message, 0, len(sqlMsgs))
+	for _, sqlMsg := range sqlMsgs {
+		msg, err := sqlMsg.Message()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, msg)
+	}
+	return result, nil
+}
+
+func (s *sqliteArchiveRepo) getArchivedMessage(query string, arg interface{}) (*types.Message, error) {
+	var sqlMsg sqliteArchivedMessage
+	if err := s.DB.Where(query, arg).First(&sqlMsg).Error; err != nil {
+		return nil, err
+	}
+	return sqlMsg.Message()
+}
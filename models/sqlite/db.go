@@ -17,6 +17,10 @@ func (d SqlLiteRepo) MessageRepo() repo.MessageRepo {
 	return newSqliteMessageRepo(d.DB)
 }
 
+func (d SqlLiteRepo) MessageQueryRepo() repo.MessageQueryRepo {
+	return newSqliteMessageRepo(d.DB)
+}
+
 func (d SqlLiteRepo) WalletRepo() repo.WalletRepo {
 	return newSqliteWalletRepo(d.DB)
 }
@@ -37,29 +41,32 @@ func (d SqlLiteRepo) WalletAddressRepo() repo.WalletAddressRepo {
 	return newSqliteWalletAddressRepo(d.DB)
 }
 
-func (d SqlLiteRepo) AutoMigrate() error {
-	err := d.GetDb().AutoMigrate(sqliteMessage{})
-	if err != nil {
-		return err
-	}
+func (d SqlLiteRepo) ArchiveRepo() repo.ArchiveRepo {
+	return newSqliteArchiveRepo(d.DB)
+}
 
-	if err := d.GetDb().AutoMigrate(sqliteAddress{}); err != nil {
-		return err
-	}
+func (d SqlLiteRepo) AuditRepo() repo.AuditRepo {
+	return newSqliteAuditRepo(d.DB)
+}
 
-	if err := d.GetDb().AutoMigrate(sqliteSharedParams{}); err != nil {
-		return err
-	}
+func (d SqlLiteRepo) ParamsVersionRepo() repo.ParamsVersionRepo {
+	return newSqliteParamsVersionRepo(d.DB)
+}
 
-	if err := d.GetDb().AutoMigrate(sqliteNode{}); err != nil {
-		return err
-	}
+func (d SqlLiteRepo) MultisigRepo() repo.MultisigRepo {
+	return newSqliteMultisigRepo(d.DB)
+}
+
+func (d SqlLiteRepo) Migrator() (*repo.Migrator, error) {
+	return repo.NewMigrator(d.DB, migrations)
+}
 
-	if err := d.GetDb().AutoMigrate(sqliteWalletAddress{}); err != nil {
+func (d SqlLiteRepo) AutoMigrate() error {
+	migrator, err := d.Migrator()
+	if err != nil {
 		return err
 	}
-
-	return d.GetDb().AutoMigrate(sqliteWallet{})
+	return migrator.Migrate(0)
 }
 
 func (d SqlLiteRepo) GetDb() *gorm.DB {
@@ -83,6 +90,18 @@ func (t *TxSqlliteRepo) WalletAddressRepo() repo.WalletAddressRepo {
 	return newSqliteWalletAddressRepo(t.DB)
 }
 
+func (t *TxSqlliteRepo) ArchiveRepo() repo.ArchiveRepo {
+	return newSqliteArchiveRepo(t.DB)
+}
+
+func (t *TxSqlliteRepo) SharedParamsRepo() repo.SharedParamsRepo {
+	return newSqliteSharedParamsRepo(t.DB)
+}
+
+func (t *TxSqlliteRepo) ParamsVersionRepo() repo.ParamsVersionRepo {
+	return newSqliteParamsVersionRepo(t.DB)
+}
+
 func (t *TxSqlliteRepo) WalletRepo() repo.WalletRepo {
 	return newSqliteWalletRepo(t.DB)
 }
@@ -95,6 +114,10 @@ func (t *TxSqlliteRepo) AddressRepo() repo.AddressRepo {
 	return newSqliteAddressRepo(t.DB)
 }
 
+func (t *TxSqlliteRepo) NodeRepo() repo.NodeRepo {
+	return newSqliteNodeRepo(t.DB)
+}
+
 func (d SqlLiteRepo) DbClose() error {
 	// todo: if '*gorm.DB' need to dispose?
 	return nil
@@ -1,6 +1,7 @@
 package sqlite
 
 import (
+	"strings"
 	"time"
 
 	"github.com/filecoin-project/go-address"
@@ -12,6 +13,7 @@ import (
 	"github.com/filecoin-project/venus-messager/utils"
 	venustypes "github.com/filecoin-project/venus/pkg/types"
 	"github.com/ipfs/go-cid"
+	"golang.org/x/xerrors"
 	"gorm.io/gorm"
 )
 
@@ -44,9 +46,12 @@ type sqliteMessage struct {
 
 	Meta *MsgMeta `gorm:"embedded;embeddedPrefix:meta_"`
 
-	WalletName string `gorm:"column:wallet_name;type:varchar(256)"`
+	WalletName   string `gorm:"column:wallet_name;type:varchar(256)"`
+	SignerWallet string `gorm:"column:signer_wallet;type:varchar(256)"`
 
 	State types.MessageState `gorm:"column:state;type:int;index:msg_state;index:msg_from_state;"`
+	// increased by every update, used to detect concurrent updates
+	Revision int64 `gorm:"column:revision;type:bigint;default:0;NOT NULL"`
 
 	IsDeleted int       `gorm:"column:is_deleted;index;default:-1;NOT NULL"` // 是否删除 1:是  -1:否
 	CreatedAt time.Time `gorm:"column:created_at;index;NOT NULL"`            // 创建时间
@@ -70,14 +75,16 @@ func (sqlMsg *sqliteMessage) Message() *types.Message {
 			Method:     abi.MethodNum(sqlMsg.Method),
 			Params:     sqlMsg.Params,
 		},
-		Height:     sqlMsg.Height,
-		Receipt:    sqlMsg.Receipt.MsgReceipt(),
-		Signature:  (*crypto.Signature)(sqlMsg.Signature),
-		Meta:       sqlMsg.Meta.Meta(),
-		State:      sqlMsg.State,
-		WalletName: sqlMsg.WalletName,
-		UpdatedAt:  sqlMsg.UpdatedAt,
-		CreatedAt:  sqlMsg.CreatedAt,
+		Height:       sqlMsg.Height,
+		Receipt:      sqlMsg.Receipt.MsgReceipt(),
+		Signature:    (*crypto.Signature)(sqlMsg.Signature),
+		Meta:         sqlMsg.Meta.Meta(),
+		State:        sqlMsg.State,
+		Revision:     sqlMsg.Revision,
+		WalletName:   sqlMsg.WalletName,
+		SignerWallet: sqlMsg.SignerWallet,
+		UpdatedAt:    sqlMsg.UpdatedAt,
+		CreatedAt:    sqlMsg.CreatedAt,
 	}
 	destMsg.From, _ = address.NewFromString(sqlMsg.From)
 	destMsg.To, _ = address.NewFromString(sqlMsg.To)
@@ -98,21 +105,25 @@ func (sqlMsg *sqliteMessage) Message() *types.Message {
 
 func FromMessage(srcMsg *types.Message) *sqliteMessage {
 	destMsg := &sqliteMessage{
-		ID:         srcMsg.ID,
-		Version:    srcMsg.Version,
-		To:         srcMsg.To.String(),
-		From:       srcMsg.From.String(),
-		Nonce:      srcMsg.Nonce,
-		GasLimit:   srcMsg.GasLimit,
-		Method:     int(srcMsg.Method),
-		Params:     srcMsg.Params,
-		Signature:  (*repo.SqlSignature)(srcMsg.Signature),
-		Height:     srcMsg.Height,
-		Receipt:    repo.FromMsgReceipt(srcMsg.Receipt),
-		Meta:       FromMeta(srcMsg.Meta),
-		WalletName: srcMsg.WalletName,
-		State:      srcMsg.State,
-		IsDeleted:  repo.NotDeleted,
+		ID:           srcMsg.ID,
+		Version:      srcMsg.Version,
+		To:           srcMsg.To.String(),
+		From:         srcMsg.From.String(),
+		Nonce:        srcMsg.Nonce,
+		GasLimit:     srcMsg.GasLimit,
+		Method:       int(srcMsg.Method),
+		Params:       srcMsg.Params,
+		Signature:    (*repo.SqlSignature)(srcMsg.Signature),
+		Height:       srcMsg.Height,
+		Receipt:      repo.FromMsgReceipt(srcMsg.Receipt),
+		Meta:         FromMeta(srcMsg.Meta),
+		WalletName:   srcMsg.WalletName,
+		SignerWallet: srcMsg.SignerWallet,
+		State:        srcMsg.State,
+		Revision:     srcMsg.Revision,
+		IsDeleted:    repo.NotDeleted,
+		CreatedAt:    srcMsg.CreatedAt,
+		UpdatedAt:    srcMsg.UpdatedAt,
 	}
 
 	if srcMsg.UnsignedCid != nil {
@@ -220,10 +231,16 @@ func (m *sqliteMessageRepo) HasMessageByUid(id string) (bool, error) {
 
 func (m *sqliteMessageRepo) ExpireMessage(msgs []*types.Message) error {
 	for _, msg := range msgs {
-		err := m.DB.Table("messages").Where("id=?", msg.ID).UpdateColumn("state", types.FailedMsg).Error
-		if err != nil {
-			return err
+		result := m.DB.Model(&sqliteMessage{}).Where("id = ? AND revision = ?", msg.ID, msg.Revision).
+			UpdateColumns(map[string]interface{}{"state": types.FailedMsg, "revision": gorm.Expr("revision + 1")})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return &repo.RevisionConflictError{ID: msg.ID, Revision: msg.Revision}
 		}
+		msg.State = types.FailedMsg
+		msg.Revision++
 	}
 	return nil
 }
@@ -254,9 +271,22 @@ func (m *sqliteMessageRepo) ListFilledMessageByWallet(walletName string, addr ad
 	return result, nil
 }
 
+func (m *sqliteMessageRepo) ListOfflineSignMessageByWallet(walletName string) ([]*types.Message, error) {
+	var sqlMsgs []*sqliteMessage
+	err := m.DB.Find(&sqlMsgs, "state=? and wallet_name = ?", types.OfflineSignMsg, walletName).Error
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*types.Message, len(sqlMsgs))
+	for index, sqlMsg := range sqlMsgs {
+		result[index] = sqlMsg.Message()
+	}
+	return result, nil
+}
+
 func (m *sqliteMessageRepo) ListFilledMessageBelowNonce(addr address.Address, nonce uint64) ([]*types.Message, error) {
 	var sqlMsgs []*sqliteMessage
-	err := m.DB.Find(&sqlMsgs, "from_addr=? AND state=? AND nonce <", addr.String(), types.FillMsg, nonce).Error
+	err := m.DB.Find(&sqlMsgs, "from_addr=? AND state=? AND nonce < ?", addr.String(), types.FillMsg, nonce).Error
 	if err != nil {
 		return nil, err
 	}
@@ -280,6 +310,67 @@ func (m *sqliteMessageRepo) ListFilledMessageByHeight(height abi.ChainEpoch) ([]
 	return result, nil
 }
 
+func (m *sqliteMessageRepo) ListChainMessageByHeight(height abi.ChainEpoch) ([]*types.Message, error) {
+	var sqlMsgs []*sqliteMessage
+	err := m.DB.Find(&sqlMsgs, "height=? AND state=?", height, types.OnChainMsg).Error
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*types.Message, len(sqlMsgs))
+	for index, sqlMsg := range sqlMsgs {
+		result[index] = sqlMsg.Message()
+	}
+	return result, nil
+}
+
+func (m *sqliteMessageRepo) ListMessageAfterID(id string, limit int) ([]*types.Message, error) {
+	var sqlMsgs []*sqliteMessage
+	if err := m.DB.Where("id > ?", id).Order("id").Limit(limit).Find(&sqlMsgs).Error; err != nil {
+		return nil, err
+	}
+	result := make([]*types.Message, len(sqlMsgs))
+	for index, sqlMsg := range sqlMsgs {
+		result[index] = sqlMsg.Message()
+	}
+	return result, nil
+}
+
+func (m *sqliteMessageRepo) ListArchivableMessage(before time.Time, belowHeight abi.ChainEpoch, limit int) ([]*types.Message, error) {
+	var conds []string
+	var args []interface{}
+	if !before.IsZero() {
+		conds = append(conds, "updated_at < ?")
+		args = append(args, before)
+	}
+	if belowHeight > 0 {
+		conds = append(conds, "(height > 0 AND height < ?)")
+		args = append(args, belowHeight)
+	}
+	if len(conds) == 0 {
+		return nil, nil
+	}
+
+	var sqlMsgs []*sqliteMessage
+	err := m.DB.Where("state in (?, ?, ?)", types.OnChainMsg, types.FailedMsg, types.ReplacedMsg).
+		Where("("+strings.Join(conds, " OR ")+")", args...).
+		Order("updated_at").Limit(limit).Find(&sqlMsgs).Error
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*types.Message, len(sqlMsgs))
+	for index, sqlMsg := range sqlMsgs {
+		result[index] = sqlMsg.Message()
+	}
+	return result, nil
+}
+
+func (m *sqliteMessageRepo) DeleteMessage(ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return m.DB.Where("id in ?", ids).Delete(&sqliteMessage{}).Error
+}
+
 func (m *sqliteMessageRepo) ListUnChainMessageByAddress(addr address.Address) ([]*types.Message, error) {
 	var sqlMsgs []*sqliteMessage
 	err := m.DB.Find(&sqlMsgs, "from_addr=? AND state=?", addr.String(), types.UnFillMsg).Order("created_at").Error
@@ -306,17 +397,34 @@ func (m *sqliteMessageRepo) BatchSaveMessage(msgs []*types.Message) error {
 
 func (m *sqliteMessageRepo) CreateMessage(msg *types.Message) error {
 	sqlMsg := FromMessage(msg)
-	sqlMsg.CreatedAt = time.Now()
-	sqlMsg.UpdatedAt = time.Now()
+	// keep the time of message imported from other messager
+	if sqlMsg.CreatedAt.IsZero() {
+		sqlMsg.CreatedAt = time.Now()
+	}
+	if sqlMsg.UpdatedAt.IsZero() {
+		sqlMsg.UpdatedAt = time.Now()
+	}
 	return m.DB.Create(sqlMsg).Error
 }
 
-// SaveMessage used to update message and create message with CreateMessage
+// SaveMessage used to update message and create message with CreateMessage,
+// the update fails with RevisionConflictError if the message is changed after msg was read
 func (m *sqliteMessageRepo) SaveMessage(msg *types.Message) error {
 	sqlMsg := FromMessage(msg)
 	sqlMsg.UpdatedAt = time.Now()
+	sqlMsg.Revision = msg.Revision + 1
 
-	return m.DB.Omit("created_at").Save(sqlMsg).Error
+	result := m.DB.Model(&sqliteMessage{}).Where("id = ? AND revision = ?", msg.ID, msg.Revision).
+		Select("*").Omit("id", "created_at").Updates(sqlMsg)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return &repo.RevisionConflictError{ID: msg.ID, Revision: msg.Revision}
+	}
+	msg.Revision = sqlMsg.Revision
+	msg.UpdatedAt = sqlMsg.UpdatedAt
+	return nil
 }
 
 func (m *sqliteMessageRepo) GetMessageByUid(id string) (*types.Message, error) {
@@ -463,6 +571,7 @@ func (m *sqliteMessageRepo) ListSignedMsgs() ([]*types.Message, error) {
 }
 
 func (m *sqliteMessageRepo) UpdateMessageInfoByCid(unsignedCid string,
+	revision int64,
 	receipt *venustypes.MessageReceipt,
 	height abi.ChainEpoch,
 	state types.MessageState,
@@ -476,30 +585,59 @@ func (m *sqliteMessageRepo) UpdateMessageInfoByCid(unsignedCid string,
 		"state":                state,
 		"tipset_key":           tsKey.String(),
 	}
-	return m.DB.Model(&sqliteMessage{}).
-		Where("unsigned_cid = ?", unsignedCid).
-		UpdateColumns(updateClause).Error
+	return m.updateColumns("unsigned_cid", unsignedCid, revision, updateClause)
 }
 
-func (m *sqliteMessageRepo) UpdateMessageStateByCid(cid string, state types.MessageState) error {
-	return m.DB.Model(&sqliteMessage{}).
-		Where("unsigned_cid = ?", cid).UpdateColumn("state", state).Error
+func (m *sqliteMessageRepo) UpdateMessageStateByCid(cid string, revision int64, state types.MessageState) error {
+	return m.updateColumns("unsigned_cid", cid, revision, map[string]interface{}{"state": state})
 }
 
-func (m *sqliteMessageRepo) UpdateMessageStateByID(id string, state types.MessageState) error {
-	return m.DB.Model(&sqliteMessage{}).
-		Where("id = ?", id).UpdateColumn("state", state).Error
+func (m *sqliteMessageRepo) UpdateMessageStateByID(id string, revision int64, state types.MessageState) error {
+	return m.updateColumns("id", id, revision, map[string]interface{}{"state": state})
 }
 
 func (m *sqliteMessageRepo) UpdateUnFilledMessageState(walletName string, addr address.Address, state types.MessageState) error {
 	return m.DB.Model(&sqliteMessage{}).Where("wallet_name = ? and from_addr = ? and state = ?", walletName, addr.String(), types.UnFillMsg).
-		UpdateColumn("state", state).Error
+		UpdateColumns(map[string]interface{}{"state": state, "revision": gorm.Expr("revision + 1")}).Error
 }
 
-func (m *sqliteMessageRepo) MarkBadMessage(id string) (struct{}, error) {
-	return struct{}{}, m.DB.Model(&sqliteMessage{}).Where("id = ?", id).UpdateColumn("state", types.FailedMsg).Error
+// MigrateUnFilledMessage fails with ErrRevisionConflict if the messages are changed between reading the ids and updating them,
+// the update repeats the conditions so messages filled in between are never moved
+func (m *sqliteMessageRepo) MigrateUnFilledMessage(fromWallet, toWallet string, addr address.Address) ([]string, error) {
+	states := []types.MessageState{types.UnFillMsg, types.NoWalletMsg}
+	var ids []string
+	if err := m.DB.Model(&sqliteMessage{}).Where("wallet_name = ? and from_addr = ? and state in ?", fromWallet, addr.String(), states).
+		Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	result := m.DB.Model(&sqliteMessage{}).Where("wallet_name = ? and from_addr = ? and state in ?", fromWallet, addr.String(), states).
+		UpdateColumns(map[string]interface{}{"wallet_name": toWallet, "state": types.UnFillMsg, "revision": gorm.Expr("revision + 1")})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected != int64(len(ids)) {
+		return nil, xerrors.Errorf("unfilled messages of %s changed while migrating: %w", addr, repo.ErrRevisionConflict)
+	}
+	return ids, nil
+}
+
+func (m *sqliteMessageRepo) MarkBadMessage(id string, revision int64) (struct{}, error) {
+	return struct{}{}, m.updateColumns("id", id, revision, map[string]interface{}{"state": types.FailedMsg})
+}
+
+func (m *sqliteMessageRepo) UpdateReturnValue(id string, revision int64, returnVal string) error {
+	return m.updateColumns("id", id, revision, map[string]interface{}{"receipt_return_value": returnVal})
 }
 
-func (m *sqliteMessageRepo) UpdateReturnValue(id string, returnVal string) error {
-	return m.DB.Model(&sqliteMessage{}).Where("id = ?", id).UpdateColumn("receipt_return_value", returnVal).Error
+// updateColumns update the message whose key column equals value if it is still at revision
+func (m *sqliteMessageRepo) updateColumns(key, value string, revision int64, columns map[string]interface{}) error {
+	columns["revision"] = gorm.Expr("revision + 1")
+	result := m.DB.Model(&sqliteMessage{}).Where(key+" = ? AND revision = ?", value, revision).UpdateColumns(columns)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return &repo.RevisionConflictError{ID: value, Revision: revision}
+	}
+	return nil
 }
@@ -0,0 +1,111 @@
+package sqlite
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-messager/models/repo"
+)
+
+// migrations is the schema history of sqlite, only append to it, an applied migration must not be changed
+var migrations = []repo.Migration{
+	{
+		// databases created by AutoMigrate before schema_version existed are adopted by this migration
+		Version:     1,
+		Description: "initial schema",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(v1Tables...)
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(v1Tables...)
+		},
+	},
+	{
+		Version:     2,
+		Description: "archived messages",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&v2ArchivedMessage{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&v2ArchivedMessage{})
+		},
+	},
+	{
+		Version:     3,
+		Description: "message revision",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().AddColumn(&sqliteMessage{}, "Revision")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&sqliteMessage{}, "revision")
+		},
+	},
+	{
+		Version:     4,
+		Description: "audit log",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&v4AuditLog{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&v4AuditLog{})
+		},
+	},
+	{
+		Version:     5,
+		Description: "params version",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&v5ParamsVersion{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&v5ParamsVersion{})
+		},
+	},
+	{
+		Version:     6,
+		Description: "message signer wallet",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().AddColumn(&sqliteMessage{}, "SignerWallet")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&sqliteMessage{}, "signer_wallet")
+		},
+	},
+	{
+		Version:     7,
+		Description: "multisig proposal",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&v7MsigProposal{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&v7MsigProposal{})
+		},
+	},
+	{
+		// sqlite doesn't limit the length of varchar, the migration keeps the versions of backends aligned
+		Version:     8,
+		Description: "token column size",
+		Up: func(tx *gorm.DB) error {
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			return nil
+		},
+	},
+	{
+		Version:     9,
+		Description: "wallet sign limit",
+		Up: func(tx *gorm.DB) error {
+			for _, field := range []string{"SignConcurrency", "SignRate"} {
+				if err := tx.Migrator().AddColumn(&sqliteWallet{}, field); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropColumn(&sqliteWallet{}, "sign_concurrency"); err != nil {
+				return err
+			}
+			return tx.Migrator().DropColumn(&sqliteWallet{}, "sign_rate")
+		},
+	},
+}
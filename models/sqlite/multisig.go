@@ -0,0 +1,159 @@
+package sqlite
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-messager/models/repo"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+type sqliteMsigProposal struct {
+	ID         string    `gorm:"column:id;type:varchar(256);primaryKey"`
+	Msig       string    `gorm:"column:msig;type:varchar(256);NOT NULL;index:msig_proposal_msig"`
+	TxID       int64     `gorm:"column:tx_id;type:bigint"`
+	Proposer   string    `gorm:"column:proposer;type:varchar(256);NOT NULL"`
+	WalletName string    `gorm:"column:wallet_name;type:varchar(256)"`
+	To         string    `gorm:"column:to;type:varchar(256);NOT NULL"`
+	Value      types.Int `gorm:"column:value;type:varchar(256);"`
+	Method     int       `gorm:"column:method;type:int"`
+	Params     []byte    `gorm:"column:params;type:blob;"`
+
+	State types.MsigProposalState `gorm:"column:state;type:int;index:msig_proposal_state"`
+	// json of approvals
+	Approvals   string `gorm:"column:approvals;type:text"`
+	CancelMsgID string `gorm:"column:cancel_msg_id;type:varchar(256)"`
+	Error       string `gorm:"column:error;type:text"`
+
+	CreatedAt time.Time `gorm:"column:created_at;index;NOT NULL"`
+	UpdatedAt time.Time `gorm:"column:updated_at;NOT NULL"`
+}
+
+func (p *sqliteMsigProposal) TableName() string {
+	return "msig_proposals"
+}
+
+func fromSqliteMsigProposal(p *types.MsigProposal) (*sqliteMsigProposal, error) {
+	approvals, err := json.Marshal(p.Approvals)
+	if err != nil {
+		return nil, err
+	}
+	proposal := &sqliteMsigProposal{
+		ID:          p.ID,
+		Msig:        p.Msig.String(),
+		TxID:        p.TxID,
+		Proposer:    p.Proposer.String(),
+		WalletName:  p.WalletName,
+		To:          p.To.String(),
+		Method:      int(p.Method),
+		Params:      p.Params,
+		State:       p.State,
+		Approvals:   string(approvals),
+		CancelMsgID: p.CancelMsgID,
+		Error:       p.Error,
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+	}
+	if p.Value.Int != nil {
+		proposal.Value = types.Int{Int: p.Value.Int}
+	}
+	return proposal, nil
+}
+
+func (p *sqliteMsigProposal) MsigProposal() (*types.MsigProposal, error) {
+	proposal := &types.MsigProposal{
+		ID:          p.ID,
+		TxID:        p.TxID,
+		WalletName:  p.WalletName,
+		Value:       big.Zero(),
+		Method:      abi.MethodNum(p.Method),
+		Params:      p.Params,
+		State:       p.State,
+		CancelMsgID: p.CancelMsgID,
+		Error:       p.Error,
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+	}
+	var err error
+	if proposal.Msig, err = address.NewFromString(p.Msig); err != nil {
+		return nil, err
+	}
+	if proposal.Proposer, err = address.NewFromString(p.Proposer); err != nil {
+		return nil, err
+	}
+	if proposal.To, err = address.NewFromString(p.To); err != nil {
+		return nil, err
+	}
+	if p.Value.Int != nil {
+		proposal.Value = big.NewFromGo(p.Value.Int)
+	}
+	if len(p.Approvals) > 0 {
+		if err := json.Unmarshal([]byte(p.Approvals), &proposal.Approvals); err != nil {
+			return nil, err
+		}
+	}
+	return proposal, nil
+}
+
+var _ repo.MultisigRepo = (*sqliteMultisigRepo)(nil)
+
+type sqliteMultisigRepo struct {
+	*gorm.DB
+}
+
+func newSqliteMultisigRepo(db *gorm.DB) *sqliteMultisigRepo {
+	return &sqliteMultisigRepo{DB: db}
+}
+
+func (s *sqliteMultisigRepo) SaveProposal(p *types.MsigProposal) error {
+	proposal, err := fromSqliteMsigProposal(p)
+	if err != nil {
+		return err
+	}
+	return s.DB.Save(proposal).Error
+}
+
+func (s *sqliteMultisigRepo) GetProposal(id string) (*types.MsigProposal, error) {
+	var p sqliteMsigProposal
+	if err := s.DB.Where("id = ?", id).Take(&p).Error; err != nil {
+		return nil, err
+	}
+	return p.MsigProposal()
+}
+
+func (s *sqliteMultisigRepo) DeleteProposal(id string) error {
+	return s.DB.Where("id = ?", id).Delete(&sqliteMsigProposal{}).Error
+}
+
+func (s *sqliteMultisigRepo) ListProposal(msig address.Address) ([]*types.MsigProposal, error) {
+	query := s.DB.Order("created_at desc")
+	if msig != address.Undef {
+		query = query.Where("msig = ?", msig.String())
+	}
+	return s.find(query)
+}
+
+func (s *sqliteMultisigRepo) ListUnfinishedProposal() ([]*types.MsigProposal, error) {
+	return s.find(s.DB.Where("state in ?", []types.MsigProposalState{types.MsigProposing, types.MsigPending}).Order("created_at"))
+}
+
+func (s *sqliteMultisigRepo) find(query *gorm.DB) ([]*types.MsigProposal, error) {
+	var proposals []*sqliteMsigProposal
+	if err := query.Find(&proposals).Error; err != nil {
+		return nil, err
+	}
+	result := make([]*types.MsigProposal, 0, len(proposals))
+	for _, p := range proposals {
+		proposal, err := p.MsigProposal()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, proposal)
+	}
+	return result, nil
+}
@@ -14,10 +14,11 @@ import (
 type sqliteNode struct {
 	ID types.UUID `gorm:"column:id;type:varchar(256);primary_key;"` // 主键
 
-	Name  string         `gorm:"column:name;type:varchar(256);NOT NULL"`
-	URL   string         `gorm:"column:url;type:varchar(256);NOT NULL"`
-	Token string         `gorm:"column:token;type:varchar(256);NOT NULL"`
-	Type  types.NodeType `gorm:"column:node_type;type:int"`
+	Name   string         `gorm:"column:name;type:varchar(256);NOT NULL"`
+	URL    string         `gorm:"column:url;type:varchar(256);NOT NULL"`
+	Token  string         `gorm:"column:token;type:varchar(1024);NOT NULL"`
+	Type   types.NodeType `gorm:"column:node_type;type:int"`
+	Weight int            `gorm:"column:weight;type:int"`
 
 	IsDeleted int       `gorm:"column:is_deleted;index;default:-1;NOT NULL"` // 是否删除 1:是  -1:否
 	CreatedAt time.Time `gorm:"column:created_at;index;NOT NULL"`            // 创建时间
@@ -55,7 +56,18 @@ func (s sqliteNodeRepo) CreateNode(node *types.Node) error {
 
 func (s sqliteNodeRepo) SaveNode(node *types.Node) error {
 	sNode := FromNode(node)
+	sNode.IsDeleted = repo.NotDeleted
 	sNode.UpdatedAt = time.Now()
+	// keep the creation time of existing node
+	var exist sqliteNode
+	if err := s.DB.Where("id = ?", node.ID).Take(&exist).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return err
+		}
+		sNode.CreatedAt = time.Now()
+	} else {
+		sNode.CreatedAt = exist.CreatedAt
+	}
 	return s.DB.Save(sNode).Error
 }
 
@@ -0,0 +1,92 @@
+package sqlite
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-messager/models/repo"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+type sqliteParamsVersion struct {
+	Target  string `gorm:"column:target;type:varchar(256);primaryKey"`
+	Version int64  `gorm:"column:version;type:bigint;primaryKey;autoIncrement:false"`
+	Value   string `gorm:"column:value;type:text;NOT NULL"`
+	Author  string `gorm:"column:author;type:varchar(256)"`
+	Comment string `gorm:"column:comment;type:text"`
+
+	CreatedAt time.Time `gorm:"column:created_at;NOT NULL"`
+}
+
+func (v *sqliteParamsVersion) TableName() string {
+	return "params_versions"
+}
+
+func fromSqliteParamsVersion(v *types.ParamsVersion) *sqliteParamsVersion {
+	return &sqliteParamsVersion{
+		Target:    v.Target,
+		Version:   v.Version,
+		Value:     string(v.Value),
+		Author:    v.Author,
+		Comment:   v.Comment,
+		CreatedAt: v.CreatedAt,
+	}
+}
+
+func (v *sqliteParamsVersion) ParamsVersion() *types.ParamsVersion {
+	return &types.ParamsVersion{
+		Target:    v.Target,
+		Version:   v.Version,
+		Value:     rawJSON(v.Value),
+		Author:    v.Author,
+		Comment:   v.Comment,
+		CreatedAt: v.CreatedAt,
+	}
+}
+
+var _ repo.ParamsVersionRepo = (*sqliteParamsVersionRepo)(nil)
+
+type sqliteParamsVersionRepo struct {
+	*gorm.DB
+}
+
+func newSqliteParamsVersionRepo(db *gorm.DB) *sqliteParamsVersionRepo {
+	return &sqliteParamsVersionRepo{DB: db}
+}
+
+func (s *sqliteParamsVersionRepo) CreateParamsVersion(v *types.ParamsVersion) error {
+	return s.DB.Create(fromSqliteParamsVersion(v)).Error
+}
+
+func (s *sqliteParamsVersionRepo) GetParamsVersion(target string, version int64) (*types.ParamsVersion, error) {
+	var v sqliteParamsVersion
+	if err := s.DB.Where("target = ? AND version = ?", target, version).Take(&v).Error; err != nil {
+		return nil, err
+	}
+	return v.ParamsVersion(), nil
+}
+
+func (s *sqliteParamsVersionRepo) GetLatestParamsVersion(target string) (*types.ParamsVersion, error) {
+	var v sqliteParamsVersion
+	if err := s.DB.Where("target = ?", target).Order("version desc").Take(&v).Error; err != nil {
+		return nil, err
+	}
+	return v.ParamsVersion(), nil
+}
+
+func (s *sqliteParamsVersionRepo) ListParamsVersion(target string, limit int) ([]*types.ParamsVersion, error) {
+	query := s.DB.Where("target = ?", target).Order("version desc")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	var versions []*sqliteParamsVersion
+	if err := query.Find(&versions).Error; err != nil {
+		return nil, err
+	}
+	result := make([]*types.ParamsVersion, 0, len(versions))
+	for _, v := range versions {
+		result = append(result, v.ParamsVersion())
+	}
+	return result, nil
+}
@@ -0,0 +1,156 @@
+package sqlite
+
+import (
+	"time"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/exitcode"
+
+	"github.com/filecoin-project/venus-messager/models/repo"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+// the tables created by migration 1, they are frozen so that later changes of the models don't change the initial schema
+
+type v1Message struct {
+	ID      string `gorm:"column:id;type:varchar(256);primary_key"`
+	Version uint64 `gorm:"column:version;type:unsigned bigint"`
+
+	From  string `gorm:"column:from_addr;type:varchar(256);NOT NULL;index:msg_from;index:idx_from_nonce;index:msg_from_state;"`
+	Nonce uint64 `gorm:"column:nonce;type:unsigned bigint;index:msg_nonce;index:idx_from_nonce"`
+	To    string `gorm:"column:to;type:varchar(256);NOT NULL"`
+
+	Value types.Int `gorm:"column:value;type:varchar(256);"`
+
+	GasLimit   int64     `gorm:"column:gas_limit;type:bigint"`
+	GasFeeCap  types.Int `gorm:"column:gas_fee_cap;type:varchar(256);"`
+	GasPremium types.Int `gorm:"column:gas_premium;type:varchar(256);"`
+
+	Method int `gorm:"column:method;type:int"`
+
+	Params []byte `gorm:"column:params;type:blob;"`
+
+	Signature *repo.SqlSignature `gorm:"column:signed_data;type:blob;"`
+
+	UnsignedCid string `gorm:"column:unsigned_cid;type:varchar(256);index:msg_unsigned_cid;"`
+	SignedCid   string `gorm:"column:signed_cid;type:varchar(256);index:msg_signed_cid"`
+
+	Height    int64         `gorm:"column:height;type:bigint;index:msg_height"`
+	Receipt   *v1MsgReceipt `gorm:"embedded;embeddedPrefix:receipt_"`
+	TipsetKey string        `gorm:"column:tipset_key;type:varchar(1024);"`
+
+	Meta *v1MsgMeta `gorm:"embedded;embeddedPrefix:meta_"`
+
+	WalletName string `gorm:"column:wallet_name;type:varchar(256)"`
+
+	State types.MessageState `gorm:"column:state;type:int;index:msg_state;index:msg_from_state;"`
+
+	IsDeleted int       `gorm:"column:is_deleted;index;default:-1;NOT NULL"` // 是否删除 1:是  -1:否
+	CreatedAt time.Time `gorm:"column:created_at;index;NOT NULL"`            // 创建时间
+	UpdatedAt time.Time `gorm:"column:updated_at;index;NOT NULL"`            // 更新时间
+}
+
+func (v1Message) TableName() string {
+	return "messages"
+}
+
+type v1Address struct {
+	ID     types.UUID `gorm:"column:id;type:varchar(256);primary_key"`
+	Addr   string     `gorm:"column:addr;type:varchar(256);NOT NULL"` // 主键
+	Nonce  uint64     `gorm:"column:nonce;type:unsigned bigint;index;NOT NULL"`
+	Weight int64      `gorm:"column:weight;type:bigint;index;NOT NULL"`
+
+	IsDeleted int       `gorm:"column:is_deleted;index;default:-1;NOT NULL"` // 是否删除 1:是  -1:否
+	CreatedAt time.Time `gorm:"column:created_at;index;NOT NULL"`            // 创建时间
+	UpdatedAt time.Time `gorm:"column:updated_at;index;NOT NULL"`            // 更新时间
+}
+
+func (v1Address) TableName() string {
+	return "addresses"
+}
+
+type v1SharedParams struct {
+	ID uint `gorm:"primary_key;column:id;type:INT unsigned AUTO_INCREMENT;NOT NULL" json:"id"`
+
+	ExpireEpoch       abi.ChainEpoch `gorm:"column:expire_epoch;type:INT;NOT NULL"`
+	GasOverEstimation float64        `gorm:"column:gas_over_estimation;type:REAL;NOT NULL"`
+	MaxFee            int64          `gorm:"column:max_fee;type:UNSIGNED BIG INT;NOT NULL"`
+	MaxFeeCap         int64          `gorm:"column:max_fee_cap;type:UNSIGNED BIG INT;NOT NULL"`
+
+	SelMsgNum uint64 `gorm:"column:sel_msg_num;type:UNSIGNED BIG INT;NOT NULL"`
+
+	ScanInterval int `gorm:"column:scan_interval;NOT NULL"`
+
+	MaxEstFailNumOfMsg uint64 `gorm:"column:max_ext_fail_num_of_msg;type:UNSIGNED BIG INT;NOT NULL"`
+}
+
+func (v1SharedParams) TableName() string {
+	return "shared_params"
+}
+
+type v1Node struct {
+	ID types.UUID `gorm:"column:id;type:varchar(256);primary_key;"` // 主键
+
+	Name   string         `gorm:"column:name;type:varchar(256);NOT NULL"`
+	URL    string         `gorm:"column:url;type:varchar(256);NOT NULL"`
+	Token  string         `gorm:"column:token;type:varchar(256);NOT NULL"`
+	Type   types.NodeType `gorm:"column:node_type;type:int"`
+	Weight int            `gorm:"column:weight;type:int"`
+
+	IsDeleted int       `gorm:"column:is_deleted;index;default:-1;NOT NULL"` // 是否删除 1:是  -1:否
+	CreatedAt time.Time `gorm:"column:created_at;index;NOT NULL"`            // 创建时间
+	UpdatedAt time.Time `gorm:"column:updated_at;index;NOT NULL"`            // 更新时间
+}
+
+func (v1Node) TableName() string {
+	return "nodes"
+}
+
+type v1WalletAddress struct {
+	ID           types.UUID  `gorm:"column:id;type:varchar(256);primary_key"`
+	WalletID     types.UUID  `gorm:"column:wallet_id;type:varchar(256);NOT NULL"`
+	AddrID       types.UUID  `gorm:"column:addr_id;type:varchar(256);NOT NULL"`
+	AddressState types.State `gorm:"column:addr_state;type:int;index:wallet_addr_state;"`
+	SelMsgNum    uint64      `gorm:"column:sel_msg_num;type:unsigned bigint;NOT NULL"`
+
+	IsDeleted int       `gorm:"column:is_deleted;index;default:-1;NOT NULL"` // 是否删除 1:是  -1:否
+	CreatedAt time.Time `gorm:"column:created_at;index;NOT NULL"`            // 创建时间
+	UpdatedAt time.Time `gorm:"column:updated_at;index;NOT NULL"`            // 更新时间
+}
+
+func (v1WalletAddress) TableName() string {
+	return "wallet_addresses"
+}
+
+type v1Wallet struct {
+	ID types.UUID `gorm:"column:id;type:varchar(256);primary_key;"` // 主键
+
+	Name  string      `gorm:"column:name;type:varchar(256);NOT NULL"`
+	Url   string      `gorm:"column:url;type:varchar(256);NOT NULL"`
+	Token string      `gorm:"column:token;type:varchar(256);NOT NULL"`
+	State types.State `gorm:"column:state;type:int;"`
+
+	IsDeleted int       `gorm:"column:is_deleted;index;default:-1;NOT NULL"` // 是否删除 1:是  -1:否
+	CreatedAt time.Time `gorm:"column:created_at;index;NOT NULL"`            // 创建时间
+	UpdatedAt time.Time `gorm:"column:updated_at;index;NOT NULL"`            // 更新时间
+}
+
+func (v1Wallet) TableName() string {
+	return "wallets"
+}
+
+type v1MsgMeta struct {
+	ExpireEpoch       abi.ChainEpoch `gorm:"column:expire_epoch;type:bigint;"`
+	GasOverEstimation float64        `gorm:"column:gas_over_estimation;type:decimal(10,2);"`
+	MaxFee            types.Int      `gorm:"column:max_fee;type:varchar(256);"`
+	MaxFeeCap         types.Int      `gorm:"column:max_fee_cap;type:varchar(256);"`
+}
+
+type v1MsgReceipt struct {
+	ExitCode    exitcode.ExitCode `gorm:"column:exit_code;default:-1"`
+	ReturnValue []byte            `gorm:"column:return_value;type:blob;"`
+	GasUsed     int64             `gorm:"column:gas_used;type:bigint;"`
+}
+
+// v1Tables is the schema of version 1, the structs must not be changed, change the schema by new migrations
+var v1Tables = []interface{}{&v1Message{}, &v1Address{}, &v1SharedParams{}, &v1Node{}, &v1WalletAddress{}, &v1Wallet{}}
@@ -0,0 +1,21 @@
+package sqlite
+
+import (
+	"time"
+)
+
+// the table created by migration 5, it is frozen so that later changes of the model don't change the schema
+
+type v5ParamsVersion struct {
+	Target  string `gorm:"column:target;type:varchar(256);primaryKey"`
+	Version int64  `gorm:"column:version;type:bigint;primaryKey;autoIncrement:false"`
+	Value   string `gorm:"column:value;type:text;NOT NULL"`
+	Author  string `gorm:"column:author;type:varchar(256)"`
+	Comment string `gorm:"column:comment;type:text"`
+
+	CreatedAt time.Time `gorm:"column:created_at;NOT NULL"`
+}
+
+func (v5ParamsVersion) TableName() string {
+	return "params_versions"
+}
@@ -1,7 +1,6 @@
 package sqlite
 
 import (
-	"fmt"
 	"reflect"
 	"time"
 
@@ -58,7 +57,6 @@ func (s sqliteWalletAddressRepo) GetWalletAddress(walletID, addrID types.UUID) (
 		First(&wa).Error; err != nil {
 		return nil, err
 	}
-	fmt.Println(wa)
 	return wa.WalletAddress(), nil
 }
 
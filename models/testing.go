@@ -3,6 +3,7 @@ package models
 import (
 	"encoding/json"
 	"math/rand"
+	"os"
 	"testing"
 	"time"
 
@@ -16,6 +17,8 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/filecoin-project/venus-messager/config"
+	"github.com/filecoin-project/venus-messager/models/mysql"
+	"github.com/filecoin-project/venus-messager/models/postgres"
 	"github.com/filecoin-project/venus-messager/models/repo"
 	"github.com/filecoin-project/venus-messager/models/sqlite"
 	"github.com/filecoin-project/venus-messager/types"
@@ -99,3 +102,39 @@ func setupRepo(t *testing.T) (repo.Repo, repo.Repo) {
 	//assert.NoError(t, mysqlRepo.AutoMigrate())
 	return sqliteRepo, nil
 }
+
+// setupMysqlRepo connect to the mysql specified by MESSAGER_TEST_MYSQL_DSN, the test is skipped if it is not set
+func setupMysqlRepo(t *testing.T) repo.Repo {
+	dsn := os.Getenv("MESSAGER_TEST_MYSQL_DSN")
+	if len(dsn) == 0 {
+		t.Skip("MESSAGER_TEST_MYSQL_DSN not set")
+	}
+	mysqlRepo, err := mysql.OpenMysql(&config.MySqlConfig{
+		ConnectionString: dsn,
+		MaxOpenConn:      1,
+		MaxIdleConn:      1,
+		ConnMaxLifeTime:  time.Second * 10,
+		Debug:            true,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, mysqlRepo.AutoMigrate())
+	return mysqlRepo
+}
+
+// setupPostgresRepo connect to the postgres specified by MESSAGER_TEST_POSTGRES_DSN, the test is skipped if it is not set
+func setupPostgresRepo(t *testing.T) repo.Repo {
+	dsn := os.Getenv("MESSAGER_TEST_POSTGRES_DSN")
+	if len(dsn) == 0 {
+		t.Skip("MESSAGER_TEST_POSTGRES_DSN not set")
+	}
+	postgresRepo, err := postgres.OpenPostgres(&config.PostgresConfig{
+		ConnectionString: dsn,
+		MaxOpenConn:      1,
+		MaxIdleConn:      1,
+		ConnMaxLifeTime:  time.Second * 10,
+		Debug:            true,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, postgresRepo.AutoMigrate())
+	return postgresRepo
+}
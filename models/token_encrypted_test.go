@@ -0,0 +1,129 @@
+package models
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus-messager/config"
+	"github.com/filecoin-project/venus-messager/models/repo"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+func TestTokenEncryptedRepo(t *testing.T) {
+	oldKey, err := config.GenerateTokenKey()
+	require.NoError(t, err)
+	dbCfg := config.DbConfig{
+		Type:            "sqlite",
+		Sqlite:          config.SqliteConfig{Path: filepath.Join(t.TempDir(), "message.db")},
+		TokenEncryption: config.TokenEncryptionConfig{Key: oldKey},
+	}
+	r, err := SetDataBase(&dbCfg)
+	require.NoError(t, err)
+	require.NoError(t, r.AutoMigrate())
+
+	wallet := &types.Wallet{ID: types.NewUUID(), Name: "wallet", Url: "/ip4/127.0.0.1/tcp/5678/http", Token: "wallet-token", IsDeleted: repo.NotDeleted}
+	require.NoError(t, r.Transaction(func(txRepo repo.TxRepo) error {
+		return txRepo.WalletRepo().SaveWallet(wallet)
+	}))
+	node := &types.Node{ID: types.NewUUID(), Name: "node", URL: "/ip4/127.0.0.1/tcp/1234/http", Token: "node-token", Type: types.FullNode}
+	require.NoError(t, r.NodeRepo().CreateNode(node))
+	// offline wallet has no token
+	offline := &types.Wallet{ID: types.NewUUID(), Name: "offline", Url: "offline:t01000", IsDeleted: repo.NotDeleted}
+	require.NoError(t, r.WalletRepo().SaveWallet(offline))
+	assert.Equal(t, "wallet-token", wallet.Token)
+
+	rawToken := func(table string, id types.UUID) string {
+		var token string
+		require.NoError(t, r.GetDb().Table(table).Select("token").Where("id = ?", id).Scan(&token).Error)
+		return token
+	}
+	assert.True(t, repo.IsEncryptedToken(rawToken("wallets", wallet.ID)))
+	assert.True(t, repo.IsEncryptedToken(rawToken("nodes", node.ID)))
+	assert.Empty(t, rawToken("wallets", offline.ID))
+
+	w, err := r.WalletRepo().GetWalletByName("wallet")
+	require.NoError(t, err)
+	assert.Equal(t, "wallet-token", w.Token)
+	n, err := r.NodeRepo().GetNode("node")
+	require.NoError(t, err)
+	assert.Equal(t, "node-token", n.Token)
+
+	// token saved in plaintext before encryption was enabled
+	require.NoError(t, r.GetDb().Table("nodes").Where("id = ?", node.ID).UpdateColumn("token", "legacy-token").Error)
+	nodes, err := r.NodeRepo().ListNode()
+	require.NoError(t, err)
+	require.Len(t, nodes, 1)
+	assert.Equal(t, "legacy-token", nodes[0].Token)
+
+	newTokenCipher := func(hexKey string) *repo.TokenCipher {
+		key, err := hex.DecodeString(hexKey)
+		require.NoError(t, err)
+		c, err := repo.NewTokenCipher(key)
+		require.NoError(t, err)
+		return c
+	}
+	newKey, err := config.GenerateTokenKey()
+	require.NoError(t, err)
+	_, err = repo.RotateTokenKey(r.GetDb(), nil, newTokenCipher(newKey))
+	assert.Error(t, err)
+	count, err := repo.RotateTokenKey(r.GetDb(), newTokenCipher(oldKey), newTokenCipher(newKey))
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.True(t, repo.IsEncryptedToken(rawToken("nodes", node.ID)))
+
+	// the old key can't decrypt the rotated tokens
+	_, err = r.WalletRepo().ListWallet()
+	assert.Error(t, err)
+	dbCfg.TokenEncryption.Key = newKey
+	rotated, err := SetDataBase(&dbCfg)
+	require.NoError(t, err)
+	wallets, err := rotated.WalletRepo().ListWallet()
+	require.NoError(t, err)
+	assert.Len(t, wallets, 2)
+	n, err = rotated.NodeRepo().GetNode("node")
+	require.NoError(t, err)
+	assert.Equal(t, "legacy-token", n.Token)
+}
+
+func TestTokenKeyFile(t *testing.T) {
+	dir := t.TempDir()
+	dbCfg := config.DbConfig{
+		Type:   "sqlite",
+		Sqlite: config.SqliteConfig{Path: filepath.Join(dir, "message.db")},
+	}
+	r, err := SetDataBase(&dbCfg)
+	require.NoError(t, err)
+	require.NoError(t, r.AutoMigrate())
+	node := &types.Node{ID: types.NewUUID(), Name: "node", URL: "/ip4/127.0.0.1/tcp/1234/http", Token: "node-token", Type: types.FullNode}
+	require.NoError(t, r.NodeRepo().CreateNode(node))
+	rawToken := func() string {
+		var token string
+		require.NoError(t, r.GetDb().Table("nodes").Select("token").Where("id = ?", node.ID).Scan(&token).Error)
+		return token
+	}
+	assert.Equal(t, "node-token", rawToken())
+
+	// key file is generated and the plaintext tokens are encrypted when messager starts
+	dbCfg.TokenEncryption = config.TokenEncryptionConfig{KeyFile: "token.key"}
+	dbCfg.TokenEncryption.ResolveKeyFile(dir)
+	assert.Equal(t, filepath.Join(dir, "token.key"), dbCfg.TokenEncryption.KeyFile)
+	encrypted, err := SetDataBase(&dbCfg)
+	require.NoError(t, err)
+	assert.FileExists(t, dbCfg.TokenEncryption.KeyFile)
+	require.NoError(t, encrypted.AutoMigrate())
+	assert.True(t, repo.IsEncryptedToken(rawToken()))
+	n, err := encrypted.NodeRepo().GetNode("node")
+	require.NoError(t, err)
+	assert.Equal(t, "node-token", n.Token)
+
+	// a new key can't decrypt the tokens, so it is not generated when the key file is lost
+	require.NoError(t, os.Remove(dbCfg.TokenEncryption.KeyFile))
+	_, err = SetDataBase(&dbCfg)
+	assert.Error(t, err)
+	assert.NoFileExists(t, dbCfg.TokenEncryption.KeyFile)
+}
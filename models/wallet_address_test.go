@@ -74,5 +74,8 @@ func TestWalletAddress(t *testing.T) {
 			t.SkipNow()
 			walletAddrRepoTest(t, mysqlRepo.WalletAddressRepo())
 		})
+		t.Run("postgres", func(t *testing.T) {
+			walletAddrRepoTest(t, setupPostgresRepo(t).WalletAddressRepo())
+		})
 	})
 }
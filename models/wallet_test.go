@@ -75,6 +75,9 @@ func TestWallet(t *testing.T) {
 			t.SkipNow()
 			walletRepoTest(t, mysqlRepo.WalletRepo())
 		})
+		t.Run("postgres", func(t *testing.T) {
+			walletRepoTest(t, setupPostgresRepo(t).WalletRepo())
+		})
 	})
 
 }
@@ -114,6 +117,9 @@ func TestSqliteWalletRepo_HasWallet(t *testing.T) {
 			t.SkipNow()
 			walletRepoTest(t, mysqlRepo.WalletRepo())
 		})
+		t.Run("postgres", func(t *testing.T) {
+			walletRepoTest(t, setupPostgresRepo(t).WalletRepo())
+		})
 	})
 }
 
@@ -150,6 +156,9 @@ func TestSqliteWalletRepo_GetWalletByID(t *testing.T) {
 			t.SkipNow()
 			walletRepoTest(t, mysqlRepo.WalletRepo())
 		})
+		t.Run("postgres", func(t *testing.T) {
+			walletRepoTest(t, setupPostgresRepo(t).WalletRepo())
+		})
 	})
 }
 
@@ -186,5 +195,8 @@ func TestSqliteWalletRepo_GetWalletByName(t *testing.T) {
 			t.SkipNow()
 			walletRepoTest(t, mysqlRepo.WalletRepo())
 		})
+		t.Run("postgres", func(t *testing.T) {
+			walletRepoTest(t, setupPostgresRepo(t).WalletRepo())
+		})
 	})
 }
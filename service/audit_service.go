@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/filecoin-project/venus-messager/models/repo"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+// AuditService record the administrative operations called through api
+type AuditService struct {
+	repo repo.Repo
+	log  *logrus.Logger
+}
+
+func NewAuditService(repo repo.Repo, logger *logrus.Logger) *AuditService {
+	return &AuditService{repo: repo, log: logger}
+}
+
+// Record save the call of method made by the caller in ctx, args, before and after are saved as json,
+// failure of saving is only logged so that the call is not affected
+func (as *AuditService) Record(ctx context.Context, method, target string, args, before, after interface{}, callErr error) {
+	caller := types.CallerFromContext(ctx)
+	log := &types.AuditLog{
+		ID:        types.NewUUID(),
+		Caller:    caller.Name,
+		Perm:      caller.Perm,
+		IP:        caller.IP,
+		Method:    method,
+		Target:    target,
+		Args:      as.marshal(args),
+		Before:    as.marshal(before),
+		After:     as.marshal(after),
+		CreatedAt: time.Now(),
+	}
+	if callErr != nil {
+		log.Error = callErr.Error()
+	}
+	if err := as.repo.AuditRepo().SaveAuditLog(log); err != nil {
+		as.log.Errorf("save audit log of %s %s called by %s failed %v", method, target, caller.Name, err)
+	}
+}
+
+func (as *AuditService) marshal(v interface{}) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		as.log.Warnf("marshal audit value failed %v", err)
+		return nil
+	}
+	return data
+}
+
+func (as *AuditService) ListAuditLog(ctx context.Context, filter *types.AuditLogFilter) ([]*types.AuditLog, error) {
+	return as.repo.AuditRepo().ListAuditLog(filter)
+}
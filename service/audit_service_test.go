@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+func TestAuditRecord(t *testing.T) {
+	ms, _, _, _ := setupMockMessageService(t)
+	as := NewAuditService(ms.repo, ms.log)
+
+	ctx := context.WithValue(context.Background(), types.CallerKey{}, &types.Caller{Name: "admin", Perm: "admin", IP: "10.0.0.1"})
+	as.Record(ctx, "MarkBadMessage", "id-1", []interface{}{"id-1"}, "FillMsg", "FailedMsg", nil)
+	// call without caller is recorded too
+	as.Record(context.Background(), "UpdateNonce", "t01000", []interface{}{"t01000", 10}, nil, nil, xerrors.New("address not found"))
+
+	logs, err := as.ListAuditLog(ctx, &types.AuditLogFilter{Method: "MarkBadMessage"})
+	assert.NoError(t, err)
+	assert.Len(t, logs, 1)
+	assert.Equal(t, "admin", logs[0].Caller)
+	assert.Equal(t, "10.0.0.1", logs[0].IP)
+	assert.Equal(t, "id-1", logs[0].Target)
+	assert.JSONEq(t, `["id-1"]`, string(logs[0].Args))
+	assert.JSONEq(t, `"FillMsg"`, string(logs[0].Before))
+	assert.JSONEq(t, `"FailedMsg"`, string(logs[0].After))
+	assert.Empty(t, logs[0].Error)
+
+	logs, err = as.ListAuditLog(ctx, &types.AuditLogFilter{Target: "t01000"})
+	assert.NoError(t, err)
+	assert.Len(t, logs, 1)
+	assert.Empty(t, logs[0].Caller)
+	assert.JSONEq(t, `["t01000",10]`, string(logs[0].Args))
+	assert.Nil(t, logs[0].Before)
+	assert.Equal(t, "address not found", logs[0].Error)
+}
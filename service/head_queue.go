@@ -0,0 +1,124 @@
+package service
+
+import (
+	"sync"
+
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+// headQueue buffer the head changes waiting to be processed, when the queue is full the new head change is
+// coalesced into the last one instead of blocking the caller
+type headQueue struct {
+	lk sync.Mutex
+
+	size   int
+	queue  []*headChan
+	notify chan struct{}
+
+	coalesced       uint64
+	latestHeight    abi.ChainEpoch
+	processedHeight abi.ChainEpoch
+}
+
+func newHeadQueue(size int) *headQueue {
+	if size <= 0 {
+		size = MaxHeadChangeProcess
+	}
+	return &headQueue{
+		size:   size,
+		notify: make(chan struct{}, 1),
+	}
+}
+
+func (q *headQueue) push(h *headChan) {
+	q.lk.Lock()
+	if len(h.apply) > 0 {
+		q.latestHeight = h.apply[0].Height()
+	}
+	if len(q.queue) >= q.size {
+		last := len(q.queue) - 1
+		q.queue[last] = mergeHeadChan(q.queue[last], h)
+		q.coalesced++
+	} else {
+		q.queue = append(q.queue, h)
+	}
+	q.lk.Unlock()
+
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (q *headQueue) pop() (*headChan, bool) {
+	q.lk.Lock()
+	defer q.lk.Unlock()
+	if len(q.queue) == 0 {
+		return nil, false
+	}
+	h := q.queue[0]
+	q.queue = q.queue[1:]
+	return h, true
+}
+
+func (q *headQueue) done(h *headChan) {
+	q.lk.Lock()
+	defer q.lk.Unlock()
+	if len(h.apply) > 0 {
+		q.processedHeight = h.apply[0].Height()
+	}
+}
+
+func (q *headQueue) len() int {
+	q.lk.Lock()
+	defer q.lk.Unlock()
+	return len(q.queue)
+}
+
+func (q *headQueue) state() *types.HeadQueueState {
+	q.lk.Lock()
+	defer q.lk.Unlock()
+	lag := q.latestHeight - q.processedHeight
+	if lag < 0 {
+		lag = 0
+	}
+	return &types.HeadQueueState{
+		QueueLen:        len(q.queue),
+		QueueSize:       q.size,
+		Coalesced:       q.coalesced,
+		LatestHeight:    q.latestHeight,
+		ProcessedHeight: q.processedHeight,
+		QueueLag:        lag,
+	}
+}
+
+// mergeHeadChan merge two consecutive head changes into one, tipsets applied by older and reverted by newer are dropped,
+// apply is kept sorted from high to low
+func mergeHeadChan(older, newer *headChan) *headChan {
+	newerRevert := make(map[string]struct{}, len(newer.revert))
+	for _, ts := range newer.revert {
+		newerRevert[ts.Key().String()] = struct{}{}
+	}
+
+	merged := &headChan{}
+	merged.apply = append(merged.apply, newer.apply...)
+	olderApply := make(map[string]struct{}, len(older.apply))
+	for _, ts := range older.apply {
+		key := ts.Key().String()
+		olderApply[key] = struct{}{}
+		if _, ok := newerRevert[key]; !ok {
+			merged.apply = append(merged.apply, ts)
+		}
+	}
+
+	merged.revert = append(merged.revert, older.revert...)
+	for _, ts := range newer.revert {
+		if _, ok := olderApply[ts.Key().String()]; !ok {
+			merged.revert = append(merged.revert, ts)
+		}
+	}
+
+	return merged
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	venusTypes "github.com/filecoin-project/venus/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeadQueueCoalesce(t *testing.T) {
+	ctx := context.Background()
+	node, err := NewMockFullNode()
+	assert.NoError(t, err)
+
+	var tipsets []*venusTypes.TipSet
+	for i := 0; i < 4; i++ {
+		ts, err := node.MineEmpty(ctx)
+		assert.NoError(t, err)
+		tipsets = append(tipsets, ts)
+	}
+
+	q := newHeadQueue(2)
+	for _, ts := range tipsets {
+		q.push(&headChan{apply: []*venusTypes.TipSet{ts}})
+	}
+	state := q.state()
+	assert.Equal(t, 2, state.QueueLen)
+	assert.Equal(t, uint64(2), state.Coalesced)
+	assert.Equal(t, tipsets[3].Height(), state.LatestHeight)
+
+	h, ok := q.pop()
+	assert.True(t, ok)
+	assert.Len(t, h.apply, 1)
+	q.done(h)
+	assert.Equal(t, tipsets[3].Height()-tipsets[0].Height(), q.state().QueueLag)
+
+	// the last three head changes are merged, sorted from high to low
+	h, ok = q.pop()
+	assert.True(t, ok)
+	assert.Len(t, h.apply, 3)
+	for i, ts := range h.apply {
+		assert.Equal(t, tipsets[3-i].Key(), ts.Key())
+	}
+	q.done(h)
+	assert.Equal(t, 0, int(q.state().QueueLag))
+
+	_, ok = q.pop()
+	assert.False(t, ok)
+}
+
+func TestMergeHeadChanWithRevert(t *testing.T) {
+	ctx := context.Background()
+	node, err := NewMockFullNode()
+	assert.NoError(t, err)
+
+	ts1, err := node.MineEmpty(ctx)
+	assert.NoError(t, err)
+	ts2, err := node.MineEmpty(ctx)
+	assert.NoError(t, err)
+	revert, apply, err := node.Reorg(ctx, 1)
+	assert.NoError(t, err)
+
+	older := &headChan{apply: []*venusTypes.TipSet{ts2, ts1}}
+	newer := &headChan{apply: apply, revert: revert}
+	merged := mergeHeadChan(older, newer)
+
+	// ts2 is applied and then reverted before processing, so it disappears from both side
+	assert.Len(t, merged.revert, 0)
+	assert.Len(t, merged.apply, len(apply)+1)
+	assert.Equal(t, ts1.Key(), merged.apply[len(merged.apply)-1].Key())
+	for _, ts := range merged.apply {
+		assert.NotEqual(t, ts2.Key().String(), ts.Key().String())
+	}
+}
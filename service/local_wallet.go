@@ -0,0 +1,300 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/venus-wallet/core"
+	"github.com/filecoin-project/venus-wallet/crypto/aes"
+	"github.com/filecoin-project/venus/pkg/crypto"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus-messager/config"
+)
+
+// LocalWalletScheme is the url prefix of local wallet, url `keystore:<dir>` registers the keystore directory under
+// the root dir of config as a wallet, the passphrase is read from the config of messager rather than passed as token
+const LocalWalletScheme = "keystore:"
+
+// KeystorePassphraseEnv hold the passphrase of keystore if the passphrase file is not set
+const KeystorePassphraseEnv = "MESSAGER_KEYSTORE_PASSPHRASE"
+
+var ErrKeyNotFound = xerrors.New("key not found in keystore")
+
+var _ IWalletClient = (*LocalWallet)(nil)
+
+// LocalWallet keep keys in a keystore directory, each key is a file named by its address without network prefix and
+// encrypted by the passphrase, so the keystore is shared by messagers of mainnet and testnet.
+// Keys are listed from the directory every time, so keys imported by cli while the wallet is used are found by the next scan
+type LocalWallet struct {
+	dir        string
+	passphrase []byte
+	// scrypt parameters of new keys
+	scryptN, scryptP int
+
+	lk sync.Mutex
+	// decrypted keys
+	keys map[address.Address]*crypto.KeyInfo
+}
+
+func IsLocalWalletUrl(url string) bool {
+	return strings.HasPrefix(url, LocalWalletScheme)
+}
+
+// ReadKeystorePassphrase read the passphrase from file, or KeystorePassphraseEnv if file is empty
+func ReadKeystorePassphrase(file string) (string, error) {
+	passphrase := os.Getenv(KeystorePassphraseEnv)
+	if len(file) > 0 {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		passphrase = strings.TrimRight(string(data), "\r\n")
+	}
+	if len(passphrase) == 0 {
+		return "", xerrors.Errorf("passphrase of keystore is not set by file or env %s", KeystorePassphraseEnv)
+	}
+	return passphrase, nil
+}
+
+// openConfiguredLocalWallet open the keystore directory of url by the passphrase in config,
+// the directory is relative to the root dir of config and can't be out of it
+func openConfiguredLocalWallet(cfg *config.KeystoreConfig, url string) (*LocalWallet, error) {
+	if len(cfg.RootDir) == 0 {
+		return nil, xerrors.New("local wallet is disabled as the keystore root dir is not set")
+	}
+	root, err := filepath.Abs(cfg.RootDir)
+	if err != nil {
+		return nil, err
+	}
+	dir := strings.TrimPrefix(url, LocalWalletScheme)
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(root, dir)
+	}
+	rel, err := filepath.Rel(root, filepath.Clean(dir))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, xerrors.Errorf("keystore %s is out of the root dir %s", dir, root)
+	}
+	passphrase, err := ReadKeystorePassphrase(cfg.PassphraseFile)
+	if err != nil {
+		return nil, err
+	}
+	return OpenLocalWallet(dir, passphrase)
+}
+
+// OpenLocalWallet open the keystore directory, it is created if not exist,
+// every key in it must be decrypted by the passphrase
+func OpenLocalWallet(dir, passphrase string) (*LocalWallet, error) {
+	if len(passphrase) == 0 {
+		return nil, xerrors.New("passphrase of keystore cannot be empty")
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	if err := renameLegacyKeyFiles(dir); err != nil {
+		return nil, err
+	}
+	wallet := &LocalWallet{
+		dir:        dir,
+		passphrase: []byte(passphrase),
+		scryptN:    aes.StandardScryptN,
+		scryptP:    aes.StandardScryptP,
+		keys:       make(map[address.Address]*crypto.KeyInfo),
+	}
+
+	addrs, err := wallet.WalletList(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	for _, addr := range addrs {
+		if _, err := wallet.getKey(addr); err != nil {
+			return nil, xerrors.Errorf("load key %s %w", addr, err)
+		}
+	}
+	return wallet, nil
+}
+
+func (localWallet *LocalWallet) WalletList(ctx context.Context) ([]address.Address, error) {
+	files, err := ioutil.ReadDir(localWallet.dir)
+	if err != nil {
+		return nil, err
+	}
+	addrs := make([]address.Address, 0, len(files))
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		addr, err := address.NewFromString(address.MainnetPrefix + file.Name())
+		if err != nil {
+			continue
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs, nil
+}
+
+func (localWallet *LocalWallet) WalletHas(ctx context.Context, addr address.Address) (bool, error) {
+	_, err := os.Stat(localWallet.keyPath(addr))
+	if err == nil {
+		return true, nil
+	}
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+func (localWallet *LocalWallet) WalletSign(ctx context.Context, addr address.Address, data []byte, meta core.MsgMeta) (*crypto.Signature, error) {
+	ki, err := localWallet.getKey(addr)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Sign(data, ki.PrivateKey, ki.SigType)
+}
+
+// WalletNew generate a key of sigType and save it
+func (localWallet *LocalWallet) WalletNew(ctx context.Context, sigType crypto.SigType) (address.Address, error) {
+	var ki crypto.KeyInfo
+	var err error
+	switch sigType {
+	case crypto.SigTypeSecp256k1:
+		ki, err = crypto.NewSecpKeyFromSeed(rand.Reader)
+	case crypto.SigTypeBLS:
+		ki, err = crypto.NewBLSKeyFromSeed(rand.Reader)
+	default:
+		return address.Undef, xerrors.Errorf("unsupported key type %d", sigType)
+	}
+	if err != nil {
+		return address.Undef, err
+	}
+	return localWallet.WalletImport(ctx, &ki)
+}
+
+// WalletImport save the key, fails if the key exists
+func (localWallet *LocalWallet) WalletImport(ctx context.Context, ki *crypto.KeyInfo) (address.Address, error) {
+	addr, err := ki.Address()
+	if err != nil {
+		return address.Undef, err
+	}
+	keyType := core.SignType2Key(ki.SigType)
+	if keyType == core.KTUnknown {
+		return address.Undef, xerrors.Errorf("unsupported key type %d", ki.SigType)
+	}
+	cryptoJSON, err := aes.EncryptData(localWallet.passphrase, ki.PrivateKey, localWallet.scryptN, localWallet.scryptP)
+	if err != nil {
+		return address.Undef, err
+	}
+	data, err := json.Marshal(&aes.EncryptedKey{
+		Address: addr.String(),
+		KeyType: keyType,
+		Crypto:  cryptoJSON,
+	})
+	if err != nil {
+		return address.Undef, err
+	}
+
+	localWallet.lk.Lock()
+	defer localWallet.lk.Unlock()
+	file, err := os.OpenFile(localWallet.keyPath(addr), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		if os.IsExist(err) {
+			return address.Undef, xerrors.Errorf("key %s already exists", addr)
+		}
+		return address.Undef, err
+	}
+	if _, err := file.Write(data); err != nil {
+		_ = file.Close()
+		return address.Undef, err
+	}
+	if err := file.Close(); err != nil {
+		return address.Undef, err
+	}
+	localWallet.keys[addr] = &crypto.KeyInfo{PrivateKey: ki.PrivateKey, SigType: ki.SigType}
+
+	return addr, nil
+}
+
+// WalletExport return the decrypted key of addr
+func (localWallet *LocalWallet) WalletExport(ctx context.Context, addr address.Address) (*crypto.KeyInfo, error) {
+	ki, err := localWallet.getKey(addr)
+	if err != nil {
+		return nil, err
+	}
+	return &crypto.KeyInfo{PrivateKey: ki.PrivateKey, SigType: ki.SigType}, nil
+}
+
+func (localWallet *LocalWallet) keyPath(addr address.Address) string {
+	return filepath.Join(localWallet.dir, keyName(addr))
+}
+
+// keyName is the address without network prefix
+func keyName(addr address.Address) string {
+	return addr.String()[1:]
+}
+
+// renameLegacyKeyFiles rename the key files named by address with network prefix
+func renameLegacyKeyFiles(dir string) error {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		addr, err := address.NewFromString(file.Name())
+		if err != nil {
+			continue
+		}
+		if err := os.Rename(filepath.Join(dir, file.Name()), filepath.Join(dir, keyName(addr))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getKey return the cached key or decrypt the key file
+func (localWallet *LocalWallet) getKey(addr address.Address) (*crypto.KeyInfo, error) {
+	localWallet.lk.Lock()
+	defer localWallet.lk.Unlock()
+	if ki, ok := localWallet.keys[addr]; ok {
+		return ki, nil
+	}
+
+	data, err := ioutil.ReadFile(localWallet.keyPath(addr))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, xerrors.Errorf("%s %w", addr, ErrKeyNotFound)
+		}
+		return nil, err
+	}
+	var encryptedKey aes.EncryptedKey
+	if err := json.Unmarshal(data, &encryptedKey); err != nil {
+		return nil, err
+	}
+	if encryptedKey.Crypto == nil {
+		return nil, xerrors.Errorf("key file of %s has no crypto", addr)
+	}
+	privateKey, err := aes.Decrypt(encryptedKey.Crypto, localWallet.passphrase)
+	if err != nil {
+		return nil, err
+	}
+	ki := &crypto.KeyInfo{PrivateKey: privateKey, SigType: core.KeyType2Sign(encryptedKey.KeyType)}
+	keyAddr, err := ki.Address()
+	if err != nil {
+		return nil, err
+	}
+	if keyAddr != addr {
+		return nil, xerrors.Errorf("key file of %s holds the key of %s", addr, keyAddr)
+	}
+	localWallet.keys[addr] = ki
+
+	return ki, nil
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/venus-wallet/core"
+	"github.com/filecoin-project/venus/pkg/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus-messager/config"
+	"github.com/filecoin-project/venus-messager/models/repo"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+func openTestLocalWallet(t *testing.T, dir, passphrase string) *LocalWallet {
+	wallet, err := OpenLocalWallet(dir, passphrase)
+	require.NoError(t, err)
+	// keep the test fast
+	wallet.scryptN = 1 << 12
+	return wallet
+}
+
+func TestLocalWallet(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "keystore")
+	wallet := openTestLocalWallet(t, dir, "passphrase")
+
+	secpAddr, err := wallet.WalletNew(ctx, crypto.SigTypeSecp256k1)
+	require.NoError(t, err)
+	assert.Equal(t, address.SECP256K1, secpAddr.Protocol())
+	blsAddr, err := wallet.WalletNew(ctx, crypto.SigTypeBLS)
+	require.NoError(t, err)
+	assert.Equal(t, address.BLS, blsAddr.Protocol())
+
+	addrs, err := wallet.WalletList(ctx)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []address.Address{secpAddr, blsAddr}, addrs)
+	has, err := wallet.WalletHas(ctx, secpAddr)
+	assert.NoError(t, err)
+	assert.True(t, has)
+
+	data := []byte("message cid")
+	sig, err := wallet.WalletSign(ctx, secpAddr, data, core.MsgMeta{})
+	require.NoError(t, err)
+	assert.NoError(t, crypto.ValidateSignature(data, secpAddr, *sig))
+	sig, err = wallet.WalletSign(ctx, blsAddr, data, core.MsgMeta{})
+	require.NoError(t, err)
+	assert.Equal(t, crypto.SigTypeBLS, sig.Type)
+
+	// keys are encrypted at rest
+	ki, err := wallet.WalletExport(ctx, secpAddr)
+	require.NoError(t, err)
+	info, err := os.Stat(filepath.Join(dir, keyName(secpAddr)))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	content, err := ioutil.ReadFile(filepath.Join(dir, keyName(secpAddr)))
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), string(ki.PrivateKey))
+
+	_, err = wallet.WalletImport(ctx, ki)
+	assert.Error(t, err)
+	_, err = OpenLocalWallet(dir, "wrong passphrase")
+	assert.Error(t, err)
+
+	// import into another keystore
+	other := openTestLocalWallet(t, filepath.Join(t.TempDir(), "other"), "other passphrase")
+	addr, err := other.WalletImport(ctx, ki)
+	assert.NoError(t, err)
+	assert.Equal(t, secpAddr, addr)
+	_, err = other.WalletSign(ctx, blsAddr, data, core.MsgMeta{})
+	assert.True(t, xerrors.Is(err, ErrKeyNotFound))
+
+	reopened := openTestLocalWallet(t, dir, "passphrase")
+	exported, err := reopened.WalletExport(ctx, secpAddr)
+	assert.NoError(t, err)
+	assert.True(t, ki.Equals(exported))
+
+	// key files named by address with network prefix are renamed
+	require.NoError(t, os.Rename(filepath.Join(dir, keyName(blsAddr)), filepath.Join(dir, blsAddr.String())))
+	reopened = openTestLocalWallet(t, dir, "passphrase")
+	addrs, err = reopened.WalletList(ctx)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []address.Address{secpAddr, blsAddr}, addrs)
+	_, err = os.Stat(filepath.Join(dir, keyName(blsAddr)))
+	assert.NoError(t, err)
+}
+
+func TestRegisterLocalWallet(t *testing.T) {
+	ctx := context.Background()
+	ms, _, _, _ := setupMockMessageService(t)
+	root := t.TempDir()
+	passphraseFile := filepath.Join(t.TempDir(), "passphrase")
+	require.NoError(t, ioutil.WriteFile(passphraseFile, []byte("passphrase\n"), 0600))
+	dir := filepath.Join(root, "keystore")
+	wallet := openTestLocalWallet(t, dir, "passphrase")
+	addr, err := wallet.WalletNew(ctx, crypto.SigTypeSecp256k1)
+	require.NoError(t, err)
+
+	newWallet := func(name, url, token string) *types.Wallet {
+		return &types.Wallet{
+			ID:        types.NewUUID(),
+			Name:      name,
+			Url:       url,
+			Token:     token,
+			State:     types.Alive,
+			IsDeleted: repo.NotDeleted,
+			CreatedAt: time.Now(),
+		}
+	}
+
+	// local wallet is disabled without root dir
+	_, err = ms.walletService.SaveWallet(ctx, newWallet("local", LocalWalletScheme+"keystore", ""))
+	assert.Error(t, err)
+
+	ms.walletService.cfg.Keystore = config.KeystoreConfig{RootDir: root, PassphraseFile: passphraseFile}
+	// passphrase is not accepted as token
+	_, err = ms.walletService.SaveWallet(ctx, newWallet("local", LocalWalletScheme+"keystore", "passphrase"))
+	assert.Error(t, err)
+	// directory out of root dir is not created
+	outside := filepath.Join(t.TempDir(), "outside")
+	for _, url := range []string{LocalWalletScheme + outside, LocalWalletScheme + "../outside", LocalWalletScheme + ".."} {
+		_, err = ms.walletService.SaveWallet(ctx, newWallet("outside", url, ""))
+		assert.Error(t, err, url)
+	}
+	_, err = os.Stat(outside)
+	assert.True(t, os.IsNotExist(err))
+
+	_, err = ms.walletService.SaveWallet(ctx, newWallet("local", LocalWalletScheme+"keystore", ""))
+	require.NoError(t, err)
+
+	addrs, err := ms.walletService.ListRemoteWalletAddress(ctx, "local")
+	assert.NoError(t, err)
+	assert.Equal(t, []address.Address{addr}, addrs)
+	info, ok := ms.walletService.getWalletInfo("local")
+	require.True(t, ok)
+	assert.NoError(t, ms.walletService.ProcessWallet(ctx, "local", info.walletCli))
+	addrInfo, ok := ms.walletService.GetAddressInfo("local", addr)
+	require.True(t, ok)
+	sig, err := addrInfo.WalletClient.WalletSign(ctx, addr, []byte("data"), core.MsgMeta{})
+	require.NoError(t, err)
+	assert.NoError(t, crypto.ValidateSignature([]byte("data"), addr, *sig))
+
+	// absolute path under root dir is accepted
+	_, err = ms.walletService.SaveWallet(ctx, newWallet("abs", LocalWalletScheme+dir, ""))
+	assert.NoError(t, err)
+
+	require.NoError(t, ioutil.WriteFile(passphraseFile, []byte("wrong passphrase"), 0600))
+	_, err = ms.walletService.SaveWallet(ctx, newWallet("wrong", LocalWalletScheme+"keystore", ""))
+	assert.Error(t, err)
+}
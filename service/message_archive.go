@@ -0,0 +1,308 @@
+package service
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"golang.org/x/xerrors"
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-messager/models/repo"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+const (
+	archiveTargetTable = "table"
+	archiveTargetFile  = "file"
+
+	defaultArchiveBatchSize = 1000
+	archiveFileSuffix       = ".jsonl.gz"
+	archiveTmpSuffix        = ".tmp"
+)
+
+// ArchiveMessage move finished messages older than the retention policy to the archive table or compressed jsonl files,
+// they can still be looked up by id and cid
+func (ms *MessageService) ArchiveMessage(ctx context.Context) (*types.ArchiveResult, error) {
+	ms.archiveLk.Lock()
+	defer ms.archiveLk.Unlock()
+
+	cfg := ms.cfg.Archive
+	if cfg.Target != archiveTargetTable && cfg.Target != archiveTargetFile {
+		return nil, xerrors.Errorf("unsupport archive target %s, (%s, %s)", cfg.Target, archiveTargetTable, archiveTargetFile)
+	}
+	if cfg.RetentionDays <= 0 && cfg.RetentionEpochs <= 0 {
+		return nil, xerrors.New("neither retention days nor retention epochs is set")
+	}
+	batchSize := cfg.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultArchiveBatchSize
+	}
+
+	var before time.Time
+	if cfg.RetentionDays > 0 {
+		before = time.Now().Add(-time.Hour * 24 * time.Duration(cfg.RetentionDays))
+	}
+	var belowHeight abi.ChainEpoch
+	if cfg.RetentionEpochs > 0 {
+		head, err := ms.nodeClient.ChainHead(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if head.Height() > abi.ChainEpoch(cfg.RetentionEpochs) {
+			belowHeight = head.Height() - abi.ChainEpoch(cfg.RetentionEpochs)
+		}
+	}
+
+	result := &types.ArchiveResult{Target: cfg.Target}
+	if cfg.Target == archiveTargetFile {
+		if err := ms.recoverArchiveFiles(cfg.FileDir); err != nil {
+			return result, err
+		}
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		msgs, err := ms.repo.MessageRepo().ListArchivableMessage(before, belowHeight, batchSize)
+		if err != nil {
+			return result, err
+		}
+		if len(msgs) == 0 {
+			break
+		}
+		ids := make([]string, 0, len(msgs))
+		for _, msg := range msgs {
+			ids = append(ids, msg.ID)
+		}
+
+		if cfg.Target == archiveTargetTable {
+			err = ms.repo.Transaction(func(txRepo repo.TxRepo) error {
+				if err := txRepo.ArchiveRepo().ArchiveMessage(msgs); err != nil {
+					return err
+				}
+				return txRepo.MessageRepo().DeleteMessage(ids)
+			})
+		} else {
+			// the batch is written to a temp file which is renamed only after the messages are deleted,
+			// so messages failed to delete are not left in the archive files and archived again later
+			var tmpPath string
+			if tmpPath, err = writeArchiveFile(cfg.FileDir, msgs); err == nil {
+				if err = ms.repo.MessageRepo().DeleteMessage(ids); err != nil {
+					if rmErr := os.Remove(tmpPath); rmErr != nil {
+						ms.log.Errorf("remove archive file %s %v", tmpPath, rmErr)
+					}
+				} else {
+					path := strings.TrimSuffix(tmpPath, archiveTmpSuffix)
+					if err = os.Rename(tmpPath, path); err == nil {
+						result.Files = append(result.Files, path)
+					}
+				}
+			}
+		}
+		if err != nil {
+			return result, xerrors.Errorf("archive messages %w", err)
+		}
+
+		for _, id := range ids {
+			ms.messageState.DeleteMessage(id)
+		}
+		result.Archived += len(msgs)
+		if len(msgs) < batchSize {
+			break
+		}
+	}
+	ms.log.Infof("archive %d messages to %s", result.Archived, cfg.Target)
+
+	return result, nil
+}
+
+func (ms *MessageService) StartArchive(ctx context.Context) {
+	tm := time.NewTicker(time.Duration(ms.cfg.Archive.Interval) * time.Second)
+	defer tm.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			ms.log.Warnf("stop archive message: %v", ctx.Err())
+			return
+		case <-tm.C:
+			if _, err := ms.ArchiveMessage(ctx); err != nil {
+				ms.log.Errorf("archive message %v", err)
+			}
+		}
+	}
+}
+
+// getArchivedMessage look up the archive table first and then the archive files,
+// gorm.ErrRecordNotFound is returned when the message is not found
+func (ms *MessageService) getArchivedMessage(fromTable func(repo.ArchiveRepo) (*types.Message, error), match func(*types.Message) bool) (*types.Message, error) {
+	msg, err := fromTable(ms.repo.ArchiveRepo())
+	if err == nil || !xerrors.Is(err, gorm.ErrRecordNotFound) {
+		return msg, err
+	}
+	if len(ms.cfg.Archive.FileDir) == 0 {
+		return nil, err
+	}
+
+	msg, findErr := findArchivedMessageInFiles(ms.cfg.Archive.FileDir, match)
+	if findErr != nil {
+		return nil, findErr
+	}
+	if msg == nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// writeArchiveFile write messages to a new compressed jsonl file with temp suffix, it is ignored by the look up
+func writeArchiveFile(dir string, msgs []*types.Message) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("archive-%s%s%s", time.Now().Format("20060102-150405.000000000"), archiveFileSuffix, archiveTmpSuffix))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return "", err
+	}
+	err = func() error {
+		gw := gzip.NewWriter(file)
+		enc := json.NewEncoder(gw)
+		for _, msg := range msgs {
+			if err := enc.Encode(msg); err != nil {
+				return err
+			}
+		}
+		if err := gw.Close(); err != nil {
+			return err
+		}
+		return file.Sync()
+	}()
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return "", err
+	}
+	return path, nil
+}
+
+// recoverArchiveFiles finish the temp files left by the archive interrupted before renaming them, a temp file is
+// renamed if its messages were deleted, otherwise it is removed and the messages will be archived again
+func (ms *MessageService) recoverArchiveFiles(dir string) error {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, info := range infos {
+		if info.IsDir() || !strings.HasSuffix(info.Name(), archiveFileSuffix+archiveTmpSuffix) {
+			continue
+		}
+		tmpPath := filepath.Join(dir, info.Name())
+		// messages of a file are deleted together, the first one tells whether they are deleted
+		var first *types.Message
+		if _, err := findArchivedMessageInFile(tmpPath, func(msg *types.Message) bool {
+			first = msg
+			return true
+		}); err != nil {
+			return xerrors.Errorf("read archive file %s %w", info.Name(), err)
+		}
+		deleted := false
+		if first != nil {
+			inDB, err := ms.repo.MessageRepo().HasMessageByUid(first.ID)
+			if err != nil {
+				return err
+			}
+			deleted = !inDB
+		}
+		if deleted {
+			ms.log.Warnf("rename archive file %s left by interrupted archive", info.Name())
+			err = os.Rename(tmpPath, strings.TrimSuffix(tmpPath, archiveTmpSuffix))
+		} else {
+			ms.log.Warnf("remove archive file %s left by interrupted archive", info.Name())
+			err = os.Remove(tmpPath)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findArchivedMessageInFiles scan the archive files from the newest one, nil is returned if no message matches
+func findArchivedMessageInFiles(dir string, match func(*types.Message) bool) (*types.Message, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var names []string
+	for _, info := range infos {
+		if !info.IsDir() && strings.HasSuffix(info.Name(), archiveFileSuffix) {
+			names = append(names, info.Name())
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+
+	for _, name := range names {
+		msg, err := findArchivedMessageInFile(filepath.Join(dir, name), match)
+		if err != nil {
+			return nil, xerrors.Errorf("read archive file %s %w", name, err)
+		}
+		if msg != nil {
+			return msg, nil
+		}
+	}
+	return nil, nil
+}
+
+func findArchivedMessageInFile(path string, match func(*types.Message) bool) (*types.Message, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close() // nolint
+
+	gr, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer gr.Close() // nolint
+
+	reader := bufio.NewReader(gr)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 && line[len(line)-1] == '\n' {
+			var msg types.Message
+			if err := json.Unmarshal(line, &msg); err != nil {
+				return nil, err
+			}
+			if match(&msg) {
+				return &msg, nil
+			}
+		}
+		if err != nil {
+			// a file not closed cleanly ends without gzip trailer, the flushed lines are still valid
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return nil, nil
+			}
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-messager/models"
+	"github.com/filecoin-project/venus-messager/models/repo"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+func TestArchiveMessage(t *testing.T) {
+	for _, target := range []string{archiveTargetTable, archiveTargetFile} {
+		t.Run(target, func(t *testing.T) {
+			ctx := context.Background()
+			ms, node, _, _ := setupMockMessageService(t)
+			ms.cfg.Archive.Target = target
+			ms.cfg.Archive.FileDir = t.TempDir()
+			ms.cfg.Archive.RetentionEpochs = 2
+			ms.cfg.Archive.BatchSize = 2
+
+			msgs := models.NewSignedMessages(4)
+			for i, msg := range msgs {
+				msg.State = types.OnChainMsg
+				msg.Height = int64(i + 1)
+				assert.NoError(t, ms.repo.MessageRepo().CreateMessage(msg))
+			}
+			for i := 0; i < 5; i++ {
+				_, err := node.MineEmpty(ctx)
+				assert.NoError(t, err)
+			}
+
+			// head is 5, messages below height 3 are archived
+			result, err := ms.ArchiveMessage(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, 2, result.Archived)
+			assert.Equal(t, target == archiveTargetFile, len(result.Files) > 0)
+
+			for i, msg := range msgs {
+				archived := i < 2
+				inDB, err := ms.repo.MessageRepo().HasMessageByUid(msg.ID)
+				assert.NoError(t, err)
+				assert.Equal(t, !archived, inDB)
+
+				found, err := ms.GetMessageByUid(ctx, msg.ID)
+				assert.NoError(t, err)
+				assert.Equal(t, msg.Height, found.Height)
+				found, err = ms.GetMessageByCid(ctx, *msg.UnsignedCid)
+				assert.NoError(t, err)
+				assert.Equal(t, msg.ID, found.ID)
+				found, err = ms.GetMessageBySignedCid(ctx, *msg.SignedCid)
+				assert.NoError(t, err)
+				assert.Equal(t, msg.ID, found.ID)
+			}
+
+			_, err = ms.GetMessageByUid(ctx, types.NewUUID().String())
+			assert.True(t, xerrors.Is(err, gorm.ErrRecordNotFound))
+
+			result, err = ms.ArchiveMessage(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, 0, result.Archived)
+		})
+	}
+}
+
+type failDeleteRepo struct {
+	repo.Repo
+}
+
+func (r failDeleteRepo) MessageRepo() repo.MessageRepo {
+	return failDeleteMessageRepo{r.Repo.MessageRepo()}
+}
+
+type failDeleteMessageRepo struct {
+	repo.MessageRepo
+}
+
+func (r failDeleteMessageRepo) DeleteMessage(ids []string) error {
+	return xerrors.New("delete failed")
+}
+
+func TestArchiveMessageToFileDeleteFailed(t *testing.T) {
+	ctx := context.Background()
+	ms, node, _, _ := setupMockMessageService(t)
+	dir := t.TempDir()
+	ms.cfg.Archive.Target = archiveTargetFile
+	ms.cfg.Archive.FileDir = dir
+	ms.cfg.Archive.RetentionEpochs = 1
+
+	msg := models.NewSignedMessages(1)[0]
+	msg.State = types.OnChainMsg
+	msg.Height = 1
+	assert.NoError(t, ms.repo.MessageRepo().CreateMessage(msg))
+	for i := 0; i < 3; i++ {
+		_, err := node.MineEmpty(ctx)
+		assert.NoError(t, err)
+	}
+
+	// nothing is left in archive files when the delete fails
+	db := ms.repo
+	ms.repo = failDeleteRepo{db}
+	_, err := ms.ArchiveMessage(ctx)
+	assert.Error(t, err)
+	files, err := ioutil.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, files, 0)
+
+	// the temp file left by an interrupted archive is renamed if its messages were deleted, otherwise removed
+	ms.repo = db
+	_, err = writeArchiveFile(dir, []*types.Message{msg})
+	assert.NoError(t, err)
+	assert.NoError(t, ms.recoverArchiveFiles(dir))
+	files, err = ioutil.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, files, 0)
+
+	result, err := ms.ArchiveMessage(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Archived)
+	assert.Len(t, result.Files, 1)
+
+	tmpPath, err := writeArchiveFile(dir, []*types.Message{msg})
+	assert.NoError(t, err)
+	assert.NoError(t, ms.recoverArchiveFiles(dir))
+	_, err = os.Stat(strings.TrimSuffix(tmpPath, archiveTmpSuffix))
+	assert.NoError(t, err)
+
+	found, err := ms.GetMessageByUid(ctx, msg.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, msg.ID, found.ID)
+}
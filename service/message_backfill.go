@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	venusTypes "github.com/filecoin-project/venus/pkg/types"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus-messager/models/repo"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+// number of tipset processed in one db transaction
+const backfillBatchSize = 100
+
+// Backfill walk the tipsets between fromHeight and toHeight, reconcile the state, receipt, height and tipset key of local messages
+// against the messages on chain, used to repair db after restoring from an old backup
+func (ms *MessageService) Backfill(ctx context.Context, fromHeight, toHeight abi.ChainEpoch) (*types.BackfillResult, error) {
+	if fromHeight < 0 || fromHeight > toHeight {
+		return nil, xerrors.Errorf("invalid height range [%d, %d]", fromHeight, toHeight)
+	}
+	head, err := ms.nodeClient.ChainHead(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if toHeight > head.Height() {
+		return nil, xerrors.Errorf("to height %d is greater than head %d", toHeight, head.Height())
+	}
+
+	ms.log.Infof("start backfill from %d to %d", fromHeight, toHeight)
+	result := &types.BackfillResult{FromHeight: fromHeight, ToHeight: toHeight}
+	var batch []*venusTypes.TipSet
+	var lastKey venusTypes.TipSetKey
+	for h := fromHeight; h <= toHeight; h++ {
+		ts, err := ms.nodeClient.ChainGetTipSetByHeight(ctx, h, head.Key())
+		if err != nil {
+			return result, xerrors.Errorf("get tipset at %d failed %v", h, err)
+		}
+		// null round returns the previous tipset
+		if ts.Key().Equals(lastKey) {
+			continue
+		}
+		lastKey = ts.Key()
+		batch = append(batch, ts)
+
+		if len(batch) >= backfillBatchSize {
+			if err := ms.backfillTipsets(ctx, batch, result); err != nil {
+				return result, err
+			}
+			batch = batch[:0]
+		}
+	}
+	if err := ms.backfillTipsets(ctx, batch, result); err != nil {
+		return result, err
+	}
+	ms.log.Infof("end backfill from %d to %d, tipset %d, on chain message %d, replaced message %d", fromHeight, toHeight,
+		result.TipsetNum, result.OnChainNum, result.ReplaceNum)
+
+	return result, nil
+}
+
+func (ms *MessageService) backfillTipsets(ctx context.Context, tsList []*venusTypes.TipSet, result *types.BackfillResult) error {
+	if len(tsList) == 0 {
+		return nil
+	}
+	applyMsgs, err := ms.processBlockParentMessages(ctx, tsList)
+	if err != nil {
+		return xerrors.Errorf("process tipset failed %v", err)
+	}
+
+	tsKeys := make(map[abi.ChainEpoch]venusTypes.TipSetKey, len(tsList))
+	for _, ts := range tsList {
+		tsKeys[ts.Height()] = ts.Key()
+	}
+
+	var replaceMsg map[string]*types.Message
+	var onChain int
+	if err := ms.repo.Transaction(func(txRepo repo.TxRepo) error {
+		replaceMsg, onChain, err = ms.updateChainMessages(txRepo, applyMsgs, tsKeys)
+		return err
+	}); err != nil {
+		return err
+	}
+	ms.updateChainMessagesCache(applyMsgs, replaceMsg)
+
+	result.TipsetNum += len(tsList)
+	result.OnChainNum += onChain
+	result.ReplaceNum += len(replaceMsg)
+	ms.log.Infof("backfill tipset %d to %d, found %d message", tsList[0].Height(), tsList[len(tsList)-1].Height(), len(applyMsgs))
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	venusTypes "github.com/filecoin-project/venus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus-messager/models"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+func TestBackfill(t *testing.T) {
+	ctx := context.Background()
+	ms, node, walletName, from := setupMockMessageService(t)
+
+	// the first message is in local db, the second one is sent out of messager
+	signed := make([]venusTypes.SignedMessage, 0, 2)
+	var local *types.Message
+	for nonce := uint64(0); nonce < 2; nonce++ {
+		msg := models.NewMessage()
+		msg.From = from
+		msg.To = from
+		msg.Nonce = nonce
+		msg.WalletName = walletName
+		msg.Meta = &types.MsgMeta{}
+		signedMsg, err := ToSignedMsg(ctx, ms.walletService, msg)
+		require.NoError(t, err)
+		signed = append(signed, signedMsg)
+		if local == nil {
+			local = msg
+		}
+	}
+	require.NoError(t, ms.repo.MessageRepo().CreateMessage(local))
+	for i := range signed {
+		_, err := node.MpoolPush(ctx, &signed[i])
+		require.NoError(t, err)
+	}
+	_, err := node.Mine(ctx)
+	require.NoError(t, err)
+	head, err := node.MineEmpty(ctx)
+	require.NoError(t, err)
+
+	_, err = ms.Backfill(ctx, head.Height()+1, head.Height()+1)
+	assert.Error(t, err)
+
+	res, err := ms.Backfill(ctx, 0, head.Height())
+	require.NoError(t, err)
+	assert.Equal(t, 1, res.OnChainNum)
+	assert.Equal(t, 0, res.ReplaceNum)
+	msg, err := ms.GetPrimaryMessageByUid(ctx, local.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.OnChainMsg, msg.State)
+	// the message packed by the first tipset is executed by head
+	assert.Equal(t, int64(head.Height()), msg.Height)
+	assert.Equal(t, head.Key().String(), msg.TipSetKey.String())
+}
@@ -43,8 +43,9 @@ type MsgSelectResult struct {
 }
 
 type msgErrInfo struct {
-	id  string
-	err string
+	id       string
+	revision int64
+	err      string
 }
 
 func NewMessageSelector(repo repo.Repo,
@@ -88,7 +89,7 @@ func (messageSelector *MessageSelector) SelectMessage(ctx context.Context, ts *v
 				<-sem
 			}()
 
-			selectResult, err := messageSelector.selectAddrMessage(ctx, addr, ts)
+			addrSelectResult, err := messageSelector.selectAddrMessage(ctx, addr, ts)
 			if err != nil {
 				messageSelector.log.Errorf("select message of %s fail %v", addr.Addr, err)
 				return
@@ -96,13 +97,13 @@ func (messageSelector *MessageSelector) SelectMessage(ctx context.Context, ts *v
 			lk.Lock()
 			defer lk.Unlock()
 
-			selectResult.ExpireMsg = append(selectResult.ExpireMsg, selectResult.ExpireMsg...)
-			selectResult.ToPushMsg = append(selectResult.ToPushMsg, selectResult.ToPushMsg...)
-			if len(selectResult.SelectMsg) > 0 {
-				selectResult.SelectMsg = append(selectResult.SelectMsg, selectResult.SelectMsg...)
+			selectResult.ExpireMsg = append(selectResult.ExpireMsg, addrSelectResult.ExpireMsg...)
+			selectResult.ToPushMsg = append(selectResult.ToPushMsg, addrSelectResult.ToPushMsg...)
+			if len(addrSelectResult.SelectMsg) > 0 {
+				selectResult.SelectMsg = append(selectResult.SelectMsg, addrSelectResult.SelectMsg...)
 				selectResult.ModifyAddress = append(selectResult.ModifyAddress, addr)
 			}
-			selectResult.ErrMsg = append(selectResult.ErrMsg, selectResult.ErrMsg...)
+			selectResult.ErrMsg = append(selectResult.ErrMsg, addrSelectResult.ErrMsg...)
 		}(addr)
 	}
 
@@ -145,19 +146,21 @@ func (messageSelector *MessageSelector) selectAddrMessage(ctx context.Context, a
 			return nil, xerrors.Errorf("update address %s nonce fail", addr.Addr)
 		}
 	}
-	//todo push signed but not onchain message, when to resend message
-	filledMessage, err := messageSelector.repo.MessageRepo().ListFilledMessageByAddress(addr.Addr)
-	if err != nil {
-		messageSelector.log.Warnf("list filled message %v", err)
-	}
-	for _, msg := range filledMessage {
-		if actor.Nonce > msg.Nonce {
-			continue
+	// signed but not onchain message will be rebroadcast by mpool checker when it is enabled
+	if messageSelector.cfg.MpoolCheckInterval <= 0 {
+		filledMessage, err := messageSelector.repo.MessageRepo().ListFilledMessageByAddress(addr.Addr)
+		if err != nil {
+			messageSelector.log.Warnf("list filled message %v", err)
+		}
+		for _, msg := range filledMessage {
+			if actor.Nonce > msg.Nonce {
+				continue
+			}
+			toPushMessage = append(toPushMessage, &venusTypes.SignedMessage{
+				Message:   msg.UnsignedMessage,
+				Signature: *msg.Signature,
+			})
 		}
-		toPushMessage = append(toPushMessage, &venusTypes.SignedMessage{
-			Message:   msg.UnsignedMessage,
-			Signature: *msg.Signature,
-		})
 	}
 
 	//消息排序
@@ -215,6 +218,10 @@ func (messageSelector *MessageSelector) selectAddrMessage(ctx context.Context, a
 			messageSelector.log.Infof("wallet %s address %v state is %s, skip select unchain message", msg.WalletName, addr.Addr, types.StateToString(addrInfo.State))
 			continue
 		}
+		if !messageSelector.walletService.CanSign(msg.WalletName, addr.Addr) {
+			messageSelector.log.Warnf("wallet %s is unavailable, skip select unchain message of address %v", msg.WalletName, addr.Addr)
+			continue
+		}
 
 		//分配nonce
 		msg.Nonce = addr.Nonce
@@ -227,7 +234,7 @@ func (messageSelector *MessageSelector) selectAddrMessage(ctx context.Context, a
 		newMsg, err := messageSelector.GasEstimateMessageGas(ctx, msg.VMMessage(), newMsgMeta, ts.Key())
 		if err != nil {
 			failedCount++
-			msgsErrInfo = append(msgsErrInfo, msgErrInfo{id: msg.ID, err: gasEstimate + err.Error()})
+			msgsErrInfo = append(msgsErrInfo, msgErrInfo{id: msg.ID, revision: msg.Revision, err: gasEstimate + err.Error()})
 			if strings.Contains(err.Error(), "exit SysErrSenderStateInvalid(2)") {
 				// SysErrSenderStateInvalid(2))
 				messageSelector.log.Errorf("message %s estimate message fail %v break address %s", msg.ID, err, addr.Addr)
@@ -243,24 +250,32 @@ func (messageSelector *MessageSelector) selectAddrMessage(ctx context.Context, a
 
 		unsignedCid := msg.UnsignedMessage.Cid()
 		msg.UnsignedCid = &unsignedCid
+		if messageSelector.walletService.IsOfflineWallet(msg.WalletName) {
+			// wait the signature imported from offline wallet, the nonce is kept by the message
+			msg.State = types.OfflineSignMsg
+			selectMsg = append(selectMsg, msg)
+			addr.Nonce++
+			count++
+			continue
+		}
 		//签名
 		data, err := msg.UnsignedMessage.ToStorageBlock()
 		if err != nil {
 			messageSelector.log.Errorf("calc message unsigned message id %s fail %v", msg.ID, err)
 			continue
 		}
-		sig, err := addrInfo.WalletClient.WalletSign(ctx, addr.Addr, unsignedCid.Bytes(), core.MsgMeta{
+		sig, signerWallet, err := messageSelector.walletService.WalletSign(ctx, msg.WalletName, addr.Addr, unsignedCid.Bytes(), core.MsgMeta{
 			Type:  core.MTChainMsg,
 			Extra: data.RawData(),
 		})
 		if err != nil {
-			//todo client net crash?
-			msgsErrInfo = append(msgsErrInfo, msgErrInfo{id: msg.ID, err: signMsg + err.Error()})
+			msgsErrInfo = append(msgsErrInfo, msgErrInfo{id: msg.ID, revision: msg.Revision, err: signMsg + err.Error()})
 			messageSelector.log.Errorf("wallet sign failed %s fail %v", msg.ID, err)
 			continue
 		}
 
 		msg.Signature = sig
+		msg.SignerWallet = signerWallet
 		//state
 		msg.State = types.FillMsg
 
@@ -20,6 +20,7 @@ import (
 	"github.com/ipfs/go-cid"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/xerrors"
+	"gorm.io/gorm"
 
 	"github.com/filecoin-project/venus-messager/config"
 	"github.com/filecoin-project/venus-messager/models/repo"
@@ -35,6 +36,9 @@ const (
 	LookBackLimit = 900
 
 	maxStoreTipsetCount = 3000
+
+	// times to retry when the messages updated by head change are changed by others at the same time
+	maxRevisionConflictRetry = 3
 )
 
 type MessageService struct {
@@ -46,7 +50,7 @@ type MessageService struct {
 	walletService *WalletService
 
 	triggerPush chan *venusTypes.TipSet
-	headChans   chan *headChan
+	headQueue   *headQueue
 
 	readFileOnce sync.Once
 	tsCache      *TipsetCache
@@ -55,6 +59,10 @@ type MessageService struct {
 
 	sps         *SharedParamsService
 	nodeService *NodeService
+
+	mpoolConflicts *mpoolConflictCache
+
+	archiveLk sync.Mutex
 }
 
 type headChan struct {
@@ -84,7 +92,7 @@ func NewMessageService(repo repo.Repo,
 		nodeClient:      nc,
 		cfg:             cfg,
 		messageSelector: selector,
-		headChans:       make(chan *headChan, MaxHeadChangeProcess),
+		headQueue:       newHeadQueue(cfg.HeadChangeQueueSize),
 
 		messageState:  messageState,
 		walletService: walletService,
@@ -92,9 +100,13 @@ func NewMessageService(repo repo.Repo,
 			Cache:      make(map[int64]*tipsetFormat, maxStoreTipsetCount),
 			CurrHeight: 0,
 		},
-		triggerPush: make(chan *venusTypes.TipSet, 20),
+		triggerPush: make(chan *venusTypes.TipSet, 1),
 		sps:         sps,
 		nodeService: nodeService,
+
+		mpoolConflicts: &mpoolConflictCache{
+			cache: make(map[string]*types.MpoolConflict),
+		},
 	}
 	ms.refreshMessageState(context.TODO())
 
@@ -131,12 +143,29 @@ func (ms *MessageService) PushMessage(ctx context.Context, msg *types.Message) e
 	return err
 }
 
+// GetMessageByUid may be served by a read replica, use GetPrimaryMessageByUid to read the message to be changed
 func (ms *MessageService) GetMessageByUid(ctx context.Context, id string) (*types.Message, error) {
+	return ms.getMessageByUid(ctx, ms.repo.MessageQueryRepo(), id)
+}
+
+// GetPrimaryMessageByUid always read the primary database
+func (ms *MessageService) GetPrimaryMessageByUid(ctx context.Context, id string) (*types.Message, error) {
+	return ms.getMessageByUid(ctx, ms.repo.MessageRepo(), id)
+}
+
+func (ms *MessageService) getMessageByUid(ctx context.Context, messageRepo repo.MessageQueryRepo, id string) (*types.Message, error) {
 	ts, err := ms.nodeClient.ChainHead(ctx)
 	if err != nil {
 		return nil, err
 	}
-	msg, err := ms.repo.MessageRepo().GetMessageByUid(id)
+	msg, err := messageRepo.GetMessageByUid(id)
+	if xerrors.Is(err, gorm.ErrRecordNotFound) {
+		msg, err = ms.getArchivedMessage(func(archiveRepo repo.ArchiveRepo) (*types.Message, error) {
+			return archiveRepo.GetArchivedMessageByUid(id)
+		}, func(msg *types.Message) bool {
+			return msg.ID == id
+		})
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -146,8 +175,13 @@ func (ms *MessageService) GetMessageByUid(ctx context.Context, id string) (*type
 	return msg, nil
 }
 
+// HasMessageByUid also check the archive table, archive files are not scanned
 func (ms *MessageService) HasMessageByUid(ctx context.Context, id string) (bool, error) {
-	return ms.repo.MessageRepo().HasMessageByUid(id)
+	has, err := ms.repo.MessageQueryRepo().HasMessageByUid(id)
+	if err != nil || has {
+		return has, err
+	}
+	return ms.repo.ArchiveRepo().HasArchivedMessageByUid(id)
 }
 
 func (ms *MessageService) GetMessageByCid(ctx context.Context, id cid.Cid) (*types.Message, error) {
@@ -155,7 +189,7 @@ func (ms *MessageService) GetMessageByCid(ctx context.Context, id cid.Cid) (*typ
 	if err != nil {
 		return nil, err
 	}
-	msg, err := ms.repo.MessageRepo().GetMessageByCid(id)
+	msg, err := ms.GetMessageByUnsignedCid(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -170,15 +204,31 @@ func (ms *MessageService) GetMessageState(ctx context.Context, id string) (types
 }
 
 func (ms *MessageService) GetMessageBySignedCid(ctx context.Context, signedCid cid.Cid) (*types.Message, error) {
-	return ms.repo.MessageRepo().GetMessageBySignedCid(signedCid)
+	msg, err := ms.repo.MessageQueryRepo().GetMessageBySignedCid(signedCid)
+	if xerrors.Is(err, gorm.ErrRecordNotFound) {
+		return ms.getArchivedMessage(func(archiveRepo repo.ArchiveRepo) (*types.Message, error) {
+			return archiveRepo.GetArchivedMessageBySignedCid(signedCid)
+		}, func(msg *types.Message) bool {
+			return msg.SignedCid != nil && *msg.SignedCid == signedCid
+		})
+	}
+	return msg, err
 }
 
 func (ms *MessageService) GetMessageByUnsignedCid(ctx context.Context, unsignedCid cid.Cid) (*types.Message, error) {
-	return ms.repo.MessageRepo().GetMessageByCid(unsignedCid)
+	msg, err := ms.repo.MessageQueryRepo().GetMessageByCid(unsignedCid)
+	if xerrors.Is(err, gorm.ErrRecordNotFound) {
+		return ms.getArchivedMessage(func(archiveRepo repo.ArchiveRepo) (*types.Message, error) {
+			return archiveRepo.GetArchivedMessageByCid(unsignedCid)
+		}, func(msg *types.Message) bool {
+			return msg.UnsignedCid != nil && *msg.UnsignedCid == unsignedCid
+		})
+	}
+	return msg, err
 }
 
 func (ms *MessageService) GetMessageByFromAndNonce(ctx context.Context, from address.Address, nonce uint64) (*types.Message, error) {
-	return ms.repo.MessageRepo().GetMessageByFromAndNonce(from, nonce)
+	return ms.repo.MessageQueryRepo().GetMessageByFromAndNonce(from, nonce)
 }
 
 func (ms *MessageService) ListMessage(ctx context.Context) ([]*types.Message, error) {
@@ -186,7 +236,7 @@ func (ms *MessageService) ListMessage(ctx context.Context) ([]*types.Message, er
 	if err != nil {
 		return nil, err
 	}
-	msgs, err := ms.repo.MessageRepo().ListMessage()
+	msgs, err := ms.repo.MessageQueryRepo().ListMessage()
 	if err != nil {
 		return nil, err
 	}
@@ -204,7 +254,7 @@ func (ms *MessageService) ListMessageByAddress(ctx context.Context, addr address
 	if err != nil {
 		return nil, err
 	}
-	msgs, err := ms.repo.MessageRepo().ListMessageByAddress(addr)
+	msgs, err := ms.repo.MessageQueryRepo().ListMessageByAddress(addr)
 	if err != nil {
 		return nil, err
 	}
@@ -218,7 +268,7 @@ func (ms *MessageService) ListMessageByAddress(ctx context.Context, addr address
 }
 
 func (ms *MessageService) ListFailedMessage(ctx context.Context) ([]*types.Message, error) {
-	return ms.repo.MessageRepo().ListFailedMessage()
+	return ms.repo.MessageQueryRepo().ListFailedMessage()
 }
 
 func (ms *MessageService) ListFilledMessageByAddress(ctx context.Context, addr address.Address) ([]*types.Message, error) {
@@ -238,14 +288,14 @@ func (ms *MessageService) ListBlockedMessage(ctx context.Context, addr address.A
 	var msgs []*types.Message
 	var err error
 	if addr != address.Undef {
-		msgs, err = ms.repo.MessageRepo().ListBlockedMessage(addr, d)
+		msgs, err = ms.repo.MessageQueryRepo().ListBlockedMessage(addr, d)
 	} else {
 		addrList, err := ms.repo.AddressRepo().ListAddress(ctx)
 		if err != nil {
 			return nil, err
 		}
 		for _, a := range addrList {
-			msgsT, err := ms.repo.MessageRepo().ListBlockedMessage(a.Addr, d)
+			msgsT, err := ms.repo.MessageQueryRepo().ListBlockedMessage(a.Addr, d)
 			if err != nil {
 				return nil, err
 			}
@@ -264,17 +314,34 @@ func (ms *MessageService) ListBlockedMessage(ctx context.Context, addr address.A
 	return msgs, err
 }
 
-func (ms *MessageService) UpdateMessageStateByCid(ctx context.Context, cid string, state types.MessageState) (string, error) {
-	return cid, ms.repo.MessageRepo().UpdateMessageStateByCid(cid, state)
+// UpdateMessageStateByCid fails with RevisionConflictError if the message is changed by others at the same time
+func (ms *MessageService) UpdateMessageStateByCid(ctx context.Context, unsignedCid string, state types.MessageState) (string, error) {
+	c, err := cid.Decode(unsignedCid)
+	if err != nil {
+		return unsignedCid, err
+	}
+	msg, err := ms.repo.MessageRepo().GetMessageByCid(c)
+	if err != nil {
+		return unsignedCid, err
+	}
+	defer ms.messageState.DeleteMessageByCid(unsignedCid)
+	return unsignedCid, ms.repo.MessageRepo().UpdateMessageStateByCid(unsignedCid, msg.Revision, state)
 }
 
+// UpdateMessageStateByID fails with RevisionConflictError if the message is changed by others at the same time
 func (ms *MessageService) UpdateMessageStateByID(ctx context.Context, id string, state types.MessageState) (string, error) {
-	return id, ms.repo.MessageRepo().UpdateMessageStateByID(id, state)
+	msg, err := ms.repo.MessageRepo().GetMessageByUid(id)
+	if err != nil {
+		return id, err
+	}
+	defer ms.messageState.DeleteMessage(id)
+	return id, ms.repo.MessageRepo().UpdateMessageStateByID(id, msg.Revision, state)
 }
 
-func (ms *MessageService) UpdateMessageInfoByCid(unsignedCid string, receipt *venusTypes.MessageReceipt,
+func (ms *MessageService) UpdateMessageInfoByCid(unsignedCid string, revision int64, receipt *venusTypes.MessageReceipt,
 	height abi.ChainEpoch, state types.MessageState, tsKey venusTypes.TipSetKey) (string, error) {
-	return unsignedCid, ms.repo.MessageRepo().UpdateMessageInfoByCid(unsignedCid, receipt, height, state, tsKey)
+	defer ms.messageState.DeleteMessageByCid(unsignedCid)
+	return unsignedCid, ms.repo.MessageRepo().UpdateMessageInfoByCid(unsignedCid, revision, receipt, height, state, tsKey)
 }
 
 func (ms *MessageService) ProcessNewHead(ctx context.Context, apply, revert []*venusTypes.TipSet) error {
@@ -294,10 +361,10 @@ func (ms *MessageService) ProcessNewHead(ctx context.Context, apply, revert []*v
 	smallestTs := apply[len(apply)-1]
 
 	if ts == nil || smallestTs.Parents().String() == ts[0].Key {
-		ms.headChans <- &headChan{
+		ms.headQueue.push(&headChan{
 			apply:  apply,
 			revert: nil,
-		}
+		})
 	} else {
 		gapTipset, revertTipset, err := ms.lookAncestors(ctx, ts, smallestTs)
 		if err != nil {
@@ -306,13 +373,13 @@ func (ms *MessageService) ProcessNewHead(ctx context.Context, apply, revert []*v
 		}
 
 		apply = append(apply, gapTipset...)
-		ms.headChans <- &headChan{
+		ms.headQueue.push(&headChan{
 			apply:  apply,
 			revert: revertTipset,
-		}
+		})
 	}
 
-	ms.log.Infof("%d head wait to process", len(ms.headChans))
+	ms.log.Infof("%d head wait to process", ms.headQueue.len())
 	return nil
 }
 
@@ -354,10 +421,10 @@ func (ms *MessageService) ReconnectCheck(ctx context.Context, head *venusTypes.T
 		return err
 	}
 
-	ms.headChans <- &headChan{
+	ms.headQueue.push(&headChan{
 		apply:  gapTipset,
 		revert: revertTipset,
-	}
+	})
 
 	return nil
 }
@@ -469,7 +536,7 @@ func (ms *MessageService) pushMessageToPool(ctx context.Context, ts *venusTypes.
 		}
 
 		for _, m := range selectResult.ErrMsg {
-			err := txRepo.MessageRepo().UpdateReturnValue(m.id, m.err)
+			err := txRepo.MessageRepo().UpdateReturnValue(m.id, m.revision, m.err)
 			if err != nil {
 				return err
 			}
@@ -477,6 +544,15 @@ func (ms *MessageService) pushMessageToPool(ctx context.Context, ts *venusTypes.
 
 		return nil
 	}); err != nil {
+		if xerrors.Is(err, repo.ErrRevisionConflict) {
+			// the selected messages are changed by others, drop them from cache and select again at next head
+			for _, msg := range selectResult.SelectMsg {
+				ms.messageState.DeleteMessage(msg.ID)
+			}
+			for _, msg := range selectResult.ExpireMsg {
+				ms.messageState.DeleteMessage(msg.ID)
+			}
+		}
 		ms.log.Errorf("save signed message failed %v", err)
 		return err
 	}
@@ -486,10 +562,13 @@ func (ms *MessageService) pushMessageToPool(ctx context.Context, ts *venusTypes.
 	tCacheUpdate := time.Now()
 	//update cache
 	for _, msg := range selectResult.SelectMsg {
-		selectResult.ToPushMsg = append(selectResult.ToPushMsg, &venusTypes.SignedMessage{
-			Message:   msg.UnsignedMessage,
-			Signature: *msg.Signature,
-		})
+		// message of offline wallet is pushed after its signature is imported
+		if msg.State == types.FillMsg {
+			selectResult.ToPushMsg = append(selectResult.ToPushMsg, &venusTypes.SignedMessage{
+				Message:   msg.UnsignedMessage,
+				Signature: *msg.Signature,
+			})
+		}
 		//update cache
 		err := ms.messageState.MutatorMessage(msg.ID, func(message *types.Message) error {
 			message.SignedCid = msg.SignedCid
@@ -497,7 +576,9 @@ func (ms *MessageService) pushMessageToPool(ctx context.Context, ts *venusTypes.
 			message.UnsignedMessage = msg.UnsignedMessage
 			message.State = msg.State
 			message.Signature = msg.Signature
+			message.SignerWallet = msg.SignerWallet
 			message.Nonce = msg.Nonce
+			message.Revision = msg.Revision
 			if message.Receipt != nil {
 				message.Receipt.ReturnValue = nil //cover data for err before
 			}
@@ -507,6 +588,18 @@ func (ms *MessageService) pushMessageToPool(ctx context.Context, ts *venusTypes.
 			return err
 		}
 	}
+	for _, msg := range selectResult.ExpireMsg {
+		if err := ms.messageState.MutatorMessage(msg.ID, func(message *types.Message) error {
+			message.State = msg.State
+			message.Revision = msg.Revision
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	for _, m := range selectResult.ErrMsg {
+		ms.messageState.DeleteMessage(m.id)
+	}
 
 	//broad cast  push to node in config ,push to multi node in db config
 	go func() {
@@ -530,9 +623,10 @@ func (ms *MessageService) pushMessageToPool(ctx context.Context, ts *venusTypes.
 }
 
 type nodeClient struct {
-	name  string
-	cli   *NodeClient
-	close jsonrpc.ClientCloser
+	name   string
+	weight int
+	cli    *NodeClient
+	close  jsonrpc.ClientCloser
 }
 
 func (ms *MessageService) multiNodeToPush(ctx context.Context, msgs []*venusTypes.SignedMessage) {
@@ -540,41 +634,66 @@ func (ms *MessageService) multiNodeToPush(ctx context.Context, msgs []*venusType
 		return
 	}
 
+	// both full node and light node receive pushed message
+	nc := ms.connectNodes(false)
+	if len(nc) == 0 {
+		return
+	}
+
+	for _, msg := range msgs {
+		node := pickNodeByWeight(nc)
+		if _, err := node.cli.MpoolPush(ctx, msg); err != nil &&
+			!strings.Contains(err.Error(), errAlreadyInMpool.Error()) {
+			ms.log.Errorf("push message to node %s %v", node.name, err)
+		}
+	}
+
+	for _, n := range nc {
+		n.close()
+	}
+}
+
+// connectNodes connect to nodes in db, light nodes are skipped when fullNodeOnly is true, the caller should close the returned clients
+func (ms *MessageService) connectNodes(fullNodeOnly bool) []nodeClient {
 	nodeList, err := ms.nodeService.ListNode(context.TODO())
 	if err != nil {
 		ms.log.Errorf("list node %v", err)
-		return
+		return nil
 	}
 	nc := make([]nodeClient, 0, len(nodeList))
 	for _, node := range nodeList {
+		if fullNodeOnly && !node.IsFullNode() {
+			continue
+		}
 		cli, closer, err := NewNodeClient(context.TODO(), &config.NodeConfig{Token: node.Token, Url: node.URL})
 		if err != nil {
 			ms.log.Warnf("connect node(%s) %v", node.Name, err)
 			continue
 		}
-		nc = append(nc, nodeClient{name: node.Name, cli: cli, close: closer})
-	}
-	if len(nc) == 0 {
-		return
+		weight := node.Weight
+		if weight <= 0 {
+			weight = defaultNodeWeight
+		}
+		nc = append(nc, nodeClient{name: node.Name, weight: weight, cli: cli, close: closer})
 	}
 
-	rand.Shuffle(len(msgs), func(i, j int) {
-		msgs[i], msgs[j] = msgs[j], msgs[i]
-	})
+	return nc
+}
 
-	next := 0
-	nodeLen := len(nc)
-	for _, msg := range msgs {
-		if _, err := nc[next].cli.MpoolPush(ctx, msg); err != nil &&
-			!strings.Contains(err.Error(), errAlreadyInMpool.Error()) {
-			ms.log.Errorf("push message to node %s %v", nc[next].name, err)
-		}
-		next = (next + 1) % nodeLen
+// pickNodeByWeight randomly pick a node, the probability is proportional to its weight
+func pickNodeByWeight(nc []nodeClient) nodeClient {
+	total := 0
+	for _, n := range nc {
+		total += n.weight
 	}
-
+	r := rand.Intn(total)
 	for _, n := range nc {
-		n.close()
+		if r < n.weight {
+			return n
+		}
+		r -= n.weight
 	}
+	return nc[len(nc)-1]
 }
 
 func (ms *MessageService) StartPushMessage(ctx context.Context) {
@@ -596,8 +715,12 @@ func (ms *MessageService) StartPushMessage(ctx context.Context) {
 			//	ms.log.Errorf("push message error %v", err)
 			//}
 		case newHead := <-ms.triggerPush:
+			if lag := ms.headLag(ctx, newHead); ms.cfg.MaxHeadLag > 0 && lag > abi.ChainEpoch(ms.cfg.MaxHeadLag) {
+				ms.log.Warnf("node head %d lags behind %d epoch, skip select message", newHead.Height(), lag)
+				continue
+			}
 			start := time.Now()
-			ms.log.Infof("start to push message %d task wait", len(ms.triggerPush))
+			ms.log.Infof("start to push message %d head change wait", ms.headQueue.len())
 			err := ms.pushMessageToPool(ctx, newHead)
 			if err != nil {
 				ms.log.Errorf("push message error %v", err)
@@ -607,6 +730,46 @@ func (ms *MessageService) StartPushMessage(ctx context.Context) {
 	}
 }
 
+// triggerPushMessage never block, only the latest head is kept if the previous one has not been consumed
+func (ms *MessageService) triggerPushMessage(ts *venusTypes.TipSet) {
+	select {
+	case ms.triggerPush <- ts:
+	default:
+		select {
+		case <-ms.triggerPush:
+		default:
+		}
+		select {
+		case ms.triggerPush <- ts:
+		default:
+		}
+	}
+}
+
+// headLag returns how many epochs the tipset lags behind the current time
+func (ms *MessageService) headLag(ctx context.Context, ts *venusTypes.TipSet) abi.ChainEpoch {
+	blockDelay := ms.nodeClient.BlockTime(ctx)
+	if blockDelay <= 0 {
+		return 0
+	}
+	behind := time.Since(time.Unix(int64(ts.At(0).Timestamp), 0))
+	if behind <= 0 {
+		return 0
+	}
+	return abi.ChainEpoch(behind / blockDelay)
+}
+
+func (ms *MessageService) GetHeadQueueState(ctx context.Context) (*types.HeadQueueState, error) {
+	state := ms.headQueue.state()
+	head, err := ms.nodeClient.ChainHead(ctx)
+	if err != nil {
+		return nil, err
+	}
+	state.HeadLag = ms.headLag(ctx, head)
+
+	return state, nil
+}
+
 func (ms *MessageService) UpdateAllFilledMessage(ctx context.Context) (int, error) {
 	msgs := make([]*types.Message, 0)
 	for addr := range ms.walletService.AllAddresses() {
@@ -637,7 +800,7 @@ func (ms *MessageService) updateFilledMessage(ctx context.Context, msg *types.Me
 		if err != nil || msgLookup == nil {
 			return xerrors.Errorf("search message %s from node %v", cid.String(), err)
 		}
-		if _, err := ms.UpdateMessageInfoByCid(msg.UnsignedCid.String(), &msgLookup.Receipt, msgLookup.Height, types.OnChainMsg, msgLookup.TipSet); err != nil {
+		if _, err := ms.UpdateMessageInfoByCid(msg.UnsignedCid.String(), msg.Revision, &msgLookup.Receipt, msgLookup.Height, types.OnChainMsg, msgLookup.TipSet); err != nil {
 			return err
 		}
 		ms.log.Infof("update message %v by node success", msg.ID)
@@ -647,7 +810,7 @@ func (ms *MessageService) updateFilledMessage(ctx context.Context, msg *types.Me
 }
 
 func (ms *MessageService) UpdateSignedMessageByID(ctx context.Context, id string) (string, error) {
-	msg, err := ms.GetMessageByUid(ctx, id)
+	msg, err := ms.GetPrimaryMessageByUid(ctx, id)
 	if err != nil {
 		return id, err
 	}
@@ -656,7 +819,7 @@ func (ms *MessageService) UpdateSignedMessageByID(ctx context.Context, id string
 }
 
 func (ms *MessageService) ReplaceMessage(ctx context.Context, id string, auto bool, maxFee string, gasLimit int64, gasPremium string, gasFeecap string) (cid.Cid, error) {
-	msg, err := ms.GetMessageByUid(ctx, id)
+	msg, err := ms.GetPrimaryMessageByUid(ctx, id)
 	if err != nil {
 		return cid.Undef, xerrors.Errorf("found message %v", err)
 	}
@@ -709,17 +872,20 @@ func (ms *MessageService) ReplaceMessage(ctx context.Context, id string, auto bo
 		}
 	}
 
-	addrInfo, exist := ms.walletService.GetAddressInfo(msg.WalletName, msg.From)
-	if !exist {
+	if _, exist := ms.walletService.GetAddressInfo(msg.WalletName, msg.From); !exist {
 		return cid.Undef, xerrors.Errorf("not found %s", msg.From.String())
 	}
 
-	signedMsg, err := ToSignedMsg(ctx, addrInfo.WalletClient, msg)
+	signedMsg, err := ToSignedMsg(ctx, ms.walletService, msg)
 	if err != nil {
 		return cid.Undef, err
 	}
 
 	if err := ms.repo.MessageRepo().SaveMessage(msg); err != nil {
+		if xerrors.Is(err, repo.ErrRevisionConflict) {
+			ms.messageState.DeleteMessage(msg.ID)
+			return cid.Undef, xerrors.Errorf("message is changed while replacing, try again: %w", err)
+		}
 		return cid.Undef, err
 	}
 	err = ms.messageState.MutatorMessage(msg.ID, func(message *types.Message) error {
@@ -728,7 +894,9 @@ func (ms *MessageService) ReplaceMessage(ctx context.Context, id string, auto bo
 		message.UnsignedMessage = msg.UnsignedMessage
 		message.State = msg.State
 		message.Signature = msg.Signature
+		message.SignerWallet = msg.SignerWallet
 		message.Nonce = msg.Nonce
+		message.Revision = msg.Revision
 		return nil
 	})
 	if err != nil {
@@ -740,12 +908,18 @@ func (ms *MessageService) ReplaceMessage(ctx context.Context, id string, auto bo
 	return signedMsg.Cid(), err
 }
 
+// MarkBadMessage fails with RevisionConflictError if the message is changed by others at the same time
 func (ms *MessageService) MarkBadMessage(ctx context.Context, id string) (struct{}, error) {
-	return ms.repo.MessageRepo().MarkBadMessage(id)
+	msg, err := ms.repo.MessageRepo().GetMessageByUid(id)
+	if err != nil {
+		return struct{}{}, err
+	}
+	defer ms.messageState.DeleteMessage(id)
+	return ms.repo.MessageRepo().MarkBadMessage(id, msg.Revision)
 }
 
 func (ms *MessageService) RepublishMessage(ctx context.Context, id string) (struct{}, error) {
-	msg, err := ms.GetMessageByUid(ctx, id)
+	msg, err := ms.GetPrimaryMessageByUid(ctx, id)
 	if err != nil {
 		return struct{}{}, nil
 	}
@@ -767,7 +941,8 @@ func (ms *MessageService) RepublishMessage(ctx context.Context, id string) (stru
 	return struct{}{}, nil
 }
 
-func ToSignedMsg(ctx context.Context, walletCli IWalletClient, msg *types.Message) (venusTypes.SignedMessage, error) {
+// ToSignedMsg sign msg by its wallet, or other wallet holding msg.From when sign failover is enabled
+func ToSignedMsg(ctx context.Context, walletService *WalletService, msg *types.Message) (venusTypes.SignedMessage, error) {
 	unsignedCid := msg.UnsignedMessage.Cid()
 	msg.UnsignedCid = &unsignedCid
 	//签名
@@ -775,7 +950,7 @@ func ToSignedMsg(ctx context.Context, walletCli IWalletClient, msg *types.Messag
 	if err != nil {
 		return venusTypes.SignedMessage{}, xerrors.Errorf("calc message unsigned message id %s fail %v", msg.ID, err)
 	}
-	sig, err := walletCli.WalletSign(ctx, msg.From, unsignedCid.Bytes(), core.MsgMeta{
+	sig, signerWallet, err := walletService.WalletSign(ctx, msg.WalletName, msg.From, unsignedCid.Bytes(), core.MsgMeta{
 		Type:  core.MTChainMsg,
 		Extra: data.RawData(),
 	})
@@ -784,6 +959,7 @@ func ToSignedMsg(ctx context.Context, walletCli IWalletClient, msg *types.Messag
 	}
 
 	msg.Signature = sig
+	msg.SignerWallet = signerWallet
 	//state
 	msg.State = types.FillMsg
 
@@ -74,21 +74,28 @@ func (ms *MessageState) DeleteMessage(id string) {
 	ms.messageCache.Delete(id)
 }
 
+// DeleteMessageByCid drop the cached message, it will be loaded from db when it is used again
+func (ms *MessageState) DeleteMessageByCid(cid string) {
+	if id, ok := ms.idCids.Get(cid); ok {
+		ms.DeleteMessage(id)
+	}
+}
+
+// MutatorMessage apply the change already written to db to the cached message, including the revision,
+// f is not called if the message is not in cache, since the message loaded from db already has the change
 func (ms *MessageState) MutatorMessage(id string, f func(*types.Message) error) error {
-	var msg *types.Message
 	if v, ok := ms.messageCache.Get(id); ok {
-		msg = v.(*types.Message)
-	} else {
-		var err error
-		msg, err = ms.repo.MessageRepo().GetMessageByUid(id)
-		if err != nil {
-			ms.log.Errorf("get message failed, id: %v, err: %v", id, err)
+		msg := v.(*types.Message)
+		if err := f(msg); err != nil {
 			return err
 		}
+		ms.messageCache.SetDefault(id, msg)
+		return nil
 	}
 
-	err := f(msg)
+	msg, err := ms.repo.MessageRepo().GetMessageByUid(id)
 	if err != nil {
+		ms.log.Errorf("get message failed, id: %v, err: %v", id, err)
 		return err
 	}
 	ms.messageCache.SetDefault(id, msg)
@@ -102,8 +109,9 @@ func (ms *MessageState) UpdateMessageByCid(cid cid.Cid, f func(message *types.Me
 		if err != nil {
 			return err
 		}
+		ms.idCids.Set(cid.String(), msg.ID)
 		ms.SetMessage(msg.ID, msg)
-		id = msg.ID
+		return nil
 	}
 
 	return ms.MutatorMessage(id, f)
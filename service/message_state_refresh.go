@@ -20,13 +20,20 @@ func (ms *MessageService) refreshMessageState(ctx context.Context) {
 	go func() {
 		for {
 			select {
-			case h := <-ms.headChans:
-				ms.log.Info("start refresh message state")
-				now := time.Now()
-				if err := ms.doRefreshMessageState(ctx, h); err != nil {
-					ms.log.Errorf("doRefreshMessageState occurs unexpected err:\n%v\n", err)
+			case <-ms.headQueue.notify:
+				for {
+					h, ok := ms.headQueue.pop()
+					if !ok {
+						break
+					}
+					ms.log.Info("start refresh message state")
+					now := time.Now()
+					if err := ms.doRefreshMessageState(ctx, h); err != nil {
+						ms.log.Errorf("doRefreshMessageState occurs unexpected err:\n%v\n", err)
+					}
+					ms.headQueue.done(h)
+					ms.log.Infof("end refresh message state, cost %d 'ms' ", time.Since(now).Milliseconds())
 				}
-				ms.log.Infof("end refresh message state, cost %d 'ms' ", time.Since(now).Milliseconds())
 			case <-ctx.Done():
 				ms.log.Warnf("context error: %v", ctx.Err())
 				return
@@ -58,71 +65,48 @@ func (ms *MessageService) doRefreshMessageState(ctx context.Context, h *headChan
 		tsKeys[height] = ts.Key()
 	}
 
-	// update db
-	replaceMsg := make(map[string]*types.Message)
-	err = ms.repo.Transaction(func(txRepo repo.TxRepo) error {
-		for _, msg := range applyMsgs {
-			localMsg, err := txRepo.MessageRepo().GetMessageByFromAndNonce(msg.msg.From, msg.msg.Nonce)
+	for _, msg := range applyMsgs {
+		delete(revertMsgs, msg.cid)
+	}
+
+	// update db, the replaced messages may be changed by others at the same time, read them again and retry
+	var replaceMsg map[string]*types.Message
+	for i := 0; ; i++ {
+		err = ms.repo.Transaction(func(txRepo repo.TxRepo) error {
+			replaceMsg, _, err = ms.updateChainMessages(txRepo, applyMsgs, tsKeys)
 			if err != nil {
-				ms.log.Warnf("msg not exit in local db maybe address %s send out of messager", msg.msg.From)
-				continue
+				return err
 			}
-
-			if localMsg.UnsignedCid == nil || *localMsg.UnsignedCid != msg.cid {
-				//replace msg
-				unsignedCid := msg.msg.Cid()
-				localMsg.UnsignedMessage = *msg.msg
-				localMsg.UnsignedCid = &unsignedCid
-				localMsg.SignedCid = &msg.cid
-				localMsg.State = types.ReplacedMsg
-				localMsg.Receipt = msg.receipt
-				localMsg.Height = int64(msg.height)
-				localMsg.TipSetKey = tsKeys[msg.height]
-				if err = txRepo.MessageRepo().SaveMessage(localMsg); err != nil {
-					return xerrors.Errorf("update message receipt failed, cid:%s failed:%v", msg.cid.String(), err)
+			for cid := range revertMsgs {
+				msg, err := txRepo.MessageRepo().GetMessageByCid(cid)
+				if err != nil {
+					return err
 				}
-				replaceMsg[localMsg.ID] = localMsg
-				ms.log.Warnf("replace message old msg cid %s new msg cid %s", localMsg.UnsignedCid, msg.cid)
-			} else {
-				if err = txRepo.MessageRepo().UpdateMessageInfoByCid(msg.cid.String(), msg.receipt, msg.height, types.OnChainMsg, tsKeys[msg.height]); err != nil {
-					return xerrors.Errorf("update message receipt failed, cid:%s failed:%v", msg.cid.String(), err)
+				if err := txRepo.MessageRepo().UpdateMessageInfoByCid(cid.String(), msg.Revision, &venustypes.MessageReceipt{ExitCode: -1},
+					abi.ChainEpoch(0), types.FillMsg, venustypes.EmptyTSK); err != nil {
+					return err
 				}
 			}
-			delete(revertMsgs, msg.cid)
+			return nil
+		})
+		if err == nil {
+			break
 		}
-		for cid := range revertMsgs {
-			if err := txRepo.MessageRepo().UpdateMessageInfoByCid(cid.String(), &venustypes.MessageReceipt{ExitCode: -1},
-				abi.ChainEpoch(0), types.FillMsg, venustypes.EmptyTSK); err != nil {
-				return err
-			}
+		if !xerrors.Is(err, repo.ErrRevisionConflict) || i >= maxRevisionConflictRetry {
+			return err
 		}
-		return nil
-	})
-	if err != nil {
-		return err
+		ms.log.Warnf("update chain messages conflict, retry %d: %v", i+1, err)
 	}
 
 	// update cache
-	for id, msg := range replaceMsg {
-		ms.messageState.SetMessage(id, msg)
-	}
-
-	for _, msg := range applyMsgs {
-		if err := ms.messageState.UpdateMessageByCid(msg.cid, func(message *types.Message) error {
-			message.Receipt = msg.receipt
-			message.Height = int64(msg.height)
-			message.State = types.OnChainMsg
-			return nil
-		}); err != nil {
-			ms.log.Errorf("update message failed cid: %s error: %v", msg.cid.String(), err)
-		}
-	}
+	ms.updateChainMessagesCache(applyMsgs, replaceMsg)
 
 	for cid := range revertMsgs {
 		if err := ms.messageState.UpdateMessageByCid(cid, func(message *types.Message) error {
 			message.Receipt = &venustypes.MessageReceipt{ExitCode: -1}
 			message.Height = 0
 			message.State = types.FillMsg
+			message.Revision++
 			return nil
 		}); err != nil {
 			ms.log.Errorf("update message failed cid: %s error: %v", cid.String(), err)
@@ -136,7 +120,7 @@ func (ms *MessageService) doRefreshMessageState(ctx context.Context, h *headChan
 	}
 
 	ms.log.Infof("process block %d, revert %d message apply %d message ", ms.tsCache.CurrHeight, len(revertMsgs), len(applyMsgs))
-	ms.triggerPush <- h.apply[0]
+	ms.triggerPushMessage(h.apply[0])
 
 	return nil
 }
@@ -144,7 +128,7 @@ func (ms *MessageService) doRefreshMessageState(ctx context.Context, h *headChan
 func (ms *MessageService) processRevertHead(ctx context.Context, h *headChan) (map[cid.Cid]struct{}, error) {
 	revertMsgs := make(map[cid.Cid]struct{})
 	for _, ts := range h.revert {
-		msgs, err := ms.repo.MessageRepo().ListFilledMessageByHeight(ts.Height())
+		msgs, err := ms.repo.MessageRepo().ListChainMessageByHeight(ts.Height())
 		if err != nil {
 			return nil, xerrors.Errorf("found message at height %d error %v", ts.Height(), err)
 		}
@@ -161,6 +145,67 @@ func (ms *MessageService) processRevertHead(ctx context.Context, h *headChan) (m
 	return revertMsgs, nil
 }
 
+// updateChainMessages update the local messages which found on chain, returns the messages replaced by other message with the same from and nonce
+// and the number of local messages updated to on chain, messages not in local db are not counted
+func (ms *MessageService) updateChainMessages(txRepo repo.TxRepo, applyMsgs []pendingMessage, tsKeys map[abi.ChainEpoch]venustypes.TipSetKey) (map[string]*types.Message, int, error) {
+	replaceMsg := make(map[string]*types.Message)
+	onChain := 0
+	for _, msg := range applyMsgs {
+		localMsg, err := txRepo.MessageRepo().GetMessageByFromAndNonce(msg.msg.From, msg.msg.Nonce)
+		if err != nil {
+			ms.log.Warnf("msg not exit in local db maybe address %s send out of messager", msg.msg.From)
+			continue
+		}
+
+		if localMsg.UnsignedCid == nil || *localMsg.UnsignedCid != msg.cid {
+			//replace msg
+			unsignedCid := msg.msg.Cid()
+			localMsg.UnsignedMessage = *msg.msg
+			localMsg.UnsignedCid = &unsignedCid
+			localMsg.SignedCid = &msg.cid
+			localMsg.State = types.ReplacedMsg
+			localMsg.Receipt = msg.receipt
+			localMsg.Height = int64(msg.height)
+			localMsg.TipSetKey = tsKeys[msg.height]
+			if err = txRepo.MessageRepo().SaveMessage(localMsg); err != nil {
+				return nil, 0, xerrors.Errorf("update message receipt failed, cid:%s failed:%w", msg.cid.String(), err)
+			}
+			replaceMsg[localMsg.ID] = localMsg
+			ms.log.Warnf("replace message old msg cid %s new msg cid %s", localMsg.UnsignedCid, msg.cid)
+		} else {
+			if err = txRepo.MessageRepo().UpdateMessageInfoByCid(msg.cid.String(), localMsg.Revision, msg.receipt, msg.height, types.OnChainMsg, tsKeys[msg.height]); err != nil {
+				return nil, 0, xerrors.Errorf("update message receipt failed, cid:%s failed:%v", msg.cid.String(), err)
+			}
+			onChain++
+		}
+	}
+
+	return replaceMsg, onChain, nil
+}
+
+func (ms *MessageService) updateChainMessagesCache(applyMsgs []pendingMessage, replaceMsg map[string]*types.Message) {
+	replaced := make(map[cid.Cid]struct{}, len(replaceMsg))
+	for id, msg := range replaceMsg {
+		ms.messageState.SetMessage(id, msg)
+		replaced[*msg.UnsignedCid] = struct{}{}
+	}
+
+	for _, msg := range applyMsgs {
+		if _, ok := replaced[msg.cid]; ok {
+			continue
+		}
+		if err := ms.messageState.UpdateMessageByCid(msg.cid, func(message *types.Message) error {
+			message.Receipt = msg.receipt
+			message.Height = int64(msg.height)
+			message.State = types.OnChainMsg
+			message.Revision++
+			return nil
+		}); err != nil {
+			ms.log.Errorf("update message failed cid: %s error: %v", msg.cid.String(), err)
+		}
+	}
+}
+
 type pendingMessage struct {
 	cid     cid.Cid
 	msg     *venustypes.UnsignedMessage
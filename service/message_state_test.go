@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/filecoin-project/venus-messager/config"
+	"github.com/filecoin-project/venus-messager/models/memory"
 	"github.com/filecoin-project/venus-messager/models/sqlite"
 	"github.com/filecoin-project/venus-messager/types"
 )
@@ -56,3 +57,31 @@ func TestMessageStateCache(t *testing.T) {
 	assert.True(t, flag)
 	assert.Equal(t, types.OnChainMsg, state)
 }
+
+func TestMessageStateCacheMemory(t *testing.T) {
+	db, err := memory.OpenMemory()
+	assert.NoError(t, err)
+
+	msgs := models.NewSignedMessages(10)
+	for _, msg := range msgs {
+		assert.NoError(t, db.MessageRepo().CreateMessage(msg))
+	}
+
+	msgState, err := NewMessageState(db, logrus.New(), &config.MessageStateConfig{
+		BackTime:          60,
+		CleanupInterval:   3,
+		DefaultExpiration: 2,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, msgState.loadRecentMessage())
+	assert.Equal(t, 10, len(msgState.idCids.cache))
+
+	err = msgState.UpdateMessageByCid(msgs[1].Cid(), func(message *types.Message) error {
+		message.State = types.OnChainMsg
+		return nil
+	})
+	assert.NoError(t, err)
+	state, flag := msgState.GetMessageStateByCid(msgs[1].Cid().String())
+	assert.True(t, flag)
+	assert.Equal(t, types.OnChainMsg, state)
+}
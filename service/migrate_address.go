@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/venus-wallet/core"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus-messager/models/repo"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+// MigrateAddress move addr from fromWallet to toWallet after toWallet proves it can sign for addr.
+// The wallet address records and unfilled messages are moved in one transaction, messages failed as NoWalletMsg are unfilled again.
+// fromWallet keeps addr as Removing until its filled messages are on chain
+func (walletService *WalletService) MigrateAddress(ctx context.Context, fromWallet, toWallet string, addr address.Address) (*types.MigrateAddressResult, error) {
+	if fromWallet == toWallet {
+		return nil, xerrors.Errorf("address %s is already in wallet %s", addr, toWallet)
+	}
+	addrID := walletService.getAddressID(addr)
+	if addrID == (types.UUID{}) {
+		return nil, xerrors.Errorf("address %s not found", addr)
+	}
+	if _, ok := walletService.getWalletInfo(fromWallet); !ok {
+		return nil, xerrors.Errorf("wallet %s not exist", fromWallet)
+	}
+	if _, ok := walletService.GetAddressInfo(fromWallet, addr); !ok {
+		return nil, xerrors.Errorf("address %s not in wallet %s", addr, fromWallet)
+	}
+	toInfo, ok := walletService.getWalletInfo(toWallet)
+	if !ok || toInfo.walletState != types.Alive {
+		return nil, xerrors.Errorf("wallet %s not exist", toWallet)
+	}
+	if err := walletService.verifyCanSign(ctx, toWallet, toInfo, addr); err != nil {
+		return nil, xerrors.Errorf("wallet %s can't sign for %s: %w", toWallet, addr, err)
+	}
+
+	fromID, toID := walletService.getWalletID(fromWallet), walletService.getWalletID(toWallet)
+	result := &types.MigrateAddressResult{
+		FromWallet: fromWallet,
+		ToWallet:   toWallet,
+		Address:    addr,
+	}
+	state := types.Alive
+	var fromRemoving bool
+	if err := walletService.repo.Transaction(func(txRepo repo.TxRepo) error {
+		fromWA, err := txRepo.WalletAddressRepo().GetWalletAddress(fromID, addrID)
+		if err != nil {
+			return xerrors.Errorf("get address %s of wallet %s: %w", addr, fromWallet, err)
+		}
+		toWA := &types.WalletAddress{
+			ID:           types.NewUUID(),
+			WalletID:     toID,
+			AddrID:       addrID,
+			AddressState: types.Alive,
+			IsDeleted:    repo.NotDeleted,
+			CreatedAt:    time.Now(),
+			UpdatedAt:    time.Now(),
+		}
+		toWA.SelMsgNum = fromWA.SelMsgNum
+		if fromWA.AddressState == types.Forbiden {
+			toWA.AddressState = types.Forbiden
+		}
+		has, err := txRepo.WalletAddressRepo().HasWalletAddress(toID, addrID)
+		if err != nil {
+			return err
+		}
+		if has {
+			old, err := txRepo.WalletAddressRepo().GetOneRecord(toID, addrID)
+			if err != nil {
+				return err
+			}
+			toWA.ID = old.ID
+			toWA.CreatedAt = old.CreatedAt
+			if toWA.SelMsgNum == 0 {
+				toWA.SelMsgNum = old.SelMsgNum
+			}
+			if old.IsDeleted == repo.NotDeleted && old.AddressState == types.Forbiden {
+				toWA.AddressState = types.Forbiden
+			}
+		}
+		if err := txRepo.WalletAddressRepo().SaveWalletAddress(toWA); err != nil {
+			return err
+		}
+
+		if fromWA.AddressState != types.Removing {
+			if err := txRepo.WalletAddressRepo().UpdateAddressState(fromID, addrID, types.Removing); err != nil {
+				return err
+			}
+			fromRemoving = true
+		}
+
+		result.Messages, err = txRepo.MessageRepo().MigrateUnFilledMessage(fromWallet, toWallet, addr)
+		result.SelMsgNum = toWA.SelMsgNum
+		state = toWA.AddressState
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	walletService.mutatorAddressInfo(toWallet, addr, func(addressInfo *AddressInfo) {
+		*addressInfo = AddressInfo{
+			State:        state,
+			SelectMsgNum: result.SelMsgNum,
+			WalletClient: toInfo.walletCli,
+		}
+	})
+	if fromRemoving {
+		walletService.mutatorAddressInfo(fromWallet, addr, func(addressInfo *AddressInfo) {
+			addressInfo.State = types.Removing
+		})
+		go func() {
+			walletService.pendingAddrChan <- pendingAddr{walletName: fromWallet, addr: addr}
+		}()
+	}
+	walletService.log.Infof("migrate address %s from wallet %s to %s with %d unfilled messages", addr, fromWallet, toWallet, len(result.Messages))
+
+	return result, nil
+}
+
+// verifyCanSign check the wallet holds addr and signs a random challenge by the key of addr,
+// offline wallet is only checked to hold addr
+func (walletService *WalletService) verifyCanSign(ctx context.Context, walletName string, walletInfo *WalletInfo, addr address.Address) error {
+	has, err := walletInfo.walletCli.WalletHas(ctx, addr)
+	if err != nil {
+		return err
+	}
+	if !has {
+		return xerrors.New("address not found in wallet")
+	}
+	if walletInfo.offline {
+		return nil
+	}
+
+	challenge := make([]byte, 32)
+	if _, err := rand.Read(challenge); err != nil {
+		return err
+	}
+	_, err = walletService.signAndVerify(ctx, walletName, walletInfo.walletCli, addr, challenge, core.MsgMeta{
+		Type:  core.MTUnknown,
+		Extra: challenge,
+	})
+	return err
+}
+
+// MigrateAddress move addr between wallets, the moved messages are dropped from cache to load the new wallet name
+func (ms *MessageService) MigrateAddress(ctx context.Context, fromWallet, toWallet string, addr address.Address) (*types.MigrateAddressResult, error) {
+	result, err := ms.walletService.MigrateAddress(ctx, fromWallet, toWallet, addr)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range result.Messages {
+		ms.messageState.DeleteMessage(id)
+	}
+	return result, nil
+}
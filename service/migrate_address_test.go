@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/filecoin-project/venus/pkg/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus-messager/models"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+func TestMigrateAddress(t *testing.T) {
+	ctx := context.Background()
+	ms, node, _, _ := setupMockMessageService(t)
+	ws := ms.walletService
+
+	// wallet address records are keyed by wallet id
+	for _, name := range []string{"old", "new", "other"} {
+		ws.setWallets(name, types.NewUUID())
+	}
+	// the old and new wallets share the keystore, the other wallet has no key of addr
+	dir := filepath.Join(t.TempDir(), "keystore")
+	oldWallet := openTestLocalWallet(t, dir, "passphrase")
+	addr, err := oldWallet.WalletNew(ctx, crypto.SigTypeSecp256k1)
+	require.NoError(t, err)
+	addTestWallet(t, ws, "old", oldWallet)
+	addTestWallet(t, ws, "new", openTestLocalWallet(t, dir, "passphrase"))
+	otherWallet := openTestLocalWallet(t, filepath.Join(t.TempDir(), "keystore"), "passphrase")
+	_, err = otherWallet.WalletNew(ctx, crypto.SigTypeSecp256k1)
+	require.NoError(t, err)
+	addTestWallet(t, ws, "other", otherWallet)
+	_, err = ws.SetSelectMsgNum(ctx, "old", addr, 5)
+	require.NoError(t, err)
+
+	ids := make([]string, 0, 2)
+	for i := 0; i < 2; i++ {
+		msg := models.NewMessage()
+		msg.From = addr
+		msg.WalletName = "old"
+		msg.State = types.UnFillMsg
+		msg.Meta = &types.MsgMeta{}
+		require.NoError(t, ms.PushMessage(ctx, msg))
+		ids = append(ids, msg.ID)
+	}
+
+	_, err = ms.MigrateAddress(ctx, "old", "old", addr)
+	assert.Error(t, err)
+	_, err = ms.MigrateAddress(ctx, "old", "other", addr)
+	assert.Error(t, err)
+	_, err = ms.MigrateAddress(ctx, "not-exist", "new", addr)
+	assert.Error(t, err)
+	_, err = ms.MigrateAddress(ctx, "other", "new", addr)
+	assert.Error(t, err, "address not in from wallet")
+	wa, err := ws.GetWalletAddress(ctx, "old", addr)
+	require.NoError(t, err)
+	assert.Equal(t, types.Alive, wa.AddressState)
+
+	res, err := ms.MigrateAddress(ctx, "old", "new", addr)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, ids, res.Messages)
+	assert.Equal(t, uint64(5), res.SelMsgNum)
+
+	// the old record is deleted in background as it has no filled message
+	if wa, err := ws.GetWalletAddress(ctx, "old", addr); err == nil {
+		assert.Equal(t, types.Removing, wa.AddressState)
+	}
+	wa, err = ws.GetWalletAddress(ctx, "new", addr)
+	require.NoError(t, err)
+	assert.Equal(t, types.Alive, wa.AddressState)
+	assert.Equal(t, uint64(5), wa.SelMsgNum)
+	for _, id := range ids {
+		msg, err := ms.GetMessageByUid(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, "new", msg.WalletName)
+		assert.Equal(t, types.UnFillMsg, msg.State)
+	}
+
+	// the moved messages are signed by the new wallet
+	head, err := node.ChainHead(ctx)
+	require.NoError(t, err)
+	require.NoError(t, ms.pushMessageToPool(ctx, head))
+	for _, id := range ids {
+		msg, err := ms.GetMessageByUid(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, types.FillMsg, msg.State)
+		assert.Equal(t, "new", msg.SignerWallet)
+	}
+}
@@ -0,0 +1,630 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	chain2 "github.com/filecoin-project/venus/app/submodule/chain"
+	"github.com/filecoin-project/venus/app/submodule/network"
+	"github.com/filecoin-project/venus/pkg/chain"
+	venusTypes "github.com/filecoin-project/venus/pkg/types"
+	"github.com/ipfs/go-cid"
+	"golang.org/x/xerrors"
+)
+
+const (
+	mockBlockDelay = time.Second * 30
+
+	mockGasLimit   = 1000000
+	mockGasPremium = 100
+	mockGasFeeCap  = 1000
+
+	mhSha256 = 0x12
+)
+
+// MockFullNode an in-process simulated chain, just for test.
+// Like a real chain, messages packed into a tipset are executed by its child, they are returned with their receipts as
+// the parent messages of the child, so a message is on chain at the height of the tipset after the one which packed it.
+type MockFullNode struct {
+	lk sync.Mutex
+
+	miner   address.Address
+	seq     int
+	chain   []*mockTipset // canonical chain, from genesis to head
+	tipsets map[string]*mockTipset
+	blocks  map[cid.Cid]*mockTipset
+
+	mpool  map[address.Address]map[uint64]*venusTypes.SignedMessage
+	nonces map[address.Address]uint64
+	keys   map[address.Address]address.Address // id address to key address
+
+	notifees []chan []*chain.HeadChange
+}
+
+type mockTipset struct {
+	ts       *venusTypes.TipSet
+	msgs     []*venusTypes.SignedMessage
+	receipts []*venusTypes.MessageReceipt
+}
+
+func NewMockFullNode() (*MockFullNode, error) {
+	miner, err := address.NewIDAddress(1000)
+	if err != nil {
+		return nil, err
+	}
+	m := &MockFullNode{
+		miner:   miner,
+		tipsets: make(map[string]*mockTipset),
+		blocks:  make(map[cid.Cid]*mockTipset),
+		mpool:   make(map[address.Address]map[uint64]*venusTypes.SignedMessage),
+		nonces:  make(map[address.Address]uint64),
+		keys:    make(map[address.Address]address.Address),
+	}
+	genesis, err := m.newTipset(venusTypes.EmptyTSK, 0)
+	if err != nil {
+		return nil, err
+	}
+	m.addTipset(&mockTipset{ts: genesis})
+
+	return m, nil
+}
+
+// NodeClient returns a NodeClient whose function fields are all served by the mock node
+func (m *MockFullNode) NodeClient() *NodeClient {
+	return &NodeClient{
+		ChainNotify:            m.ChainNotify,
+		BlockTime:              func(context.Context) time.Duration { return mockBlockDelay },
+		ChainHead:              m.ChainHead,
+		ChainList:              m.ChainList,
+		ChainSetHead:           m.ChainSetHead,
+		ChainGetTipSet:         m.ChainGetTipSet,
+		ChainGetTipSetByHeight: m.ChainGetTipSetByHeight,
+		ChainGetBlock:          m.ChainGetBlock,
+		ChainGetMessage:        m.ChainGetMessage,
+		ChainGetBlockMessages:  m.ChainGetBlockMessages,
+		ChainGetReceipts:       m.ChainGetReceipts,
+		ChainGetParentMessages: m.ChainGetParentMessages,
+		ChainGetParentReceipts: m.ChainGetParentReceipts,
+		GetFullBlock:           m.GetFullBlock,
+		GetEntry: func(ctx context.Context, height abi.ChainEpoch, round uint64) (*venusTypes.BeaconEntry, error) {
+			return &venusTypes.BeaconEntry{Round: round, Data: []byte(fmt.Sprintf("mock-beacon-%d", round))}, nil
+		},
+		MessageWait: func(context.Context, cid.Cid, abi.ChainEpoch, abi.ChainEpoch) (*chain.ChainMessage, error) {
+			return nil, xerrors.Errorf("mock node not support MessageWait, use StateSearchMsg")
+		},
+		StateAccountKey: m.StateAccountKey,
+		StateNetworkName: func(context.Context) (chain2.NetworkName, error) {
+			return chain2.NetworkName("mock"), nil
+		},
+		StateSearchMsg: m.StateSearchMsg,
+		StateNetworkVersion: func(context.Context, venusTypes.TipSetKey) (network.Version, error) {
+			return network.Version{Version: "mock"}, nil
+		},
+		StateGetActor: m.StateGetActor,
+		StateSearchMsgLimited: func(ctx context.Context, c cid.Cid, limit abi.ChainEpoch) (*chain.MsgLookup, error) {
+			return m.StateSearchMsg(ctx, c)
+		},
+
+		GasEstimateMessageGas: m.GasEstimateMessageGas,
+		GasEstimateFeeCap: func(context.Context, *venusTypes.UnsignedMessage, int64, venusTypes.TipSetKey) (big.Int, error) {
+			return big.NewInt(mockGasFeeCap), nil
+		},
+		GasEstimateGasPremium: func(context.Context, uint64, address.Address, int64, venusTypes.TipSetKey) (big.Int, error) {
+			return big.NewInt(mockGasPremium), nil
+		},
+		GasEstimateGasLimit: func(context.Context, *venusTypes.UnsignedMessage, venusTypes.TipSetKey) (int64, error) {
+			return mockGasLimit, nil
+		},
+
+		MpoolPush:      m.MpoolPush,
+		MpoolBatchPush: m.MpoolBatchPush,
+		MpoolPending:   m.MpoolPending,
+	}
+}
+
+// SetAccountKey map an id address to key address, used by StateAccountKey
+func (m *MockFullNode) SetAccountKey(idAddr, keyAddr address.Address) {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+	m.keys[idAddr] = keyAddr
+}
+
+//// chain ////
+
+// Mine append a tipset which packs all the executable messages in mpool and notify it
+func (m *MockFullNode) Mine(ctx context.Context) (*venusTypes.TipSet, error) {
+	m.lk.Lock()
+	mt, err := m.mine(true)
+	m.lk.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	m.notify([]*chain.HeadChange{{Type: chain.HCApply, Val: mt.ts}})
+
+	return mt.ts, nil
+}
+
+// MineEmpty append a tipset without message and notify it
+func (m *MockFullNode) MineEmpty(ctx context.Context) (*venusTypes.TipSet, error) {
+	m.lk.Lock()
+	mt, err := m.mine(false)
+	m.lk.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	m.notify([]*chain.HeadChange{{Type: chain.HCApply, Val: mt.ts}})
+
+	return mt.ts, nil
+}
+
+// Reorg revert the last depth tipsets and switch to a heavier fork with depth+1 empty tipsets,
+// the messages in reverted tipsets are returned to mpool. Both result are sorted from high to low.
+func (m *MockFullNode) Reorg(ctx context.Context, depth int) ([]*venusTypes.TipSet, []*venusTypes.TipSet, error) {
+	m.lk.Lock()
+	if depth <= 0 || depth >= len(m.chain) {
+		m.lk.Unlock()
+		return nil, nil, xerrors.Errorf("invalid reorg depth %d, chain length %d", depth, len(m.chain))
+	}
+	revert := m.revertTo(len(m.chain) - 1 - depth)
+	var apply []*venusTypes.TipSet
+	for i := 0; i <= depth; i++ {
+		mt, err := m.mine(false)
+		if err != nil {
+			m.lk.Unlock()
+			return nil, nil, err
+		}
+		apply = append([]*venusTypes.TipSet{mt.ts}, apply...)
+	}
+	m.lk.Unlock()
+
+	changes := make([]*chain.HeadChange, 0, len(revert)+len(apply))
+	for _, ts := range revert {
+		changes = append(changes, &chain.HeadChange{Type: chain.HCRevert, Val: ts})
+	}
+	for _, ts := range apply {
+		changes = append(changes, &chain.HeadChange{Type: chain.HCApply, Val: ts})
+	}
+	m.notify(changes)
+
+	return revert, apply, nil
+}
+
+func (m *MockFullNode) ChainNotify(ctx context.Context) (<-chan []*chain.HeadChange, error) {
+	ch := make(chan []*chain.HeadChange, 100)
+	m.lk.Lock()
+	ch <- []*chain.HeadChange{{Type: chain.HCCurrent, Val: m.head().ts}}
+	m.notifees = append(m.notifees, ch)
+	m.lk.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		m.lk.Lock()
+		defer m.lk.Unlock()
+		for i, c := range m.notifees {
+			if c == ch {
+				m.notifees = append(m.notifees[:i], m.notifees[i+1:]...)
+				close(ch)
+				break
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+func (m *MockFullNode) ChainHead(ctx context.Context) (*venusTypes.TipSet, error) {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+	return m.head().ts, nil
+}
+
+func (m *MockFullNode) ChainList(ctx context.Context, tsk venusTypes.TipSetKey, count int) ([]venusTypes.TipSetKey, error) {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+	mt, err := m.getTipset(tsk)
+	if err != nil {
+		return nil, err
+	}
+	var keys []venusTypes.TipSetKey
+	for len(keys) < count {
+		keys = append(keys, mt.ts.Key())
+		if mt.ts.Height() == 0 {
+			break
+		}
+		if mt, err = m.getTipset(mt.ts.Parents()); err != nil {
+			return nil, err
+		}
+	}
+
+	return keys, nil
+}
+
+func (m *MockFullNode) ChainSetHead(ctx context.Context, tsk venusTypes.TipSetKey) error {
+	m.lk.Lock()
+	idx := -1
+	for i, mt := range m.chain {
+		if mt.ts.Key().String() == tsk.String() {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		m.lk.Unlock()
+		return xerrors.Errorf("tipset %s not in canonical chain", tsk)
+	}
+	revert := m.revertTo(idx)
+	m.lk.Unlock()
+
+	changes := make([]*chain.HeadChange, 0, len(revert))
+	for _, ts := range revert {
+		changes = append(changes, &chain.HeadChange{Type: chain.HCRevert, Val: ts})
+	}
+	m.notify(changes)
+
+	return nil
+}
+
+func (m *MockFullNode) ChainGetTipSet(ctx context.Context, tsk venusTypes.TipSetKey) (*venusTypes.TipSet, error) {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+	mt, err := m.getTipset(tsk)
+	if err != nil {
+		return nil, err
+	}
+	return mt.ts, nil
+}
+
+func (m *MockFullNode) ChainGetTipSetByHeight(ctx context.Context, height abi.ChainEpoch, tsk venusTypes.TipSetKey) (*venusTypes.TipSet, error) {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+	for i := len(m.chain) - 1; i >= 0; i-- {
+		if m.chain[i].ts.Height() <= height {
+			return m.chain[i].ts, nil
+		}
+	}
+	return nil, xerrors.Errorf("not found tipset at height %d", height)
+}
+
+func (m *MockFullNode) ChainGetBlock(ctx context.Context, c cid.Cid) (*venusTypes.BlockHeader, error) {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+	mt, ok := m.blocks[c]
+	if !ok {
+		return nil, xerrors.Errorf("block %s not found", c)
+	}
+	return mt.ts.At(0), nil
+}
+
+func (m *MockFullNode) ChainGetMessage(ctx context.Context, c cid.Cid) (*venusTypes.UnsignedMessage, error) {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+	for _, mt := range m.tipsets {
+		for _, msg := range mt.msgs {
+			if msg.Message.Cid() == c || msg.Cid() == c {
+				return &msg.Message, nil
+			}
+		}
+	}
+	for _, msgs := range m.mpool {
+		for _, msg := range msgs {
+			if msg.Message.Cid() == c || msg.Cid() == c {
+				return &msg.Message, nil
+			}
+		}
+	}
+	return nil, xerrors.Errorf("message %s not found", c)
+}
+
+func (m *MockFullNode) ChainGetBlockMessages(ctx context.Context, c cid.Cid) (*chain2.BlockMessages, error) {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+	mt, ok := m.blocks[c]
+	if !ok {
+		return nil, xerrors.Errorf("block %s not found", c)
+	}
+	bm := &chain2.BlockMessages{}
+	for _, msg := range mt.msgs {
+		bm.SecpkMessages = append(bm.SecpkMessages, msg)
+		bm.Cids = append(bm.Cids, msg.Cid())
+	}
+	return bm, nil
+}
+
+// ChainGetReceipts receipts root is the ParentMessageReceipts of block
+func (m *MockFullNode) ChainGetReceipts(ctx context.Context, c cid.Cid) ([]venusTypes.MessageReceipt, error) {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+	for _, mt := range m.tipsets {
+		if mt.ts.At(0).ParentMessageReceipts == c {
+			parent, err := m.parent(mt)
+			if err != nil {
+				return nil, err
+			}
+			receipts := make([]venusTypes.MessageReceipt, 0, len(parent.receipts))
+			for _, r := range parent.receipts {
+				receipts = append(receipts, *r)
+			}
+			return receipts, nil
+		}
+	}
+	return nil, xerrors.Errorf("receipts %s not found", c)
+}
+
+func (m *MockFullNode) ChainGetParentMessages(ctx context.Context, bcid cid.Cid) ([]chain2.Message, error) {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+	parent, err := m.parentOfBlock(bcid)
+	if err != nil {
+		return nil, err
+	}
+	msgs := make([]chain2.Message, 0, len(parent.msgs))
+	for _, msg := range parent.msgs {
+		unsigned := msg.Message
+		msgs = append(msgs, chain2.Message{Cid: unsigned.Cid(), Message: &unsigned})
+	}
+	return msgs, nil
+}
+
+func (m *MockFullNode) ChainGetParentReceipts(ctx context.Context, bcid cid.Cid) ([]*venusTypes.MessageReceipt, error) {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+	parent, err := m.parentOfBlock(bcid)
+	if err != nil {
+		return nil, err
+	}
+	return parent.receipts, nil
+}
+
+func (m *MockFullNode) GetFullBlock(ctx context.Context, c cid.Cid) (*venusTypes.FullBlock, error) {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+	mt, ok := m.blocks[c]
+	if !ok {
+		return nil, xerrors.Errorf("block %s not found", c)
+	}
+	return &venusTypes.FullBlock{
+		Header:       mt.ts.At(0),
+		SECPMessages: mt.msgs,
+	}, nil
+}
+
+//// state ////
+
+func (m *MockFullNode) StateAccountKey(ctx context.Context, addr address.Address, tsk venusTypes.TipSetKey) (address.Address, error) {
+	if addr.Protocol() != address.ID {
+		return addr, nil
+	}
+	m.lk.Lock()
+	defer m.lk.Unlock()
+	keyAddr, ok := m.keys[addr]
+	if !ok {
+		return address.Undef, xerrors.Errorf("not found key address of %s", addr)
+	}
+	return keyAddr, nil
+}
+
+// StateSearchMsg returns the tipset executed the message, the message packed by head is not executed yet
+func (m *MockFullNode) StateSearchMsg(ctx context.Context, c cid.Cid) (*chain.MsgLookup, error) {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+	for idx, mt := range m.chain[:len(m.chain)-1] {
+		for i, msg := range mt.msgs {
+			if msg.Message.Cid() == c || msg.Cid() == c {
+				child := m.chain[idx+1].ts
+				return &chain.MsgLookup{
+					Receipt: *mt.receipts[i],
+					TipSet:  child.Key(),
+					Height:  child.Height(),
+				}, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (m *MockFullNode) StateGetActor(ctx context.Context, addr address.Address, tsk venusTypes.TipSetKey) (*venusTypes.Actor, error) {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+	if keyAddr, ok := m.keys[addr]; ok {
+		addr = keyAddr
+	}
+	return &venusTypes.Actor{
+		Nonce:   m.nonces[addr],
+		Balance: abi.NewTokenAmount(0),
+	}, nil
+}
+
+func (m *MockFullNode) GasEstimateMessageGas(ctx context.Context, msg *venusTypes.UnsignedMessage, spec *venusTypes.MessageSendSpec, tsk venusTypes.TipSetKey) (*venusTypes.UnsignedMessage, error) {
+	if msg.GasLimit == 0 {
+		msg.GasLimit = mockGasLimit
+	}
+	if msg.GasPremium.NilOrZero() {
+		msg.GasPremium = big.NewInt(mockGasPremium)
+	}
+	if msg.GasFeeCap.NilOrZero() {
+		msg.GasFeeCap = big.NewInt(mockGasFeeCap)
+	}
+	return msg, nil
+}
+
+//// mpool ////
+
+func (m *MockFullNode) MpoolPush(ctx context.Context, msg *venusTypes.SignedMessage) (cid.Cid, error) {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+	from := msg.Message.From
+	if msg.Message.Nonce < m.nonces[from] {
+		return cid.Undef, xerrors.Errorf("message nonce %d is smaller than actor nonce %d", msg.Message.Nonce, m.nonces[from])
+	}
+	if _, ok := m.mpool[from]; !ok {
+		m.mpool[from] = make(map[uint64]*venusTypes.SignedMessage)
+	}
+	if exist, ok := m.mpool[from][msg.Message.Nonce]; ok {
+		if exist.Cid() == msg.Cid() {
+			return cid.Undef, errAlreadyInMpool
+		}
+		if big.Cmp(msg.Message.GasPremium, exist.Message.GasPremium) <= 0 {
+			return cid.Undef, xerrors.Errorf("replace message %s need higher gas premium", exist.Cid())
+		}
+	}
+	m.mpool[from][msg.Message.Nonce] = msg
+
+	return msg.Cid(), nil
+}
+
+func (m *MockFullNode) MpoolBatchPush(ctx context.Context, msgs []*venusTypes.SignedMessage) ([]cid.Cid, error) {
+	cids := make([]cid.Cid, 0, len(msgs))
+	for _, msg := range msgs {
+		c, err := m.MpoolPush(ctx, msg)
+		if err != nil {
+			return cids, err
+		}
+		cids = append(cids, c)
+	}
+	return cids, nil
+}
+
+func (m *MockFullNode) MpoolPending(ctx context.Context, tsk venusTypes.TipSetKey) ([]*venusTypes.SignedMessage, error) {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+	var msgs []*venusTypes.SignedMessage
+	for _, fromMsgs := range m.mpool {
+		for _, msg := range fromMsgs {
+			msgs = append(msgs, msg)
+		}
+	}
+	return msgs, nil
+}
+
+//// internal, called with lock held ////
+
+func (m *MockFullNode) head() *mockTipset {
+	return m.chain[len(m.chain)-1]
+}
+
+func (m *MockFullNode) getTipset(tsk venusTypes.TipSetKey) (*mockTipset, error) {
+	if tsk.IsEmpty() {
+		return m.head(), nil
+	}
+	mt, ok := m.tipsets[tsk.String()]
+	if !ok {
+		return nil, xerrors.Errorf("tipset %s not found", tsk)
+	}
+	return mt, nil
+}
+
+// parent returns the parent of tipset, genesis has no parent so an empty tipset is returned for it
+func (m *MockFullNode) parent(mt *mockTipset) (*mockTipset, error) {
+	if mt.ts.Height() == 0 {
+		return &mockTipset{ts: mt.ts}, nil
+	}
+	return m.getTipset(mt.ts.Parents())
+}
+
+func (m *MockFullNode) parentOfBlock(bcid cid.Cid) (*mockTipset, error) {
+	mt, ok := m.blocks[bcid]
+	if !ok {
+		return nil, xerrors.Errorf("block %s not found", bcid)
+	}
+	return m.parent(mt)
+}
+
+func (m *MockFullNode) newTipset(parents venusTypes.TipSetKey, height abi.ChainEpoch) (*venusTypes.TipSet, error) {
+	m.seq++
+	seq := fmt.Sprintf("%d-%d", height, m.seq)
+	return venusTypes.NewTipSet(&venusTypes.BlockHeader{
+		Miner:                 m.miner,
+		Parents:               parents,
+		ParentWeight:          big.NewInt(int64(height)),
+		Height:                height,
+		ParentStateRoot:       mockCid("state-" + seq),
+		ParentMessageReceipts: mockCid("receipts-" + seq),
+		Messages:              mockCid("messages-" + seq),
+		Timestamp:             uint64(m.seq),
+		ParentBaseFee:         abi.NewTokenAmount(100),
+	})
+}
+
+func (m *MockFullNode) addTipset(mt *mockTipset) {
+	m.chain = append(m.chain, mt)
+	m.tipsets[mt.ts.Key().String()] = mt
+	m.blocks[mt.ts.At(0).Cid()] = mt
+}
+
+func (m *MockFullNode) mine(pack bool) (*mockTipset, error) {
+	parent := m.head().ts
+	ts, err := m.newTipset(parent.Key(), parent.Height()+1)
+	if err != nil {
+		return nil, err
+	}
+	mt := &mockTipset{ts: ts}
+	if pack {
+		for from, fromMsgs := range m.mpool {
+			nonces := make([]uint64, 0, len(fromMsgs))
+			for nonce := range fromMsgs {
+				nonces = append(nonces, nonce)
+			}
+			sort.Slice(nonces, func(i, j int) bool { return nonces[i] < nonces[j] })
+			for _, nonce := range nonces {
+				if nonce != m.nonces[from] {
+					break
+				}
+				msg := fromMsgs[nonce]
+				mt.msgs = append(mt.msgs, msg)
+				mt.receipts = append(mt.receipts, &venusTypes.MessageReceipt{ExitCode: 0, GasUsed: msg.Message.GasLimit / 2})
+				delete(fromMsgs, nonce)
+				m.nonces[from]++
+			}
+		}
+	}
+	m.addTipset(mt)
+
+	return mt, nil
+}
+
+// revertTo drop the tipsets after the index of canonical chain, returns the reverted tipsets from high to low
+func (m *MockFullNode) revertTo(idx int) []*venusTypes.TipSet {
+	var revert []*venusTypes.TipSet
+	for len(m.chain)-1 > idx {
+		mt := m.head()
+		m.chain = m.chain[:len(m.chain)-1]
+		for _, msg := range mt.msgs {
+			from := msg.Message.From
+			if _, ok := m.mpool[from]; !ok {
+				m.mpool[from] = make(map[uint64]*venusTypes.SignedMessage)
+			}
+			m.mpool[from][msg.Message.Nonce] = msg
+			if m.nonces[from] > msg.Message.Nonce {
+				m.nonces[from] = msg.Message.Nonce
+			}
+		}
+		revert = append(revert, mt.ts)
+	}
+	return revert
+}
+
+func (m *MockFullNode) notify(changes []*chain.HeadChange) {
+	if len(changes) == 0 {
+		return
+	}
+	m.lk.Lock()
+	defer m.lk.Unlock()
+	for _, ch := range m.notifees {
+		select {
+		case ch <- changes:
+		default:
+			// drop the notification if the subscriber is too slow
+		}
+	}
+}
+
+func mockCid(data string) cid.Cid {
+	c, _ := cid.NewPrefixV1(cid.Raw, mhSha256).Sum([]byte(data))
+	return c
+}
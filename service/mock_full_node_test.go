@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/venus/pkg/chain"
+	venusTypes "github.com/filecoin-project/venus/pkg/types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/filecoin-project/venus-messager/config"
+	"github.com/filecoin-project/venus-messager/models"
+	"github.com/filecoin-project/venus-messager/models/memory"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+func TestMockFullNodeReorg(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	node, err := NewMockFullNode()
+	assert.NoError(t, err)
+	nc := node.NodeClient()
+
+	notifs, err := nc.ChainNotify(ctx)
+	assert.NoError(t, err)
+	current := <-notifs
+	assert.Len(t, current, 1)
+	assert.Equal(t, chain.HCCurrent, current[0].Type)
+
+	for i := 0; i < 3; i++ {
+		_, err := node.MineEmpty(ctx)
+		assert.NoError(t, err)
+		<-notifs
+	}
+	head, err := nc.ChainHead(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), int64(head.Height()))
+
+	revert, apply, err := node.Reorg(ctx, 2)
+	assert.NoError(t, err)
+	assert.Len(t, revert, 2)
+	assert.Len(t, apply, 3)
+	assert.Equal(t, int64(3), int64(revert[0].Height()))
+	assert.Equal(t, int64(4), int64(apply[0].Height()))
+
+	changes := <-notifs
+	assert.Len(t, changes, 5)
+
+	keys, err := nc.ChainList(ctx, apply[0].Key(), 10)
+	assert.NoError(t, err)
+	assert.Len(t, keys, 5)
+	assert.Equal(t, apply[len(apply)-1].Parents().String(), keys[3].String())
+}
+
+func TestMockFullNodeParentMessages(t *testing.T) {
+	ctx := context.Background()
+	ms, node, walletName, from := setupMockMessageService(t)
+
+	msg := models.NewMessage()
+	msg.From = from
+	msg.To = from
+	msg.WalletName = walletName
+	msg.Meta = &types.MsgMeta{}
+	signedMsg, err := ToSignedMsg(ctx, ms.walletService, msg)
+	assert.NoError(t, err)
+	_, err = node.MpoolPush(ctx, &signedMsg)
+	assert.NoError(t, err)
+
+	packTs, err := node.Mine(ctx)
+	assert.NoError(t, err)
+	lookup, err := node.StateSearchMsg(ctx, signedMsg.Cid())
+	assert.NoError(t, err)
+	assert.Nil(t, lookup)
+	execTs, err := node.MineEmpty(ctx)
+	assert.NoError(t, err)
+
+	// the messages of a tipset are the parent messages of its child
+	msgs, err := node.ChainGetParentMessages(ctx, packTs.At(0).Cid())
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 0)
+	msgs, err = node.ChainGetParentMessages(ctx, execTs.At(0).Cid())
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 1)
+	assert.Equal(t, signedMsg.Message.Cid(), msgs[0].Cid)
+	receipts, err := node.ChainGetParentReceipts(ctx, execTs.At(0).Cid())
+	assert.NoError(t, err)
+	assert.Len(t, receipts, 1)
+	blockReceipts, err := node.ChainGetReceipts(ctx, execTs.At(0).ParentMessageReceipts)
+	assert.NoError(t, err)
+	assert.Equal(t, *receipts[0], blockReceipts[0])
+
+	lookup, err = node.StateSearchMsg(ctx, signedMsg.Cid())
+	assert.NoError(t, err)
+	assert.Equal(t, execTs.Key(), lookup.TipSet)
+	assert.Equal(t, execTs.Height(), lookup.Height)
+}
+
+func TestPushMessageEndToEnd(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ms, node, walletName, from := setupMockMessageService(t)
+
+	msg := models.NewMessage()
+	msg.From = from
+	msg.WalletName = walletName
+	msg.State = types.UnFillMsg
+	msg.Meta = &types.MsgMeta{}
+	assert.NoError(t, ms.PushMessage(ctx, msg))
+
+	// select and sign
+	head, err := node.ChainHead(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, ms.pushMessageToPool(ctx, head))
+
+	filledMsg, err := ms.GetMessageByUid(ctx, msg.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, types.FillMsg, filledMsg.State)
+	assert.NotNil(t, filledMsg.SignedCid)
+	assert.Eventually(t, func() bool {
+		pending, _ := node.MpoolPending(ctx, venusTypes.EmptyTSK)
+		return len(pending) == 1 && pending[0].Cid() == *filledMsg.SignedCid
+	}, time.Second*5, time.Millisecond*100)
+
+	// include, the message is not on chain until the tipset packed it is executed
+	ts, err := node.Mine(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, ms.doRefreshMessageState(ctx, &headChan{apply: []*venusTypes.TipSet{ts}}))
+	packedMsg, err := ms.GetMessageByUid(ctx, msg.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, types.FillMsg, packedMsg.State)
+
+	execTs, err := node.MineEmpty(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, ms.doRefreshMessageState(ctx, &headChan{apply: []*venusTypes.TipSet{execTs}}))
+
+	onChainMsg, err := ms.GetMessageByUid(ctx, msg.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, types.OnChainMsg, onChainMsg.State)
+	assert.Equal(t, int64(execTs.Height()), onChainMsg.Height)
+	assert.NotNil(t, onChainMsg.Receipt)
+
+	// revert the tipset packed the message
+	revert, apply, err := node.Reorg(ctx, 2)
+	assert.NoError(t, err)
+	assert.NoError(t, ms.doRefreshMessageState(ctx, &headChan{apply: apply, revert: revert}))
+
+	revertedMsg, err := ms.GetMessageByUid(ctx, msg.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, types.FillMsg, revertedMsg.State)
+	pending, err := node.MpoolPending(ctx, venusTypes.EmptyTSK)
+	assert.NoError(t, err)
+	assert.Len(t, pending, 1)
+}
+
+func TestMessageRevisionOfCache(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ms, node, walletName, from := setupMockMessageService(t)
+	msg := models.NewMessage()
+	msg.From = from
+	msg.WalletName = walletName
+	msg.State = types.UnFillMsg
+	msg.Meta = &types.MsgMeta{}
+	assert.NoError(t, ms.PushMessage(ctx, msg))
+
+	assertRevision := func(expect int64) {
+		dbMsg, err := ms.repo.MessageRepo().GetMessageByUid(msg.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, expect, dbMsg.Revision)
+		cached, ok := ms.messageState.GetMessage(msg.ID)
+		assert.True(t, ok)
+		assert.Equal(t, dbMsg.Revision, cached.Revision)
+		assert.Equal(t, dbMsg.State, cached.State)
+	}
+
+	head, err := node.ChainHead(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, ms.pushMessageToPool(ctx, head))
+	assertRevision(1)
+
+	_, err = ms.ReplaceMessage(ctx, msg.ID, false, "", 0, "100", "200")
+	assert.NoError(t, err)
+	assertRevision(2)
+
+	ts, err := node.Mine(ctx)
+	assert.NoError(t, err)
+	execTs, err := node.MineEmpty(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, ms.doRefreshMessageState(ctx, &headChan{apply: []*venusTypes.TipSet{execTs, ts}}))
+	assertRevision(3)
+
+	revert, apply, err := node.Reorg(ctx, 2)
+	assert.NoError(t, err)
+	assert.NoError(t, ms.doRefreshMessageState(ctx, &headChan{apply: apply, revert: revert}))
+	assertRevision(4)
+
+	// cache dropped by the update of api is loaded from db
+	_, err = ms.MarkBadMessage(ctx, msg.ID)
+	assert.NoError(t, err)
+	_, ok := ms.messageState.GetMessage(msg.ID)
+	assert.False(t, ok)
+	assert.NoError(t, ms.messageState.MutatorMessage(msg.ID, func(*types.Message) error {
+		panic("change is not applied to message loaded from db")
+	}))
+	assertRevision(5)
+}
+
+func setupMockMessageService(t *testing.T) (*MessageService, *MockFullNode, string, address.Address) {
+	return setupMockMessageServiceWithConfig(t, &config.WalletConfig{ScanInterval: 10})
+}
+
+// setupMockMessageServiceWithConfig is setupMockMessageService with the wallet config, which must not be changed after
+func setupMockMessageServiceWithConfig(t *testing.T, walletCfg *config.WalletConfig) (*MessageService, *MockFullNode, string, address.Address) {
+	ctx := context.Background()
+	log := logrus.New()
+	dir := t.TempDir()
+
+	db, err := memory.OpenMemory()
+	assert.NoError(t, err)
+
+	node, err := NewMockFullNode()
+	assert.NoError(t, err)
+	nc := node.NodeClient()
+
+	sps, err := NewSharedParamsService(db, log)
+	assert.NoError(t, err)
+	addressService := NewAddressService(db, log)
+	walletService, err := NewWalletService(db, log, nc, addressService, walletCfg, sps)
+	assert.NoError(t, err)
+
+	walletName := "mem"
+	memWallet := NewMemWallet()
+	walletService.addWallet(walletName, &WalletInfo{
+		walletCli:    memWallet,
+		cliClose:     func() {},
+		walletState:  types.Alive,
+		addressInfos: make(map[address.Address]*AddressInfo),
+	})
+	assert.NoError(t, walletService.ProcessWallet(ctx, walletName, memWallet))
+	addrs, err := memWallet.WalletList(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, addrs, 1)
+
+	msgState, err := NewMessageState(db, log, &config.MessageStateConfig{
+		BackTime:          60,
+		CleanupInterval:   3,
+		DefaultExpiration: 2,
+	})
+	assert.NoError(t, err)
+	ms, err := NewMessageService(db, nc, log, &config.MessageServiceConfig{
+		TipsetFilePath: filepath.Join(dir, "tipset.json"),
+	}, msgState, addressService, walletService, sps, NewNodeService(db, log))
+	assert.NoError(t, err)
+
+	return ms, node, walletName, addrs[0]
+}
@@ -15,13 +15,17 @@ func MakeServiceMap(msgService *MessageService,
 	walletService *WalletService,
 	addressService *AddressService,
 	sps *SharedParamsService,
-	nodeService *NodeService) ServiceMap {
+	nodeService *NodeService,
+	auditService *AuditService,
+	msigService *MultisigService) ServiceMap {
 	sMap := make(ServiceMap)
 	sMap[reflect.TypeOf(msgService)] = msgService
 	sMap[reflect.TypeOf(walletService)] = walletService
 	sMap[reflect.TypeOf(addressService)] = addressService
 	sMap[reflect.TypeOf(sps)] = sps
 	sMap[reflect.TypeOf(nodeService)] = nodeService
+	sMap[reflect.TypeOf(auditService)] = auditService
+	sMap[reflect.TypeOf(msigService)] = msigService
 	return sMap
 }
 
@@ -32,11 +36,13 @@ func MessagerService() fx.Option {
 		fx.Provide(NewAddressService),
 		fx.Provide(NewSharedParamsService),
 		fx.Provide(NewNodeService),
+		fx.Provide(NewAuditService),
+		fx.Provide(NewMultisigService),
 		fx.Provide(MakeServiceMap),
 	)
 }
 
-func StartNodeEvents(lc fx.Lifecycle, client *NodeClient, msgService *MessageService, log *logrus.Logger) *NodeEvents {
+func StartNodeEvents(lc fx.Lifecycle, client *NodeClient, msgService *MessageService, msigService *MultisigService, log *logrus.Logger) *NodeEvents {
 	nd := &NodeEvents{
 		client:     client,
 		log:        log,
@@ -47,9 +53,18 @@ func StartNodeEvents(lc fx.Lifecycle, client *NodeClient, msgService *MessageSer
 		OnStart: func(ctx context.Context) error {
 			if !msgService.cfg.SkipPushMessage {
 				go msgService.StartPushMessage(ctx)
+				if msgService.cfg.MpoolCheckInterval > 0 {
+					go msgService.StartCheckMpool(ctx)
+				}
 			} else {
 				msgService.log.Infof("skip push message")
 			}
+			if msgService.cfg.Archive.Interval > 0 {
+				go msgService.StartArchive(ctx)
+			}
+			if msgService.cfg.MultisigTrackInterval > 0 {
+				go msigService.StartTrackProposal(ctx)
+			}
 			go func() {
 				for {
 					if err := nd.listenHeadChangesOnce(ctx); err != nil {
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	venusTypes "github.com/filecoin-project/venus/pkg/types"
+	"github.com/ipfs/go-cid"
+
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+const defaultNodeName = "default"
+
+type mpoolConflictCache struct {
+	cache map[string]*types.MpoolConflict // message id as key
+	l     sync.Mutex
+}
+
+func (mc *mpoolConflictCache) Reset(conflicts map[string]*types.MpoolConflict) {
+	mc.l.Lock()
+	defer mc.l.Unlock()
+	mc.cache = conflicts
+}
+
+func (mc *mpoolConflictCache) List() []*types.MpoolConflict {
+	mc.l.Lock()
+	defer mc.l.Unlock()
+	list := make([]*types.MpoolConflict, 0, len(mc.cache))
+	for _, c := range mc.cache {
+		list = append(list, c)
+	}
+
+	return list
+}
+
+type fromNonce struct {
+	from  address.Address
+	nonce uint64
+}
+
+func (ms *MessageService) StartCheckMpool(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(ms.cfg.MpoolCheckInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			start := time.Now()
+			pushed, err := ms.checkMpool(ctx)
+			if err != nil {
+				ms.log.Errorf("check mpool error %v", err)
+				continue
+			}
+			ms.log.Infof("end check mpool, rebroadcast %d message spent %d ms", pushed, time.Since(start).Milliseconds())
+		case <-ctx.Done():
+			ms.log.Infof("Stop check mpool")
+			return
+		}
+	}
+}
+
+// checkMpool check whether the filled messages are in the mpool of each node, only the missing messages will be rebroadcast,
+// message which has a different message with the same from and nonce in mpool is recorded as conflict
+func (ms *MessageService) checkMpool(ctx context.Context) (int, error) {
+	filledMsgs, err := ms.listPendingFilledMessage(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if len(filledMsgs) == 0 {
+		ms.mpoolConflicts.Reset(make(map[string]*types.MpoolConflict))
+		return 0, nil
+	}
+
+	// light node may not serve mpool query
+	dbNodes := ms.connectNodes(true)
+	defer func() {
+		for _, n := range dbNodes {
+			n.close()
+		}
+	}()
+	nodes := append([]nodeClient{{name: defaultNodeName, cli: ms.nodeClient}}, dbNodes...)
+
+	pushed := 0
+	conflicts := make(map[string]*types.MpoolConflict)
+	for _, node := range nodes {
+		pending, err := node.cli.MpoolPending(ctx, venusTypes.EmptyTSK)
+		if err != nil {
+			ms.log.Warnf("get pending message from node %s %v", node.name, err)
+			continue
+		}
+		inPool := make(map[cid.Cid]struct{}, len(pending))
+		poolNonce := make(map[fromNonce]cid.Cid, len(pending))
+		for _, msg := range pending {
+			c := msg.Cid()
+			inPool[c] = struct{}{}
+			poolNonce[fromNonce{from: msg.Message.From, nonce: msg.Message.Nonce}] = c
+		}
+
+		for _, msg := range filledMsgs {
+			if _, ok := inPool[*msg.SignedCid]; ok {
+				continue
+			}
+			if c, ok := poolNonce[fromNonce{from: msg.From, nonce: msg.Nonce}]; ok {
+				ms.log.Warnf("message %s conflict with %s in mpool of node %s, from: %s, nonce: %d", msg.ID, c, node.name, msg.From, msg.Nonce)
+				conflicts[msg.ID] = &types.MpoolConflict{
+					ID:        msg.ID,
+					From:      msg.From,
+					Nonce:     msg.Nonce,
+					SignedCid: *msg.SignedCid,
+					PoolCid:   c,
+					Node:      node.name,
+					FoundAt:   time.Now(),
+				}
+				continue
+			}
+
+			signedMsg := &venusTypes.SignedMessage{
+				Message:   msg.UnsignedMessage,
+				Signature: *msg.Signature,
+			}
+			if _, err := node.cli.MpoolPush(ctx, signedMsg); err != nil &&
+				!strings.Contains(err.Error(), errAlreadyInMpool.Error()) {
+				ms.log.Errorf("rebroadcast message %s to node %s from: %s, nonce: %d, error %v", msg.ID, node.name, msg.From, msg.Nonce, err)
+				continue
+			}
+			pushed++
+		}
+	}
+	ms.mpoolConflicts.Reset(conflicts)
+
+	return pushed, nil
+}
+
+// listPendingFilledMessage list the filled messages whose nonce not smaller than the nonce of actor on chain
+func (ms *MessageService) listPendingFilledMessage(ctx context.Context) ([]*types.Message, error) {
+	var msgs []*types.Message
+	for addr := range ms.walletService.AllAddresses() {
+		filledMsgs, err := ms.repo.MessageRepo().ListFilledMessageByAddress(addr)
+		if err != nil {
+			return nil, err
+		}
+		if len(filledMsgs) == 0 {
+			continue
+		}
+		actor, err := ms.nodeClient.StateGetActor(ctx, addr, venusTypes.EmptyTSK)
+		if err != nil {
+			ms.log.Warnf("get actor of address %s %v", addr, err)
+			continue
+		}
+		for _, msg := range filledMsgs {
+			if msg.Nonce < actor.Nonce || msg.SignedCid == nil || msg.Signature == nil {
+				continue
+			}
+			msgs = append(msgs, msg)
+		}
+	}
+
+	return msgs, nil
+}
+
+func (ms *MessageService) ListMpoolConflict(ctx context.Context) ([]*types.MpoolConflict, error) {
+	conflicts := ms.mpoolConflicts.List()
+	sort.Slice(conflicts, func(i, j int) bool {
+		if conflicts[i].From == conflicts[j].From {
+			return conflicts[i].Nonce < conflicts[j].Nonce
+		}
+		return conflicts[i].From.String() < conflicts[j].From.String()
+	})
+
+	return conflicts, nil
+}
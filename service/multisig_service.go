@@ -0,0 +1,375 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	multisig3 "github.com/filecoin-project/specs-actors/v3/actors/builtin/multisig"
+	"github.com/filecoin-project/venus/pkg/specactors"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/multisig"
+	venusTypes "github.com/filecoin-project/venus/pkg/types"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus-messager/config"
+	"github.com/filecoin-project/venus-messager/models/repo"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+// MultisigService push the messages of multisig proposals and track the proposals by the receipts of the messages
+type MultisigService struct {
+	repo       repo.Repo
+	log        *logrus.Logger
+	cfg        *config.MessageServiceConfig
+	msgService *MessageService
+
+	// serialize the changes of proposals
+	lk sync.Mutex
+}
+
+func NewMultisigService(repo repo.Repo, logger *logrus.Logger, cfg *config.MessageServiceConfig, msgService *MessageService) *MultisigService {
+	return &MultisigService{
+		repo:       repo,
+		log:        logger,
+		cfg:        cfg,
+		msgService: msgService,
+	}
+}
+
+// PushMultisigPropose push the propose message and return the id of the message which is also the id of the proposal
+func (msigService *MultisigService) PushMultisigPropose(ctx context.Context, params *types.MsigProposeParams) (string, error) {
+	value := params.Value
+	if value.Int == nil {
+		value = big.Zero()
+	}
+	unsignedMsg, err := multisig.Message(specactors.Version3, params.Proposer).Propose(params.Msig, params.To, value, params.Method, params.Params)
+	if err != nil {
+		return "", xerrors.Errorf("build propose message: %w", err)
+	}
+
+	msigService.lk.Lock()
+	defer msigService.lk.Unlock()
+
+	// the proposal is saved before the message is pushed, so a pushed message always has its proposal
+	msg, err := msigService.newMessage(ctx, unsignedMsg, params.WalletName, params.Meta)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	proposal := &types.MsigProposal{
+		ID:         msg.ID,
+		Msig:       params.Msig,
+		TxID:       -1,
+		Proposer:   msg.From,
+		WalletName: params.WalletName,
+		To:         params.To,
+		Value:      value,
+		Method:     params.Method,
+		Params:     params.Params,
+		State:      types.MsigProposing,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := msigService.repo.MultisigRepo().SaveProposal(proposal); err != nil {
+		return "", xerrors.Errorf("save proposal: %w", err)
+	}
+	if err := msigService.msgService.PushMessage(ctx, msg); err != nil {
+		if delErr := msigService.repo.MultisigRepo().DeleteProposal(proposal.ID); delErr != nil {
+			msigService.log.Errorf("delete proposal %s of message not pushed failed %v", proposal.ID, delErr)
+		}
+		return "", err
+	}
+	msigService.log.Infof("propose %s to multisig %s by %s", msg.ID, params.Msig, msg.From)
+
+	return msg.ID, nil
+}
+
+// PushMultisigApprove push the approve message of signer and return the id of the message
+func (msigService *MultisigService) PushMultisigApprove(ctx context.Context, id string, signer address.Address, walletName string, meta *types.MsgMeta) (string, error) {
+	msigService.lk.Lock()
+	defer msigService.lk.Unlock()
+
+	proposal, err := msigService.pendingProposal(id)
+	if err != nil {
+		return "", err
+	}
+	if signer == proposal.Proposer {
+		return "", xerrors.Errorf("proposal %s is approved by the proposer %s", id, signer)
+	}
+	for _, approval := range proposal.Approvals {
+		if approval.Signer == signer && approval.State != types.MsigApprovalFailed {
+			return "", xerrors.Errorf("proposal %s is approved by %s in message %s", id, signer, approval.MsgID)
+		}
+	}
+
+	unsignedMsg, err := multisig.Message(specactors.Version3, signer).Approve(proposal.Msig, uint64(proposal.TxID), nil)
+	if err != nil {
+		return "", xerrors.Errorf("build approve message: %w", err)
+	}
+	msg, err := msigService.newMessage(ctx, unsignedMsg, walletName, meta)
+	if err != nil {
+		return "", err
+	}
+	approvals := proposal.Approvals
+	proposal.Approvals = append(proposal.Approvals, &types.MsigApproval{
+		Signer:    msg.From,
+		MsgID:     msg.ID,
+		State:     types.MsigApprovalPending,
+		CreatedAt: time.Now(),
+	})
+	err = msigService.pushWithProposal(ctx, msg, proposal, func() {
+		proposal.Approvals = approvals
+	})
+	if err != nil {
+		return "", err
+	}
+	msigService.log.Infof("approve proposal %s by %s in message %s", id, msg.From, msg.ID)
+
+	return msg.ID, nil
+}
+
+// PushMultisigCancel push the cancel message of proposer and return the id of the message
+func (msigService *MultisigService) PushMultisigCancel(ctx context.Context, id string, meta *types.MsgMeta) (string, error) {
+	msigService.lk.Lock()
+	defer msigService.lk.Unlock()
+
+	proposal, err := msigService.pendingProposal(id)
+	if err != nil {
+		return "", err
+	}
+	if len(proposal.CancelMsgID) > 0 {
+		return "", xerrors.Errorf("proposal %s is being canceled by message %s", id, proposal.CancelMsgID)
+	}
+
+	unsignedMsg, err := multisig.Message(specactors.Version3, proposal.Proposer).Cancel(proposal.Msig, uint64(proposal.TxID), nil)
+	if err != nil {
+		return "", xerrors.Errorf("build cancel message: %w", err)
+	}
+	msg, err := msigService.newMessage(ctx, unsignedMsg, proposal.WalletName, meta)
+	if err != nil {
+		return "", err
+	}
+	proposal.CancelMsgID = msg.ID
+	err = msigService.pushWithProposal(ctx, msg, proposal, func() {
+		proposal.CancelMsgID = ""
+	})
+	if err != nil {
+		return "", err
+	}
+	msigService.log.Infof("cancel proposal %s in message %s", id, msg.ID)
+
+	return msg.ID, nil
+}
+
+func (msigService *MultisigService) GetMultisigProposal(ctx context.Context, id string) (*types.MsigProposal, error) {
+	return msigService.repo.MultisigRepo().GetProposal(id)
+}
+
+// ListMultisigProposal list proposals of msig from the latest, address.Undef means all multisig
+func (msigService *MultisigService) ListMultisigProposal(ctx context.Context, msig address.Address) ([]*types.MsigProposal, error) {
+	return msigService.repo.MultisigRepo().ListProposal(msig)
+}
+
+func (msigService *MultisigService) pendingProposal(id string) (*types.MsigProposal, error) {
+	proposal, err := msigService.repo.MultisigRepo().GetProposal(id)
+	if err != nil {
+		return nil, xerrors.Errorf("get proposal %s: %w", id, err)
+	}
+	if proposal.State != types.MsigPending {
+		return nil, xerrors.Errorf("proposal %s is %s, only pending proposal can be approved or canceled", id, types.MsigProposalStateToString(proposal.State))
+	}
+	return proposal, nil
+}
+
+// newMessage build the message to be pushed, the id address of signer is resolved to the key address recorded in proposal
+func (msigService *MultisigService) newMessage(ctx context.Context, unsignedMsg *venusTypes.UnsignedMessage, walletName string, meta *types.MsgMeta) (*types.Message, error) {
+	if meta == nil {
+		meta = &types.MsgMeta{}
+	}
+	if unsignedMsg.From.Protocol() == address.ID {
+		from, err := msigService.msgService.nodeClient.StateAccountKey(ctx, unsignedMsg.From, venusTypes.EmptyTSK)
+		if err != nil {
+			return nil, xerrors.Errorf("getting key address: %w", err)
+		}
+		unsignedMsg.From = from
+	}
+	return &types.Message{
+		ID:              types.NewUUID().String(),
+		UnsignedMessage: *unsignedMsg,
+		Meta:            meta,
+		State:           types.UnFillMsg,
+		WalletName:      walletName,
+	}, nil
+}
+
+// pushWithProposal save the proposal referring the message before the message is pushed,
+// the proposal is restored by revert and saved again if the push fails
+func (msigService *MultisigService) pushWithProposal(ctx context.Context, msg *types.Message, proposal *types.MsigProposal, revert func()) error {
+	if err := msigService.saveProposal(proposal); err != nil {
+		return xerrors.Errorf("save proposal: %w", err)
+	}
+	if err := msigService.msgService.PushMessage(ctx, msg); err != nil {
+		revert()
+		if saveErr := msigService.saveProposal(proposal); saveErr != nil {
+			msigService.log.Errorf("restore proposal %s of message not pushed failed %v", proposal.ID, saveErr)
+		}
+		return err
+	}
+	return nil
+}
+
+func (msigService *MultisigService) saveProposal(proposal *types.MsigProposal) error {
+	proposal.UpdatedAt = time.Now()
+	return msigService.repo.MultisigRepo().SaveProposal(proposal)
+}
+
+func (msigService *MultisigService) StartTrackProposal(ctx context.Context) {
+	tm := time.NewTicker(time.Duration(msigService.cfg.MultisigTrackInterval) * time.Second)
+	defer tm.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			msigService.log.Infof("stop track multisig proposal")
+			return
+		case <-tm.C:
+			if err := msigService.refreshProposals(ctx); err != nil {
+				msigService.log.Errorf("refresh multisig proposals failed %v", err)
+			}
+		}
+	}
+}
+
+// refreshProposals update the unfinished proposals by the messages finished since last refresh
+func (msigService *MultisigService) refreshProposals(ctx context.Context) error {
+	msigService.lk.Lock()
+	defer msigService.lk.Unlock()
+
+	proposals, err := msigService.repo.MultisigRepo().ListUnfinishedProposal()
+	if err != nil {
+		return err
+	}
+	for _, proposal := range proposals {
+		changed, err := msigService.refreshProposal(ctx, proposal)
+		if err != nil {
+			msigService.log.Errorf("refresh proposal %s failed %v", proposal.ID, err)
+			continue
+		}
+		if !changed {
+			continue
+		}
+		if err := msigService.saveProposal(proposal); err != nil {
+			return xerrors.Errorf("save proposal %s: %w", proposal.ID, err)
+		}
+		msigService.log.Infof("proposal %s of multisig %s is %s", proposal.ID, proposal.Msig, types.MsigProposalStateToString(proposal.State))
+	}
+	return nil
+}
+
+func (msigService *MultisigService) refreshProposal(ctx context.Context, proposal *types.MsigProposal) (bool, error) {
+	if proposal.State == types.MsigProposing {
+		receipt, finished, err := msigService.messageReceipt(ctx, proposal.ID)
+		if err != nil || !finished {
+			return false, err
+		}
+		if receipt == nil || !receipt.ExitCode.IsSuccess() {
+			proposal.State = types.MsigFailed
+			proposal.Error = messageError("propose", proposal.ID, receipt)
+			return true, nil
+		}
+		var ret multisig.ProposeReturn
+		if err := ret.UnmarshalCBOR(bytes.NewReader(receipt.ReturnValue)); err != nil {
+			return false, xerrors.Errorf("decode propose return: %w", err)
+		}
+		proposal.TxID = int64(ret.TxnID)
+		proposal.State = types.MsigPending
+		if ret.Applied {
+			applyProposal(proposal, ret.Code)
+		}
+		return true, nil
+	}
+
+	changed := false
+	for _, approval := range proposal.Approvals {
+		if approval.State != types.MsigApprovalPending {
+			continue
+		}
+		receipt, finished, err := msigService.messageReceipt(ctx, approval.MsgID)
+		if err != nil {
+			return changed, err
+		}
+		if !finished {
+			continue
+		}
+		changed = true
+		if receipt == nil || !receipt.ExitCode.IsSuccess() {
+			approval.State = types.MsigApprovalFailed
+			proposal.Error = messageError("approve", approval.MsgID, receipt)
+			continue
+		}
+		approval.State = types.MsigApprovalConfirmed
+		var ret multisig3.ApproveReturn
+		if err := ret.UnmarshalCBOR(bytes.NewReader(receipt.ReturnValue)); err != nil {
+			return changed, xerrors.Errorf("decode approve return: %w", err)
+		}
+		if ret.Applied {
+			applyProposal(proposal, ret.Code)
+		}
+	}
+
+	if len(proposal.CancelMsgID) > 0 && proposal.State == types.MsigPending {
+		receipt, finished, err := msigService.messageReceipt(ctx, proposal.CancelMsgID)
+		if err != nil || !finished {
+			return changed, err
+		}
+		if receipt == nil || !receipt.ExitCode.IsSuccess() {
+			// cancel can be pushed again
+			proposal.Error = messageError("cancel", proposal.CancelMsgID, receipt)
+			proposal.CancelMsgID = ""
+			return true, nil
+		}
+		proposal.State = types.MsigCanceled
+		changed = true
+	}
+
+	return changed, nil
+}
+
+// messageReceipt return the receipt of message if it is on chain, finished is false if it is still waiting to be packed
+func (msigService *MultisigService) messageReceipt(ctx context.Context, id string) (*venusTypes.MessageReceipt, bool, error) {
+	msg, err := msigService.msgService.GetPrimaryMessageByUid(ctx, id)
+	if err != nil {
+		return nil, false, err
+	}
+	switch msg.State {
+	case types.OnChainMsg:
+		return msg.Receipt, msg.Receipt != nil, nil
+	case types.FailedMsg, types.ReplacedMsg:
+		return nil, true, nil
+	default:
+		return nil, false, nil
+	}
+}
+
+// applyProposal mark the proposal applied, code is the exit code of the transaction executed by multisig
+func applyProposal(proposal *types.MsigProposal, code exitcode.ExitCode) {
+	if code.IsSuccess() {
+		proposal.State = types.MsigApplied
+		return
+	}
+	proposal.State = types.MsigFailed
+	proposal.Error = fmt.Sprintf("transaction exit with %s", code)
+}
+
+func messageError(kind, id string, receipt *venusTypes.MessageReceipt) string {
+	if receipt == nil {
+		return fmt.Sprintf("%s message %s failed or replaced", kind, id)
+	}
+	return fmt.Sprintf("%s message %s exit with %s", kind, id, receipt.ExitCode)
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	multisig3 "github.com/filecoin-project/specs-actors/v3/actors/builtin/multisig"
+	"github.com/filecoin-project/venus/pkg/crypto"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/multisig"
+	venusTypes "github.com/filecoin-project/venus/pkg/types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus-messager/config"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+type cborMarshaler interface {
+	MarshalCBOR(w io.Writer) error
+}
+
+// landMessage put the message on chain with the receipt
+func landMessage(t *testing.T, ms *MessageService, id string, code exitcode.ExitCode, ret cborMarshaler) {
+	msg, err := ms.repo.MessageRepo().GetMessageByUid(id)
+	require.NoError(t, err)
+	receipt := &venusTypes.MessageReceipt{ExitCode: code}
+	if ret != nil {
+		buf := new(bytes.Buffer)
+		require.NoError(t, ret.MarshalCBOR(buf))
+		receipt.ReturnValue = buf.Bytes()
+	}
+	msg.State = types.OnChainMsg
+	msg.Receipt = receipt
+	require.NoError(t, ms.repo.MessageRepo().SaveMessage(msg))
+}
+
+func TestMultisigProposal(t *testing.T) {
+	ctx := context.Background()
+	ms, _, walletName, proposer := setupMockMessageService(t)
+	msigService := NewMultisigService(ms.repo, logrus.New(), &config.MessageServiceConfig{}, ms)
+
+	approverWallet := openTestLocalWallet(t, filepath.Join(t.TempDir(), "keystore"), "passphrase")
+	approver, err := approverWallet.WalletNew(ctx, crypto.SigTypeSecp256k1)
+	require.NoError(t, err)
+	addTestWallet(t, ms.walletService, "approver", approverWallet)
+
+	msig, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	to, err := address.NewIDAddress(1001)
+	require.NoError(t, err)
+	id, err := msigService.PushMultisigPropose(ctx, &types.MsigProposeParams{
+		Msig:       msig,
+		To:         to,
+		Value:      big.NewInt(100),
+		Proposer:   proposer,
+		WalletName: walletName,
+	})
+	require.NoError(t, err)
+
+	// proposal is not kept if the message isn't pushed
+	_, err = msigService.PushMultisigPropose(ctx, &types.MsigProposeParams{
+		Msig:       msig,
+		To:         to,
+		Proposer:   proposer,
+		WalletName: "not-exist",
+	})
+	assert.Error(t, err)
+	proposals, err := msigService.ListMultisigProposal(ctx, msig)
+	require.NoError(t, err)
+	assert.Len(t, proposals, 1)
+
+	// the params of propose message is encoded
+	msg, err := ms.repo.MessageRepo().GetMessageByUid(id)
+	require.NoError(t, err)
+	assert.Equal(t, msig, msg.To)
+	assert.Equal(t, multisig.Methods.Propose, msg.Method)
+	var params multisig.ProposeParams
+	require.NoError(t, params.UnmarshalCBOR(bytes.NewReader(msg.Params)))
+	assert.Equal(t, to, params.To)
+	assert.Equal(t, big.NewInt(100), params.Value)
+
+	proposal, err := msigService.GetMultisigProposal(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, types.MsigProposing, proposal.State)
+	assert.Equal(t, int64(-1), proposal.TxID)
+	_, err = msigService.PushMultisigApprove(ctx, id, approver, "approver", nil)
+	assert.Error(t, err, "transaction id is unknown")
+
+	// transaction id is read from the return of propose message
+	landMessage(t, ms, id, exitcode.Ok, &multisig.ProposeReturn{TxnID: 7})
+	require.NoError(t, msigService.refreshProposals(ctx))
+	proposal, err = msigService.GetMultisigProposal(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, types.MsigPending, proposal.State)
+	assert.Equal(t, int64(7), proposal.TxID)
+
+	_, err = msigService.PushMultisigApprove(ctx, id, proposer, walletName, nil)
+	assert.Error(t, err, "proposer approved already")
+	_, err = msigService.PushMultisigApprove(ctx, id, approver, walletName, nil)
+	assert.Error(t, err, "approver not in wallet")
+	proposal, err = msigService.GetMultisigProposal(ctx, id)
+	require.NoError(t, err)
+	assert.Empty(t, proposal.Approvals)
+	approveID, err := msigService.PushMultisigApprove(ctx, id, approver, "approver", nil)
+	require.NoError(t, err)
+	_, err = msigService.PushMultisigApprove(ctx, id, approver, "approver", nil)
+	assert.Error(t, err, "approved twice")
+	msg, err = ms.repo.MessageRepo().GetMessageByUid(approveID)
+	require.NoError(t, err)
+	assert.Equal(t, multisig.Methods.Approve, msg.Method)
+	assert.Equal(t, approver, msg.From)
+
+	landMessage(t, ms, approveID, exitcode.Ok, &multisig3.ApproveReturn{Applied: true, Code: exitcode.Ok})
+	require.NoError(t, msigService.refreshProposals(ctx))
+	proposal, err = msigService.GetMultisigProposal(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, types.MsigApplied, proposal.State)
+	require.Len(t, proposal.Approvals, 1)
+	assert.Equal(t, approver, proposal.Approvals[0].Signer)
+	assert.Equal(t, types.MsigApprovalConfirmed, proposal.Approvals[0].State)
+	_, err = msigService.PushMultisigCancel(ctx, id, nil)
+	assert.Error(t, err, "applied proposal can't be canceled")
+
+	// cancel a pending proposal, a failed cancel can be pushed again
+	id2, err := msigService.PushMultisigPropose(ctx, &types.MsigProposeParams{
+		Msig:       msig,
+		To:         to,
+		Proposer:   proposer,
+		WalletName: walletName,
+	})
+	require.NoError(t, err)
+	landMessage(t, ms, id2, exitcode.Ok, &multisig.ProposeReturn{TxnID: 8})
+	require.NoError(t, msigService.refreshProposals(ctx))
+
+	cancelID, err := msigService.PushMultisigCancel(ctx, id2, nil)
+	require.NoError(t, err)
+	landMessage(t, ms, cancelID, exitcode.ErrForbidden, nil)
+	require.NoError(t, msigService.refreshProposals(ctx))
+	proposal, err = msigService.GetMultisigProposal(ctx, id2)
+	require.NoError(t, err)
+	assert.Equal(t, types.MsigPending, proposal.State)
+	assert.Empty(t, proposal.CancelMsgID)
+	assert.Contains(t, proposal.Error, cancelID)
+
+	cancelID, err = msigService.PushMultisigCancel(ctx, id2, nil)
+	require.NoError(t, err)
+	landMessage(t, ms, cancelID, exitcode.Ok, nil)
+	require.NoError(t, msigService.refreshProposals(ctx))
+	proposal, err = msigService.GetMultisigProposal(ctx, id2)
+	require.NoError(t, err)
+	assert.Equal(t, types.MsigCanceled, proposal.State)
+
+	// failed propose message fails the proposal
+	id3, err := msigService.PushMultisigPropose(ctx, &types.MsigProposeParams{
+		Msig:       msig,
+		To:         to,
+		Proposer:   proposer,
+		WalletName: walletName,
+	})
+	require.NoError(t, err)
+	landMessage(t, ms, id3, exitcode.ErrInsufficientFunds, nil)
+	require.NoError(t, msigService.refreshProposals(ctx))
+	proposal, err = msigService.GetMultisigProposal(ctx, id3)
+	require.NoError(t, err)
+	assert.Equal(t, types.MsigFailed, proposal.State)
+
+	proposals, err = msigService.ListMultisigProposal(ctx, msig)
+	require.NoError(t, err)
+	require.Len(t, proposals, 3)
+	assert.Equal(t, id3, proposals[0].ID)
+}
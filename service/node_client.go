@@ -52,6 +52,7 @@ type NodeClient struct {
 
 	MpoolPush      func(context.Context, *types.SignedMessage) (cid.Cid, error)
 	MpoolBatchPush func(context.Context, []*types.SignedMessage) ([]cid.Cid, error)
+	MpoolPending   func(context.Context, types.TipSetKey) ([]*types.SignedMessage, error)
 }
 
 func NewNodeClient(ctx context.Context, cfg *config.NodeConfig) (*NodeClient, jsonrpc.ClientCloser, error) {
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-jsonrpc"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	chain2 "github.com/filecoin-project/venus/app/submodule/chain"
+	"github.com/filecoin-project/venus/app/submodule/network"
+	"github.com/filecoin-project/venus/pkg/chain"
+	venusTypes "github.com/filecoin-project/venus/pkg/types"
+	"github.com/ipfs/go-cid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/filecoin-project/venus-messager/config"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+// failoverNodeClient serve the state queries and gas estimation of configured node by the full nodes when it is
+// unreachable, head changes and mpool calls are still served by the configured node
+type failoverNodeClient struct {
+	primary     *NodeClient
+	nodeService *NodeService
+	log         *logrus.Logger
+	connect     func(node *types.Node) (*NodeClient, jsonrpc.ClientCloser, error)
+
+	lk sync.Mutex
+	// connected full nodes, they are kept open as the head changes are received from the connection
+	nodes map[types.UUID]*connectedNode
+}
+
+type connectedNode struct {
+	node  types.Node
+	cli   *NodeClient
+	close jsonrpc.ClientCloser
+}
+
+// NewFailoverNodeClient return a NodeClient calling the configured node first, state and gas calls failed with
+// transport error are retried on the full nodes in db in turn
+func NewFailoverNodeClient(primary *NodeClient, nodeService *NodeService, log *logrus.Logger) *NodeClient {
+	return newFailoverNodeClient(primary, nodeService, log, func(node *types.Node) (*NodeClient, jsonrpc.ClientCloser, error) {
+		return NewNodeClient(context.TODO(), &config.NodeConfig{Token: node.Token, Url: node.URL})
+	})
+}
+
+func newFailoverNodeClient(primary *NodeClient,
+	nodeService *NodeService,
+	log *logrus.Logger,
+	connect func(node *types.Node) (*NodeClient, jsonrpc.ClientCloser, error)) *NodeClient {
+	f := &failoverNodeClient{
+		primary:     primary,
+		nodeService: nodeService,
+		log:         log,
+		connect:     connect,
+		nodes:       make(map[types.UUID]*connectedNode),
+	}
+
+	res := *primary
+	res.StateAccountKey = func(ctx context.Context, addr address.Address, tsk venusTypes.TipSetKey) (keyAddr address.Address, err error) {
+		err = f.call("StateAccountKey", func(cli *NodeClient) (callErr error) {
+			keyAddr, callErr = cli.StateAccountKey(ctx, addr, tsk)
+			return
+		})
+		return
+	}
+	res.StateNetworkName = func(ctx context.Context) (name chain2.NetworkName, err error) {
+		err = f.call("StateNetworkName", func(cli *NodeClient) (callErr error) {
+			name, callErr = cli.StateNetworkName(ctx)
+			return
+		})
+		return
+	}
+	res.StateSearchMsg = func(ctx context.Context, c cid.Cid) (lookup *chain.MsgLookup, err error) {
+		err = f.call("StateSearchMsg", func(cli *NodeClient) (callErr error) {
+			lookup, callErr = cli.StateSearchMsg(ctx, c)
+			return
+		})
+		return
+	}
+	res.StateNetworkVersion = func(ctx context.Context, tsk venusTypes.TipSetKey) (version network.Version, err error) {
+		err = f.call("StateNetworkVersion", func(cli *NodeClient) (callErr error) {
+			version, callErr = cli.StateNetworkVersion(ctx, tsk)
+			return
+		})
+		return
+	}
+	res.StateGetActor = func(ctx context.Context, addr address.Address, tsk venusTypes.TipSetKey) (actor *venusTypes.Actor, err error) {
+		err = f.call("StateGetActor", func(cli *NodeClient) (callErr error) {
+			actor, callErr = cli.StateGetActor(ctx, addr, tsk)
+			return
+		})
+		return
+	}
+	res.StateSearchMsgLimited = func(ctx context.Context, c cid.Cid, limit abi.ChainEpoch) (lookup *chain.MsgLookup, err error) {
+		err = f.call("StateSearchMsgLimited", func(cli *NodeClient) (callErr error) {
+			lookup, callErr = cli.StateSearchMsgLimited(ctx, c, limit)
+			return
+		})
+		return
+	}
+	res.GasEstimateMessageGas = func(ctx context.Context, msg *venusTypes.UnsignedMessage, spec *venusTypes.MessageSendSpec, tsk venusTypes.TipSetKey) (estimated *venusTypes.UnsignedMessage, err error) {
+		err = f.call("GasEstimateMessageGas", func(cli *NodeClient) (callErr error) {
+			estimated, callErr = cli.GasEstimateMessageGas(ctx, msg, spec, tsk)
+			return
+		})
+		return
+	}
+	res.GasEstimateFeeCap = func(ctx context.Context, msg *venusTypes.UnsignedMessage, maxqueueblks int64, tsk venusTypes.TipSetKey) (feeCap big.Int, err error) {
+		err = f.call("GasEstimateFeeCap", func(cli *NodeClient) (callErr error) {
+			feeCap, callErr = cli.GasEstimateFeeCap(ctx, msg, maxqueueblks, tsk)
+			return
+		})
+		return
+	}
+	res.GasEstimateGasPremium = func(ctx context.Context, nblocksincl uint64, sender address.Address, gaslimit int64, tsk venusTypes.TipSetKey) (premium big.Int, err error) {
+		err = f.call("GasEstimateGasPremium", func(cli *NodeClient) (callErr error) {
+			premium, callErr = cli.GasEstimateGasPremium(ctx, nblocksincl, sender, gaslimit, tsk)
+			return
+		})
+		return
+	}
+	res.GasEstimateGasLimit = func(ctx context.Context, msg *venusTypes.UnsignedMessage, tsk venusTypes.TipSetKey) (gasLimit int64, err error) {
+		err = f.call("GasEstimateGasLimit", func(cli *NodeClient) (callErr error) {
+			gasLimit, callErr = cli.GasEstimateGasLimit(ctx, msg, tsk)
+			return
+		})
+		return
+	}
+	return &res
+}
+
+// call run the call by the configured node, retry it by the full nodes in turn if it fails with transport error
+func (f *failoverNodeClient) call(method string, call func(cli *NodeClient) error) error {
+	err := call(f.primary)
+	if !isTransportError(err) {
+		return err
+	}
+	for _, node := range f.fullNodes() {
+		nodeErr := call(node.cli)
+		if nodeErr == nil {
+			f.log.Warnf("node unavailable, call %s by full node %s: %v", method, node.node.Name, err)
+			return nil
+		}
+		f.log.Warnf("call %s by full node %s %v", method, node.node.Name, nodeErr)
+	}
+	return err
+}
+
+// fullNodes return the clients of full nodes in db, the connections of nodes removed or changed are closed
+func (f *failoverNodeClient) fullNodes() []*connectedNode {
+	nodeList, err := f.nodeService.ListNode(context.TODO())
+	if err != nil {
+		f.log.Errorf("list node %v", err)
+		return nil
+	}
+
+	f.lk.Lock()
+	defer f.lk.Unlock()
+	nodes := make([]*connectedNode, 0, len(nodeList))
+	listed := make(map[types.UUID]struct{}, len(nodeList))
+	for _, node := range nodeList {
+		if !node.IsFullNode() {
+			continue
+		}
+		listed[node.ID] = struct{}{}
+		if cn, ok := f.nodes[node.ID]; ok {
+			if cn.node.URL == node.URL && cn.node.Token == node.Token {
+				nodes = append(nodes, cn)
+				continue
+			}
+			cn.close()
+			delete(f.nodes, node.ID)
+		}
+		cli, closer, err := f.connect(node)
+		if err != nil {
+			f.log.Warnf("connect node(%s) %v", node.Name, err)
+			continue
+		}
+		cn := &connectedNode{node: *node, cli: cli, close: closer}
+		f.nodes[node.ID] = cn
+		nodes = append(nodes, cn)
+	}
+	for id, cn := range f.nodes {
+		if _, ok := listed[id]; !ok {
+			cn.close()
+			delete(f.nodes, id)
+		}
+	}
+	return nodes
+}
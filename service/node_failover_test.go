@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-jsonrpc"
+	venusTypes "github.com/filecoin-project/venus/pkg/types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus-messager/models/memory"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+func TestFailoverNodeClient(t *testing.T) {
+	ctx := context.Background()
+	db, err := memory.OpenMemory()
+	require.NoError(t, err)
+	nodeService := NewNodeService(db, logrus.New())
+
+	primaryNode, err := NewMockFullNode()
+	require.NoError(t, err)
+	fullNode, err := NewMockFullNode()
+	require.NoError(t, err)
+	idAddr, err := address.NewIDAddress(100)
+	require.NoError(t, err)
+	keyAddr, err := address.NewSecp256k1Address([]byte("key"))
+	require.NoError(t, err)
+	fullNode.SetAccountKey(idAddr, keyAddr)
+
+	mockNodes := map[string]*MockFullNode{"full": fullNode, "light": primaryNode}
+	connected := 0
+	connect := func(node *types.Node) (*NodeClient, jsonrpc.ClientCloser, error) {
+		connected++
+		return mockNodes[node.URL].NodeClient(), func() {}, nil
+	}
+	require.NoError(t, db.NodeRepo().CreateNode(&types.Node{ID: types.NewUUID(), Name: "light", URL: "light", Type: types.LightNode}))
+
+	primary := primaryNode.NodeClient()
+	var primaryErr error
+	primary.StateAccountKey = func(ctx context.Context, addr address.Address, tsk venusTypes.TipSetKey) (address.Address, error) {
+		if primaryErr != nil {
+			return address.Undef, primaryErr
+		}
+		return primaryNode.StateAccountKey(ctx, addr, tsk)
+	}
+	primary.ChainHead = func(ctx context.Context) (*venusTypes.TipSet, error) {
+		if primaryErr != nil {
+			return nil, primaryErr
+		}
+		return primaryNode.ChainHead(ctx)
+	}
+	cli := newFailoverNodeClient(primary, nodeService, logrus.New(), connect)
+
+	_, err = cli.StateAccountKey(ctx, idAddr, venusTypes.EmptyTSK)
+	assert.Error(t, err)
+	assert.False(t, isTransportError(err))
+
+	// light node is not used
+	primaryErr = &jsonrpc.ErrClient{}
+	_, err = cli.StateAccountKey(ctx, idAddr, venusTypes.EmptyTSK)
+	assert.True(t, isTransportError(err))
+
+	require.NoError(t, db.NodeRepo().CreateNode(&types.Node{ID: types.NewUUID(), Name: "full", URL: "full", Type: types.FullNode}))
+	res, err := cli.StateAccountKey(ctx, idAddr, venusTypes.EmptyTSK)
+	require.NoError(t, err)
+	assert.Equal(t, keyAddr, res)
+	// connection is reused
+	_, err = cli.StateAccountKey(ctx, idAddr, venusTypes.EmptyTSK)
+	require.NoError(t, err)
+	assert.Equal(t, 1, connected)
+
+	// head changes are only served by the configured node
+	_, err = cli.ChainHead(ctx)
+	assert.True(t, isTransportError(err))
+
+	// only transport error fails over
+	primaryErr = xerrors.New("actor not found")
+	_, err = cli.StateAccountKey(ctx, idAddr, venusTypes.EmptyTSK)
+	assert.EqualError(t, err, "actor not found")
+}
@@ -4,12 +4,15 @@ import (
 	"context"
 
 	"github.com/sirupsen/logrus"
+	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/venus-messager/config"
 	"github.com/filecoin-project/venus-messager/models/repo"
 	"github.com/filecoin-project/venus-messager/types"
 )
 
+const defaultNodeWeight = 1
+
 type NodeService struct {
 	repo repo.Repo
 	log  *logrus.Logger
@@ -20,16 +23,34 @@ func NewNodeService(repo repo.Repo, logger *logrus.Logger) *NodeService {
 }
 
 func (ns *NodeService) SaveNode(ctx context.Context, node *types.Node) (struct{}, error) {
+	if node.Type == 0 {
+		node.Type = types.FullNode
+	}
+	if node.Type != types.FullNode && node.Type != types.LightNode {
+		return struct{}{}, xerrors.Errorf("unknown node type %d", node.Type)
+	}
+	if node.Weight < 0 {
+		return struct{}{}, xerrors.Errorf("node weight %d must not be negative", node.Weight)
+	}
+	if node.Weight == 0 {
+		node.Weight = defaultNodeWeight
+	}
+
 	// try connect node
-	_, close, err := NewNodeClient(context.TODO(), &config.NodeConfig{Token: node.Token, Url: node.URL})
+	cli, close, err := NewNodeClient(context.TODO(), &config.NodeConfig{Token: node.Token, Url: node.URL})
 	if err != nil {
 		return struct{}{}, err
 	}
-	close()
+	defer close()
+	if node.IsFullNode() {
+		if _, err := cli.ChainHead(ctx); err != nil {
+			return struct{}{}, xerrors.Errorf("node %s can not serve state query, add it as light node: %v", node.Name, err)
+		}
+	}
 	if err := ns.repo.NodeRepo().SaveNode(node); err != nil {
 		return struct{}{}, err
 	}
-	ns.log.Infof("add node %s", node.Name)
+	ns.log.Infof("add %s node %s, weight %d", node.Type, node.Name, node.Weight)
 
 	return struct{}{}, nil
 }
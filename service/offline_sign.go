@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"sort"
+
+	venusTypes "github.com/filecoin-project/venus/pkg/types"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus-messager/models/repo"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+// ExportOfflineMessage list the messages of offline wallet waiting for signatures, ordered by address and nonce
+func (ms *MessageService) ExportOfflineMessage(ctx context.Context, walletName string) ([]*types.OfflineSignMessage, error) {
+	msgs, err := ms.repo.MessageRepo().ListOfflineSignMessageByWallet(walletName)
+	if err != nil {
+		return nil, err
+	}
+	sortByFromNonce(msgs)
+
+	result := make([]*types.OfflineSignMessage, 0, len(msgs))
+	for _, msg := range msgs {
+		result = append(result, &types.OfflineSignMessage{
+			ID:          msg.ID,
+			WalletName:  msg.WalletName,
+			Message:     msg.UnsignedMessage,
+			UnsignedCid: msg.UnsignedMessage.Cid(),
+		})
+	}
+	return result, nil
+}
+
+// ImportOfflineSignature verify the signatures of exported messages, messages signed by the key of from are saved and pushed,
+// the others keep waiting and the reasons are returned in the results
+func (ms *MessageService) ImportOfflineSignature(ctx context.Context, msgs []*types.OfflineSignMessage) ([]*types.OfflineImportResult, error) {
+	results := make([]*types.OfflineImportResult, 0, len(msgs))
+	toPush := make([]*venusTypes.SignedMessage, 0, len(msgs))
+	for _, m := range msgs {
+		result := &types.OfflineImportResult{ID: m.ID}
+		signedMsg, err := ms.importOfflineSignature(ctx, m)
+		if err != nil {
+			ms.log.Warnf("import signature of message %s failed %v", m.ID, err)
+			result.Error = err.Error()
+		} else {
+			signedCid := signedMsg.Cid()
+			result.SignedCid = &signedCid
+			toPush = append(toPush, signedMsg)
+		}
+		results = append(results, result)
+	}
+	if len(toPush) == 0 {
+		return results, nil
+	}
+
+	// push by nonce, otherwise node may reject the message with nonce gap
+	sort.Slice(toPush, func(i, j int) bool {
+		if toPush[i].Message.From != toPush[j].Message.From {
+			return toPush[i].Message.From.String() < toPush[j].Message.From.String()
+		}
+		return toPush[i].Message.Nonce < toPush[j].Message.Nonce
+	})
+	if _, err := ms.nodeClient.MpoolBatchPush(ctx, toPush); err != nil {
+		// the messages are saved as filled, they are pushed again by mpool checker or next selection
+		ms.log.Errorf("push imported messages failed %v", err)
+	}
+	ms.multiNodeToPush(ctx, toPush)
+
+	return results, nil
+}
+
+func (ms *MessageService) importOfflineSignature(ctx context.Context, m *types.OfflineSignMessage) (*venusTypes.SignedMessage, error) {
+	msg, err := ms.GetPrimaryMessageByUid(ctx, m.ID)
+	if err != nil {
+		return nil, err
+	}
+	if msg.State != types.OfflineSignMsg {
+		return nil, xerrors.Errorf("need %s got %s", types.MsgStateToString(types.OfflineSignMsg), types.MsgStateToString(msg.State))
+	}
+	// the signature is verified against the message in db, not the one in file
+	unsignedCid := msg.UnsignedMessage.Cid()
+	if m.UnsignedCid.Defined() && m.UnsignedCid != unsignedCid {
+		return nil, xerrors.Errorf("message changed after exported, unsigned cid %s, expect %s", m.UnsignedCid, unsignedCid)
+	}
+	if err := ms.walletService.verifySignature(ctx, msg.From, unsignedCid.Bytes(), m.Signature); err != nil {
+		return nil, err
+	}
+
+	msg.UnsignedCid = &unsignedCid
+	msg.Signature = m.Signature
+	msg.SignerWallet = msg.WalletName
+	msg.State = types.FillMsg
+	signedMsg := &venusTypes.SignedMessage{
+		Message:   msg.UnsignedMessage,
+		Signature: *msg.Signature,
+	}
+	signedCid := signedMsg.Cid()
+	msg.SignedCid = &signedCid
+
+	if err := ms.repo.MessageRepo().SaveMessage(msg); err != nil {
+		if xerrors.Is(err, repo.ErrRevisionConflict) {
+			ms.messageState.DeleteMessage(msg.ID)
+		}
+		return nil, err
+	}
+	if err := ms.messageState.MutatorMessage(msg.ID, func(message *types.Message) error {
+		message.SignedCid = msg.SignedCid
+		message.UnsignedCid = msg.UnsignedCid
+		message.State = msg.State
+		message.Signature = msg.Signature
+		message.SignerWallet = msg.SignerWallet
+		message.Revision = msg.Revision
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return signedMsg, nil
+}
+
+func sortByFromNonce(msgs []*types.Message) {
+	sort.Slice(msgs, func(i, j int) bool {
+		if msgs[i].From != msgs[j].From {
+			return msgs[i].From.String() < msgs[j].From.String()
+		}
+		return msgs[i].Nonce < msgs[j].Nonce
+	})
+}
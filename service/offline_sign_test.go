@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/venus-wallet/core"
+	"github.com/filecoin-project/venus/pkg/crypto"
+	venusTypes "github.com/filecoin-project/venus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus-messager/models"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+func TestOfflineSign(t *testing.T) {
+	ctx := context.Background()
+	ms, node, _, _ := setupMockMessageService(t)
+
+	// keys are kept by the keystore on the air-gapped machine
+	keystore := openTestLocalWallet(t, filepath.Join(t.TempDir(), "keystore"), "passphrase")
+	cold, err := keystore.WalletNew(ctx, crypto.SigTypeSecp256k1)
+	require.NoError(t, err)
+	other, err := keystore.WalletNew(ctx, crypto.SigTypeSecp256k1)
+	require.NoError(t, err)
+	offlineWallet, err := NewOfflineWallet(cold.String())
+	require.NoError(t, err)
+	addTestWallet(t, ms.walletService, "offline", offlineWallet)
+	assert.True(t, ms.walletService.IsOfflineWallet("offline"))
+
+	ids := make([]string, 0, 2)
+	for i := 0; i < 2; i++ {
+		msg := models.NewMessage()
+		msg.From = cold
+		msg.WalletName = "offline"
+		msg.State = types.UnFillMsg
+		msg.Meta = &types.MsgMeta{}
+		require.NoError(t, ms.PushMessage(ctx, msg))
+		ids = append(ids, msg.ID)
+	}
+
+	// nonce and gas are assigned, the messages wait for signatures
+	head, err := node.ChainHead(ctx)
+	require.NoError(t, err)
+	require.NoError(t, ms.pushMessageToPool(ctx, head))
+	for i, id := range ids {
+		msg, err := ms.GetMessageByUid(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, types.OfflineSignMsg, msg.State)
+		assert.Equal(t, uint64(i), msg.Nonce)
+		assert.NotZero(t, msg.GasLimit)
+		assert.Nil(t, msg.Signature)
+	}
+	pending, err := node.MpoolPending(ctx, venusTypes.EmptyTSK)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	exported, err := ms.ExportOfflineMessage(ctx, "offline")
+	require.NoError(t, err)
+	require.Len(t, exported, 2)
+	assert.Equal(t, ids[0], exported[0].ID)
+	assert.Equal(t, exported[0].Message.Cid(), exported[0].UnsignedCid)
+
+	signBy := func(m *types.OfflineSignMessage, addr address.Address) {
+		sig, err := keystore.WalletSign(ctx, addr, m.UnsignedCid.Bytes(), core.MsgMeta{})
+		require.NoError(t, err)
+		m.Signature = sig
+	}
+	signBy(exported[0], cold)
+	signBy(exported[1], other)
+
+	// the message signed by wrong key keeps waiting
+	results, err := ms.ImportOfflineSignature(ctx, exported)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Empty(t, results[0].Error)
+	assert.Contains(t, results[1].Error, ErrInvalidSignature.Error())
+	msg, err := ms.GetMessageByUid(ctx, ids[0])
+	require.NoError(t, err)
+	assert.Equal(t, types.FillMsg, msg.State)
+	assert.Equal(t, results[0].SignedCid, msg.SignedCid)
+	msg, err = ms.GetMessageByUid(ctx, ids[1])
+	require.NoError(t, err)
+	assert.Equal(t, types.OfflineSignMsg, msg.State)
+	pending, err = node.MpoolPending(ctx, venusTypes.EmptyTSK)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, *results[0].SignedCid, pending[0].Cid())
+
+	// imported message can't be imported again
+	results, err = ms.ImportOfflineSignature(ctx, exported[:1])
+	require.NoError(t, err)
+	assert.NotEmpty(t, results[0].Error)
+
+	signBy(exported[1], cold)
+	results, err = ms.ImportOfflineSignature(ctx, exported[1:])
+	require.NoError(t, err)
+	assert.Empty(t, results[0].Error)
+	pending, err = node.MpoolPending(ctx, venusTypes.EmptyTSK)
+	require.NoError(t, err)
+	assert.Len(t, pending, 2)
+
+	exported, err = ms.ExportOfflineMessage(ctx, "offline")
+	require.NoError(t, err)
+	assert.Empty(t, exported)
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/venus-wallet/core"
+	"github.com/filecoin-project/venus/pkg/crypto"
+	"golang.org/x/xerrors"
+)
+
+// OfflineWalletScheme is the url prefix of offline wallet, url `offline:<addr1>,<addr2>` registers addresses
+// whose keys are kept on an air-gapped machine, their messages are exported to sign and imported back
+const OfflineWalletScheme = "offline:"
+
+var ErrOfflineWallet = xerrors.New("offline wallet can't sign, export the message and import its signature")
+
+var _ IWalletClient = (*OfflineWallet)(nil)
+
+// OfflineWallet hold the addresses of offline wallet only
+type OfflineWallet struct {
+	addrs []address.Address
+}
+
+func IsOfflineWalletUrl(url string) bool {
+	return strings.HasPrefix(url, OfflineWalletScheme)
+}
+
+// NewOfflineWallet parse the comma separated addresses
+func NewOfflineWallet(addrList string) (*OfflineWallet, error) {
+	wallet := &OfflineWallet{}
+	for _, s := range strings.Split(addrList, ",") {
+		s = strings.TrimSpace(s)
+		if len(s) == 0 {
+			continue
+		}
+		addr, err := address.NewFromString(s)
+		if err != nil {
+			return nil, xerrors.Errorf("parse address %s of offline wallet %w", s, err)
+		}
+		if addr.Protocol() == address.ID {
+			return nil, xerrors.Errorf("address %s of offline wallet must be key address", s)
+		}
+		wallet.addrs = append(wallet.addrs, addr)
+	}
+	if len(wallet.addrs) == 0 {
+		return nil, xerrors.New("offline wallet has no address")
+	}
+	return wallet, nil
+}
+
+func (offlineWallet *OfflineWallet) WalletList(ctx context.Context) ([]address.Address, error) {
+	return append([]address.Address{}, offlineWallet.addrs...), nil
+}
+
+func (offlineWallet *OfflineWallet) WalletHas(ctx context.Context, addr address.Address) (bool, error) {
+	for _, a := range offlineWallet.addrs {
+		if a == addr {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (offlineWallet *OfflineWallet) WalletSign(ctx context.Context, addr address.Address, data []byte, meta core.MsgMeta) (*crypto.Signature, error) {
+	return nil, ErrOfflineWallet
+}
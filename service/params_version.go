@@ -0,0 +1,117 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"golang.org/x/xerrors"
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus-messager/models/repo"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+// saveParamsVersion save after as the next version of target, before is saved as the first version
+// if target has no version yet, so that the value before versioning can be rolled back to
+func saveParamsVersion(ctx context.Context, txRepo repo.TxRepo, target string, before, after interface{}, comment string) (*types.ParamsVersion, error) {
+	var latest int64
+	v, err := txRepo.ParamsVersionRepo().GetLatestParamsVersion(target)
+	if err == nil {
+		latest = v.Version
+	} else if !xerrors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	now := time.Now()
+	if latest == 0 && before != nil {
+		value, err := json.Marshal(before)
+		if err != nil {
+			return nil, err
+		}
+		latest++
+		if err := txRepo.ParamsVersionRepo().CreateParamsVersion(&types.ParamsVersion{
+			Target:    target,
+			Version:   latest,
+			Value:     value,
+			Comment:   "value before versioning",
+			CreatedAt: now,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	value, err := json.Marshal(after)
+	if err != nil {
+		return nil, err
+	}
+	version := &types.ParamsVersion{
+		Target:    target,
+		Version:   latest + 1,
+		Value:     value,
+		Author:    types.CallerFromContext(ctx).Name,
+		Comment:   comment,
+		CreatedAt: now,
+	}
+	if err := txRepo.ParamsVersionRepo().CreateParamsVersion(version); err != nil {
+		return nil, err
+	}
+	return version, nil
+}
+
+func listParamsVersion(r repo.Repo, target string, limit int) ([]*types.ParamsVersion, error) {
+	return r.ParamsVersionRepo().ListParamsVersion(target, limit)
+}
+
+// diffParamsVersion list the fields changed from version from to version to of target, ordered by field name
+func diffParamsVersion(r repo.Repo, target string, from, to int64) ([]*types.ParamsChange, error) {
+	fromVersion, err := r.ParamsVersionRepo().GetParamsVersion(target, from)
+	if err != nil {
+		return nil, xerrors.Errorf("get version %d of %s %w", from, target, err)
+	}
+	toVersion, err := r.ParamsVersionRepo().GetParamsVersion(target, to)
+	if err != nil {
+		return nil, xerrors.Errorf("get version %d of %s %w", to, target, err)
+	}
+
+	var fromFields, toFields map[string]json.RawMessage
+	if err := json.Unmarshal(fromVersion.Value, &fromFields); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(toVersion.Value, &toFields); err != nil {
+		return nil, err
+	}
+
+	fields := make([]string, 0, len(fromFields))
+	for field := range fromFields {
+		fields = append(fields, field)
+	}
+	for field := range toFields {
+		if _, ok := fromFields[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	changes := make([]*types.ParamsChange, 0)
+	for _, field := range fields {
+		fromValue, toValue := fromFields[field], toFields[field]
+		if !jsonEqual(fromValue, toValue) {
+			changes = append(changes, &types.ParamsChange{Field: field, From: fromValue, To: toValue})
+		}
+	}
+	return changes, nil
+}
+
+func jsonEqual(a, b json.RawMessage) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	var ca, cb bytes.Buffer
+	if json.Compact(&ca, a) != nil || json.Compact(&cb, b) != nil {
+		return bytes.Equal(a, b)
+	}
+	return bytes.Equal(ca.Bytes(), cb.Bytes())
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+func TestSharedParamsVersion(t *testing.T) {
+	ms, _, _, _ := setupMockMessageService(t)
+	ctx := context.WithValue(context.Background(), types.CallerKey{}, &types.Caller{Name: "admin", Perm: "admin"})
+
+	// default params are saved as the first version when service starts
+	versions, err := ms.sps.ListParamsVersion(ctx, types.SharedParamsTarget, 0)
+	assert.NoError(t, err)
+	require.Len(t, versions, 1)
+
+	params, err := ms.sps.GetSharedParams(ctx)
+	assert.NoError(t, err)
+	params.SelMsgNum = 30
+	params.MaxFeeCap = 100
+	_, err = ms.sps.SetSharedParamsWithComment(ctx, params, "raise select number")
+	assert.NoError(t, err)
+
+	versions, err = ms.sps.ListParamsVersion(ctx, types.SharedParamsTarget, 0)
+	assert.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, int64(2), versions[0].Version)
+	assert.Equal(t, "admin", versions[0].Author)
+	assert.Equal(t, "raise select number", versions[0].Comment)
+
+	changes, err := ms.sps.DiffParamsVersion(ctx, types.SharedParamsTarget, 1, 2)
+	assert.NoError(t, err)
+	require.Len(t, changes, 2)
+	assert.Equal(t, "maxFeeCap", changes[0].Field)
+	assert.Equal(t, "selMsgNum", changes[1].Field)
+	assert.JSONEq(t, "20", string(changes[1].From))
+	assert.JSONEq(t, "30", string(changes[1].To))
+	_, err = ms.sps.DiffParamsVersion(ctx, types.SharedParamsTarget, 1, 3)
+	assert.Error(t, err)
+
+	rolledBack, err := ms.sps.RollbackSharedParams(ctx, 1, "")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(20), rolledBack.SelMsgNum)
+	assert.Equal(t, uint64(20), ms.sps.GetParams().SelMsgNum)
+	assert.Equal(t, int64(0), ms.sps.GetParams().MaxFeeCap)
+
+	versions, err = ms.sps.ListParamsVersion(ctx, types.SharedParamsTarget, 1)
+	assert.NoError(t, err)
+	require.Len(t, versions, 1)
+	assert.Equal(t, int64(3), versions[0].Version)
+	assert.Equal(t, "rollback to version 1", versions[0].Comment)
+	changes, err = ms.sps.DiffParamsVersion(ctx, types.SharedParamsTarget, 1, 3)
+	assert.NoError(t, err)
+	assert.Len(t, changes, 0)
+}
+
+func TestSelectMsgNumVersion(t *testing.T) {
+	ms, _, walletName, addr := setupMockMessageService(t)
+	ctx := context.WithValue(context.Background(), types.CallerKey{}, &types.Caller{Name: "admin", Perm: "admin"})
+	target := types.SelMsgNumTarget(walletName, addr)
+
+	wa, err := ms.walletService.GetWalletAddress(ctx, walletName, addr)
+	assert.NoError(t, err)
+	origin := wa.SelMsgNum
+
+	_, err = ms.walletService.SetSelectMsgNumWithComment(ctx, walletName, addr, origin+5, "busy address")
+	assert.NoError(t, err)
+
+	// the value before versioning is saved as the first version
+	versions, err := ms.sps.ListParamsVersion(ctx, target, 0)
+	assert.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.JSONEq(t, fmt.Sprintf(`{"selMsgNum":%d}`, origin+5), string(versions[0].Value))
+	assert.Equal(t, "admin", versions[0].Author)
+	assert.JSONEq(t, fmt.Sprintf(`{"selMsgNum":%d}`, origin), string(versions[1].Value))
+
+	num, err := ms.walletService.RollbackSelectMsgNum(ctx, walletName, addr, 1, "revert")
+	assert.NoError(t, err)
+	assert.Equal(t, origin, num)
+	wa, err = ms.walletService.GetWalletAddress(ctx, walletName, addr)
+	assert.NoError(t, err)
+	assert.Equal(t, origin, wa.SelMsgNum)
+
+	versions, err = ms.sps.ListParamsVersion(ctx, target, 0)
+	assert.NoError(t, err)
+	require.Len(t, versions, 3)
+	assert.Equal(t, "revert", versions[0].Comment)
+
+	_, err = ms.walletService.RollbackSelectMsgNum(ctx, walletName, addr, 4, "")
+	assert.Error(t, err)
+}
@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"reflect"
 	"time"
 
@@ -74,14 +76,69 @@ func (sps *SharedParamsService) GetSharedParams(ctx context.Context) (*types.Sha
 }
 
 func (sps *SharedParamsService) SetSharedParams(ctx context.Context, params *types.SharedParams) (struct{}, error) {
-	id, err := sps.repo.SharedParamsRepo().SetSharedParams(ctx, params)
+	return sps.SetSharedParamsWithComment(ctx, params, "")
+}
+
+// SetSharedParamsWithComment set shared params and save them as a new version with the comment
+func (sps *SharedParamsService) SetSharedParamsWithComment(ctx context.Context, params *types.SharedParams, comment string) (struct{}, error) {
+	err := sps.repo.Transaction(func(txRepo repo.TxRepo) error {
+		// before is nil when shared params are set the first time
+		var before interface{}
+		if current, err := txRepo.SharedParamsRepo().GetSharedParams(ctx); err == nil {
+			before = current
+		} else if !xerrors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		id, err := txRepo.SharedParamsRepo().SetSharedParams(ctx, params)
+		if err != nil {
+			return err
+		}
+		params.ID = id
+		after, err := txRepo.SharedParamsRepo().GetSharedParams(ctx)
+		if err != nil {
+			return err
+		}
+		version, err := saveParamsVersion(ctx, txRepo, types.SharedParamsTarget, before, after, comment)
+		if err != nil {
+			return xerrors.Errorf("save shared params version %w", err)
+		}
+		sps.log.Infof("shared params changed to version %d by %s", version.Version, version.Author)
+		return nil
+	})
 	if err != nil {
 		return struct{}{}, err
 	}
-	params.ID = id
 	sps.SetParams(params)
 
-	return struct{}{}, err
+	return struct{}{}, nil
+}
+
+// ListParamsVersion list versions of shared params or select message number of address, the latest first
+func (sps *SharedParamsService) ListParamsVersion(ctx context.Context, target string, limit int) ([]*types.ParamsVersion, error) {
+	return listParamsVersion(sps.repo, target, limit)
+}
+
+func (sps *SharedParamsService) DiffParamsVersion(ctx context.Context, target string, from, to int64) ([]*types.ParamsChange, error) {
+	return diffParamsVersion(sps.repo, target, from, to)
+}
+
+// RollbackSharedParams set shared params to the value of version, the rollback is saved as a new version
+func (sps *SharedParamsService) RollbackSharedParams(ctx context.Context, version int64, comment string) (*types.SharedParams, error) {
+	v, err := sps.repo.ParamsVersionRepo().GetParamsVersion(types.SharedParamsTarget, version)
+	if err != nil {
+		return nil, xerrors.Errorf("get version %d of shared params %w", version, err)
+	}
+	params := &types.SharedParams{}
+	if err := json.Unmarshal(v.Value, params); err != nil {
+		return nil, err
+	}
+	if len(comment) == 0 {
+		comment = fmt.Sprintf("rollback to version %d", version)
+	}
+	if _, err := sps.SetSharedParamsWithComment(ctx, params, comment); err != nil {
+		return nil, err
+	}
+	return params, nil
 }
 
 func (sps *SharedParamsService) GetParams() *Params {
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"sort"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-jsonrpc"
+	"github.com/filecoin-project/venus-wallet/core"
+	"github.com/filecoin-project/venus/pkg/crypto"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+// WalletSign sign data by addr in the wallet walletName and return the name of wallet which signed it, the signature is verified against addr.
+// When the wallet can't be reached or returns an invalid signature and sign failover is enabled,
+// other alive wallets holding addr are tried in the configured order
+func (walletService *WalletService) WalletSign(ctx context.Context, walletName string, addr address.Address, data []byte, meta core.MsgMeta) (*crypto.Signature, string, error) {
+	addrInfo, ok := walletService.GetAddressInfo(walletName, addr)
+	if !ok {
+		return nil, "", xerrors.Errorf("not found wallet %s address %s", walletName, addr)
+	}
+	sig, err := walletService.signAndVerify(ctx, walletName, addrInfo.WalletClient, addr, data, meta)
+	if err == nil || !walletService.cfg.SignFailover.Enable || !(isTransportError(err) || xerrors.Is(err, ErrInvalidSignature)) {
+		return sig, walletName, err
+	}
+
+	for _, name := range walletService.failoverWallets(walletName, addr) {
+		addrInfo, ok := walletService.GetAddressInfo(name, addr)
+		if !ok {
+			continue
+		}
+		walletService.log.Warnf("wallet %s sign failed %v, failover to wallet %s", walletName, err, name)
+		sig, signErr := walletService.signAndVerify(ctx, name, addrInfo.WalletClient, addr, data, meta)
+		if signErr == nil {
+			return sig, name, nil
+		}
+		walletService.log.Errorf("wallet %s sign failed %v", name, signErr)
+	}
+
+	return nil, "", err
+}
+
+// failoverWallets return the alive and available online wallets holding addr except walletName, wallets in config order come first
+func (walletService *WalletService) failoverWallets(walletName string, addr address.Address) []string {
+	walletService.l.RLock()
+	defer walletService.l.RUnlock()
+
+	candidates := make(map[string]struct{})
+	for name, walletInfo := range walletService.walletInfos {
+		if name == walletName || walletInfo.walletState != types.Alive || walletInfo.offline ||
+			(walletInfo.health != nil && walletInfo.health.allow() != nil) {
+			continue
+		}
+		if addrInfo, ok := walletInfo.addressInfos[addr]; ok && addrInfo != nil && addrInfo.State == types.Alive {
+			candidates[name] = struct{}{}
+		}
+	}
+
+	names := make([]string, 0, len(candidates))
+	for _, name := range walletService.cfg.SignFailover.Order {
+		if _, ok := candidates[name]; ok {
+			names = append(names, name)
+			delete(candidates, name)
+		}
+	}
+	rest := make([]string, 0, len(candidates))
+	for name := range candidates {
+		rest = append(rest, name)
+	}
+	sort.Strings(rest)
+
+	return append(names, rest...)
+}
+
+// isTransportError check whether err means the wallet can't be reached, not the wallet refused the call
+func isTransportError(err error) bool {
+	if xerrors.Is(err, ErrWalletUnavailable) {
+		return true
+	}
+	var clientErr *jsonrpc.ErrClient
+	return xerrors.As(err, &clientErr)
+}
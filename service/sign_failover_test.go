@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-jsonrpc"
+	"github.com/filecoin-project/venus-wallet/core"
+	"github.com/filecoin-project/venus/pkg/crypto"
+	venusTypes "github.com/filecoin-project/venus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+type failedSignWallet struct {
+	IWalletClient
+	err error
+}
+
+func (w *failedSignWallet) WalletSign(ctx context.Context, addr address.Address, data []byte, meta core.MsgMeta) (*crypto.Signature, error) {
+	return nil, w.err
+}
+
+func addTestWallet(t *testing.T, walletService *WalletService, walletName string, cli IWalletClient) {
+	walletService.addWallet(walletName, &WalletInfo{
+		walletCli:    cli,
+		cliClose:     func() {},
+		walletState:  types.Alive,
+		addressInfos: make(map[address.Address]*AddressInfo),
+	})
+	info, ok := walletService.getWalletInfo(walletName)
+	require.True(t, ok)
+	require.NoError(t, walletService.ProcessWallet(context.Background(), walletName, info.walletCli))
+}
+
+// setWalletClient replace the client of wallet and its addresses
+func setWalletClient(walletService *WalletService, walletName string, cli IWalletClient) {
+	walletService.l.Lock()
+	defer walletService.l.Unlock()
+	walletInfo := walletService.walletInfos[walletName]
+	walletInfo.walletCli = cli
+	for _, addrInfo := range walletInfo.addressInfos {
+		addrInfo.WalletClient = cli
+	}
+}
+
+func TestSignFailover(t *testing.T) {
+	ctx := context.Background()
+	ms, _, walletName, from := setupMockMessageService(t)
+	walletService := ms.walletService
+	info, ok := walletService.getWalletInfo(walletName)
+	require.True(t, ok)
+	memWallet := info.walletCli
+
+	// the wallet of messages is down, backup wallets hold the same key
+	down := &failedSignWallet{IWalletClient: memWallet, err: &jsonrpc.ErrClient{}}
+	setWalletClient(walletService, walletName, down)
+	addTestWallet(t, walletService, "backup-a", memWallet)
+	addTestWallet(t, walletService, "backup-b", memWallet)
+
+	data := []byte("data")
+	_, _, err := walletService.WalletSign(ctx, walletName, from, data, core.MsgMeta{})
+	assert.True(t, isTransportError(err))
+
+	walletService.cfg.SignFailover.Enable = true
+	sig, signer, err := walletService.WalletSign(ctx, walletName, from, data, core.MsgMeta{})
+	assert.NoError(t, err)
+	assert.NotNil(t, sig)
+	assert.Equal(t, "backup-a", signer)
+
+	walletService.cfg.SignFailover.Order = []string{"backup-b"}
+	msg := &types.Message{
+		ID:              types.NewUUID().String(),
+		UnsignedMessage: venusTypes.UnsignedMessage{From: from, To: from},
+		WalletName:      walletName,
+	}
+	_, err = ToSignedMsg(ctx, walletService, msg)
+	assert.NoError(t, err)
+	assert.Equal(t, "backup-b", msg.SignerWallet)
+	assert.Equal(t, types.FillMsg, msg.State)
+
+	// removed wallets and forbidden addresses are not used
+	walletService.mutatorAddressInfo("backup-b", from, func(addressInfo *AddressInfo) {
+		addressInfo.State = types.Forbiden
+	})
+	info, ok = walletService.getWalletInfo("backup-a")
+	require.True(t, ok)
+	info.walletState = types.Removing
+	_, _, err = walletService.WalletSign(ctx, walletName, from, data, core.MsgMeta{})
+	assert.Error(t, err)
+
+	// errors returned by the wallet itself don't fail over
+	info.walletState = types.Alive
+	down.err = xerrors.New("key locked")
+	_, _, err = walletService.WalletSign(ctx, walletName, from, data, core.MsgMeta{})
+	assert.EqualError(t, err, "key locked")
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+// signLimiter limit the concurrent sign requests and the sign requests per second of a wallet,
+// requests over the limits wait in queue until ctx is done
+type signLimiter struct {
+	lk sync.Mutex
+	// nil means unlimited, it is replaced when the limit changes and requests release the slot to the one they acquired,
+	// so requests running before the change are not counted by the new limit
+	sem chan struct{}
+	// minimal interval between the start of requests, 0 means unlimited
+	interval time.Duration
+	// the earliest time the next request can start
+	next time.Time
+
+	waiting      int
+	running      int
+	signed       uint64
+	wait         time.Duration
+	latency      time.Duration
+	totalLatency time.Duration
+}
+
+func newSignLimiter(concurrency int, rate float64) *signLimiter {
+	l := &signLimiter{}
+	l.setLimit(concurrency, rate)
+	return l
+}
+
+func checkSignLimit(concurrency int, rate float64) error {
+	if concurrency < 0 {
+		return xerrors.Errorf("sign concurrency %d must not be negative", concurrency)
+	}
+	if rate < 0 {
+		return xerrors.Errorf("sign rate %v must not be negative", rate)
+	}
+	return nil
+}
+
+func (l *signLimiter) setLimit(concurrency int, rate float64) {
+	l.lk.Lock()
+	defer l.lk.Unlock()
+	l.sem = nil
+	if concurrency > 0 {
+		l.sem = make(chan struct{}, concurrency)
+	}
+	l.interval = 0
+	if rate > 0 {
+		l.interval = time.Duration(float64(time.Second) / rate)
+	}
+}
+
+// acquire wait for the limits, the returned release must be called when the request is done
+func (l *signLimiter) acquire(ctx context.Context) (func(), error) {
+	start := time.Now()
+	l.lk.Lock()
+	l.waiting++
+	sem := l.sem
+	var delay, interval time.Duration
+	var reserved time.Time
+	if l.interval > 0 {
+		if l.next.Before(start) {
+			l.next = start
+		}
+		delay = l.next.Sub(start)
+		interval = l.interval
+		l.next = l.next.Add(interval)
+		reserved = l.next
+	}
+	l.lk.Unlock()
+
+	err := func() error {
+		if delay > 0 {
+			timer := time.NewTimer(delay)
+			defer timer.Stop()
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if sem != nil {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	}()
+
+	l.lk.Lock()
+	l.waiting--
+	if err != nil {
+		// give back the rate slot if no request reserved one after it
+		if interval > 0 && l.next.Equal(reserved) {
+			l.next = l.next.Add(-interval)
+		}
+		l.lk.Unlock()
+		return nil, err
+	}
+	l.running++
+	l.wait = time.Since(start)
+	l.lk.Unlock()
+
+	callStart := time.Now()
+	return func() {
+		latency := time.Since(callStart)
+		if sem != nil {
+			<-sem
+		}
+		l.lk.Lock()
+		defer l.lk.Unlock()
+		l.running--
+		l.signed++
+		l.latency = latency
+		l.totalLatency += latency
+	}, nil
+}
+
+func (l *signLimiter) snapshot() *types.WalletSignStats {
+	l.lk.Lock()
+	defer l.lk.Unlock()
+	stats := &types.WalletSignStats{
+		Waiting: l.waiting,
+		Running: l.running,
+		Signed:  l.signed,
+		Wait:    l.wait,
+		Latency: l.latency,
+	}
+	if l.signed > 0 {
+		stats.AvgLatency = l.totalLatency / time.Duration(l.signed)
+	}
+	return stats
+}
+
+// SetWalletSignLimit change the sign limits of wallet, requests waiting for the old limits are not affected
+func (walletService *WalletService) SetWalletSignLimit(ctx context.Context, walletName string, concurrency int, rate float64) (struct{}, error) {
+	if err := checkSignLimit(concurrency, rate); err != nil {
+		return struct{}{}, err
+	}
+	walletInfo, ok := walletService.getWalletInfo(walletName)
+	if !ok {
+		return struct{}{}, xerrors.Errorf("wallet %s not exist", walletName)
+	}
+	if err := walletService.repo.WalletRepo().UpdateSignLimit(walletName, concurrency, rate); err != nil {
+		return struct{}{}, err
+	}
+	walletInfo.signLimiter.setLimit(concurrency, rate)
+	walletService.log.Infof("set sign limit of wallet %s, concurrency %d, rate %v/s", walletName, concurrency, rate)
+
+	return struct{}{}, nil
+}
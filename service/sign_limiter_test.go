@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/venus-wallet/core"
+	"github.com/filecoin-project/venus/pkg/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowSignWallet record the max number of concurrent sign requests
+type slowSignWallet struct {
+	IWalletClient
+	delay time.Duration
+
+	running, maxRunning int32
+}
+
+func (w *slowSignWallet) WalletSign(ctx context.Context, addr address.Address, data []byte, meta core.MsgMeta) (*crypto.Signature, error) {
+	running := atomic.AddInt32(&w.running, 1)
+	defer atomic.AddInt32(&w.running, -1)
+	for {
+		max := atomic.LoadInt32(&w.maxRunning)
+		if running <= max || atomic.CompareAndSwapInt32(&w.maxRunning, max, running) {
+			break
+		}
+	}
+	time.Sleep(w.delay)
+	return w.IWalletClient.WalletSign(ctx, addr, data, meta)
+}
+
+func TestSignLimit(t *testing.T) {
+	ctx := context.Background()
+	ms, _, walletName, from := setupMockMessageService(t)
+	ws := ms.walletService
+	info, ok := ws.getWalletInfo(walletName)
+	require.True(t, ok)
+	slow := &slowSignWallet{IWalletClient: info.walletCli, delay: 20 * time.Millisecond}
+	addTestWallet(t, ws, "slow", slow)
+	slowInfo, ok := ws.getWalletInfo("slow")
+	require.True(t, ok)
+
+	_, err := ws.SetWalletSignLimit(ctx, "slow", -1, 0)
+	assert.Error(t, err)
+	_, err = ws.SetWalletSignLimit(ctx, "not-exist", 1, 0)
+	assert.Error(t, err)
+
+	signAll := func(n int) {
+		var wg sync.WaitGroup
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := slowInfo.walletCli.WalletSign(ctx, from, []byte("data"), core.MsgMeta{})
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+	}
+
+	_, err = ws.SetWalletSignLimit(ctx, "slow", 2, 0)
+	require.NoError(t, err)
+	signAll(6)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&slow.maxRunning))
+	stats := slowInfo.signLimiter.snapshot()
+	assert.Equal(t, uint64(6), stats.Signed)
+	assert.Zero(t, stats.Waiting)
+	assert.Zero(t, stats.Running)
+	assert.GreaterOrEqual(t, int64(stats.AvgLatency), int64(slow.delay))
+
+	// 5 requests start in 4 intervals
+	_, err = ws.SetWalletSignLimit(ctx, "slow", 0, 20)
+	require.NoError(t, err)
+	start := time.Now()
+	signAll(5)
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(4*50*time.Millisecond-10*time.Millisecond))
+
+	// request waiting for the limits gives up when ctx is done
+	_, err = ws.SetWalletSignLimit(ctx, "slow", 0, 0.001)
+	require.NoError(t, err)
+	_, err = slowInfo.walletCli.WalletSign(ctx, from, []byte("data"), core.MsgMeta{})
+	require.NoError(t, err)
+	timeoutCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	_, err = slowInfo.walletCli.WalletSign(timeoutCtx, from, []byte("data"), core.MsgMeta{})
+	assert.Error(t, err)
+	assert.Zero(t, slowInfo.signLimiter.snapshot().Waiting)
+}
+
+func TestSignLimitCancel(t *testing.T) {
+	ctx := context.Background()
+	l := newSignLimiter(0, 10)
+	start := time.Now()
+	release, err := l.acquire(ctx)
+	require.NoError(t, err)
+	release()
+
+	// the rate slot of cancelled request is given back
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = l.acquire(timeoutCtx)
+	assert.Error(t, err)
+
+	release, err = l.acquire(ctx)
+	require.NoError(t, err)
+	release()
+	assert.Less(t, int64(time.Since(start)), int64(180*time.Millisecond))
+	assert.Equal(t, uint64(2), l.snapshot().Signed)
+}
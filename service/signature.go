@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/venus-wallet/core"
+	"github.com/filecoin-project/venus/pkg/crypto"
+	venusTypes "github.com/filecoin-project/venus/pkg/types"
+	"golang.org/x/xerrors"
+)
+
+var ErrInvalidSignature = xerrors.New("invalid signature")
+
+// signAndVerify sign data by the wallet client and verify the signature against addr,
+// the wallet is flagged if the signature is not made by the key of addr
+func (walletService *WalletService) signAndVerify(ctx context.Context, walletName string, cli IWalletClient, addr address.Address, data []byte, meta core.MsgMeta) (*crypto.Signature, error) {
+	sig, err := cli.WalletSign(ctx, addr, data, meta)
+	if err != nil {
+		return nil, err
+	}
+	if err := walletService.verifySignature(ctx, addr, data, sig); err != nil {
+		if xerrors.Is(err, ErrInvalidSignature) {
+			walletService.flagInvalidSignature(walletName, err)
+		}
+		return nil, err
+	}
+	return sig, nil
+}
+
+// verifySignature check sig is made by the key of addr, an id address is resolved to its key address
+func (walletService *WalletService) verifySignature(ctx context.Context, addr address.Address, data []byte, sig *crypto.Signature) error {
+	keyAddr := addr
+	if addr.Protocol() == address.ID {
+		var err error
+		keyAddr, err = walletService.nodeClient.StateAccountKey(ctx, addr, venusTypes.EmptyTSK)
+		if err != nil {
+			return xerrors.Errorf("resolve key address of %s: %w", addr, err)
+		}
+	}
+	if sig == nil {
+		return xerrors.Errorf("%w: wallet returned no signature for %s", ErrInvalidSignature, keyAddr)
+	}
+	if err := crypto.ValidateSignature(data, keyAddr, *sig); err != nil {
+		return xerrors.Errorf("%w: not signed by %s: %v", ErrInvalidSignature, keyAddr, err)
+	}
+	return nil
+}
+
+// flagInvalidSignature record the invalid signature in the health of wallet and stop using the wallet until the cool-off period ends
+func (walletService *WalletService) flagInvalidSignature(walletName string, err error) {
+	walletService.log.Errorf("wallet %s returned invalid signature, skip it for %ds: %v", walletName, walletService.cfg.Health.CoolOff, err)
+	walletInfo, ok := walletService.getWalletInfo(walletName)
+	if !ok || walletInfo.health == nil {
+		return
+	}
+	walletInfo.health.recordInvalidSignature(err)
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/venus-wallet/core"
+	"github.com/filecoin-project/venus/pkg/crypto"
+	venusTypes "github.com/filecoin-project/venus/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+// wrongKeyWallet sign by another key of the wallet
+type wrongKeyWallet struct {
+	IWalletClient
+	key address.Address
+}
+
+func (w *wrongKeyWallet) WalletSign(ctx context.Context, addr address.Address, data []byte, meta core.MsgMeta) (*crypto.Signature, error) {
+	return w.IWalletClient.WalletSign(ctx, w.key, data, meta)
+}
+
+func TestSignatureVerification(t *testing.T) {
+	ctx := context.Background()
+	ms, node, walletName, from := setupMockMessageService(t)
+	walletService := ms.walletService
+	walletService.cfg.Health.CoolOff = 60
+	info, ok := walletService.getWalletInfo(walletName)
+	require.True(t, ok)
+	memWallet := info.walletCli
+
+	// the misconfigured wallet holds from, but signs with other key
+	local := openTestLocalWallet(t, filepath.Join(t.TempDir(), "keystore"), "passphrase")
+	other, err := local.WalletNew(ctx, crypto.SigTypeSecp256k1)
+	require.NoError(t, err)
+	addTestWallet(t, walletService, "wrong-key", &wrongKeyWallet{IWalletClient: memWallet, key: from})
+	walletService.mutatorAddressInfo("wrong-key", from, func(addressInfo *AddressInfo) {
+		addressInfo.WalletClient = &wrongKeyWallet{IWalletClient: local, key: other}
+	})
+
+	data := []byte("data")
+	_, _, err = walletService.WalletSign(ctx, "wrong-key", from, data, core.MsgMeta{})
+	assert.True(t, xerrors.Is(err, ErrInvalidSignature))
+	assert.False(t, walletService.WalletAvailable("wrong-key"))
+	info, ok = walletService.getWalletInfo("wrong-key")
+	require.True(t, ok)
+	health := info.health.snapshot()
+	assert.Equal(t, types.WalletCircuitOpen, health.State)
+	assert.Equal(t, 1, health.InvalidSignatures)
+	assert.Contains(t, health.LastError, "invalid signature")
+
+	// the message is not filled by the invalid signature
+	msg := &types.Message{
+		ID:              types.NewUUID().String(),
+		UnsignedMessage: venusTypes.UnsignedMessage{From: from, To: from},
+		WalletName:      "wrong-key",
+	}
+	_, err = ToSignedMsg(ctx, walletService, msg)
+	assert.Error(t, err)
+	assert.Nil(t, msg.Signature)
+	assert.NotEqual(t, types.FillMsg, msg.State)
+
+	// another wallet holding the key signs when failover is enabled
+	walletService.cfg.SignFailover.Enable = true
+	_, err = ToSignedMsg(ctx, walletService, msg)
+	assert.NoError(t, err)
+	assert.Equal(t, walletName, msg.SignerWallet)
+	assert.Equal(t, types.FillMsg, msg.State)
+
+	// id address is verified by its key address
+	idAddr, err := address.NewIDAddress(1234)
+	require.NoError(t, err)
+	sig, err := memWallet.WalletSign(ctx, from, data, core.MsgMeta{})
+	require.NoError(t, err)
+	assert.Error(t, walletService.verifySignature(ctx, idAddr, data, sig))
+	node.SetAccountKey(idAddr, from)
+	assert.NoError(t, walletService.verifySignature(ctx, idAddr, data, sig))
+	assert.True(t, xerrors.Is(walletService.verifySignature(ctx, other, data, sig), ErrInvalidSignature))
+}
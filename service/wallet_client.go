@@ -3,12 +3,16 @@ package service
 import (
 	"context"
 	"net/http"
+	"strings"
 
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-jsonrpc"
 	"github.com/filecoin-project/venus-wallet/api/remotecli/httpparse"
 	"github.com/filecoin-project/venus-wallet/core"
 	"github.com/filecoin-project/venus/pkg/crypto"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus-messager/config"
 )
 
 type IWalletClient interface {
@@ -17,13 +21,36 @@ type IWalletClient interface {
 	WalletSign(ctx context.Context, signer address.Address, toSign []byte, meta core.MsgMeta) (*crypto.Signature, error)
 }
 
+type AddressEventType int
+
+const (
+	AddressAdded AddressEventType = iota + 1
+	AddressRemoved
+)
+
+// AddressEvent is pushed by wallet when an address is added or removed
+type AddressEvent struct {
+	Type    AddressEventType `json:"type"`
+	Address address.Address  `json:"address"`
+}
+
+var ErrEventsNotSupported = xerrors.New("wallet does not support address events")
+
+// IWalletEventClient is implemented by wallets which push address events, the channel is closed when the connection is lost.
+// Remote wallet support it over websocket if it serves WalletAddressEvents
+type IWalletEventClient interface {
+	WalletAddressEvents(ctx context.Context) (<-chan AddressEvent, error)
+}
+
 var _ IWalletClient = (*WalletClient)(nil)
+var _ IWalletEventClient = (*WalletClient)(nil)
 
 type WalletClient struct {
 	Internal struct {
-		WalletList func(context.Context) ([]address.Address, error)
-		WalletHas  func(ctx context.Context, address address.Address) (bool, error)
-		WalletSign func(ctx context.Context, signer address.Address, toSign []byte, meta core.MsgMeta) (*crypto.Signature, error)
+		WalletList          func(context.Context) ([]address.Address, error)
+		WalletHas           func(ctx context.Context, address address.Address) (bool, error)
+		WalletSign          func(ctx context.Context, signer address.Address, toSign []byte, meta core.MsgMeta) (*crypto.Signature, error)
+		WalletAddressEvents func(ctx context.Context) (<-chan AddressEvent, error)
 	}
 }
 
@@ -39,6 +66,10 @@ func (walletClient *WalletClient) WalletSign(ctx context.Context, signer address
 	return walletClient.Internal.WalletSign(ctx, signer, toSign, meta)
 }
 
+func (walletClient *WalletClient) WalletAddressEvents(ctx context.Context) (<-chan AddressEvent, error) {
+	return walletClient.Internal.WalletAddressEvents(ctx)
+}
+
 func NewWalletClient(ctx context.Context, url, token string) (WalletClient, jsonrpc.ClientCloser, error) {
 	headers := http.Header{}
 	if len(token) != 0 {
@@ -56,3 +87,27 @@ func NewWalletClient(ctx context.Context, url, token string) (WalletClient, json
 	closer, err := jsonrpc.NewMergeClient(ctx, addr, "Filecoin", []interface{}{&res.Internal}, headers)
 	return res, closer, err
 }
+
+// newWalletClient open the keystore directory under the root dir of keystoreCfg if url starts with LocalWalletScheme,
+// hold the listed addresses if url starts with OfflineWalletScheme, otherwise connect to the remote wallet
+func newWalletClient(ctx context.Context, url, token string, keystoreCfg *config.KeystoreConfig) (IWalletClient, jsonrpc.ClientCloser, error) {
+	if IsOfflineWalletUrl(url) {
+		offlineWallet, err := NewOfflineWallet(strings.TrimPrefix(url, OfflineWalletScheme))
+		if err != nil {
+			return nil, nil, err
+		}
+		return offlineWallet, func() {}, nil
+	}
+	if IsLocalWalletUrl(url) {
+		localWallet, err := openConfiguredLocalWallet(keystoreCfg, url)
+		if err != nil {
+			return nil, nil, err
+		}
+		return localWallet, func() {}, nil
+	}
+	cli, closer, err := NewWalletClient(ctx, url, token)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &cli, closer, nil
+}
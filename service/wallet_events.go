@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+// max interval of subscribing address events again
+const maxResubscribeInterval = 5 * time.Minute
+
+// rpcMethodNotFound is the json-rpc error code of calling a method not served
+const rpcMethodNotFound = "RPC error (-32601)"
+
+// isMethodNotFound return true if the remote wallet doesn't serve the method, go-jsonrpc doesn't export its error type
+func isMethodNotFound(err error) bool {
+	return err != nil && strings.Contains(err.Error(), rpcMethodNotFound)
+}
+
+// subscribeWallet keep the address event subscription of wallet until ctx is done,
+// the wallet is polled by listenWalletChange while it is not subscribed
+func (walletService *WalletService) subscribeWallet(ctx context.Context, walletName string, walletInfo *WalletInfo) {
+	walletService.l.RLock()
+	cli := walletInfo.walletCli
+	walletService.l.RUnlock()
+	eventCli, ok := cli.(IWalletEventClient)
+	if !ok {
+		return
+	}
+
+	minInterval := time.Duration(walletService.cfg.ScanInterval) * time.Second
+	if minInterval <= 0 {
+		minInterval = time.Second * 10
+	}
+	interval := minInterval
+	for {
+		err := walletService.listenAddressEvents(ctx, walletName, walletInfo, cli, eventCli)
+		if atomic.SwapInt32(&walletInfo.subscribed, 0) == 1 {
+			// the subscription worked before it was lost, back off from the start
+			interval = minInterval
+		}
+		if ctx.Err() != nil || xerrors.Is(err, ErrEventsNotSupported) {
+			return
+		}
+		walletService.log.Infof("address events of wallet %s unavailable, poll it instead: %v", walletName, err)
+
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return
+		}
+		if interval *= 2; interval > maxResubscribeInterval {
+			interval = maxResubscribeInterval
+		}
+	}
+}
+
+func (walletService *WalletService) listenAddressEvents(ctx context.Context, walletName string, walletInfo *WalletInfo, cli IWalletClient, eventCli IWalletEventClient) error {
+	events, err := eventCli.WalletAddressEvents(ctx)
+	if err != nil {
+		if isMethodNotFound(err) {
+			return xerrors.Errorf("wallet %s %v: %w", walletName, err, ErrEventsNotSupported)
+		}
+		return err
+	}
+	// catch up the changes before subscribed
+	if err := walletService.ProcessWallet(ctx, walletName, cli); err != nil {
+		return err
+	}
+	atomic.StoreInt32(&walletInfo.subscribed, 1)
+	walletService.log.Infof("subscribed address events of wallet %s", walletName)
+
+	for event := range events {
+		switch event.Type {
+		case AddressAdded:
+			if err := walletService.addWalletAddress(ctx, walletName, cli, event.Address); err != nil {
+				walletService.log.Errorf("wallet %s add address %s failed %v", walletName, event.Address, err)
+			}
+		case AddressRemoved:
+			walletService.removeWalletAddress(walletName, event.Address)
+		default:
+			walletService.log.Warnf("unknown address event %d of wallet %s", event.Type, walletName)
+		}
+	}
+
+	return xerrors.New("address event channel closed")
+}
+
+func (walletService *WalletService) isSubscribed(walletName string) bool {
+	walletInfo, ok := walletService.getWalletInfo(walletName)
+	return ok && atomic.LoadInt32(&walletInfo.subscribed) == 1
+}
+
+// ScanWallet refresh the addresses of wallet now and return the addresses not removed
+func (walletService *WalletService) ScanWallet(ctx context.Context, walletName string) ([]address.Address, error) {
+	walletInfo, ok := walletService.getWalletInfo(walletName)
+	if !ok {
+		return nil, xerrors.Errorf("wallet %s not exist", walletName)
+	}
+	if err := walletService.ProcessWallet(ctx, walletName, walletInfo.walletCli); err != nil {
+		return nil, err
+	}
+
+	addrs := make([]address.Address, 0)
+	for addr := range walletService.listOneWalletAddress(walletName) {
+		if addrInfo, ok := walletService.GetAddressInfo(walletName, addr); ok && addrInfo.State != types.Removing {
+			addrs = append(addrs, addr)
+		}
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		return addrs[i].String() < addrs[j].String()
+	})
+	return addrs, nil
+}
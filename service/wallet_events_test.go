@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-jsonrpc"
+	"github.com/filecoin-project/venus-wallet/core"
+	"github.com/filecoin-project/venus/pkg/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus-messager/config"
+)
+
+type eventWallet struct {
+	IWalletClient
+	events chan AddressEvent
+}
+
+func (w *eventWallet) WalletAddressEvents(ctx context.Context) (<-chan AddressEvent, error) {
+	return w.events, nil
+}
+
+func TestWalletAddressEvents(t *testing.T) {
+	ctx := context.Background()
+	ms, _, _, _ := setupMockMessageServiceWithConfig(t, &config.WalletConfig{ScanInterval: 1})
+	walletService := ms.walletService
+
+	local := openTestLocalWallet(t, filepath.Join(t.TempDir(), "keystore"), "passphrase")
+	addr1, err := local.WalletNew(ctx, crypto.SigTypeSecp256k1)
+	require.NoError(t, err)
+	wallet := &eventWallet{IWalletClient: local, events: make(chan AddressEvent)}
+	addTestWallet(t, walletService, "events", wallet)
+	require.Eventually(t, func() bool {
+		return walletService.isSubscribed("events")
+	}, time.Second*5, time.Millisecond*10)
+	assert.True(t, walletService.HasAddress("events", addr1))
+
+	addr2, err := local.WalletNew(ctx, crypto.SigTypeSecp256k1)
+	require.NoError(t, err)
+	wallet.events <- AddressEvent{Type: AddressAdded, Address: addr2}
+	wallet.events <- AddressEvent{Type: AddressRemoved, Address: addr1}
+	require.Eventually(t, func() bool {
+		return !walletService.HasAddress("events", addr1)
+	}, time.Second*5, time.Millisecond*10)
+	assert.True(t, walletService.HasAddress("events", addr2))
+
+	// fall back to polling when the subscription is lost
+	close(wallet.events)
+	require.Eventually(t, func() bool {
+		return !walletService.isSubscribed("events")
+	}, time.Second*5, time.Millisecond*10)
+
+	addr3, err := local.WalletNew(ctx, crypto.SigTypeSecp256k1)
+	require.NoError(t, err)
+	addrs, err := walletService.ScanWallet(ctx, "events")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []address.Address{addr1, addr2, addr3}, addrs)
+
+	_, err = walletService.ScanWallet(ctx, "not exist")
+	assert.Error(t, err)
+}
+
+// noEventsWalletAPI is a remote wallet api without WalletAddressEvents
+type noEventsWalletAPI struct {
+	local *LocalWallet
+}
+
+func (w *noEventsWalletAPI) WalletList(ctx context.Context) ([]address.Address, error) {
+	return w.local.WalletList(ctx)
+}
+
+func (w *noEventsWalletAPI) WalletHas(ctx context.Context, addr address.Address) (bool, error) {
+	return w.local.WalletHas(ctx, addr)
+}
+
+func (w *noEventsWalletAPI) WalletSign(ctx context.Context, signer address.Address, toSign []byte, meta core.MsgMeta) (*crypto.Signature, error) {
+	return w.local.WalletSign(ctx, signer, toSign, meta)
+}
+
+func TestWalletWithoutAddressEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ms, _, _, _ := setupMockMessageServiceWithConfig(t, &config.WalletConfig{ScanInterval: 1})
+	walletService := ms.walletService
+
+	local := openTestLocalWallet(t, filepath.Join(t.TempDir(), "keystore"), "passphrase")
+	addr, err := local.WalletNew(ctx, crypto.SigTypeSecp256k1)
+	require.NoError(t, err)
+	rpcServer := jsonrpc.NewServer()
+	rpcServer.Register("Filecoin", &noEventsWalletAPI{local: local})
+	mux := http.NewServeMux()
+	mux.Handle("/rpc/v0", rpcServer)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	cli, closer, err := NewWalletClient(ctx, "ws://"+srv.Listener.Addr().String(), "")
+	require.NoError(t, err)
+	defer closer()
+	addTestWallet(t, walletService, "remote", &cli)
+	assert.True(t, walletService.HasAddress("remote", addr))
+
+	info, ok := walletService.getWalletInfo("remote")
+	require.True(t, ok)
+	err = walletService.listenAddressEvents(ctx, "remote", info, info.walletCli, info.walletCli.(IWalletEventClient))
+	assert.True(t, xerrors.Is(err, ErrEventsNotSupported), err)
+
+	// the wallet is polled instead of subscribing it again and again
+	done := make(chan struct{})
+	go func() {
+		walletService.subscribeWallet(ctx, "remote", info)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second * 5):
+		t.Fatal("subscribe wallet without address events doesn't return")
+	}
+	assert.False(t, walletService.isSubscribed("remote"))
+}
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/venus-wallet/core"
+	"github.com/filecoin-project/venus/pkg/crypto"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus-messager/config"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+var ErrWalletUnavailable = xerrors.New("wallet is unavailable")
+
+// walletHealth record the result of calls to a wallet, the circuit is opened after repeated failures
+// and calls are rejected until the cool-off period ends, then the next call decides whether to close it
+type walletHealth struct {
+	cfg *config.WalletHealthConfig
+
+	lk        sync.Mutex
+	state     types.WalletHealthState
+	lastErr   error
+	latency   time.Duration
+	failures  int
+	lastCall  time.Time
+	openUntil time.Time
+	// number of signatures not made by the key of the signing address
+	invalidSignatures int
+}
+
+func newWalletHealth(cfg *config.WalletHealthConfig) *walletHealth {
+	return &walletHealth{cfg: cfg, state: types.WalletHealthy}
+}
+
+func (h *walletHealth) allow() error {
+	h.lk.Lock()
+	defer h.lk.Unlock()
+	if h.state == types.WalletCircuitOpen && time.Now().Before(h.openUntil) {
+		return xerrors.Errorf("%w until %s, last error: %v", ErrWalletUnavailable, h.openUntil.Format(time.RFC3339), h.lastErr)
+	}
+	return nil
+}
+
+// record return true when the circuit is opened by this failure
+func (h *walletHealth) record(latency time.Duration, err error) bool {
+	h.lk.Lock()
+	defer h.lk.Unlock()
+	h.latency = latency
+	h.lastCall = time.Now()
+	if err == nil {
+		h.state = types.WalletHealthy
+		h.failures = 0
+		return false
+	}
+
+	h.lastErr = err
+	h.failures++
+	if h.cfg.FailureThreshold > 0 && h.failures >= h.cfg.FailureThreshold {
+		h.state = types.WalletCircuitOpen
+		h.openUntil = h.lastCall.Add(time.Duration(h.cfg.CoolOff) * time.Second)
+		return true
+	}
+	h.state = types.WalletUnhealthy
+	return false
+}
+
+// recordInvalidSignature open the circuit at once, a wallet signing with wrong keys must not be used until it is checked
+func (h *walletHealth) recordInvalidSignature(err error) {
+	h.lk.Lock()
+	defer h.lk.Unlock()
+	h.lastErr = err
+	h.invalidSignatures++
+	h.state = types.WalletCircuitOpen
+	h.openUntil = time.Now().Add(time.Duration(h.cfg.CoolOff) * time.Second)
+}
+
+func (h *walletHealth) snapshot() *types.WalletHealth {
+	h.lk.Lock()
+	defer h.lk.Unlock()
+	health := &types.WalletHealth{
+		State:     h.state,
+		Latency:   h.latency,
+		Failures:  h.failures,
+		LastCall:  h.lastCall,
+		OpenUntil: h.openUntil,
+
+		InvalidSignatures: h.invalidSignatures,
+	}
+	if h.lastErr != nil {
+		health.LastError = h.lastErr.Error()
+	}
+	return health
+}
+
+var _ IWalletClient = (*healthWalletClient)(nil)
+var _ IWalletEventClient = (*healthWalletClient)(nil)
+
+// healthWalletClient apply the call timeout and circuit breaker to calls of the wallet client,
+// only errors meaning the wallet can't be reached are counted as failures.
+// Sign requests wait for the sign limits of wallet before the call, the waiting time is not limited by the call timeout
+type healthWalletClient struct {
+	walletName string
+	cli        IWalletClient
+	health     *walletHealth
+	limiter    *signLimiter
+	timeout    time.Duration
+	log        *logrus.Logger
+}
+
+func (c *healthWalletClient) call(ctx context.Context, f func(ctx context.Context) error) error {
+	if err := c.health.allow(); err != nil {
+		return xerrors.Errorf("wallet %s %w", c.walletName, err)
+	}
+
+	callCtx := ctx
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	start := time.Now()
+	err := f(callCtx)
+	latency := time.Since(start)
+	if err != nil && ctx.Err() == nil && callCtx.Err() == context.DeadlineExceeded {
+		err = xerrors.Errorf("call wallet %s timeout after %v %v: %w", c.walletName, c.timeout, err, ErrWalletUnavailable)
+	}
+
+	var failure error
+	if err != nil && isTransportError(err) {
+		failure = err
+	}
+	if c.health.record(latency, failure) {
+		c.log.Warnf("wallet %s failed %d times, skip it for %ds, last error: %v", c.walletName,
+			c.health.cfg.FailureThreshold, c.health.cfg.CoolOff, err)
+	}
+	return err
+}
+
+func (c *healthWalletClient) WalletList(ctx context.Context) ([]address.Address, error) {
+	var addrs []address.Address
+	err := c.call(ctx, func(ctx context.Context) error {
+		var err error
+		addrs, err = c.cli.WalletList(ctx)
+		return err
+	})
+	return addrs, err
+}
+
+func (c *healthWalletClient) WalletHas(ctx context.Context, addr address.Address) (bool, error) {
+	var has bool
+	err := c.call(ctx, func(ctx context.Context) error {
+		var err error
+		has, err = c.cli.WalletHas(ctx, addr)
+		return err
+	})
+	return has, err
+}
+
+func (c *healthWalletClient) WalletSign(ctx context.Context, addr address.Address, data []byte, meta core.MsgMeta) (*crypto.Signature, error) {
+	release, err := c.limiter.acquire(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("wallet %s wait for sign limit %w", c.walletName, err)
+	}
+	defer release()
+
+	var sig *crypto.Signature
+	err = c.call(ctx, func(ctx context.Context) error {
+		var err error
+		sig, err = c.cli.WalletSign(ctx, addr, data, meta)
+		return err
+	})
+	return sig, err
+}
+
+// WalletAddressEvents is not limited by the call timeout, the subscription lasts until ctx is done
+func (c *healthWalletClient) WalletAddressEvents(ctx context.Context) (<-chan AddressEvent, error) {
+	eventCli, ok := c.cli.(IWalletEventClient)
+	if !ok {
+		return nil, ErrEventsNotSupported
+	}
+	if err := c.health.allow(); err != nil {
+		return nil, xerrors.Errorf("wallet %s %w", c.walletName, err)
+	}
+	return eventCli.WalletAddressEvents(ctx)
+}
+
+// WalletAvailable return false if the circuit of wallet is open
+func (walletService *WalletService) WalletAvailable(walletName string) bool {
+	walletInfo, ok := walletService.getWalletInfo(walletName)
+	if !ok {
+		return false
+	}
+	return walletInfo.health == nil || walletInfo.health.allow() == nil
+}
+
+// CanSign check whether the message of addr in wallet walletName can be signed now,
+// by the wallet itself or a failover wallet
+func (walletService *WalletService) CanSign(walletName string, addr address.Address) bool {
+	if walletService.WalletAvailable(walletName) {
+		return true
+	}
+	return walletService.cfg.SignFailover.Enable && len(walletService.failoverWallets(walletName, addr)) > 0
+}
+
+func (walletService *WalletService) probeWallets(ctx context.Context) {
+	interval := time.Duration(walletService.cfg.Health.ProbeInterval) * time.Second
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			walletNames, clis := walletService.ListWalletClient()
+			for i, cli := range clis {
+				if _, err := cli.WalletList(ctx); err != nil {
+					walletService.log.Warnf("probe wallet %s failed %v", walletNames[i], err)
+				}
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/venus-wallet/core"
+	"github.com/filecoin-project/venus/pkg/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus-messager/models/repo"
+	"github.com/filecoin-project/venus-messager/types"
+)
+
+type hangingWallet struct {
+	IWalletClient
+	hang bool
+}
+
+func (w *hangingWallet) WalletSign(ctx context.Context, addr address.Address, data []byte, meta core.MsgMeta) (*crypto.Signature, error) {
+	if w.hang {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return w.IWalletClient.WalletSign(ctx, addr, data, meta)
+}
+
+func TestWalletHealth(t *testing.T) {
+	ctx := context.Background()
+	ms, _, walletName, from := setupMockMessageService(t)
+	walletService := ms.walletService
+	walletService.cfg.CallTimeout = 1
+	walletService.cfg.Health.FailureThreshold = 2
+	walletService.cfg.Health.CoolOff = 60
+
+	info, ok := walletService.getWalletInfo(walletName)
+	require.True(t, ok)
+	wallet := &hangingWallet{IWalletClient: info.walletCli, hang: true}
+	addTestWallet(t, walletService, "hanging", wallet)
+	require.NoError(t, walletService.repo.WalletRepo().SaveWallet(&types.Wallet{
+		ID:        types.NewUUID(),
+		Name:      "hanging",
+		Url:       "/ip4/127.0.0.1/tcp/5678/http",
+		Token:     "token",
+		State:     types.Alive,
+		IsDeleted: repo.NotDeleted,
+		CreatedAt: time.Now(),
+	}))
+	addrInfo, ok := walletService.GetAddressInfo("hanging", from)
+	require.True(t, ok)
+
+	// calls time out and open the circuit after repeated failures
+	for i := 0; i < 2; i++ {
+		_, err := addrInfo.WalletClient.WalletSign(ctx, from, []byte("data"), core.MsgMeta{})
+		assert.True(t, xerrors.Is(err, ErrWalletUnavailable))
+	}
+	assert.False(t, walletService.WalletAvailable("hanging"))
+	assert.False(t, walletService.CanSign("hanging", from))
+	start := time.Now()
+	wallet.hang = false
+	_, err := addrInfo.WalletClient.WalletSign(ctx, from, []byte("data"), core.MsgMeta{})
+	assert.True(t, xerrors.Is(err, ErrWalletUnavailable))
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+
+	wallets, err := walletService.ListWallet(ctx)
+	require.NoError(t, err)
+	require.Len(t, wallets, 1)
+	require.NotNil(t, wallets[0].Health)
+	assert.Equal(t, types.WalletCircuitOpen, wallets[0].Health.State)
+	assert.Equal(t, 2, wallets[0].Health.Failures)
+	assert.Contains(t, wallets[0].Health.LastError, "timeout")
+
+	// the wallet of message is skipped, another wallet holding the address signs
+	walletService.cfg.SignFailover.Enable = true
+	assert.True(t, walletService.CanSign("hanging", from))
+	_, signer, err := walletService.WalletSign(ctx, "hanging", from, []byte("data"), core.MsgMeta{})
+	assert.NoError(t, err)
+	assert.Equal(t, walletName, signer)
+
+	// the circuit is closed by a successful call after the cool-off period
+	info, ok = walletService.getWalletInfo("hanging")
+	require.True(t, ok)
+	info.health.lk.Lock()
+	info.health.openUntil = time.Now()
+	info.health.lk.Unlock()
+	assert.True(t, walletService.WalletAvailable("hanging"))
+	_, err = addrInfo.WalletClient.WalletSign(ctx, from, []byte("data"), core.MsgMeta{})
+	assert.NoError(t, err)
+	health := info.health.snapshot()
+	assert.Equal(t, types.WalletHealthy, health.State)
+	assert.Equal(t, 0, health.Failures)
+
+	// errors returned by the wallet are not failures
+	_, err = addrInfo.WalletClient.WalletSign(ctx, address.TestAddress, []byte("data"), core.MsgMeta{})
+	assert.Error(t, err)
+	assert.Equal(t, types.WalletHealthy, info.health.snapshot().State)
+}
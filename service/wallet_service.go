@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"sync"
 	"time"
 
@@ -44,6 +46,15 @@ type WalletInfo struct {
 	cliClose     jsonrpc.ClientCloser
 	walletState  types.State
 	addressInfos map[address.Address]*AddressInfo
+	health       *walletHealth
+	// stop the address event subscription
+	stopEvents context.CancelFunc
+	// set when address events are subscribed, the wallet is not polled
+	subscribed int32
+	// messages of offline wallet are signed by exporting and importing
+	offline bool
+	// limit the sign requests to wallet
+	signLimiter *signLimiter
 }
 
 type pendingAddr struct {
@@ -77,7 +88,13 @@ func NewWalletService(repo repo.Repo,
 }
 
 func (walletService *WalletService) SaveWallet(ctx context.Context, wallet *types.Wallet) (types.UUID, error) {
-	cli, close, err := NewWalletClient(ctx, wallet.Url, wallet.Token)
+	if err := checkSignLimit(wallet.SignConcurrency, wallet.SignRate); err != nil {
+		return types.UUID{}, err
+	}
+	if IsLocalWalletUrl(wallet.Url) && len(wallet.Token) > 0 {
+		return types.UUID{}, xerrors.New("passphrase of keystore is read from the config of messager, don't pass it as token")
+	}
+	cli, close, err := newWalletClient(ctx, wallet.Url, wallet.Token, &walletService.cfg.Keystore)
 	if err != nil {
 		return types.UUID{}, err
 	}
@@ -101,13 +118,14 @@ func (walletService *WalletService) SaveWallet(ctx context.Context, wallet *type
 		return types.UUID{}, err
 	}
 	walletService.addWallet(wallet.Name, &WalletInfo{
-		walletCli:    &cli,
+		walletCli:    cli,
 		cliClose:     close,
 		walletState:  wallet.State,
 		addressInfos: make(map[address.Address]*AddressInfo),
+		signLimiter:  newSignLimiter(wallet.SignConcurrency, wallet.SignRate),
 	})
 	walletService.setWallets(wallet.Name, wallet.ID)
-	walletService.log.Infof("save wallet %v", wallet)
+	walletService.log.Infof("save wallet %s %s", wallet.Name, wallet.Url)
 
 	return wallet.ID, nil
 }
@@ -125,7 +143,19 @@ func (walletService *WalletService) HasWallet(ctx context.Context, name string)
 }
 
 func (walletService *WalletService) ListWallet(ctx context.Context) ([]*types.Wallet, error) {
-	return walletService.repo.WalletRepo().ListWallet()
+	wallets, err := walletService.repo.WalletRepo().ListWallet()
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range wallets {
+		if walletInfo, ok := walletService.getWalletInfo(w.Name); ok {
+			if walletInfo.health != nil {
+				w.Health = walletInfo.health.snapshot()
+			}
+			w.SignStats = walletInfo.signLimiter.snapshot()
+		}
+	}
+	return wallets, nil
 }
 
 func (walletService *WalletService) ListRemoteWalletAddress(ctx context.Context, walletName string) ([]address.Address, error) {
@@ -160,7 +190,25 @@ func (walletService *WalletService) HasWalletAddress(ctx context.Context, wallet
 }
 
 func (walletService *WalletService) SetSelectMsgNum(ctx context.Context, walletName string, addr address.Address, num uint64) (address.Address, error) {
-	if err := walletService.repo.WalletAddressRepo().UpdateSelectMsgNum(walletService.getWalletID(walletName), walletService.getAddressID(addr), num); err != nil {
+	return walletService.SetSelectMsgNumWithComment(ctx, walletName, addr, num, "")
+}
+
+// SetSelectMsgNumWithComment set select message number of address and save it as a new version with the comment
+func (walletService *WalletService) SetSelectMsgNumWithComment(ctx context.Context, walletName string, addr address.Address, num uint64, comment string) (address.Address, error) {
+	walletID, addrID := walletService.getWalletID(walletName), walletService.getAddressID(addr)
+	err := walletService.repo.Transaction(func(txRepo repo.TxRepo) error {
+		wa, err := txRepo.WalletAddressRepo().GetWalletAddress(walletID, addrID)
+		if err != nil {
+			return xerrors.Errorf("get address %s of wallet %s %w", addr, walletName, err)
+		}
+		if err := txRepo.WalletAddressRepo().UpdateSelectMsgNum(walletID, addrID, num); err != nil {
+			return err
+		}
+		_, err = saveParamsVersion(ctx, txRepo, types.SelMsgNumTarget(walletName, addr),
+			types.SelMsgNumValue{SelMsgNum: wa.SelMsgNum}, types.SelMsgNumValue{SelMsgNum: num}, comment)
+		return err
+	})
+	if err != nil {
 		return addr, err
 	}
 	walletService.mutatorAddressInfo(walletName, addr, func(addressInfo *AddressInfo) {
@@ -170,6 +218,25 @@ func (walletService *WalletService) SetSelectMsgNum(ctx context.Context, walletN
 	return addr, nil
 }
 
+// RollbackSelectMsgNum set select message number of address to the value of version, the rollback is saved as a new version
+func (walletService *WalletService) RollbackSelectMsgNum(ctx context.Context, walletName string, addr address.Address, version int64, comment string) (uint64, error) {
+	v, err := walletService.repo.ParamsVersionRepo().GetParamsVersion(types.SelMsgNumTarget(walletName, addr), version)
+	if err != nil {
+		return 0, xerrors.Errorf("get version %d of select message number %w", version, err)
+	}
+	var value types.SelMsgNumValue
+	if err := json.Unmarshal(v.Value, &value); err != nil {
+		return 0, err
+	}
+	if len(comment) == 0 {
+		comment = fmt.Sprintf("rollback to version %d", version)
+	}
+	if _, err := walletService.SetSelectMsgNumWithComment(ctx, walletName, addr, value.SelMsgNum, comment); err != nil {
+		return 0, err
+	}
+	return value.SelMsgNum, nil
+}
+
 func (walletService *WalletService) ListWalletAddress(ctx context.Context) ([]*types.WalletAddress, error) {
 	return walletService.repo.WalletAddressRepo().ListWalletAddress()
 }
@@ -194,7 +261,7 @@ func (walletService *WalletService) start() error {
 		return err
 	}
 	for _, w := range walletList {
-		cli, close, err := NewWalletClient(context.Background(), w.Url, w.Token)
+		cli, close, err := newWalletClient(context.Background(), w.Url, w.Token, &walletService.cfg.Keystore)
 		if err != nil {
 			return err
 		}
@@ -206,16 +273,17 @@ func (walletService *WalletService) start() error {
 					addressInfos[addr] = &AddressInfo{
 						State:        wa.AddressState,
 						SelectMsgNum: wa.SelMsgNum,
-						WalletClient: &cli,
+						WalletClient: cli,
 					}
 				}
 			}
 		}
 		walletService.addWallet(w.Name, &WalletInfo{
-			walletCli:    &cli,
+			walletCli:    cli,
 			cliClose:     close,
 			walletState:  w.State,
 			addressInfos: addressInfos,
+			signLimiter:  newSignLimiter(w.SignConcurrency, w.SignRate),
 		})
 		walletService.setWallets(w.Name, w.ID)
 	}
@@ -231,6 +299,7 @@ func (walletService *WalletService) start() error {
 	go walletService.listenWalletChange(context.TODO())
 	go walletService.checkWalletState()
 	go walletService.checkAddressState()
+	go walletService.probeWallets(context.TODO())
 
 	return nil
 }
@@ -249,6 +318,10 @@ func (walletService *WalletService) listenWalletChange(ctx context.Context) {
 		case <-ticker.C:
 			walletNames, clis := walletService.ListWalletClient()
 			for i, cli := range clis {
+				// addresses of wallet pushing events are up to date
+				if walletService.isSubscribed(walletNames[i]) {
+					continue
+				}
 				if err := walletService.ProcessWallet(ctx, walletNames[i], cli); err != nil {
 					walletService.log.Errorf("process wallet failed %v %v", walletNames[i], err)
 				}
@@ -272,36 +345,47 @@ func (walletService *WalletService) ProcessWallet(ctx context.Context, walletNam
 	walletAddrs := walletService.listOneWalletAddress(walletName)
 	for _, addr := range addrList {
 		delete(walletAddrs, addr)
-
-		if addrInfo, ok := walletService.GetAddressInfo(walletName, addr); ok &&
-			(addrInfo.State == types.Alive || addrInfo.State == types.Forbiden) {
-			continue
-		}
-		// store address
-		if err := walletService.saveAddress(ctx, addr); err != nil {
-			walletService.log.Errorf("save address %v", err)
-			continue
-		}
-
-		if err := walletService.updateWalletAddress(ctx, cli, walletName, addr); err != nil {
-			walletService.log.Errorf("save wallet address %v", err)
-			continue
+		if err := walletService.addWalletAddress(ctx, walletName, cli, addr); err != nil {
+			walletService.log.Errorf("wallet %s add address %s failed %v", walletName, addr, err)
 		}
-		walletService.log.Infof("wallet %s add address %s", walletName, addr.String())
 	}
 
 	// address to handle remote wallet deletion
 	for addr := range walletAddrs {
-		addrInfo, ok := walletService.GetAddressInfo(walletName, addr)
-		if !ok || addrInfo.State == types.Removing {
-			continue
-		}
-		walletService.delAddress(walletName, addr)
+		walletService.removeWalletAddress(walletName, addr)
 	}
 
 	return nil
 }
 
+// addWalletAddress record the address found in wallet, the address already alive or forbidden is skipped
+func (walletService *WalletService) addWalletAddress(ctx context.Context, walletName string, cli IWalletClient, addr address.Address) error {
+	if addrInfo, ok := walletService.GetAddressInfo(walletName, addr); ok &&
+		(addrInfo.State == types.Alive || addrInfo.State == types.Forbiden) {
+		return nil
+	}
+	// store address
+	if err := walletService.saveAddress(ctx, addr); err != nil {
+		return xerrors.Errorf("save address %w", err)
+	}
+
+	if err := walletService.updateWalletAddress(ctx, cli, walletName, addr); err != nil {
+		return xerrors.Errorf("save wallet address %w", err)
+	}
+	walletService.log.Infof("wallet %s add address %s", walletName, addr.String())
+
+	return nil
+}
+
+// removeWalletAddress handle the address removed from wallet
+func (walletService *WalletService) removeWalletAddress(walletName string, addr address.Address) {
+	addrInfo, ok := walletService.GetAddressInfo(walletName, addr)
+	if !ok || addrInfo.State == types.Removing {
+		return
+	}
+	walletService.delAddress(walletName, addr)
+}
+
 // update address table
 func (walletService *WalletService) saveAddress(ctx context.Context, addr address.Address) error {
 	var nonce uint64
@@ -496,11 +580,41 @@ func (walletService *WalletService) ActiveAddress(ctx context.Context, walletNam
 
 /// wallet info ///
 
+// addWallet guard the wallet client by the call timeout and circuit breaker
 func (walletService *WalletService) addWallet(walletName string, walletInfo *WalletInfo) {
-	walletService.l.Lock()
-	defer walletService.l.Unlock()
+	_, walletInfo.offline = walletInfo.walletCli.(*OfflineWallet)
+	walletInfo.health = newWalletHealth(&walletService.cfg.Health)
+	if walletInfo.signLimiter == nil {
+		walletInfo.signLimiter = newSignLimiter(0, 0)
+	}
+	walletInfo.walletCli = &healthWalletClient{
+		walletName: walletName,
+		cli:        walletInfo.walletCli,
+		health:     walletInfo.health,
+		limiter:    walletInfo.signLimiter,
+		timeout:    time.Duration(walletService.cfg.CallTimeout) * time.Second,
+		log:        walletService.log,
+	}
+	for _, addrInfo := range walletInfo.addressInfos {
+		addrInfo.WalletClient = walletInfo.walletCli
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	walletInfo.stopEvents = cancel
 
+	walletService.l.Lock()
+	if old, ok := walletService.walletInfos[walletName]; ok && old.stopEvents != nil {
+		old.stopEvents()
+	}
 	walletService.walletInfos[walletName] = walletInfo
+	walletService.l.Unlock()
+
+	go walletService.subscribeWallet(ctx, walletName, walletInfo)
+}
+
+// IsOfflineWallet check whether the messages of wallet are signed offline
+func (walletService *WalletService) IsOfflineWallet(walletName string) bool {
+	walletInfo, ok := walletService.getWalletInfo(walletName)
+	return ok && walletInfo.offline
 }
 
 func (walletService *WalletService) getWalletInfo(walletName string) (*WalletInfo, bool) {
@@ -609,6 +723,9 @@ func (walletService *WalletService) deleteWallet(walletName string) {
 	var addrs []address.Address
 	if info, ok := walletService.walletInfos[walletName]; ok {
 		info.walletState = types.Removing
+		if info.stopEvents != nil {
+			info.stopEvents()
+		}
 		walletService.walletDelChan <- walletName
 		for addr := range info.addressInfos {
 			addrs = append(addrs, addr)
@@ -626,6 +743,9 @@ func (walletService *WalletService) removeWallet(walletName string) {
 	defer walletService.l.Unlock()
 	// close client
 	if walletInfo, ok := walletService.walletInfos[walletName]; ok {
+		if walletInfo.stopEvents != nil {
+			walletInfo.stopEvents()
+		}
 		walletInfo.cliClose()
 	}
 	delete(walletService.walletInfos, walletName)
@@ -1,3 +1,23 @@
 package types
 
+import "context"
+
 type Arguments struct{}
+
+// CallerKey is the context key of the api caller verified by jwt filter
+type CallerKey struct{}
+
+// Caller is the identity of api caller
+type Caller struct {
+	Name string `json:"name"`
+	Perm string `json:"perm"`
+	IP   string `json:"ip"`
+}
+
+// CallerFromContext return the caller set by jwt filter, an empty caller is returned for internal calls
+func CallerFromContext(ctx context.Context) *Caller {
+	if caller, ok := ctx.Value(CallerKey{}).(*Caller); ok {
+		return caller
+	}
+	return &Caller{}
+}
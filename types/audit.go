@@ -0,0 +1,33 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditLog is the record of an administrative api call
+type AuditLog struct {
+	ID     UUID   `json:"id"`
+	Caller string `json:"caller"`
+	Perm   string `json:"perm"`
+	IP     string `json:"ip"`
+	Method string `json:"method"`
+	// message id, address or name of the changed object
+	Target string          `json:"target"`
+	Args   json.RawMessage `json:"args"`
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+	// error returned by the call, empty if it succeeded
+	Error     string    `json:"error"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// AuditLogFilter zero value of field disable the condition, logs are listed from the latest
+type AuditLogFilter struct {
+	Caller string    `json:"caller"`
+	Method string    `json:"method"`
+	Target string    `json:"target"`
+	Start  time.Time `json:"start"` // include
+	End    time.Time `json:"end"`   // exclude
+	Limit  int       `json:"limit"`
+}
@@ -0,0 +1,19 @@
+package types
+
+import (
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/ipfs/go-cid"
+)
+
+// MpoolConflict a different message with the same from and nonce as the local message was found in the mpool of node
+type MpoolConflict struct {
+	ID        string          `json:"id"` // local message id
+	From      address.Address `json:"from"`
+	Nonce     uint64          `json:"nonce"`
+	SignedCid cid.Cid         `json:"signedCid"` // local message signed cid
+	PoolCid   cid.Cid         `json:"poolCid"`   // signed cid of the message in mpool
+	Node      string          `json:"node"`
+	FoundAt   time.Time       `json:"foundAt"`
+}
@@ -20,13 +20,14 @@ const (
 	FailedMsg
 	ReplacedMsg
 	NoWalletMsg
+	OfflineSignMsg // nonce and gas are assigned, waiting for the signature from offline wallet
 )
 
 //						---> FailedMsg <------
 //					    |					 |
 // 				UnFillMsg ---------------> FillMsg --------> OnChainMsg
-//						|					 |
-//		 NoWalletMsg <---				     ---->ReplacedMsg
+//						|	|				 ^	 |
+//		 NoWalletMsg <---	 --> OfflineSignMsg --	 ---->ReplacedMsg
 //
 
 type MessageWithUID struct {
@@ -48,8 +49,12 @@ type Message struct {
 	TipSetKey  venusTypes.TipSetKey
 	Meta       *MsgMeta
 	WalletName string
+	// the wallet actually signed the message, differs from WalletName when signing failed over to another wallet
+	SignerWallet string
 
 	State MessageState
+	// increased by every update of the message in db, an update based on an older revision is rejected
+	Revision int64
 
 	CreatedAt time.Time
 	UpdatedAt time.Time
@@ -82,7 +87,36 @@ func MsgStateToString(state MessageState) string {
 		return "ReplacedMsg"
 	case NoWalletMsg:
 		return "NoWalletMsg"
+	case OfflineSignMsg:
+		return "OfflineSignMsg"
 	default:
 		return "UnKnown"
 	}
 }
+
+// BackfillResult statistics of rebuilding message state from chain data
+type BackfillResult struct {
+	FromHeight abi.ChainEpoch `json:"fromHeight"`
+	ToHeight   abi.ChainEpoch `json:"toHeight"`
+	TipsetNum  int            `json:"tipsetNum"`  // number of tipset processed
+	OnChainNum int            `json:"onChainNum"` // number of message sent by local address found on chain
+	ReplaceNum int            `json:"replaceNum"` // number of local message replaced by other message
+}
+
+// ArchiveResult statistics of moving finished messages out of the messages table
+type ArchiveResult struct {
+	Archived int      `json:"archived"`
+	Target   string   `json:"target"`
+	Files    []string `json:"files,omitempty"` // compressed jsonl files written when target is file, one for each batch
+}
+
+// HeadQueueState state of the head changes waiting to be processed
+type HeadQueueState struct {
+	QueueLen        int            `json:"queueLen"`
+	QueueSize       int            `json:"queueSize"`
+	Coalesced       uint64         `json:"coalesced"` // number of head change merged into the previous one because the queue is full
+	LatestHeight    abi.ChainEpoch `json:"latestHeight"`
+	ProcessedHeight abi.ChainEpoch `json:"processedHeight"`
+	QueueLag        abi.ChainEpoch `json:"queueLag"` // latest received height - latest processed height
+	HeadLag         abi.ChainEpoch `json:"headLag"`  // epochs of the node head behind the current time
+}
@@ -0,0 +1,113 @@
+package types
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+)
+
+// MsigProposalState is MsigProposing until the propose message is on chain, then MsigPending while waiting for approvals
+type MsigProposalState int
+
+const (
+	_ MsigProposalState = iota
+	MsigProposing
+	MsigPending
+	MsigApplied
+	MsigCanceled
+	MsigFailed
+)
+
+//                    ---> MsigApplied
+//                    |
+// MsigProposing ---> MsigPending ---> MsigCanceled
+//      |                 |
+//       ---> MsigFailed <-
+//
+
+type MsigApprovalState int
+
+const (
+	_ MsigApprovalState = iota
+	MsigApprovalPending
+	MsigApprovalConfirmed
+	MsigApprovalFailed
+)
+
+// MsigApproval is an approve message pushed by the messager
+type MsigApproval struct {
+	Signer    address.Address   `json:"signer"`
+	MsgID     string            `json:"msgId"`
+	State     MsigApprovalState `json:"state"`
+	CreatedAt time.Time         `json:"createdAt"`
+}
+
+// MsigProposal is a multisig transaction proposed by the messager, the proposer is the first approver
+type MsigProposal struct {
+	// id of the propose message
+	ID   string          `json:"id"`
+	Msig address.Address `json:"msig"`
+	// transaction id in the multisig actor, -1 until the propose message is on chain
+	TxID       int64           `json:"txId"`
+	Proposer   address.Address `json:"proposer"`
+	WalletName string          `json:"walletName"`
+
+	To     address.Address `json:"to"`
+	Value  big.Int         `json:"value"`
+	Method abi.MethodNum   `json:"method"`
+	Params []byte          `json:"params"`
+
+	State       MsigProposalState `json:"state"`
+	Approvals   []*MsigApproval   `json:"approvals"`
+	CancelMsgID string            `json:"cancelMsgId"`
+	// reason of the failure of proposal, approval or cancel
+	Error string `json:"error"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// MsigProposeParams is the transaction to propose to msig by proposer in wallet
+type MsigProposeParams struct {
+	Msig       address.Address `json:"msig"`
+	To         address.Address `json:"to"`
+	Value      big.Int         `json:"value"`
+	Method     abi.MethodNum   `json:"method"`
+	Params     []byte          `json:"params"`
+	Proposer   address.Address `json:"proposer"`
+	WalletName string          `json:"walletName"`
+	Meta       *MsgMeta        `json:"meta"`
+}
+
+func MsigProposalStateToString(state MsigProposalState) string {
+	switch state {
+	case MsigProposing:
+		return "Proposing"
+	case MsigPending:
+		return "Pending"
+	case MsigApplied:
+		return "Applied"
+	case MsigCanceled:
+		return "Canceled"
+	case MsigFailed:
+		return "Failed"
+	default:
+		return fmt.Sprintf("unknow proposal state %d", state)
+	}
+}
+
+func MsigApprovalStateToString(state MsigApprovalState) string {
+	switch state {
+	case MsigApprovalPending:
+		return "Pending"
+	case MsigApprovalConfirmed:
+		return "Confirmed"
+	case MsigApprovalFailed:
+		return "Failed"
+	default:
+		return fmt.Sprintf("unknow approval state %d", state)
+	}
+}
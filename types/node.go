@@ -1,5 +1,7 @@
 package types
 
+import "golang.org/x/xerrors"
+
 type NodeType int
 
 const (
@@ -8,11 +10,40 @@ const (
 	LightNode
 )
 
+var nodeTypeStr = map[NodeType]string{
+	FullNode:  "full",
+	LightNode: "light",
+}
+
+func (nt NodeType) String() string {
+	if str, ok := nodeTypeStr[nt]; ok {
+		return str
+	}
+	// node added before node type was introduced is treated as full node
+	return nodeTypeStr[FullNode]
+}
+
+func ParseNodeType(str string) (NodeType, error) {
+	for nt, s := range nodeTypeStr {
+		if s == str {
+			return nt, nil
+		}
+	}
+	return 0, xerrors.Errorf("unknown node type %s, support full and light", str)
+}
+
 type Node struct {
 	ID UUID
 
 	Name  string
 	URL   string
 	Token string
-	Type  NodeType
+	// full node is used for state query and push message, light node only receive pushed message
+	Type NodeType
+	// weight of node when choosing a node to push message
+	Weight int
+}
+
+func (n *Node) IsFullNode() bool {
+	return n.Type != LightNode
 }
@@ -0,0 +1,22 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNodeType(t *testing.T) {
+	for _, nt := range []NodeType{FullNode, LightNode} {
+		parsed, err := ParseNodeType(nt.String())
+		assert.NoError(t, err)
+		assert.Equal(t, nt, parsed)
+	}
+
+	_, err := ParseNodeType("archive")
+	assert.Error(t, err)
+
+	// node saved without type is treated as full node
+	assert.True(t, (&Node{}).IsFullNode())
+	assert.False(t, (&Node{Type: LightNode}).IsFullNode())
+}
@@ -0,0 +1,24 @@
+package types
+
+import (
+	"github.com/filecoin-project/go-state-types/crypto"
+	venusTypes "github.com/filecoin-project/venus/pkg/types"
+	"github.com/ipfs/go-cid"
+)
+
+// OfflineSignMessage is exported to sign on the machine holding the key of offline wallet,
+// the bytes of UnsignedCid is the data to sign, Signature is filled by the signer and imported back
+type OfflineSignMessage struct {
+	ID          string                     `json:"id"`
+	WalletName  string                     `json:"walletName"`
+	Message     venusTypes.UnsignedMessage `json:"message"`
+	UnsignedCid cid.Cid                    `json:"unsignedCid"`
+	Signature   *crypto.Signature          `json:"signature,omitempty"`
+}
+
+// OfflineImportResult result of importing the signature of one message, Error is set if the signature is rejected
+type OfflineImportResult struct {
+	ID        string   `json:"id"`
+	SignedCid *cid.Cid `json:"signedCid,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
@@ -0,0 +1,39 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+)
+
+// SharedParamsTarget is the target of shared params versions
+const SharedParamsTarget = "shared_params"
+
+// SelMsgNumTarget is the target of versions of the select message number of address in wallet
+func SelMsgNumTarget(walletName string, addr address.Address) string {
+	return "sel_msg_num/" + walletName + "/" + addr.String()
+}
+
+// ParamsVersion is a saved value of params, every change of params creates a new version
+type ParamsVersion struct {
+	Target string `json:"target"`
+	// increase from 1 for each target
+	Version   int64           `json:"version"`
+	Value     json.RawMessage `json:"value"`
+	Author    string          `json:"author"`
+	Comment   string          `json:"comment"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// SelMsgNumValue is the value of select message number versions
+type SelMsgNumValue struct {
+	SelMsgNum uint64 `json:"selMsgNum"`
+}
+
+// ParamsChange is a field whose value differs between two versions
+type ParamsChange struct {
+	Field string          `json:"field"`
+	From  json.RawMessage `json:"from"`
+	To    json.RawMessage `json:"to"`
+}
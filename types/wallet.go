@@ -3,6 +3,8 @@ package types
 import (
 	"fmt"
 	"time"
+
+	"github.com/filecoin-project/go-address"
 )
 
 type State int
@@ -28,12 +30,67 @@ type Wallet struct {
 	Url   string `json:"url"`
 	Token string `json:"token"`
 	State State  `json:"state"`
+	// max number of concurrent sign requests to the wallet, 0 means unlimited
+	SignConcurrency int `json:"signConcurrency"`
+	// max number of sign requests per second to the wallet, 0 means unlimited
+	SignRate float64 `json:"signRate"`
+	// runtime health of wallet, not saved
+	Health *WalletHealth `json:"health,omitempty"`
+	// runtime sign requests of wallet, not saved
+	SignStats *WalletSignStats `json:"signStats,omitempty"`
 
 	IsDeleted int       `json:"isDeleted"` // 是否删除 1:是  -1:否
 	CreatedAt time.Time `json:"createAt"`  // 创建时间
 	UpdatedAt time.Time `json:"updateAt"`  // 更新时间
 }
 
+// RedactedToken replace the tokens of wallets and nodes returned by api, the tokens are only used by messager itself
+const RedactedToken = "******"
+
+// RedactToken return RedactedToken for non empty token
+func RedactToken(token string) string {
+	if len(token) == 0 {
+		return token
+	}
+	return RedactedToken
+}
+
+type WalletHealthState int
+
+const (
+	_ WalletHealthState = iota
+	WalletHealthy
+	WalletUnhealthy
+	WalletCircuitOpen // calls are rejected until the cool-off period ends
+)
+
+type WalletHealth struct {
+	State     WalletHealthState `json:"state"`
+	LastError string            `json:"lastError"`
+	// latency of the last call
+	Latency time.Duration `json:"latency"`
+	// number of consecutive failed calls
+	Failures  int       `json:"failures"`
+	LastCall  time.Time `json:"lastCall"`
+	OpenUntil time.Time `json:"openUntil"`
+	// number of signatures returned by the wallet which failed verification
+	InvalidSignatures int `json:"invalidSignatures"`
+}
+
+// WalletSignStats is the sign requests to a wallet limited by its SignConcurrency and SignRate
+type WalletSignStats struct {
+	// number of sign requests waiting for the limits
+	Waiting int `json:"waiting"`
+	// number of sign requests being served by the wallet
+	Running int    `json:"running"`
+	Signed  uint64 `json:"signed"`
+	// waiting time of the last sign request
+	Wait time.Duration `json:"wait"`
+	// latency of the last sign request, waiting time excluded
+	Latency    time.Duration `json:"latency"`
+	AvgLatency time.Duration `json:"avgLatency"`
+}
+
 type WalletAddress struct {
 	ID           UUID  `json:"id"` // 主键
 	WalletID     UUID  `json:"walletID"`
@@ -47,6 +104,16 @@ type WalletAddress struct {
 	UpdatedAt time.Time `json:"updateAt"`  // 更新时间
 }
 
+// MigrateAddressResult the address moved between wallets and the pending messages moved with it
+type MigrateAddressResult struct {
+	FromWallet string          `json:"fromWallet"`
+	ToWallet   string          `json:"toWallet"`
+	Address    address.Address `json:"address"`
+	SelMsgNum  uint64          `json:"selMsgNum"`
+	// ids of unfilled messages now signed by ToWallet
+	Messages []string `json:"messages"`
+}
+
 func StateToString(state State) string {
 	switch state {
 	case Alive:
@@ -61,3 +128,16 @@ func StateToString(state State) string {
 		return fmt.Sprintf("unknow state %d", state)
 	}
 }
+
+func WalletHealthStateToString(state WalletHealthState) string {
+	switch state {
+	case WalletHealthy:
+		return "Healthy"
+	case WalletUnhealthy:
+		return "Unhealthy"
+	case WalletCircuitOpen:
+		return "CircuitOpen"
+	default:
+		return fmt.Sprintf("unknow health state %d", state)
+	}
+}